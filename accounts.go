@@ -0,0 +1,91 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log"
+)
+
+// Minimum password length for new accounts, in characters
+const minPasswordLength = 6
+
+// Rounds of SHA-256 applied to a password, to slow down guessing from a
+// copied accounts file
+const passwordHashRounds = 10000
+
+// errUsernameTaken is returned when registering a name that already has an account
+var errUsernameTaken = errors.New("username already has an account")
+
+// Account is a registered user. Users who played before accounts existed
+// have no account and log in without a password.
+type Account struct {
+	Username     string `json:"username"`
+	Salt         string `json:"salt"`
+	PasswordHash string `json:"password_hash"`
+}
+
+// accountKey returns the storage key holding a user's account
+func accountKey(username string) string {
+	return "accounts/" + userFileName(username) + ".json"
+}
+
+// hashPassword hashes a password with the given salt
+func hashPassword(password, salt string) string {
+	sum := sha256.Sum256([]byte(salt + password))
+	for range passwordHashRounds - 1 {
+		sum = sha256.Sum256(sum[:])
+	}
+	return hex.EncodeToString(sum[:])
+}
+
+// loadAccount reads a user's account, returning false if they don't have one
+func loadAccount(store Storage, username string) (Account, bool) {
+	data, err := store.Load(accountKey(username))
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("loading account: %v", err)
+		}
+		return Account{}, false
+	}
+	var account Account
+	if err := json.Unmarshal(data, &account); err != nil {
+		log.Printf("loading account: %v", err)
+		return Account{}, false
+	}
+	return account, true
+}
+
+// accountExists reports whether a username is already registered
+func accountExists(store Storage, username string) bool {
+	_, found := loadAccount(store, username)
+	return found
+}
+
+// registerAccount creates an account with a freshly salted password hash
+func registerAccount(store Storage, username, password string) error {
+	if accountExists(store, username) {
+		return errUsernameTaken
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	account := Account{Username: username, Salt: hex.EncodeToString(salt)}
+	account.PasswordHash = hashPassword(password, account.Salt)
+	data, err := json.MarshalIndent(account, "", "  ")
+	if err != nil {
+		return err
+	}
+	return store.Save(accountKey(username), data)
+}
+
+// checkPassword reports whether password matches the account's
+func (a Account) checkPassword(password string) bool {
+	hash := hashPassword(password, a.Salt)
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(a.PasswordHash)) == 1
+}
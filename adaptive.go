@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log"
+)
+
+// Engine settings for each level of the adaptive difficulty, weakest first
+var adaptiveLevels = []struct {
+	depth       int
+	blunderRate float64
+}{
+	{1, 0.4},
+	{2, 0.25},
+	{3, 0.15},
+	{4, 0.05},
+	{5, 0},
+	{6, 0},
+	{7, 0},
+}
+
+// Adaptive difficulty tuning
+const (
+	adaptiveStartLevel = 3 // Level a new user starts on
+	adaptiveWindow     = 3 // Wins or losses in a row that move the level
+)
+
+// AdaptiveState is a user's adaptive difficulty level and the results since
+// it last changed, most recent last
+type AdaptiveState struct {
+	Level  int   `json:"level"`
+	Recent []int `json:"recent"` // Winners: Player, Computer or Empty for a tie
+}
+
+// adaptiveKey returns the storage key holding a user's adaptive difficulty
+func adaptiveKey(username string) string {
+	return "adaptive/" + userFileName(username) + ".json"
+}
+
+// newAdaptiveState returns the state of a user who hasn't played adaptive games
+func newAdaptiveState() AdaptiveState {
+	return AdaptiveState{Level: adaptiveStartLevel}
+}
+
+// loadAdaptive reads a user's adaptive difficulty, starting new users in the middle
+func loadAdaptive(store Storage, username string) AdaptiveState {
+	state := newAdaptiveState()
+	data, err := store.Load(adaptiveKey(username))
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("loading adaptive difficulty: %v", err)
+		}
+		return state
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		log.Printf("loading adaptive difficulty: %v", err)
+		return newAdaptiveState()
+	}
+	state.Level = max(0, min(state.Level, len(adaptiveLevels)-1))
+	return state
+}
+
+// saveAdaptive writes a user's adaptive difficulty to storage
+func saveAdaptive(store Storage, username string, state AdaptiveState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return store.Save(adaptiveKey(username), data)
+}
+
+// record adds a game's winner and moves the level up after a run of player
+// wins or down after a run of losses, returning the change. Ties break a run.
+func (a *AdaptiveState) record(winner int) int {
+	if winner == Empty {
+		a.Recent = nil
+		return 0
+	}
+	if len(a.Recent) > 0 && a.Recent[len(a.Recent)-1] != winner {
+		a.Recent = nil
+	}
+	a.Recent = append(a.Recent, winner)
+	if len(a.Recent) < adaptiveWindow {
+		return 0
+	}
+
+	a.Recent = nil
+	before := a.Level
+	if winner == Player {
+		a.Level = min(a.Level+1, len(adaptiveLevels)-1)
+	} else {
+		a.Level = max(a.Level-1, 0)
+	}
+	return a.Level - before
+}
+
+// loadUserAdaptive switches to the adaptive difficulty of the user who just
+// logged in
+func (g *ConnectFourGame) loadUserAdaptive() {
+	g.adaptive = loadAdaptive(g.userStore, g.username)
+	g.setDifficulty(g.difficulty)
+}
+
+// recordAdaptiveResult moves the adaptive level after a game against the
+// computer, telling the player if it changed
+func (g *ConnectFourGame) recordAdaptiveResult(winner int) {
+	if g.difficulty != DifficultyAdaptive || g.practiceGame != nil {
+		return
+	}
+	change := g.adaptive.record(winner)
+	if err := saveAdaptive(g.userStore, g.username, g.adaptive); err != nil {
+		log.Printf("saving adaptive difficulty: %v", err)
+	}
+	g.setDifficulty(DifficultyAdaptive)
+	switch {
+	case change > 0:
+		g.showToast(tr("adaptive.raised", g.adaptive.Level+1))
+	case change < 0:
+		g.showToast(tr("adaptive.lowered", g.adaptive.Level+1))
+	}
+}
+
+// adaptiveLevelText describes the current adaptive level, e.g. "Level 4/7"
+func (g *ConnectFourGame) adaptiveLevelText() string {
+	return tr("adaptive.level", g.adaptive.Level+1, len(adaptiveLevels))
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"image/color"
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// Analysis limits: every position is solved to analysisDepth plies but gives
+// up after analysisNodeBudget positions, so long games can't hang the screen
+const (
+	analysisDepth      = solverDepth
+	analysisNodeBudget = 50000
+	analysisRows       = 12 // Moves visible at once
+)
+
+// Highlight behind moves that changed the outcome
+var colorBlunder = color.RGBA{255, 200, 200, 255}
+
+// MoveAnalysis is the solver's verdict on one move, with outcomes given from
+// the point of view of the side that moved
+type MoveAnalysis struct {
+	Move   Move
+	Cell   string // Where the piece landed, e.g. "D1"
+	Before int    // Outcome the mover could force before the move
+	After  int    // Outcome the mover could force after it
+
+	Patterns []string // Named patterns the move set up for the mover
+}
+
+// Analysis walks a game's moves, solving the position before and after each
+type Analysis struct {
+	moves   []Move
+	boards  []GameBoard // Position before each move
+	results []MoveAnalysis
+	cache   map[string]int // Solver results by position and side to move
+	rules   Rules          // The standard rules, started by the first mover
+}
+
+// NewAnalysis prepares to analyze a game, ignoring anything after an illegal move
+func NewAnalysis(moves []Move) *Analysis {
+	replay := NewReplay(moves, VariantStandard)
+	a := &Analysis{
+		moves: replay.moves,
+		cache: make(map[string]int),
+		rules: standardRules,
+	}
+	if len(a.moves) > 0 {
+		a.rules.Starter = a.moves[0].Player
+	}
+	for ply := range a.moves {
+		replay.Seek(ply)
+		a.boards = append(a.boards, replay.Board())
+	}
+	return a
+}
+
+// Done reports whether every move has been analyzed
+func (a *Analysis) Done() bool {
+	return len(a.results) == len(a.moves)
+}
+
+// Step analyzes the next move
+func (a *Analysis) Step() {
+	if a.Done() {
+		return
+	}
+
+	ply := len(a.results)
+	move := a.moves[ply]
+	board := a.boards[ply]
+	after := dropPiece(board, move.Column, move.Player)
+
+	result := MoveAnalysis{
+		Move:   move,
+		Before: a.solve(board, move.Player),
+	}
+	for row := 0; row < Rows; row++ {
+		if board.At(row, move.Column) == Empty && after.At(row, move.Column) != Empty {
+			result.Cell = cellName(row, move.Column)
+		}
+	}
+
+	result.Patterns = newPatterns(DetectPatterns(board, move.Player),
+		DetectPatterns(after, opponentOf(move.Player)), move.Player)
+
+	switch {
+	case checkWin(after, move.Player):
+		result.After = SolveWin
+	case isBoardFull(after):
+		result.After = SolveUnknown
+	default:
+		// The opponent's outcome is the reverse of the mover's
+		result.After = -a.solve(after, opponentOf(move.Player))
+	}
+
+	a.results = append(a.results, result)
+}
+
+// solve returns the outcome for the side to move, reusing earlier results.
+// The position after one move is the position before the next, so each
+// position is normally only searched once.
+func (a *Analysis) solve(board GameBoard, toMove int) int {
+	key := analysisKey(board, toMove)
+	if outcome, ok := a.cache[key]; ok {
+		return outcome
+	}
+	outcome := solveWithBudget(board, toMove, analysisDepth, analysisNodeBudget, a.rules)
+	a.cache[key] = outcome
+	return outcome
+}
+
+// analysisKey is the cache key for the outcome of board with toMove to move
+func analysisKey(board GameBoard, toMove int) string {
+	return FormatBoard(board) + " " + strconv.Itoa(toMove)
+}
+
+// Flagged reports whether the move threw away part of the mover's outcome
+func (m MoveAnalysis) Flagged() bool {
+	return m.After < m.Before
+}
+
+// outcomeText describes a solver outcome for use in a sentence
+func outcomeText(outcome int) string {
+	switch outcome {
+	case SolveWin:
+		return tr("analysis.win")
+	case SolveLoss:
+		return tr("analysis.loss")
+	default:
+		return tr("analysis.unknown")
+	}
+}
+
+// describe returns a one-line summary of the move, explaining what changed
+// if it was flagged
+func (m MoveAnalysis) describe(ply int) string {
+	mover := "you"
+	if m.Move.Player == Computer {
+		mover = "computer"
+	}
+	line := tr("analysis.played_"+mover, ply+1, m.Cell)
+	if m.Flagged() {
+		line = tr("analysis.flagged_"+mover, ply+1, outcomeText(m.Before), m.Cell, outcomeText(m.After))
+	}
+	if len(m.Patterns) > 0 {
+		names := make([]string, len(m.Patterns))
+		for i, name := range m.Patterns {
+			names[i] = tr("pattern." + name)
+		}
+		line += tr("analysis.patterns", strings.Join(names, ", "))
+	}
+	return line
+}
+
+// openAnalysis starts analyzing the finished game and shows the analysis
+// screen. The solver only knows the standard rules.
+func (g *ConnectFourGame) openAnalysis() {
+	if gameVariant != VariantStandard {
+		g.showToast(tr("analysis.standard_only"))
+		return
+	}
+	if g.fromPosition {
+		g.showToast(tr("analysis.position_game"))
+		return
+	}
+	g.analysis = NewAnalysis(g.moves)
+	g.analysisScroll = 0
+	g.transitionTo(StateAnalysis)
+}
+
+// scrollAnalysis moves the visible window of moves, keeping it in range
+func (g *ConnectFourGame) scrollAnalysis(delta int) {
+	maxScroll := max(0, len(g.analysis.moves)-analysisRows)
+	g.analysisScroll = max(0, min(maxScroll, g.analysisScroll+delta))
+}
+
+// updateAnalysis analyzes one move per frame and scrolls the list
+func (g *ConnectFourGame) updateAnalysis() {
+	if !g.analysis.Done() {
+		g.analysis.Step()
+	}
+
+	if _, dy := ebiten.Wheel(); dy > 0 {
+		g.scrollAnalysis(-1)
+	} else if dy < 0 {
+		g.scrollAnalysis(1)
+	}
+	if action(ActionUp) {
+		g.scrollAnalysis(-1)
+	}
+	if action(ActionDown) {
+		g.scrollAnalysis(1)
+	}
+}
+
+// initAnalysisUI creates the back and scroll buttons
+func (g *ConnectFourGame) initAnalysisUI() {
+	// Back button
+	g.buttons = append(g.buttons, &Button{
+		x:    float64(g.screenWidth) - 120*g.scaleX,
+		y:    20 * g.scaleY,
+		w:    100 * g.scaleX,
+		h:    30 * g.scaleY,
+		text: tr("common.back"),
+		action: func() {
+			g.transitionTo(StateGameOver)
+		},
+	})
+
+	if len(g.analysis.moves) > analysisRows {
+		g.buttons = append(g.buttons, &Button{
+			x:    float64(g.screenWidth)/2 - 110*g.scaleX,
+			y:    520 * g.scaleY,
+			w:    100 * g.scaleX,
+			h:    30 * g.scaleY,
+			text: tr("common.up"),
+			action: func() {
+				g.scrollAnalysis(-analysisRows)
+			},
+		})
+		g.buttons = append(g.buttons, &Button{
+			x:    float64(g.screenWidth)/2 + 10*g.scaleX,
+			y:    520 * g.scaleY,
+			w:    100 * g.scaleX,
+			h:    30 * g.scaleY,
+			text: tr("common.down"),
+			action: func() {
+				g.scrollAnalysis(analysisRows)
+			},
+		})
+	}
+}
+
+// drawAnalysisScreen renders the analyzed moves, highlighting flagged ones
+func (g *ConnectFourGame) drawAnalysisScreen(screen *ebiten.Image) {
+	a := g.analysis
+	title := tr("analysis.title")
+	if !a.Done() {
+		title = tr("analysis.progress", len(a.results)+1, len(a.moves))
+	} else if len(a.moves) == 0 {
+		title = tr("analysis.empty")
+	}
+	titleBounds := boundString(fontFace, title)
+	text.Draw(screen, title, fontFace,
+		g.screenWidth/2-titleBounds.Dx()/2, int(80*g.scaleY), colorText)
+
+	x := float64(g.screenWidth)/2 - 300*g.scaleX
+	end := min(len(a.results), g.analysisScroll+analysisRows)
+	for i := g.analysisScroll; i < end; i++ {
+		result := a.results[i]
+		y := (120 + float64(i-g.analysisScroll)*30) * g.scaleY
+		if result.Flagged() {
+			drawRect(screen, x-6, y-16, 600*g.scaleX+12, 22, colorBlunder)
+		}
+		text.Draw(screen, result.describe(i), fontFace, int(x), int(y), colorText)
+	}
+
+	// Draw buttons
+	for _, btn := range g.buttons {
+		g.drawButton(screen, btn)
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// Search depth limits for API requests
+const (
+	apiDefaultDepth = 5
+	apiMaxDepth     = 9
+)
+
+// moveRequest is the body of POST /move
+type moveRequest struct {
+	Position string `json:"position"`        // Board in ParseBoard format, with the computer (O) to move
+	Depth    int    `json:"depth,omitempty"` // Search depth, apiDefaultDepth if 0
+}
+
+// moveResponse is the reply to POST /move
+type moveResponse struct {
+	Column int    `json:"column"` // 0-based column
+	Name   string `json:"name"`   // Column letter, e.g. "D"
+}
+
+// evalResponse is the reply to GET /eval
+type evalResponse struct {
+	Score int `json:"score"` // Positive favours the computer (O)
+}
+
+// newAPIHandler returns the engine's HTTP API
+func newAPIHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/move", handleMove)
+	mux.HandleFunc("/eval", handleEval)
+	return mux
+}
+
+// serveAPI runs the engine's HTTP API on addr without starting the GUI
+func serveAPI(addr string) error {
+	log.Printf("serving engine API on %s", addr)
+	return http.ListenAndServe(addr, newAPIHandler())
+}
+
+// handleMove replies with the engine's move for the computer in a position.
+// The computer has to be the side to move: X moved first if it is a piece
+// ahead, and O did if the counts are level.
+func handleMove(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeAPIError(w, http.StatusMethodNotAllowed, "use POST")
+		return
+	}
+
+	var req moveRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<10)).Decode(&req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+	board, err := ParseBoard(req.Position)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("invalid position: %v", err))
+		return
+	}
+	if standardRules.terminal(board) {
+		writeAPIError(w, http.StatusBadRequest, "the game in this position is already over")
+		return
+	}
+	if err := checkMover(board, Computer); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "it is X's turn in this position, not O's")
+		return
+	}
+	rules := Rules{Variant: VariantStandard, Starter: firstMover(board, Computer)}
+
+	depth := req.Depth
+	if depth == 0 {
+		depth = apiDefaultDepth
+	}
+	if depth < 1 || depth > apiMaxDepth {
+		writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("depth must be between 1 and %d", apiMaxDepth))
+		return
+	}
+
+	col := getComputerMove(board, depth, rules)
+	writeAPIResponse(w, moveResponse{Column: col, Name: columnName(col)})
+}
+
+// handleEval replies with the static evaluation of the position in the
+// "position" query parameter
+func handleEval(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeAPIError(w, http.StatusMethodNotAllowed, "use GET")
+		return
+	}
+
+	board, err := ParseBoard(r.URL.Query().Get("position"))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("invalid position: %v", err))
+		return
+	}
+	writeAPIResponse(w, evalResponse{Score: standardRules.evaluate(board)})
+}
+
+// writeAPIResponse sends v as a JSON reply
+func writeAPIResponse(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("writing API response: %v", err)
+	}
+}
+
+// writeAPIError sends a JSON error reply with the given status
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(map[string]string{"error": message}); err != nil {
+		log.Printf("writing API error: %v", err)
+	}
+}
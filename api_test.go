@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// callAPI sends a request to the engine's API and returns the reply's
+// status and decoded JSON body
+func callAPI(t *testing.T, method, target, body string) (int, map[string]any) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	newAPIHandler().ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+	var reply map[string]any
+	if err := json.NewDecoder(w.Body).Decode(&reply); err != nil {
+		t.Fatalf("%s %s: decoding reply: %v", method, target, err)
+	}
+	return w.Code, reply
+}
+
+func TestAPIMove(t *testing.T) {
+	// X threatens D1, so O has to block it
+	status, reply := callAPI(t, http.MethodPost, "/move",
+		`{"position": "......./......./......./......./OO...../XXX....", "depth": 5}`)
+	if status != http.StatusOK || reply["column"] != 3.0 || reply["name"] != "D" {
+		t.Errorf("blocking move: %d %v, want column 3, D", status, reply)
+	}
+
+	// With the counts level O moved first, so it is still O's turn
+	status, reply = callAPI(t, http.MethodPost, "/move", `{"position": "......./......./......./......./......./.......", "depth": 1}`)
+	if col, ok := reply["column"].(float64); status != http.StatusOK || !ok || col < 0 || col >= Columns {
+		t.Errorf("empty board: %d %v, want a column", status, reply)
+	}
+}
+
+func TestAPIRejects(t *testing.T) {
+	// Full with nobody four in a row, so drawn
+	drawn := "XXOOXXO/OOXXOOX/XXOOXXO/OOXXOOX/XXOOXXO/OOXXOOX"
+	for _, tt := range []struct {
+		name, method, target, body string
+		status                     int
+		error                      string // Part of the error reported
+	}{
+		{"move by GET", http.MethodGet, "/move", "", http.StatusMethodNotAllowed, "use POST"},
+		{"eval by POST", http.MethodPost, "/eval", "", http.StatusMethodNotAllowed, "use GET"},
+		{"malformed body", http.MethodPost, "/move", `{"position": `, http.StatusBadRequest, "invalid request body"},
+		{"malformed position", http.MethodPost, "/move", `{"position": "XO"}`, http.StatusBadRequest, "invalid position"},
+		{"floating piece", http.MethodPost, "/move", `{"position": "......./......./......./......./X....../......."}`,
+			http.StatusBadRequest, "invalid position"},
+		{"game over", http.MethodPost, "/move", `{"position": "` + drawn + `"}`, http.StatusBadRequest, "already over"},
+		{"X to move", http.MethodPost, "/move", `{"position": "......./......./......./......./......./O......"}`,
+			http.StatusBadRequest, "X's turn"},
+		{"depth too low", http.MethodPost, "/move", `{"position": "......./......./......./......./......./X......", "depth": -1}`,
+			http.StatusBadRequest, "depth must be"},
+		{"depth too high", http.MethodPost, "/move", `{"position": "......./......./......./......./......./X......", "depth": 10}`,
+			http.StatusBadRequest, "depth must be"},
+		{"malformed eval position", http.MethodGet, "/eval?position=XO", "", http.StatusBadRequest, "invalid position"},
+	} {
+		status, reply := callAPI(t, tt.method, tt.target, tt.body)
+		message, _ := reply["error"].(string)
+		if status != tt.status || !strings.Contains(message, tt.error) {
+			t.Errorf("%s: %d %q, want %d with %q", tt.name, status, message, tt.status, tt.error)
+		}
+	}
+}
+
+func TestAPIEval(t *testing.T) {
+	position := "......./......./......./......./......./...X..."
+	status, reply := callAPI(t, http.MethodGet, "/eval?position="+position, "")
+	board := positionBoard(t, position)
+	if want := float64(standardRules.evaluate(board)); status != http.StatusOK || reply["score"] != want {
+		t.Errorf("eval: %d %v, want score %v", status, reply, want)
+	}
+}
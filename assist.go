@@ -0,0 +1,111 @@
+package main
+
+import "math"
+
+// Assist mode's check of the player's moves. It runs while the click is
+// handled, so it is kept shallow and capped.
+const (
+	assistDepth      = 6
+	assistNodeBudget = 200000
+)
+
+// Most results kept in outcomeCache before it starts over
+const outcomeCacheSize = 50000
+
+// outcomeKey identifies a solved position, the rules it was solved under and
+// how deeply it was searched
+type outcomeKey struct {
+	hash    uint64
+	toMove  int
+	depth   int
+	variant int
+}
+
+// Results of positions solved for the move checks, so checking the same
+// position again costs nothing. Searches that ran out of budget aren't kept.
+// Only the game loop uses it.
+var outcomeCache = map[outcomeKey]int{}
+
+// solveCached is solveWithBudget with results cached by position. It also
+// reports whether the search finished within its budget.
+func solveCached(board GameBoard, toMove, depth, maxNodes int, rules Rules) (int, bool) {
+	key := outcomeKey{HashBoard(board), toMove, depth, rules.Variant}
+	if outcome, ok := outcomeCache[key]; ok {
+		return outcome, true
+	}
+
+	s := &search{rules: rules, maxNodes: maxNodes}
+	_, score := s.minimax(board, depth, math.Inf(-1), math.Inf(1), toMove == Computer)
+	if s.aborted {
+		return SolveUnknown, false
+	}
+	if toMove == Player {
+		score = -score
+	}
+	outcome := SolveUnknown
+	switch {
+	case math.IsInf(score, 1):
+		outcome = SolveWin
+	case math.IsInf(score, -1):
+		outcome = SolveLoss
+	}
+
+	if len(outcomeCache) >= outcomeCacheSize {
+		outcomeCache = map[outcomeKey]int{}
+	}
+	outcomeCache[key] = outcome
+	return outcome, true
+}
+
+// moveOutcome returns the proven result of the player dropping in col,
+// looking depth plies ahead under rules, from the player's point of view:
+// SolveWin, SolveLoss or SolveUnknown. It also reports whether the search
+// finished within maxNodes.
+func moveOutcome(board GameBoard, col, depth, maxNodes int, rules Rules) (int, bool) {
+	after := dropPiece(board, col, Player)
+	if checkWin(after, Player) {
+		return SolveWin, true
+	}
+	if isBoardFull(after) {
+		return SolveUnknown, true
+	}
+	// The computer's result is the opposite of the player's
+	outcome, ok := solveCached(after, Computer, depth-1, maxNodes, rules)
+	return -outcome, ok
+}
+
+// isBlunder reports whether playing col loses by force under rules while
+// another move doesn't. When every move loses, none of them is a blunder.
+func isBlunder(board GameBoard, col int, rules Rules) bool {
+	if outcome, ok := moveOutcome(board, col, assistDepth, assistNodeBudget, rules); !ok || outcome != SolveLoss {
+		return false
+	}
+	for _, other := range getValidColumns(board) {
+		if other == col {
+			continue
+		}
+		if outcome, _ := moveOutcome(board, other, assistDepth, assistNodeBudget, rules); outcome != SolveLoss {
+			return true
+		}
+	}
+	return false
+}
+
+// assistActive reports whether assist mode may help the player move now. It
+// never helps two players sharing the board, nor a player against someone
+// online, where it would be playing for them.
+func (g *ConnectFourGame) assistActive() bool {
+	return g.settings.AssistMode && !g.hotseat && g.online == nil && g.canPlayerMove()
+}
+
+// warnBlunder asks the player to confirm a losing move in assist mode,
+// reporting whether it did. The move is played if they confirm.
+func (g *ConnectFourGame) warnBlunder(col int) bool {
+	if !g.assistActive() || g.board.columnFull(col) || !isBlunder(g.board, col, currentRules()) {
+		return false
+	}
+	g.confirm(tr("assist.warning"), tr("assist.play_anyway"), tr("assist.cancel"), func() {
+		g.dropInColumn(col)
+	})
+	return true
+}
@@ -0,0 +1,69 @@
+package main
+
+import "testing"
+
+func TestIsBlunder(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		moves    string // The player to move after these
+		col      int
+		blunder  bool
+		outcome  int
+		finished bool
+	}{
+		// The computer has A1 to C1 and takes D1 unless it is blocked
+		{"leaving the three open", "515263", 0, true, SolveLoss, true},
+		{"blocking the three", "515263", 3, false, SolveUnknown, true},
+		// Open at both ends nothing saves the player, so no move is singled out
+		{"every move loses", "726374", 0, false, SolveLoss, true},
+		// Winning is never a blunder
+		{"taking the win", "121314", 0, false, SolveWin, true},
+		{"quiet opening", "", 3, false, SolveUnknown, true},
+	} {
+		board := boardFromMoves(t, tt.moves)
+		if got := isBlunder(board, tt.col, standardRules); got != tt.blunder {
+			t.Errorf("%s: isBlunder(%s) = %v, want %v", tt.name, columnName(tt.col), got, tt.blunder)
+		}
+		outcome, finished := moveOutcome(board, tt.col, assistDepth, assistNodeBudget, standardRules)
+		if outcome != tt.outcome || finished != tt.finished {
+			t.Errorf("%s: moveOutcome(%s) = %d, %v, want %d, %v",
+				tt.name, columnName(tt.col), outcome, finished, tt.outcome, tt.finished)
+		}
+	}
+}
+
+func TestWarnBlunder(t *testing.T) {
+	g := newConnectFourGame(newMemoryStorage())
+	g.frame = 1
+	g.startNewGame()
+	g.cancelComputerMove()
+	g.dropAnim = nil
+	g.settings.AssistMode = true
+	g.setBoard(boardFromMoves(t, "515263"))
+	g.turn = Player
+
+	// A safe move goes ahead without asking
+	if g.warnBlunder(3) || g.dialog != nil {
+		t.Fatal("blocking the three was questioned")
+	}
+
+	// A losing one asks first, and is played once confirmed
+	if !g.warnBlunder(0) || g.dialog == nil {
+		t.Fatal("leaving the three open wasn't questioned")
+	}
+	if g.board != boardFromMoves(t, "515263") {
+		t.Fatal("move played before it was confirmed")
+	}
+	g.dialog.buttons[0].action()
+	if want := dropPiece(boardFromMoves(t, "515263"), 0, Player); g.board != want {
+		t.Error("confirmed move not played")
+	}
+
+	// Without assist mode nothing is questioned
+	g.settings.AssistMode = false
+	g.setBoard(boardFromMoves(t, "515263"))
+	g.turn, g.dialog, g.dropAnim = Player, nil, nil
+	if g.warnBlunder(0) || g.dialog != nil {
+		t.Error("blunder questioned with assist mode off")
+	}
+}
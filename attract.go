@@ -0,0 +1,171 @@
+package main
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// Attract mode: after a while with no input on the menu, the computer plays
+// itself on a faded board behind the menu until anything is touched
+const (
+	attractIdle       = 30 * time.Second
+	attractMoveDelay  = 700 * time.Millisecond
+	attractRestDelay  = 3 * time.Second // Finished board stays up this long
+	attractNodeBudget = 3000            // Search per move, kept small so moves keep their pace
+	attractRandomPly  = 2               // Opening moves played at random, for variety
+	attractCellScale  = 0.6             // Demo board cell size relative to the game's
+	attractAlpha      = 0.35
+)
+
+// AttractDemo is a game the computer plays against itself on the menu. It
+// has its own board, so the real game is never touched.
+type AttractDemo struct {
+	board    GameBoard
+	turn     int
+	plies    int
+	over     bool
+	nextMove time.Time // When the next move is made, or a new game begun once over
+	moves    chan int  // The next move, once its search finishes
+}
+
+// newAttractDemo starts a demo game with the first move after the delay
+func newAttractDemo(now time.Time) *AttractDemo {
+	d := &AttractDemo{turn: Player, nextMove: now.Add(attractMoveDelay)}
+	d.think()
+	return d
+}
+
+// think starts choosing the next move on its own goroutine, as the game's
+// own searches run, so the demo never holds up Update. The answer channel
+// is buffered and belongs to this move, so a search outliving the demo, or
+// its game, finishes without anyone waiting for it.
+func (d *AttractDemo) think() {
+	moves := make(chan int, 1)
+	d.moves = moves
+	board, side, plies := d.board, d.turn, d.plies
+	go func() {
+		moves <- selfPlayMove(board, side, plies)
+	}()
+}
+
+// step makes the next move once it is due and its search has finished, and
+// starts over a while after the game ends
+func (d *AttractDemo) step(now time.Time) {
+	if now.Before(d.nextMove) {
+		return
+	}
+	if d.over {
+		*d = *newAttractDemo(now)
+		return
+	}
+	var col int
+	select {
+	case col = <-d.moves:
+	default:
+		return // Still searching; the move is played once it arrives
+	}
+	d.board = dropPiece(d.board, col, d.turn)
+	d.plies++
+	d.turn = opponentOf(d.turn)
+	d.nextMove = now.Add(attractMoveDelay)
+	if _, over := standardRules.gameOver(d.board); over {
+		d.over = true
+		d.nextMove = now.Add(attractRestDelay)
+		return
+	}
+	d.think()
+}
+
+// selfPlayMove picks a move for side. The engine only plays the computer's
+// pieces, so for the player it searches the board with the colors swapped,
+// which makes the computer the side that moved first.
+func selfPlayMove(board GameBoard, side, plies int) int {
+	if plies < attractRandomPly {
+		columns := getValidColumns(board)
+		return columns[rand.Intn(len(columns))]
+	}
+	rules := standardRules
+	if side == Player {
+		rules.Starter = Computer
+		for row := range Rows {
+			for col := range Columns {
+				if piece := board.At(row, col); piece != Empty {
+					board.Set(row, col, opponentOf(piece))
+				}
+			}
+		}
+	}
+	return getComputerMoveNodeBudget(board, attractNodeBudget, rules)
+}
+
+// anyInput reports whether a key, mouse button, wheel, touch or cursor
+// movement happened this frame
+func (g *ConnectFourGame) anyInput() bool {
+	x, y := ebiten.CursorPosition()
+	moved := x != g.lastCursorX || y != g.lastCursorY
+	g.lastCursorX, g.lastCursorY = x, y
+	if moved || len(inpututil.AppendJustPressedKeys(nil)) > 0 || len(inpututil.AppendJustPressedTouchIDs(nil)) > 0 {
+		return true
+	}
+	if dx, dy := ebiten.Wheel(); dx != 0 || dy != 0 {
+		return true
+	}
+	for _, button := range []ebiten.MouseButton{ebiten.MouseButtonLeft, ebiten.MouseButtonRight, ebiten.MouseButtonMiddle} {
+		if inpututil.IsMouseButtonJustPressed(button) {
+			return true
+		}
+	}
+	return false
+}
+
+// updateAttract starts the demo once the menu has sat idle long enough and
+// plays it on. It reports true when input just stopped the demo, which the
+// input then does nothing else.
+func (g *ConnectFourGame) updateAttract() bool {
+	now := time.Now()
+	if g.anyInput() || g.state != StateGameMode || g.dialog != nil || g.tutorial != nil {
+		g.lastInput = now
+		stopped := g.attract != nil
+		g.attract = nil
+		return stopped && g.state == StateGameMode
+	}
+	if g.attract == nil && now.Sub(g.lastInput) >= attractIdle {
+		g.attract = newAttractDemo(now)
+	}
+	if g.attract != nil {
+		g.attract.step(now)
+	}
+	return false
+}
+
+// drawAttract draws the demo faded behind the menu, with its board scaled
+// down and centered
+func (g *ConnectFourGame) drawAttract(screen *ebiten.Image) {
+	if g.attract == nil {
+		return
+	}
+	cellSize := g.cellSize * attractCellScale
+	w, h := int(cellSize*Columns), int(cellSize*Rows)
+	if g.attractImage == nil || g.attractImage.Bounds().Dx() != w || g.attractImage.Bounds().Dy() != h {
+		if g.attractImage != nil {
+			g.attractImage.Deallocate()
+		}
+		g.attractImage = ebiten.NewImage(w, h)
+	}
+	g.attractImage.Clear()
+	g.drawBoardAt(g.attractImage, g.attract.board, 0, 0, cellSize)
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(g.screenWidth-w)/2, float64(g.screenHeight-h)/2)
+	op.ColorScale.ScaleAlpha(attractAlpha)
+	screen.DrawImage(g.attractImage, op)
+
+	hint := tr("menu.demo")
+	hintBounds := boundString(fontFace, hint)
+	text.Draw(screen, hint, fontFace,
+		g.screenWidth/2-hintBounds.Dx()/2, g.screenHeight-int(20*g.scaleY), colorText)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAttractDemoPlaysOut(t *testing.T) {
+	now := time.Now()
+	d := newAttractDemo(now)
+	deadline := time.Now().Add(testTimeout)
+	for !d.over {
+		if time.Now().After(deadline) {
+			t.Fatalf("demo stuck after %d moves", d.plies)
+		}
+		now = now.Add(attractMoveDelay)
+		d.step(now)
+		time.Sleep(time.Millisecond) // Lets the search run, even without preemption
+	}
+
+	pieces := 0
+	for row := range Rows {
+		for col := range Columns {
+			if d.board.At(row, col) != Empty {
+				pieces++
+			}
+		}
+	}
+	if pieces != d.plies {
+		t.Errorf("%d pieces on the board after %d moves", pieces, d.plies)
+	}
+	if _, over := standardRules.gameOver(d.board); !over {
+		t.Error("demo stopped before its game was over")
+	}
+
+	// It rests on the finished board, then starts again
+	d.step(now.Add(attractMoveDelay))
+	if !d.over {
+		t.Error("demo started again without resting")
+	}
+	d.step(now.Add(attractRestDelay))
+	if d.over || d.plies != 0 {
+		t.Errorf("demo didn't start over: %d moves, over %v", d.plies, d.over)
+	}
+}
+
+func TestAttractDemoNeverWaitsForItsSearch(t *testing.T) {
+	now := time.Now()
+	d := newAttractDemo(now)
+	d.moves = make(chan int) // A search that never finishes
+	done := make(chan struct{})
+	go func() {
+		d.step(now.Add(attractMoveDelay))
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(testTimeout):
+		t.Fatal("step waited for the search")
+	}
+	if d.plies != 0 || d.board != (GameBoard{}) {
+		t.Errorf("a move was played before its search finished: %v", d.board)
+	}
+}
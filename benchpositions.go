@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Search times are grouped into doubling buckets from benchFirstBucket up
+const (
+	benchFirstBucket = time.Millisecond
+	benchBuckets     = 14 // Up to about 8 seconds, with anything longer in the last
+)
+
+// BenchResult is the computer's search of one benchmark position
+type BenchResult struct {
+	Line     int // Line of the position file
+	Position string
+	Column   int
+	Stats    SearchStats
+}
+
+// readBenchPositions reads one position string per line, skipping blank
+// lines, # comments and lines that don't hold a playable position, each of
+// which is logged
+func readBenchPositions(r io.Reader) ([]BenchResult, error) {
+	positions := []BenchResult{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		board, err := ParseBoard(text)
+		if err != nil {
+			log.Printf("skipping line %d: %v", line, err)
+			continue
+		}
+		if len(getValidColumns(board)) == 0 {
+			log.Printf("skipping line %d: the board is full", line)
+			continue
+		}
+		positions = append(positions, BenchResult{Line: line, Position: text})
+	}
+	return positions, scanner.Err()
+}
+
+// benchBucket returns the histogram bucket for a search time
+func benchBucket(d time.Duration) int {
+	bucket := 0
+	for limit := benchFirstBucket; d >= limit && bucket < benchBuckets-1; limit *= 2 {
+		bucket++
+	}
+	return bucket
+}
+
+// writeBenchCSV writes one row per searched position
+func writeBenchCSV(w io.Writer, results []BenchResult) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{"line", "position", "column", "depth", "nodes", "microseconds"})
+	for _, r := range results {
+		out.Write([]string{
+			strconv.Itoa(r.Line),
+			r.Position,
+			columnName(r.Column),
+			strconv.Itoa(r.Stats.Depth),
+			strconv.Itoa(r.Stats.Nodes),
+			strconv.FormatInt(r.Stats.Elapsed.Microseconds(), 10),
+		})
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// writeBenchHistogram writes how many searches fell in each time bucket, with
+// the total nodes searched
+func writeBenchHistogram(w io.Writer, results []BenchResult) {
+	var counts [benchBuckets]int
+	nodes := 0
+	for _, r := range results {
+		counts[benchBucket(r.Stats.Elapsed)]++
+		nodes += r.Stats.Nodes
+	}
+	limit := benchFirstBucket
+	for i, count := range counts {
+		label := "< " + limit.String()
+		if i == benchBuckets-1 {
+			label = ">= " + (limit / 2).String()
+		}
+		fmt.Fprintf(w, "%10s %5d %s\n", label, count, strings.Repeat("#", count))
+		limit *= 2
+	}
+	fmt.Fprintf(w, "%d positions, %d nodes\n", len(results), nodes)
+}
+
+// runBenchPositions searches every position in a file at depth, writing a
+// CSV of the searches to stdout and a histogram of their times to stderr, so
+// changes to the search can be compared over the same corpus
+func runBenchPositions(path string, depth int) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	results, err := readBenchPositions(f)
+	if err != nil {
+		return err
+	}
+	for i := range results {
+		board, _ := ParseBoard(results[i].Position)
+		results[i].Column, results[i].Stats = getComputerMoveStats(board, depth, standardRules)
+	}
+	if err := writeBenchCSV(os.Stdout, results); err != nil {
+		return err
+	}
+	writeBenchHistogram(os.Stderr, results)
+	return nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import "fmt"
+
+// At returns the piece at (row, col), or Empty for cells outside the board.
+// Code outside the engine reads cells through At so the board's
+// representation can change without touching it.
+func (b *GameBoard) At(row, col int) int {
+	if row < 0 || row >= Rows || col < 0 || col >= Columns {
+		return Empty
+	}
+	return b[row][col]
+}
+
+// Set puts a piece, or Empty, at (row, col)
+func (b *GameBoard) Set(row, col, piece int) error {
+	if row < 0 || row >= Rows || col < 0 || col >= Columns {
+		return fmt.Errorf("cell (%d, %d) is outside the board", row, col)
+	}
+	if piece != Empty && piece != Player && piece != Computer {
+		return fmt.Errorf("invalid piece %d", piece)
+	}
+	b[row][col] = piece
+	return nil
+}
+
+// columnFull reports whether a column has no room for another piece
+func (b *GameBoard) columnFull(col int) bool {
+	return b.At(0, col) != Empty
+}
+
+// columnSpace returns how many more pieces fit in a column
+func (b *GameBoard) columnSpace(col int) int {
+	space := 0
+	for row := 0; row < Rows && b.At(row, col) == Empty; row++ {
+		space++
+	}
+	return space
+}
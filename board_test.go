@@ -0,0 +1,46 @@
+package main
+
+import "testing"
+
+func TestBoardAccessorsCheckBounds(t *testing.T) {
+	var board GameBoard
+	board[0][0], board[Rows-1][Columns-1] = Player, Computer
+	for _, tt := range []struct {
+		row, col int
+		inside   bool
+	}{
+		{0, 0, true},
+		{Rows - 1, Columns - 1, true},
+		{-1, 0, false},
+		{0, -1, false},
+		{Rows, 0, false},
+		{0, Columns, false},
+		{-1, Columns, false},
+		{Rows + 5, -3, false},
+	} {
+		want := Empty
+		if tt.inside {
+			want = board[tt.row][tt.col]
+		}
+		if got := board.At(tt.row, tt.col); got != want {
+			t.Errorf("At(%d, %d) = %d, want %d", tt.row, tt.col, got, want)
+		}
+
+		before := board
+		err := board.Set(tt.row, tt.col, Computer)
+		if (err == nil) != tt.inside {
+			t.Errorf("Set(%d, %d) = %v, want an error only outside the board", tt.row, tt.col, err)
+		}
+		if !tt.inside && board != before {
+			t.Errorf("Set(%d, %d) outside the board changed it", tt.row, tt.col)
+		}
+		board = before
+	}
+
+	if err := board.Set(2, 2, 3); err == nil || board[2][2] != Empty {
+		t.Errorf("Set with an invalid piece = %v, left %d", err, board[2][2])
+	}
+	if err := board.Set(0, 0, Empty); err != nil || board.At(0, 0) != Empty {
+		t.Errorf("clearing a cell = %v, left %d", err, board.At(0, 0))
+	}
+}
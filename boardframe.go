@@ -0,0 +1,75 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Board frame proportions
+const (
+	boardFrameBorder = 4    // Unscaled width of the rim around the board
+	boardHoleRadius  = 0.42 // Radius of a hole as a fraction of the cell size
+)
+
+// Face of the board between the holes
+var colorBoardFace = color.RGBA{160, 160, 160, 255}
+
+// BoardFrame is the front of the board, a solid frame with see-through holes
+// that pieces are drawn behind. It is drawn once and reused until the cell
+// size or theme changes.
+type BoardFrame struct {
+	image    *ebiten.Image
+	cellSize float64
+	rim      color.RGBA // colorBoardBg when it was drawn
+}
+
+// valid reports whether the cached frame can be drawn at cellSize with the
+// current theme
+func (f *BoardFrame) valid(cellSize float64, rim color.RGBA) bool {
+	return f.image != nil && f.cellSize == cellSize && f.rim == rim
+}
+
+// boardFrameImage returns the frame for cellSize, drawing it again if the
+// window was resized or the theme changed since the cached one was drawn
+func (g *ConnectFourGame) boardFrameImage(cellSize float64) *ebiten.Image {
+	if g.boardFrame.valid(cellSize, colorBoardBg) {
+		return g.boardFrame.image
+	}
+	if g.boardFrame.image != nil {
+		g.boardFrame.image.Deallocate()
+	}
+
+	boardWidth := float64(Columns) * cellSize
+	boardHeight := float64(Rows) * cellSize
+	width := int(math.Ceil(boardWidth)) + 2*boardFrameBorder
+	height := int(math.Ceil(boardHeight)) + 2*boardFrameBorder
+
+	frame := ebiten.NewImage(width, height)
+	frame.Fill(colorBoardBg)
+	drawRect(frame, boardFrameBorder, boardFrameBorder, boardWidth, boardHeight, colorBoardFace)
+
+	// Cut the holes by drawing them onto a mask and erasing the mask's shape
+	mask := ebiten.NewImage(width, height)
+	defer mask.Deallocate()
+	for row := 0; row < Rows; row++ {
+		for col := 0; col < Columns; col++ {
+			x := int(boardFrameBorder + float64(col)*cellSize + cellSize/2)
+			y := int(boardFrameBorder + float64(row)*cellSize + cellSize/2)
+			g.drawSmoothCircle(mask, x, y, cellSize*boardHoleRadius, color.White)
+		}
+	}
+	frame.DrawImage(mask, &ebiten.DrawImageOptions{Blend: ebiten.BlendDestinationOut})
+
+	g.boardFrame = BoardFrame{image: frame, cellSize: cellSize, rim: colorBoardBg}
+	return frame
+}
+
+// drawBoardFrame draws the front of a board whose top-left cell is at
+// (offsetX, offsetY), over the pieces already drawn there
+func (g *ConnectFourGame) drawBoardFrame(target *ebiten.Image, offsetX, offsetY, cellSize float64) {
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(offsetX-boardFrameBorder, offsetY-boardFrameBorder)
+	target.DrawImage(g.boardFrameImage(cellSize), op)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestBoardFrameValid(t *testing.T) {
+	rim := color.RGBA{100, 100, 100, 255}
+	drawn := BoardFrame{image: whiteImage, cellSize: 60, rim: rim}
+	for _, tt := range []struct {
+		name     string
+		frame    BoardFrame
+		cellSize float64
+		rim      color.RGBA
+		want     bool
+	}{
+		{"unchanged", drawn, 60, rim, true},
+		{"never drawn", BoardFrame{}, 60, rim, false},
+		{"window resized", drawn, 75, rim, false},
+		{"fractional resize", drawn, 60.5, rim, false},
+		{"theme changed", drawn, 60, color.RGBA{30, 30, 30, 255}, false},
+	} {
+		if got := tt.frame.valid(tt.cellSize, tt.rim); got != tt.want {
+			t.Errorf("%s: valid = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBoardFrameRedrawnOnlyWhenStale(t *testing.T) {
+	g := newConnectFourGame(newMemoryStorage())
+	defer func(rim color.RGBA) { colorBoardBg = rim }(colorBoardBg)
+
+	frame := g.boardFrameImage(60)
+	if again := g.boardFrameImage(60); again != frame {
+		t.Error("frame redrawn with nothing changed")
+	}
+
+	resized := g.boardFrameImage(80)
+	if resized == frame {
+		t.Fatal("frame not redrawn for a new cell size")
+	}
+	if w, h := resized.Bounds().Dx(), resized.Bounds().Dy(); w != Columns*80+2*boardFrameBorder || h != Rows*80+2*boardFrameBorder {
+		t.Errorf("frame for 80px cells is %dx%d", w, h)
+	}
+
+	colorBoardBg = color.RGBA{30, 30, 30, 255}
+	if themed := g.boardFrameImage(80); themed == resized {
+		t.Error("frame not redrawn for a new theme")
+	}
+	if g.boardFrame.rim != colorBoardBg || g.boardFrame.cellSize != 80 {
+		t.Errorf("cached frame recorded as %v at %g", g.boardFrame.rim, g.boardFrame.cellSize)
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// drawCapacityBars draws a thin gauge above each column showing how much of
+// it is still free, so nearly full columns stand out at a glance. The gauges
+// sit above the coordinate labels when those are shown.
+func (g *ConnectFourGame) drawCapacityBars(screen *ebiten.Image, originX, originY float64) {
+	h := math.Max(3, g.cellSize*0.06)
+	w := g.cellSize * 0.6
+	y := originY - boardFrameBorder - 3 - h
+	if g.showCoordinates {
+		y -= float64(g.px(14))
+	}
+	for col := 0; col < Columns; col++ {
+		x := originX + float64(col)*g.cellSize + (g.cellSize-w)/2
+		drawRect(screen, x, y, w, h, colorSlotBg)
+		if space := g.board.columnSpace(col); space > 0 {
+			drawRect(screen, x, y, w*float64(space)/Rows, h, colorButton)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// Chat panel layout, in logical pixels, and the lines kept for scrollback
+const (
+	chatPanelWidth  = 150
+	chatPanelTop    = 140
+	chatLineHeight  = 16
+	chatPadding     = 6
+	chatInputHeight = 26
+	chatLogSize     = 100
+)
+
+// Chat flood limit: the server drops a player's lines past chatBurst in any
+// chatWindow
+const (
+	chatBurst  = 5
+	chatWindow = 10 * time.Second
+)
+
+// chatLimiter counts one player's recent chat for the flood limit
+type chatLimiter struct {
+	sent []time.Time // When the lines still inside the window were sent
+}
+
+// allow reports whether a line sent at now is within the limit, counting
+// it if so
+func (l *chatLimiter) allow(now time.Time) bool {
+	kept := l.sent[:0]
+	for _, t := range l.sent {
+		if now.Sub(t) < chatWindow {
+			kept = append(kept, t)
+		}
+	}
+	l.sent = kept
+	if len(l.sent) >= chatBurst {
+		return false
+	}
+	l.sent = append(l.sent, now)
+	return true
+}
+
+// Words the profanity mask hides. Only whole words are masked, so words
+// that merely contain one are left alone.
+var profanity = map[string]bool{
+	"ass": true, "bastard": true, "bitch": true, "crap": true, "damn": true,
+	"fuck": true, "fucking": true, "shit": true,
+	"cabrón": true, "joder": true, "mierda": true, "puta": true,
+}
+
+// maskProfanity replaces the letters of each listed word in s with stars
+func maskProfanity(s string) string {
+	runes := []rune(s)
+	for start := 0; start < len(runes); {
+		if !unicode.IsLetter(runes[start]) {
+			start++
+			continue
+		}
+		end := start
+		for end < len(runes) && unicode.IsLetter(runes[end]) {
+			end++
+		}
+		if profanity[strings.ToLower(string(runes[start:end]))] {
+			for i := start; i < end; i++ {
+				runes[i] = '*'
+			}
+		}
+		start = end
+	}
+	return string(runes)
+}
+
+// showsChat reports whether the chat panel belongs on the current screen
+func (g *ConnectFourGame) showsChat() bool {
+	return g.online != nil && (g.state == StateGame || g.state == StateGameOver)
+}
+
+// chatRect returns the panel's area, left of the board like the move log is
+// right of it, and false if it is closed or the window is too narrow
+func (g *ConnectFourGame) chatRect() (x, y, w, h float64, ok bool) {
+	w = float64(g.px(chatPanelWidth))
+	x = 20 * g.scaleX
+	y = chatPanelTop * g.scaleY
+	h = float64(g.screenHeight) - y - 20*g.scaleY
+	boardLeft := g.boardOffsetX - g.labelMargin()
+	ok = g.showsChat() && g.chatOpen && x+w <= boardLeft-20 && h >= float64(4*g.px(chatLineHeight))
+	return x, y, w, h, ok
+}
+
+// initChatUI adds the button opening and closing the chat panel, and the
+// panel's input while it is open
+func (g *ConnectFourGame) initChatUI() {
+	if !g.showsChat() {
+		return
+	}
+	g.buttons = append(g.buttons, &Button{
+		x:    20 * g.scaleX,
+		y:    100 * g.scaleY,
+		w:    140 * g.scaleX,
+		h:    30 * g.scaleY,
+		text: tr("chat.toggle", onOff(g.chatOpen)),
+		action: func() {
+			g.chatOpen = !g.chatOpen
+			g.initUI()
+		},
+	})
+
+	x, y, w, h, ok := g.chatRect()
+	if !ok {
+		return
+	}
+	g.textInputs = append(g.textInputs, &TextInput{
+		x:           x,
+		y:           y + h - float64(g.px(chatInputHeight)),
+		w:           w,
+		h:           float64(g.px(chatInputHeight)),
+		placeholder: tr("chat.placeholder"),
+		maxLength:   maxChatLength,
+	})
+}
+
+// sendChat sends what has been typed in the chat input to the opponent
+func (g *ConnectFourGame) sendChat(input *TextInput) {
+	line := strings.TrimSpace(input.value)
+	input.value, input.cursor, input.scrollPos = "", 0, 0
+	if line == "" || g.online == nil {
+		return
+	}
+	line = truncateText(line, maxChatLength)
+	g.online.send(ChatMessage{Text: line})
+	g.addChatLine(tr("common.you"), line)
+}
+
+// receiveChat shows a line of chat from the opponent, as a toast too while
+// the panel is closed
+func (g *ConnectFourGame) receiveChat(line string) {
+	if g.settings.MaskProfanity {
+		line = maskProfanity(line)
+	}
+	g.addChatLine(g.sideNames[1], line)
+	if _, _, _, _, ok := g.chatRect(); !ok {
+		g.showToast(tr("online.chat", g.sideNames[1], line))
+	}
+}
+
+// addChatLine adds a line to the scrollback, forgetting the oldest past
+// chatLogSize
+func (g *ConnectFourGame) addChatLine(name, line string) {
+	g.chatLog = append(g.chatLog, tr("online.chat", name, line))
+	if len(g.chatLog) > chatLogSize {
+		g.chatLog = g.chatLog[len(g.chatLog)-chatLogSize:]
+	}
+}
+
+// chatLines wraps the scrollback to lines of at most width characters
+func chatLines(log []string, width int) []string {
+	lines := []string{}
+	for _, entry := range log {
+		runes := []rune(entry)
+		for len(runes) > width {
+			lines = append(lines, string(runes[:width]))
+			runes = runes[width:]
+		}
+		lines = append(lines, string(runes))
+	}
+	return lines
+}
+
+// drawChat renders the chat panel, the latest lines above the input
+func (g *ConnectFourGame) drawChat(screen *ebiten.Image) {
+	x, y, w, h, ok := g.chatRect()
+	if !ok {
+		return
+	}
+	drawRect(screen, x, y, w, h, colorSlotBg)
+	lineHeight, padding := g.px(chatLineHeight), g.px(chatPadding)
+	text.Draw(screen, tr("chat.heading"), fontFace, int(x)+padding, int(y)+lineHeight-g.px(3), colorText)
+
+	lines := chatLines(g.chatLog, max(1, (int(w)-2*padding)/g.px(moveLogCharWidth)))
+	visible := (int(h)-g.px(chatInputHeight))/lineHeight - 1 // One line for the heading
+	first := max(0, len(lines)-visible)
+	for i := first; i < len(lines); i++ {
+		lineY := int(y) + (i-first+2)*lineHeight - g.px(3)
+		text.Draw(screen, lines[i], fontFace, int(x)+padding, lineY, colorText)
+	}
+	for _, input := range g.textInputs {
+		g.drawTextInput(screen, input)
+	}
+}
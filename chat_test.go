@@ -0,0 +1,107 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChatLimiter(t *testing.T) {
+	start := time.Unix(1_000_000, 0)
+	at := func(d time.Duration) time.Time { return start.Add(d) }
+	for _, tt := range []struct {
+		name  string
+		sends []time.Duration // When each line is sent, from the start
+		want  []bool
+	}{
+		{"within the burst", []time.Duration{0, 1, 2, 3, 4},
+			[]bool{true, true, true, true, true}},
+		{"past the burst", []time.Duration{0, 0, 0, 0, 0, 0, time.Second},
+			[]bool{true, true, true, true, true, false, false}},
+		{"the window moves on", []time.Duration{0, time.Second, 2 * time.Second, 3 * time.Second,
+			4 * time.Second, 9 * time.Second, 10 * time.Second, 11 * time.Second},
+			[]bool{true, true, true, true, true, false, true, true}},
+		{"dropped lines don't count", []time.Duration{0, 0, 0, 0, 0, 5 * time.Second,
+			5 * time.Second, 10 * time.Second, 10 * time.Second},
+			[]bool{true, true, true, true, true, false, false, true, true}},
+		{"spread out", []time.Duration{0, 3 * time.Second, 6 * time.Second, 9 * time.Second,
+			12 * time.Second, 15 * time.Second, 18 * time.Second, 21 * time.Second},
+			[]bool{true, true, true, true, true, true, true, true}},
+	} {
+		var l chatLimiter
+		for i, d := range tt.sends {
+			if got := l.allow(at(d)); got != tt.want[i] {
+				t.Errorf("%s: line %d at %v allowed = %v, want %v", tt.name, i+1, d, got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestMaskProfanity(t *testing.T) {
+	for _, tt := range []struct {
+		in, want string
+	}{
+		{"damn", "****"},
+		{"well damn!", "well ****!"},
+		{"Damn, DAMN it", "****, **** it"},
+		{"damnation", "damnation"},
+		{"class assignment", "class assignment"},
+		{"crap-shit", "****-****"},
+		{"¡Joder, qué mierda!", "¡*****, qué ******!"},
+		{"cabrón", "******"},
+		{"cabrones", "cabrones"},
+		{"", ""},
+		{"good game", "good game"},
+	} {
+		if got := maskProfanity(tt.in); got != tt.want {
+			t.Errorf("maskProfanity(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTruncateText(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		max  int
+		want string
+	}{
+		{"hello", 10, "hello"},
+		{"hello", 5, "hello"},
+		{"hello", 3, "hel"},
+		{"ñandú", 4, "ñand"},
+		{"日本語のチャット", 3, "日本語"},
+		{"🙂🙃🙂", 2, "🙂🙃"},
+		{"", 3, ""},
+	} {
+		if got := truncateText(tt.in, tt.max); got != tt.want {
+			t.Errorf("truncateText(%q, %d) = %q, want %q", tt.in, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestChatStaysInItsMatch(t *testing.T) {
+	ln, _ := startTestServer(t, 0)
+	first := startMatch(t, ln)
+	watcher := connect(t, ln, SpectateMessage{Name: "erin"})
+	expect[SpectatingMessage](watcher)
+	second := startMatchAs(t, ln, HelloMessage{Name: "carol"}, HelloMessage{Name: "dave"})
+
+	first[1].send(ChatMessage{Text: "gg"})
+	second[0].send(ChatMessage{Text: "hi"})
+	if chat := expect[ChatMessage](first[0]); chat.Text != "gg" || chat.Side != Empty {
+		t.Errorf("opponent heard %+v, want the gg", chat)
+	}
+	if chat := expect[ChatMessage](watcher); chat.Text != "gg" || chat.Side != Computer {
+		t.Errorf("spectator heard %+v, want the second player's gg", chat)
+	}
+	if chat := expect[ChatMessage](second[1]); chat.Text != "hi" {
+		t.Errorf("other match's opponent heard %+v, want its own hi", chat)
+	}
+
+	// Nobody hears their own line or the other match's. Asking for the
+	// game is answered once everything sent before has been dealt with.
+	for _, c := range []*testClient{first[0], first[1], second[0], second[1]} {
+		syncWith(c)
+	}
+	watcher.send(PingMessage{Nonce: 1})
+	expect[PongMessage](watcher)
+}
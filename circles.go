@@ -0,0 +1,153 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Circle templates come in power-of-two sizes from minCircleTemplateSize up,
+// so a disc is never drawn from a template smaller than itself. At most
+// maxCircleTemplates are kept; the one drawn least recently makes room for
+// a new one.
+const (
+	minCircleTemplateSize = 128
+	maxCircleTemplates    = 48
+)
+
+// circleKey identifies a circle template by its opaque color and size
+type circleKey struct {
+	color color.RGBA
+	size  int
+}
+
+// circleTemplate is a cached circle and the frame it was last drawn in
+type circleTemplate struct {
+	image *ebiten.Image
+	used  int
+}
+
+// circleBucket returns the template size for circles of a diameter in pixels
+func circleBucket(diameter float64) int {
+	size := minCircleTemplateSize
+	for float64(size) < diameter {
+		size *= 2
+	}
+	return size
+}
+
+// drawSmoothCircle draws an anti-aliased circle. Templates are cached per
+// opaque color and translucency is applied when drawing, so colors that only
+// differ in alpha share one cache entry.
+func (g *ConnectFourGame) drawSmoothCircle(screen *ebiten.Image, centerX, centerY int, radius float64, clr color.Color) {
+	rr, gg, bb, aa := extractRGBA(clr)
+	circleImg := g.circleTemplate(color.RGBA{R: rr, G: gg, B: bb, A: 255}, circleBucket(radius*2))
+
+	// Draw the template with appropriate scaling
+	op := &g.circleOp
+	op.GeoM.Reset()
+	op.ColorScale.Reset()
+	scale := (radius * 2) / float64(circleImg.Bounds().Dx())
+	op.GeoM.Scale(scale, scale)
+	op.GeoM.Translate(float64(centerX)-radius, float64(centerY)-radius)
+	op.ColorScale.ScaleAlpha(float32(aa) / 255)
+	op.Filter = ebiten.FilterLinear
+	screen.DrawImage(circleImg, op)
+}
+
+// circleTemplate returns the template for a color and size, rendering it if
+// it isn't cached
+func (g *ConnectFourGame) circleTemplate(clr color.RGBA, size int) *ebiten.Image {
+	key := circleKey{clr, size}
+	if t, ok := g.circleImages[key]; ok {
+		t.used = g.frame
+		return t.image
+	}
+	if len(g.circleImages) >= maxCircleTemplates {
+		g.evictCircle()
+	}
+	img := renderCircle(clr, size)
+	g.circleImages[key] = &circleTemplate{image: img, used: g.frame}
+	return img
+}
+
+// evictCircle drops the template drawn least recently
+func (g *ConnectFourGame) evictCircle() {
+	var oldest circleKey
+	found := false
+	for key, t := range g.circleImages {
+		if !found || t.used < g.circleImages[oldest].used {
+			oldest, found = key, true
+		}
+	}
+	if found {
+		g.circleImages[oldest].image.Deallocate()
+		delete(g.circleImages, oldest)
+	}
+}
+
+// evictCircleSize drops every template of one size, such as the board's
+// old disc size after a resize
+func (g *ConnectFourGame) evictCircleSize(size int) {
+	for key, t := range g.circleImages {
+		if key.size == size {
+			t.image.Deallocate()
+			delete(g.circleImages, key)
+		}
+	}
+}
+
+// renderCircle draws a circle template into a pixel buffer and uploads it
+// in one go. The edge fades out over a few pixels for smooth scaling.
+func renderCircle(clr color.RGBA, size int) *ebiten.Image {
+	const aaWidth = 4.0 // Width of the anti-aliased edge
+
+	center := float64(size) / 2
+	radius := center - 2 // leave a 2px border to avoid clipping
+
+	// Premultiplied RGBA, as WritePixels expects
+	pixels := make([]byte, 4*size*size)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dist := math.Hypot(float64(x)-center, float64(y)-center)
+			if dist > radius {
+				continue
+			}
+			coverage := 1.0
+			if dist > radius-aaWidth {
+				coverage = 1 - (dist-(radius-aaWidth))/aaWidth
+				coverage = coverage * coverage * (3 - 2*coverage) // Smoothstep
+			}
+			alpha := float64(clr.A) / 255 * coverage
+			i := 4 * (y*size + x)
+			pixels[i] = uint8(float64(clr.R) * alpha)
+			pixels[i+1] = uint8(float64(clr.G) * alpha)
+			pixels[i+2] = uint8(float64(clr.B) * alpha)
+			pixels[i+3] = uint8(255 * alpha)
+		}
+	}
+
+	img := ebiten.NewImage(size, size)
+	img.WritePixels(pixels)
+	return img
+}
+
+// preRenderCircles pre-renders board-sized circles for common colors, so the
+// first frames of a game don't stall rendering them
+func (g *ConnectFourGame) preRenderCircles() {
+	colors := []color.RGBA{
+		colorPlayer,
+		colorSlotBg,
+	}
+	colors = append(colors, difficultyColors[:]...)
+
+	for _, clr := range colors {
+		g.preRenderCircle(clr)
+	}
+}
+
+// preRenderCircle renders the board-sized circle template for one color
+func (g *ConnectFourGame) preRenderCircle(clr color.RGBA) {
+	g.circleTemplate(clr, max(g.circleSize, minCircleTemplateSize))
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestCircleBucket(t *testing.T) {
+	for _, tt := range []struct {
+		diameter float64
+		want     int
+	}{
+		{0, minCircleTemplateSize},
+		{40, minCircleTemplateSize},
+		{128, 128},
+		{128.5, 256},
+		{300, 512},
+		{1024, 1024},
+	} {
+		if got := circleBucket(tt.diameter); got != tt.want {
+			t.Errorf("circleBucket(%g) = %d, want %d", tt.diameter, got, tt.want)
+		}
+	}
+}
+
+func TestCircleTemplateKeying(t *testing.T) {
+	g := newConnectFourGame(newMemoryStorage())
+	clear(g.circleImages)
+	red := color.RGBA{200, 50, 50, 255}
+
+	small := g.circleTemplate(red, 128)
+	if g.circleTemplate(red, 128) != small {
+		t.Error("same color and size rendered twice")
+	}
+	if g.circleTemplate(red, 256) == small {
+		t.Error("larger size reused the smaller template")
+	}
+	if g.circleTemplate(color.RGBA{50, 50, 200, 255}, 128) == small {
+		t.Error("another color reused the template")
+	}
+	if len(g.circleImages) != 3 {
+		t.Errorf("%d templates cached, want 3", len(g.circleImages))
+	}
+
+	// Translucent colors are drawn from the opaque color's template
+	g.drawSmoothCircle(ebiten.NewImage(64, 64), 1, 1, 30, color.RGBA{200, 50, 50, 128})
+	if len(g.circleImages) != 3 {
+		t.Errorf("translucent circle added a template, %d cached", len(g.circleImages))
+	}
+	if g.circleImages[circleKey{red, 128}].image.Bounds().Dx() != 128 {
+		t.Error("template not rendered at its key's size")
+	}
+}
+
+func TestCircleTemplateEviction(t *testing.T) {
+	g := newConnectFourGame(newMemoryStorage())
+	clear(g.circleImages)
+
+	shade := func(i int) color.RGBA { return color.RGBA{uint8(i), 0, 0, 255} }
+	for i := range maxCircleTemplates {
+		g.frame = i
+		g.circleTemplate(shade(i), 128)
+	}
+	// Drawing the oldest again makes the second oldest the one to go
+	g.frame = maxCircleTemplates
+	g.circleTemplate(shade(0), 128)
+	g.frame++
+	g.circleTemplate(shade(maxCircleTemplates), 128)
+
+	if len(g.circleImages) != maxCircleTemplates {
+		t.Errorf("%d templates cached, want at most %d", len(g.circleImages), maxCircleTemplates)
+	}
+	if _, ok := g.circleImages[circleKey{shade(1), 128}]; ok {
+		t.Error("least recently drawn template kept")
+	}
+	if _, ok := g.circleImages[circleKey{shade(0), 128}]; !ok {
+		t.Error("recently drawn template evicted")
+	}
+
+	// A resize drops the old disc size and nothing else
+	g.circleTemplate(shade(0), 256)
+	g.evictCircleSize(128)
+	if len(g.circleImages) != 1 {
+		t.Errorf("%d templates left after evicting a size, want 1", len(g.circleImages))
+	}
+	if _, ok := g.circleImages[circleKey{shade(0), 256}]; !ok {
+		t.Error("template of another size evicted")
+	}
+}
+
+// BenchmarkRenderCircle times rendering templates up to the size used for
+// full screen discs on a 4K display
+func BenchmarkRenderCircle(b *testing.B) {
+	for _, size := range []int{128, 256, 512} {
+		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				renderCircle(colorPlayer, size).Deallocate()
+			}
+		})
+	}
+}
@@ -16,85 +16,166 @@ const (
 
 type GameBoard [Rows][Columns]int
 
-// Evaluate the board to determine the score for the computer.
+// Weight of the row-parity threat term in evaluateBoard, 0 disables it
+var parityThreatWeight = 8
+
+// Side that moved first in the current game, which decides which threat rows
+// favour whom. It is the player unless a game says otherwise.
+var firstPlayer = Player
+
+// Rules is what a search needs to know about the game besides the board. A
+// search is handed a copy when it starts, so one running on another
+// goroutine never reads gameVariant or firstPlayer while the game loop
+// changes them.
+type Rules struct {
+	Variant int // Rule set, see gameVariant
+	Starter int // Side that moved first, see firstPlayer
+}
+
+// standardRules are the usual game with the player moving first
+var standardRules = Rules{Variant: VariantStandard, Starter: Player}
+
+// currentRules returns the rules of the game being played. Only the game
+// loop may call it.
+func currentRules() Rules {
+	return Rules{Variant: gameVariant, Starter: firstPlayer}
+}
+
+// Evaluate the board under the current game's rules
 func evaluateBoard(board GameBoard) int {
+	return currentRules().evaluate(board)
+}
+
+// Evaluate the board to determine the score for the computer.
+func (r Rules) evaluate(board GameBoard) int {
 	// Scoring logic for the board
 	// Positive score favors the computer, negative favors the player
 	score := 0
 
 	// Check horizontal, vertical, and diagonal lines for scoring
-	score += evaluateLines(board, Computer)
-	score -= evaluateLines(board, Player)
+	computerLines, playerLines := evaluateLines(board)
+	score += computerLines - playerLines
+
+	// Threats on the right rows decide endgames by zugzwang
+	score += parityThreatWeight * evaluateParityThreats(board, r.Starter)
+
+	// When the most fours wins, the fours already made are what counts
+	if r.Variant == VariantCount {
+		score += countWinWeight * (countWins(board, Computer) - countWins(board, Player))
+	}
 
 	return score
 }
 
-// Evaluate lines for a specific player
-func evaluateLines(board GameBoard, player int) int {
-	score := 0
-
-	// Horizontal
+// Find the empty cells where player would complete four in a row
+func threatSquares(board GameBoard, player int) [Rows][Columns]bool {
+	var threats [Rows][Columns]bool
 	for row := 0; row < Rows; row++ {
-		for col := 0; col < Columns-3; col++ {
-			score += evaluateSegment(board[row][col:col+4], player)
+		for col := 0; col < Columns; col++ {
+			if board[row][col] != Empty {
+				continue
+			}
+			threats[row][col] = completesFour(board, row, col, player)
 		}
 	}
+	return threats
+}
 
-	// Vertical
-	for col := 0; col < Columns; col++ {
-		for row := 0; row < Rows-3; row++ {
-			segment := []int{board[row][col], board[row+1][col], board[row+2][col], board[row+3][col]}
-			score += evaluateSegment(segment, player)
+// Check whether a piece for player at (row, col) would join four in a row
+func completesFour(board GameBoard, row, col, player int) bool {
+	directions := [4][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}}
+	for _, d := range directions {
+		count := 1
+		// Count matching pieces on both sides of the cell
+		for _, sign := range [2]int{1, -1} {
+			r, c := row+sign*d[0], col+sign*d[1]
+			for r >= 0 && r < Rows && c >= 0 && c < Columns && board[r][c] == player {
+				count++
+				r += sign * d[0]
+				c += sign * d[1]
+			}
 		}
-	}
-
-	// Diagonal (top-left to bottom-right)
-	for row := 0; row < Rows-3; row++ {
-		for col := 0; col < Columns-3; col++ {
-			segment := []int{board[row][col], board[row+1][col+1], board[row+2][col+2], board[row+3][col+3]}
-			score += evaluateSegment(segment, player)
+		if count >= 4 {
+			return true
 		}
 	}
+	return false
+}
 
-	// Diagonal (bottom-left to top-right)
-	for row := 3; row < Rows; row++ {
-		for col := 0; col < Columns-3; col++ {
-			segment := []int{board[row][col], board[row-1][col+1], board[row-2][col+2], board[row-3][col+3]}
-			score += evaluateSegment(segment, player)
+// Count threats on the rows that favour each side. Counting rows from 1 at
+// the bottom, the first player profits from threats on odd rows and the
+// second player from threats on even rows, since when the board fills up
+// column by column those are the squares each side ends up playing.
+// Positive counts favour the computer.
+func evaluateParityThreats(board GameBoard, starter int) int {
+	computerThreats := threatSquares(board, Computer)
+	playerThreats := threatSquares(board, Player)
+
+	score := 0
+	for row := 0; row < Rows; row++ {
+		oddRow := (Rows-row)%2 == 1
+		for col := 0; col < Columns; col++ {
+			if computerThreats[row][col] && oddRow == (starter == Computer) {
+				score++
+			}
+			if playerThreats[row][col] && oddRow == (starter == Player) {
+				score--
+			}
 		}
 	}
-
 	return score
 }
 
-// Evaluate a segment of 4 cells for scoring
-func evaluateSegment(segment []int, player int) int {
-	score := 0
-	countPlayer := 0
-	countEmpty := 0
+// Steps from one cell of a line to the next: along a row, down a column and
+// down either diagonal
+var lineSteps = [4][2]int{{0, 1}, {1, 0}, {1, 1}, {-1, 1}}
 
-	for _, cell := range segment {
-		if cell == player {
-			countPlayer++
-		} else if cell == Empty {
-			countEmpty++
+// evaluateLines scores every four-cell window of the board for both sides in
+// one pass, reading the cells straight from the board rather than copying
+// each window out. It runs at every leaf of the search.
+func evaluateLines(board GameBoard) (computer, player int) {
+	for _, step := range lineSteps {
+		dr, dc := step[0], step[1]
+		for row := 0; row < Rows; row++ {
+			if last := row + 3*dr; last < 0 || last >= Rows {
+				continue
+			}
+			for col := 0; col+3*dc < Columns; col++ {
+				var counts [3]int // By piece: Empty, Player, Computer
+				for i := 0; i < 4; i++ {
+					counts[board[row+i*dr][col+i*dc]]++
+				}
+				computer += windowScore(counts[Computer], counts[Empty])
+				player += windowScore(counts[Player], counts[Empty])
+			}
 		}
 	}
+	return computer, player
+}
 
-	if countPlayer == 4 {
-		score += 100
-	} else if countPlayer == 3 && countEmpty == 1 {
-		score += 10
-	} else if countPlayer == 2 && countEmpty == 2 {
-		score += 5
+// windowScore scores a four-cell window holding pieces of one side's and
+// empty cells
+func windowScore(pieces, empty int) int {
+	switch {
+	case pieces == 4:
+		return 100
+	case pieces == 3 && empty == 1:
+		return 10
+	case pieces == 2 && empty == 2:
+		return 5
 	}
-
-	return score
+	return 0
 }
 
 // Check if the game is over
 func isTerminalNode(board GameBoard) bool {
-	return checkWin(board, Player) || checkWin(board, Computer) || isBoardFull(board)
+	return currentRules().terminal(board)
+}
+
+// terminal reports whether the game on board is over under the rules
+func (r Rules) terminal(board GameBoard) bool {
+	_, over := r.gameOver(board)
+	return over
 }
 
 // Check if a player has won
@@ -170,22 +251,52 @@ func dropPiece(board GameBoard, col, player int) GameBoard {
 	return board
 }
 
+// Half-width of the aspiration window that iterative deepening searches
+// around the previous depth's score, 0 to always search with full bounds
+var aspirationWindow = 30.0
+
+// search holds the state of a single engine search
+type search struct {
+	rules           Rules
+	nodes           int  // Positions visited so far
+	maxNodes        int  // Abort once this many nodes have been visited, 0 for no limit
+	aborted         bool // Set when the node budget ran out mid-search
+	aspirationFails int  // Aspiration searches that had to be repeated with full bounds
+}
+
 // Minimax algorithm with alpha-beta pruning
 func minimax(board GameBoard, depth int, alpha float64, beta float64, maximizingPlayer bool) (int, float64) {
+	s := &search{rules: currentRules()}
+	return s.minimax(board, depth, alpha, beta, maximizingPlayer)
+}
+
+// minimax searches the position, counting nodes against the budget. Once the
+// budget is exhausted the search unwinds and its result must be discarded.
+func (s *search) minimax(board GameBoard, depth int, alpha float64, beta float64, maximizingPlayer bool) (int, float64) {
+	if s.aborted {
+		return -1, 0
+	}
+	s.nodes++
+	if s.maxNodes > 0 && s.nodes >= s.maxNodes {
+		s.aborted = true
+		return -1, 0
+	}
+
 	validColumns := getValidColumns(board)
-	isTerminal := isTerminalNode(board)
+	isTerminal := s.rules.terminal(board)
 
 	if depth == 0 || isTerminal {
 		if isTerminal {
-			if checkWin(board, Computer) {
+			switch winner, _ := s.rules.gameOver(board); winner {
+			case Computer:
 				return -1, math.Inf(1)
-			} else if checkWin(board, Player) {
+			case Player:
 				return -1, math.Inf(-1)
-			} else {
+			default:
 				return -1, 0
 			}
 		}
-		return -1, float64(evaluateBoard(board))
+		return -1, float64(s.rules.evaluate(board))
 	}
 
 	if maximizingPlayer {
@@ -193,7 +304,7 @@ func minimax(board GameBoard, depth int, alpha float64, beta float64, maximizing
 		column := validColumns[rand.Intn(len(validColumns))]
 		for _, col := range validColumns {
 			newBoard := dropPiece(board, col, Computer)
-			_, newScore := minimax(newBoard, depth-1, alpha, beta, false)
+			_, newScore := s.minimax(newBoard, depth-1, alpha, beta, false)
 			if newScore > value {
 				value = newScore
 				column = col
@@ -209,7 +320,7 @@ func minimax(board GameBoard, depth int, alpha float64, beta float64, maximizing
 		column := validColumns[rand.Intn(len(validColumns))]
 		for _, col := range validColumns {
 			newBoard := dropPiece(board, col, Player)
-			_, newScore := minimax(newBoard, depth-1, alpha, beta, true)
+			_, newScore := s.minimax(newBoard, depth-1, alpha, beta, true)
 			if newScore < value {
 				value = newScore
 				column = col
@@ -223,9 +334,126 @@ func minimax(board GameBoard, depth int, alpha float64, beta float64, maximizing
 	}
 }
 
+// aspirationSearch searches for the computer with a narrow window around the
+// expected score. A result outside the window only bounds the true score, so
+// the search is repeated with full bounds rather than trusting its move.
+func (s *search) aspirationSearch(board GameBoard, depth int, expected float64) (int, float64) {
+	if aspirationWindow > 0 && !math.IsInf(expected, 0) {
+		alpha, beta := expected-aspirationWindow, expected+aspirationWindow
+		column, score := s.minimax(board, depth, alpha, beta, true)
+		if s.aborted || (score > alpha && score < beta) {
+			return column, score
+		}
+		s.aspirationFails++
+	}
+	return s.minimax(board, depth, math.Inf(-1), math.Inf(1), true)
+}
+
+// SearchStats describes a finished engine search
+type SearchStats struct {
+	Depth   int           // Plies searched
+	Nodes   int           // Positions visited
+	Score   float64       // Score of the chosen move, from the computer's point of view
+	Elapsed time.Duration // Time spent searching
+
+	AspirationFails int // Depths searched again with full bounds, see aspirationSearch
+}
+
+// SearchTimes accumulates how long the computer's searches took
+type SearchTimes struct {
+	Count   int
+	Total   time.Duration
+	Fastest time.Duration
+	Slowest time.Duration
+}
+
+// add records one search's wall time
+func (t *SearchTimes) add(elapsed time.Duration) {
+	if t.Count == 0 || elapsed < t.Fastest {
+		t.Fastest = elapsed
+	}
+	if elapsed > t.Slowest {
+		t.Slowest = elapsed
+	}
+	t.Total += elapsed
+	t.Count++
+}
+
+// Average returns the mean search time, 0 if nothing has been recorded
+func (t SearchTimes) Average() time.Duration {
+	if t.Count == 0 {
+		return 0
+	}
+	return t.Total / time.Duration(t.Count)
+}
+
 // Get the computer's move
-func getComputerMove(board GameBoard, depth int) int {
+func getComputerMove(board GameBoard, depth int, rules Rules) int {
+	column, _ := getComputerMoveStats(board, depth, rules)
+	return column
+}
+
+// Get the computer's move along with statistics about the search
+func getComputerMoveStats(board GameBoard, depth int, rules Rules) (int, SearchStats) {
 	rand.Seed(time.Now().UnixNano())
-	column, _ := minimax(board, depth, math.Inf(-1), math.Inf(1), true)
+	start := time.Now()
+	s := &search{rules: rules}
+	column, score := s.minimax(board, depth, math.Inf(-1), math.Inf(1), true)
+	return column, SearchStats{
+		Depth:   depth,
+		Nodes:   s.nodes,
+		Score:   score,
+		Elapsed: time.Since(start),
+	}
+}
+
+// Get the computer's move by iterative deepening until roughly maxNodes
+// positions have been searched, using the deepest fully completed search
+func getComputerMoveNodeBudget(board GameBoard, maxNodes int, rules Rules) int {
+	column, _ := getComputerMoveNodeBudgetStats(board, maxNodes, rules)
 	return column
 }
+
+// Get the computer's move within a node budget along with statistics about
+// the search. Depth is that of the deepest completed search, and Nodes
+// counts every position visited, including those of an aborted last depth.
+func getComputerMoveNodeBudgetStats(board GameBoard, maxNodes int, rules Rules) (int, SearchStats) {
+	rand.Seed(time.Now().UnixNano())
+	start := time.Now()
+
+	// Depth 1 always runs to completion so there is a move to fall back on
+	first := &search{rules: rules}
+	column, score := first.minimax(board, 1, math.Inf(-1), math.Inf(1), true)
+	completed := 1
+
+	s := &search{rules: rules, maxNodes: maxNodes}
+	emptyCells := Rows*Columns - countPieces(board)
+	for depth := 2; depth <= emptyCells && !math.IsInf(score, 0); depth++ {
+		depthColumn, depthScore := s.aspirationSearch(board, depth, score)
+		if s.aborted {
+			break
+		}
+		column, score, completed = depthColumn, depthScore, depth
+	}
+	return column, SearchStats{
+		Depth:   completed,
+		Nodes:   first.nodes + s.nodes,
+		Score:   score,
+		Elapsed: time.Since(start),
+
+		AspirationFails: s.aspirationFails,
+	}
+}
+
+// Count the pieces on the board
+func countPieces(board GameBoard) int {
+	count := 0
+	for row := 0; row < Rows; row++ {
+		for col := 0; col < Columns; col++ {
+			if board[row][col] != Empty {
+				count++
+			}
+		}
+	}
+	return count
+}
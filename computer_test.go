@@ -0,0 +1,235 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// boardFromMoves plays a sequence of columns numbered from 1, the player
+// moving first
+func boardFromMoves(t testing.TB, moves string) GameBoard {
+	t.Helper()
+	var board GameBoard
+	side := Player
+	for _, ch := range moves {
+		col := int(ch - '1')
+		if col < 0 || col >= Columns || board.columnFull(col) {
+			t.Fatalf("bad move %q in %q", ch, moves)
+		}
+		board = dropPiece(board, col, side)
+		side = opponentOf(side)
+	}
+	return board
+}
+
+func TestNodeBudgetStaysWithinBudget(t *testing.T) {
+	positions := []string{"", "4", "4453", "44444433", "12345671234567"}
+	for _, budget := range []int{500, 3000, 20000} {
+		for _, moves := range positions {
+			_, stats := getComputerMoveNodeBudgetStats(boardFromMoves(t, moves), budget, standardRules)
+
+			// The budget search stops on the node that reaches it, and the
+			// depth-1 search it falls back on visits at most one node per
+			// column besides the root
+			if limit := budget + Columns + 1; stats.Nodes > limit {
+				t.Errorf("%q with budget %d: searched %d nodes, want at most %d", moves, budget, stats.Nodes, limit)
+			}
+			if stats.Nodes < budget*9/10 {
+				t.Errorf("%q with budget %d: searched only %d nodes", moves, budget, stats.Nodes)
+			}
+		}
+	}
+}
+
+// BenchmarkAspirationWindow deepens to a fixed depth with and without the
+// aspiration window, reporting the nodes each visits and how often the
+// window had to be widened again
+func BenchmarkAspirationWindow(b *testing.B) {
+	const depth = 7
+	positions := []GameBoard{
+		boardFromMoves(b, "4"),
+		boardFromMoves(b, "4453"),
+		boardFromMoves(b, "44444433"),
+		boardFromMoves(b, "3344552"),
+	}
+	defer func(window float64) { aspirationWindow = window }(aspirationWindow)
+
+	for _, window := range []float64{0, 30} {
+		b.Run(fmt.Sprintf("window=%g", window), func(b *testing.B) {
+			aspirationWindow = window
+			nodes, fails := 0, 0
+			for range b.N {
+				for _, board := range positions {
+					s := &search{rules: standardRules}
+					_, score := s.minimax(board, 1, math.Inf(-1), math.Inf(1), true)
+					for d := 2; d <= depth && !math.IsInf(score, 0); d++ {
+						_, score = s.aspirationSearch(board, d, score)
+					}
+					nodes += s.nodes
+					fails += s.aspirationFails
+				}
+			}
+			b.ReportMetric(float64(nodes)/float64(b.N), "nodes/op")
+			b.ReportMetric(float64(fails)/float64(b.N), "refails/op")
+		})
+	}
+}
+
+// evaluateLinesTwoPass is evaluateLines as it was before it scored both
+// sides in one pass: once per side, copying each window out of the board
+func evaluateLinesTwoPass(board GameBoard, player int) int {
+	score := 0
+	for row := 0; row < Rows; row++ {
+		for col := 0; col < Columns-3; col++ {
+			score += evaluateSegmentTwoPass(board[row][col:col+4], player)
+		}
+	}
+	for col := 0; col < Columns; col++ {
+		for row := 0; row < Rows-3; row++ {
+			segment := []int{board[row][col], board[row+1][col], board[row+2][col], board[row+3][col]}
+			score += evaluateSegmentTwoPass(segment, player)
+		}
+	}
+	for row := 0; row < Rows-3; row++ {
+		for col := 0; col < Columns-3; col++ {
+			segment := []int{board[row][col], board[row+1][col+1], board[row+2][col+2], board[row+3][col+3]}
+			score += evaluateSegmentTwoPass(segment, player)
+		}
+	}
+	for row := 3; row < Rows; row++ {
+		for col := 0; col < Columns-3; col++ {
+			segment := []int{board[row][col], board[row-1][col+1], board[row-2][col+2], board[row-3][col+3]}
+			score += evaluateSegmentTwoPass(segment, player)
+		}
+	}
+	return score
+}
+
+// evaluateSegmentTwoPass scores a window for one side, as windowScore does
+func evaluateSegmentTwoPass(segment []int, player int) int {
+	countPlayer, countEmpty := 0, 0
+	for _, cell := range segment {
+		if cell == player {
+			countPlayer++
+		} else if cell == Empty {
+			countEmpty++
+		}
+	}
+	return windowScore(countPlayer, countEmpty)
+}
+
+// linePositions are boards from the opening to a nearly full board
+func linePositions(tb testing.TB) []GameBoard {
+	var boards []GameBoard
+	for _, moves := range []string{"", "4", "4453", "44444433", "3344552", "12345671234567", "445566712332177665"} {
+		boards = append(boards, boardFromMoves(tb, moves))
+	}
+	return boards
+}
+
+func TestEvaluateLinesMatchesTwoPass(t *testing.T) {
+	for _, board := range linePositions(t) {
+		computer, player := evaluateLines(board)
+		if want := evaluateLinesTwoPass(board, Computer); computer != want {
+			t.Errorf("computer's lines scored %d, want %d on\n%v", computer, want, board)
+		}
+		if want := evaluateLinesTwoPass(board, Player); player != want {
+			t.Errorf("player's lines scored %d, want %d on\n%v", player, want, board)
+		}
+	}
+}
+
+// BenchmarkEvaluateLines compares scoring both sides' lines in one pass
+// over the board with the two passes it replaced
+func BenchmarkEvaluateLines(b *testing.B) {
+	boards := linePositions(b)
+	sink := 0
+	b.Run("one-pass", func(b *testing.B) {
+		for range b.N {
+			for _, board := range boards {
+				computer, player := evaluateLines(board)
+				sink += computer - player
+			}
+		}
+	})
+	b.Run("two-pass", func(b *testing.B) {
+		for range b.N {
+			for _, board := range boards {
+				sink += evaluateLinesTwoPass(board, Computer) - evaluateLinesTwoPass(board, Player)
+			}
+		}
+	})
+	if sink == 1 {
+		b.Log(sink) // Keeps the scores from being optimized away
+	}
+}
+
+func TestSolversUseTheRulesGiven(t *testing.T) {
+	// The game's own rules are most fours, which the searches mustn't read
+	setVariant(VariantCount)
+	t.Cleanup(func() { setVariant(VariantStandard) })
+
+	// The player already has four: lost for the computer under the standard
+	// rules, while under most fours play goes on
+	var board GameBoard
+	for col := range 4 {
+		board = dropPiece(board, col, Player)
+		if col < 3 {
+			board = dropPiece(board, col, Computer)
+		}
+	}
+	countRules := Rules{Variant: VariantCount, Starter: Player}
+	if got := solveWithBudget(board, Computer, 1, 0, standardRules); got != SolveLoss {
+		t.Errorf("solveWithBudget under the standard rules = %d, want a loss", got)
+	}
+	if got := solveWithBudget(board, Computer, 1, 0, countRules); got == SolveLoss {
+		t.Error("solveWithBudget under most fours found the game over")
+	}
+	if got, ok := solveCached(board, Computer, 1, 0, standardRules); !ok || got != SolveLoss {
+		t.Errorf("solveCached under the standard rules = %d, %v, want a loss", got, ok)
+	}
+	if got, ok := solveCached(board, Computer, 1, 0, countRules); !ok || got == SolveLoss {
+		t.Errorf("solveCached under most fours = %d, %v, want the game going on", got, ok)
+	}
+}
+
+// threeInRow returns a board with side's pieces in the first three columns
+// of row, counted from 1 at the bottom, leaving a threat beside them
+func threeInRow(side, row int) GameBoard {
+	var board GameBoard
+	for col := range 3 {
+		board[Rows-row][col] = side
+	}
+	return board
+}
+
+func TestParityThreats(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		board   GameBoard
+		starter int
+		want    int
+	}{
+		{"computer threat on an odd row, computer first", threeInRow(Computer, 3), Computer, 1},
+		{"computer threat on an odd row, player first", threeInRow(Computer, 3), Player, 0},
+		{"computer threat on an even row, player first", threeInRow(Computer, 2), Player, 1},
+		{"computer threat on an even row, computer first", threeInRow(Computer, 2), Computer, 0},
+		{"player threat on an odd row, player first", threeInRow(Player, 5), Player, -1},
+		{"player threat on an even row, player first", threeInRow(Player, 4), Player, 0},
+		{"player threat on an even row, computer first", threeInRow(Player, 4), Computer, -1},
+		{"no threats", GameBoard{}, Player, 0},
+	} {
+		if got := evaluateParityThreats(tt.board, tt.starter); got != tt.want {
+			t.Errorf("%s: evaluateParityThreats = %d, want %d", tt.name, got, tt.want)
+		}
+
+		// The term is all that changes in evaluate with who moved first
+		other := Rules{Starter: opponentOf(tt.starter)}
+		diff := Rules{Starter: tt.starter}.evaluate(tt.board) - other.evaluate(tt.board)
+		want := parityThreatWeight * (tt.want - evaluateParityThreats(tt.board, other.Starter))
+		if diff != want {
+			t.Errorf("%s: evaluate changed by %d with the starter, want %d", tt.name, diff, want)
+		}
+	}
+}
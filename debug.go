@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"runtime"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// debugMode enables developer shortcuts; set by the -debug flag
+var debugMode bool
+
+// How often the overlay refreshes its memory statistics
+const memSampleInterval = time.Second
+
+// Names of the game states, indexed by the State constants
+var stateNames = []string{
+	"StateLogin",
+	"StateGameMode",
+	"StateGame",
+	"StateGameOver",
+	"StatePuzzle",
+	"StateReplayList",
+	"StateReplay",
+	"StateStats",
+	"StateLeaderboard",
+	"StateProfile",
+	"StateAnalysis",
+	"StateSettings",
+	"StateRegister",
+	"StateTournament",
+	"StateOnline",
+	"StateKeyBindings",
+	"StateSpectate",
+	"StateLoadPosition",
+	"StateOnlineLeaderboard",
+}
+
+// memSampler reads runtime memory statistics at most once per interval,
+// since ReadMemStats briefly stops the world
+type memSampler struct {
+	interval time.Duration
+	last     time.Time
+	stats    runtime.MemStats
+}
+
+// sample returns the latest memory statistics, refreshing them if the
+// interval has passed since the last read
+func (m *memSampler) sample(now time.Time) *runtime.MemStats {
+	if m.last.IsZero() || now.Sub(m.last) >= m.interval {
+		runtime.ReadMemStats(&m.stats)
+		m.last = now
+	}
+	return &m.stats
+}
+
+// stateName returns the name of a State constant
+func stateName(state int) string {
+	if state >= 0 && state < len(stateNames) {
+		return stateNames[state]
+	}
+	return fmt.Sprintf("State(%d)", state)
+}
+
+// updateDebugKeys handles the developer shortcuts, which do nothing unless
+// the game was started with -debug
+func (g *ConnectFourGame) updateDebugKeys() {
+	if !debugMode || g.state != StateGame {
+		return
+	}
+
+	// Shift+Backspace empties the board without leaving the game
+	if actionHeld(ActionReverse) && action(ActionBackspace) {
+		g.clearBoard()
+		g.showToast(tr("game.board_cleared"))
+	}
+}
+
+// updateDebugOverlay toggles the overlay with F3
+func (g *ConnectFourGame) updateDebugOverlay() {
+	if action(ActionDebugOverlay) {
+		g.showDebugOverlay = !g.showDebugOverlay
+		g.memSampler.interval = memSampleInterval
+	}
+}
+
+// debugOverlayLines formats the overlay contents
+func debugOverlayLines(fps, tps float64, state int, offsetX, offsetY, cellSize float64,
+	hoverColumn int, search SearchStats, times SearchTimes, mem *runtime.MemStats) []string {
+	searchTimes := "Search times: none this game"
+	if times.Count > 0 {
+		searchTimes = fmt.Sprintf("Search min %s avg %s max %s (%d)",
+			times.Fastest.Round(time.Microsecond), times.Average().Round(time.Microsecond),
+			times.Slowest.Round(time.Microsecond), times.Count)
+	}
+	return []string{
+		fmt.Sprintf("FPS %.1f  TPS %.1f", fps, tps),
+		"State " + stateName(state),
+		fmt.Sprintf("Board (%.0f, %.0f) cell %.1f", offsetX, offsetY, cellSize),
+		fmt.Sprintf("Hover column %d", hoverColumn),
+		fmt.Sprintf("Search depth %d, %d nodes, %s", search.Depth, search.Nodes, search.Elapsed.Round(time.Microsecond)),
+		fmt.Sprintf("Search score %.0f", search.Score),
+		searchTimes,
+		fmt.Sprintf("Heap %.1f MiB, %d GCs", float64(mem.HeapAlloc)/(1<<20), mem.NumGC),
+	}
+}
+
+// drawDebugOverlay shows engine and runtime details in the top-left corner
+func (g *ConnectFourGame) drawDebugOverlay(screen *ebiten.Image) {
+	if !g.showDebugOverlay {
+		return
+	}
+
+	lines := debugOverlayLines(ebiten.ActualFPS(), ebiten.ActualTPS(), g.state,
+		g.boardOffsetX, g.boardOffsetY, g.cellSize, g.hoverColumn, g.lastSearch,
+		g.searchTimes, g.memSampler.sample(time.Now()))
+
+	// Translucent backdrop sized to the longest line
+	width := 0
+	for _, line := range lines {
+		width = max(width, boundString(fontFace, line).Dx())
+	}
+	lineHeight := g.px(16)
+	drawRect(screen, 4, 4, float64(width+g.px(12)), float64(len(lines)*lineHeight+g.px(8)), color.RGBA{0, 0, 0, 160})
+
+	for i, line := range lines {
+		text.Draw(screen, line, fontFace, g.px(10), g.px(20)+i*lineHeight, color.White)
+	}
+}
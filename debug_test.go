@@ -0,0 +1,71 @@
+package main
+
+import (
+	"runtime"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestMemSamplerThrottles(t *testing.T) {
+	m := &memSampler{interval: memSampleInterval}
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	first := *m.sample(start)
+	if first.NumGC == 0 && first.Sys == 0 {
+		t.Fatal("first sample didn't read the memory statistics")
+	}
+
+	// Within the interval the same figures are returned, even after a GC
+	runtime.GC()
+	for _, after := range []time.Duration{0, time.Millisecond, memSampleInterval / 2, memSampleInterval - time.Nanosecond} {
+		if got := m.sample(start.Add(after)); got.NumGC != first.NumGC || !m.last.Equal(start) {
+			t.Errorf("resampled %v after the last read", after)
+		}
+	}
+
+	// Once the interval is up they are read again
+	if got := m.sample(start.Add(memSampleInterval)); got.NumGC <= first.NumGC || !m.last.Equal(start.Add(memSampleInterval)) {
+		t.Errorf("not resampled after the interval: %d GCs, last read %v", got.NumGC, m.last)
+	}
+}
+
+func TestDebugOverlayLines(t *testing.T) {
+	search := SearchStats{Depth: 7, Nodes: 123456, Elapsed: 12345678 * time.Nanosecond, Score: -41.6}
+	var times SearchTimes
+	for _, d := range []time.Duration{1500 * time.Microsecond, 998500 * time.Microsecond, 2*time.Second + 400*time.Nanosecond} {
+		times.add(d)
+	}
+	mem := &runtime.MemStats{HeapAlloc: 3 << 19, NumGC: 12}
+
+	got := debugOverlayLines(59.94, 60, StateGame, 120.4, 99.5, 61.25, 3, search, times, mem)
+	want := []string{
+		"FPS 59.9  TPS 60.0",
+		"State StateGame",
+		"Board (120, 100) cell 61.2",
+		"Hover column 3",
+		"Search depth 7, 123456 nodes, 12.346ms",
+		"Search score -42",
+		"Search min 1.5ms avg 1s max 2s (3)",
+		"Heap 1.5 MiB, 12 GCs",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("overlay lines\n got %q\nwant %q", got, want)
+	}
+
+	got = debugOverlayLines(0, 0, 99, 0, 0, 0, -1, SearchStats{}, SearchTimes{}, &runtime.MemStats{})
+	for i, line := range map[int]string{1: "State State(99)", 3: "Hover column -1", 6: "Search times: none this game"} {
+		if got[i] != line {
+			t.Errorf("empty overlay line %d = %q, want %q", i, got[i], line)
+		}
+	}
+}
+
+func TestEveryStateHasAName(t *testing.T) {
+	if len(stateNames) != StateOnlineLeaderboard+1 {
+		t.Errorf("%d state names for %d states", len(stateNames), StateOnlineLeaderboard+1)
+	}
+	if got := stateName(StateSpectate); got != "StateSpectate" {
+		t.Errorf("stateName(StateSpectate) = %q", got)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// Dialog is a modal confirmation box that captures all input until answered
+type Dialog struct {
+	message     string
+	confirmText string
+	cancelText  string
+	onConfirm   func()
+	buttons     []*Button
+}
+
+// confirm opens a modal dialog asking the user to confirm an action
+func (g *ConnectFourGame) confirm(message, confirmText, cancelText string, onConfirm func()) {
+	g.dialog = &Dialog{
+		message:     message,
+		confirmText: confirmText,
+		cancelText:  cancelText,
+		onConfirm:   onConfirm,
+	}
+	g.layoutDialog()
+}
+
+// closeDialog dismisses the dialog, running its action if it was confirmed
+func (g *ConnectFourGame) closeDialog(confirmed bool) {
+	dialog := g.dialog
+	g.dialog = nil
+	if confirmed && dialog.onConfirm != nil {
+		dialog.onConfirm()
+	}
+}
+
+// layoutDialog positions the dialog's buttons for the current screen size
+func (g *ConnectFourGame) layoutDialog() {
+	if g.dialog == nil {
+		return
+	}
+	centerX := float64(g.screenWidth) / 2
+	y := float64(g.screenHeight)/2 + 10*g.scaleY
+	g.dialog.buttons = []*Button{
+		{
+			x:      centerX - 130*g.scaleX,
+			y:      y,
+			w:      120 * g.scaleX,
+			h:      35 * g.scaleY,
+			text:   g.dialog.confirmText,
+			action: func() { g.closeDialog(true) },
+		},
+		{
+			x:      centerX + 10*g.scaleX,
+			y:      y,
+			w:      120 * g.scaleX,
+			h:      35 * g.scaleY,
+			text:   g.dialog.cancelText,
+			action: func() { g.closeDialog(false) },
+		},
+	}
+}
+
+// updateDialog handles input while the dialog is open. Enter confirms and Escape cancels.
+func (g *ConnectFourGame) updateDialog() {
+	if action(ActionSubmit) {
+		g.closeDialog(true)
+		return
+	}
+	if action(ActionCancel) {
+		g.closeDialog(false)
+		return
+	}
+
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		x, y := ebiten.CursorPosition()
+		for _, btn := range g.dialog.buttons {
+			if btn.containsPoint(float64(x), float64(y)) {
+				btn.action()
+				return
+			}
+		}
+	}
+}
+
+// drawDialog dims the screen and renders the dialog box on top
+func (g *ConnectFourGame) drawDialog(screen *ebiten.Image) {
+	if g.dialog == nil {
+		return
+	}
+
+	// Dim everything behind the dialog
+	drawRect(screen, 0, 0, float64(g.screenWidth), float64(g.screenHeight),
+		color.RGBA{0, 0, 0, 120})
+
+	// Dialog box
+	boxWidth := 320 * g.scaleX
+	boxHeight := 120 * g.scaleY
+	boxX := float64(g.screenWidth)/2 - boxWidth/2
+	boxY := float64(g.screenHeight)/2 - 60*g.scaleY
+	drawRect(screen, boxX-2, boxY-2, boxWidth+4, boxHeight+4, colorButton)
+	drawRect(screen, boxX, boxY, boxWidth, boxHeight, colorBackground)
+
+	// Message
+	bounds := boundString(fontFace, g.dialog.message)
+	text.Draw(screen, g.dialog.message, fontFace,
+		g.screenWidth/2-bounds.Dx()/2, int(boxY+35*g.scaleY), colorText)
+
+	for _, btn := range g.dialog.buttons {
+		g.drawButton(screen, btn)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import "image/color"
+
+// Difficulty levels for games against the computer
+const (
+	DifficultyEasy = iota
+	DifficultyMedium
+	DifficultyHard
+	DifficultyAdaptive // Follows the player's results, see adaptive.go
+	numDifficulties
+)
+
+// Catalog keys naming each difficulty level
+var difficultyKeys = [numDifficulties]string{"difficulty.easy", "difficulty.medium", "difficulty.hard", "difficulty.adaptive"}
+
+// Search depth used by the computer at each difficulty. Adaptive takes its
+// settings from the user's level instead.
+var difficultyDepths = [numDifficulties]int{2, 5, 7, 0}
+
+// Chance the computer plays a random move instead of searching, per difficulty
+var difficultyBlunderRates = [numDifficulties]float64{0.25, 0, 0, 0}
+
+// Computer disc color at each difficulty, as a cue to how strong it is
+var difficultyColors = [numDifficulties]color.RGBA{
+	{40, 150, 110, 255}, // Green
+	colorComputer,       // Blue
+	{140, 20, 40, 255},  // Dark red
+	{120, 60, 170, 255}, // Purple
+}
+
+// difficultyName returns the localized name of a difficulty level
+func difficultyName(difficulty int) string {
+	return tr(difficultyKeys[difficulty])
+}
+
+// setDifficulty changes the difficulty and the engine settings that go with it
+func (g *ConnectFourGame) setDifficulty(difficulty int) {
+	if difficulty < 0 || difficulty >= numDifficulties {
+		difficulty = DifficultyMedium
+	}
+	g.difficulty = difficulty
+	g.aiDepth = difficultyDepths[difficulty]
+	g.blunderRate = difficultyBlunderRates[difficulty]
+	if difficulty == DifficultyAdaptive {
+		level := adaptiveLevels[g.adaptive.Level]
+		g.aiDepth = level.depth
+		g.blunderRate = level.blunderRate
+	}
+	g.updateComputerColor()
+}
+
+// updateComputerColor picks the computer's disc color for the difficulty,
+// keeping the default blue if the player's color is too similar
+func (g *ConnectFourGame) updateComputerColor() {
+	g.computerColor = difficultyColors[g.difficulty]
+	if colorDistance(g.computerColor, g.playerColor) < 100 {
+		g.computerColor = colorComputer
+	}
+}
+
+// colorDistance returns a rough measure of how different two colors look
+func colorDistance(a, b color.RGBA) int {
+	return abs(int(a.R)-int(b.R)) + abs(int(a.G)-int(b.G)) + abs(int(a.B)-int(b.B))
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"image/color"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// Discs at least this bright take a dark label, darker ones a light label
+const discLabelLuminance = 150
+
+// discLabel trims a label to its first character, the most that fits on a disc
+func discLabel(label string) string {
+	for _, r := range strings.TrimSpace(label) {
+		return string(r)
+	}
+	return ""
+}
+
+// discLabelColor returns the label color that stands out on a disc of clr
+func discLabelColor(clr color.RGBA) color.Color {
+	luminance := 0.299*float64(clr.R) + 0.587*float64(clr.G) + 0.114*float64(clr.B)
+	if luminance >= discLabelLuminance {
+		return color.Black
+	}
+	return color.White
+}
+
+// initDiscLabelsUI adds the inputs for each seat's disc label to the
+// settings screen, beside the dropdowns
+func (g *ConnectFourGame) initDiscLabelsUI() {
+	labels := []string{tr("settings.player_label"), tr("settings.opponent_label")}
+	for i, label := range labels {
+		value := g.settings.DiscLabels[i]
+		g.textInputs = append(g.textInputs, &TextInput{
+			x:      float64(g.screenWidth)/2 + 140*g.scaleX,
+			y:      (130 + float64(i)*60) * g.scaleY,
+			w:      60 * g.scaleX,
+			h:      26 * g.scaleY,
+			label:  label,
+			value:  value,
+			cursor: len([]rune(value)),
+		})
+	}
+}
+
+// updateDiscLabels keeps the disc labels in step with their inputs, cut to
+// one character, and saves them once typing stops
+func (g *ConnectFourGame) updateDiscLabels() {
+	for i, input := range g.textInputs {
+		label := discLabel(input.value)
+		if input.value != label {
+			input.value, input.cursor, input.scrollPos = label, len([]rune(label)), 0
+		}
+		if g.settings.DiscLabels[i] != label {
+			g.settings.DiscLabels[i] = label
+			g.settingsSaveTimer = settingsSaveDelay
+		}
+	}
+}
+
+// drawDiscLabels writes each seat's label, if it has one, across its discs
+func (g *ConnectFourGame) drawDiscLabels(screen *ebiten.Image, board GameBoard, originX, originY float64) {
+	labels := g.settings.DiscLabels
+	if labels == [2]string{} {
+		return
+	}
+	for row := 0; row < Rows; row++ {
+		for col := 0; col < Columns; col++ {
+			piece := board.At(row, col)
+			if piece == Empty || labels[piece-Player] == "" {
+				continue
+			}
+			discColor := g.playerColor
+			if piece == Computer {
+				discColor = g.computerColor
+			}
+			label := labels[piece-Player]
+			bounds := boundString(fontFace, label)
+			x := int(originX+float64(col)*g.cellSize+g.cellSize/2) - bounds.Dx()/2 - bounds.Min.X
+			y := int(originY+float64(row)*g.cellSize+g.cellSize/2) - bounds.Dy()/2 - bounds.Min.Y
+			text.Draw(screen, label, fontFace, x, y, discLabelColor(discColor))
+		}
+	}
+}
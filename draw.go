@@ -0,0 +1,78 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// buttonCornerRadius is the unscaled rounding of button corners
+const buttonCornerRadius = 6
+
+// Scratch space for filling paths, reused between calls so that the vertex
+// buffers only grow once
+var (
+	fillVertices []ebiten.Vertex
+	fillIndices  []uint16
+	fillOptions  = &ebiten.DrawTrianglesOptions{FillRule: ebiten.FillRuleNonZero, AntiAlias: true}
+)
+
+// whiteImage is the source texture for filled paths; vertex colors tint it
+var whiteImage = func() *ebiten.Image {
+	img := ebiten.NewImage(3, 3)
+	img.Fill(color.White)
+	return img
+}()
+
+// whiteSubImage is the middle pixel of whiteImage, so that sampling never
+// bleeds past its edge
+var whiteSubImage = whiteImage.SubImage(image.Rect(1, 1, 2, 2)).(*ebiten.Image)
+
+// drawRect fills a rectangle with a solid color
+func drawRect(dst *ebiten.Image, x, y, w, h float64, clr color.Color) {
+	vector.DrawFilledRect(dst, float32(x), float32(y), float32(w), float32(h), clr, false)
+}
+
+// drawLine draws a one pixel wide line between two points
+func drawLine(dst *ebiten.Image, x0, y0, x1, y1 float64, clr color.Color) {
+	vector.StrokeLine(dst, float32(x0), float32(y0), float32(x1), float32(y1), 1, clr, true)
+}
+
+// drawRoundedRect fills a rectangle whose corners are rounded off with
+// radius r, which is limited to half the shorter side
+func drawRoundedRect(dst *ebiten.Image, x, y, w, h, r float64, clr color.Color) {
+	r = math.Min(r, math.Min(w, h)/2)
+	if r <= 0 {
+		drawRect(dst, x, y, w, h, clr)
+		return
+	}
+	vertices, indices := roundedRectTriangles(x, y, w, h, r, clr)
+	dst.DrawTriangles(vertices, indices, whiteSubImage, fillOptions)
+}
+
+// roundedRectTriangles returns the triangles that fill a rounded rectangle
+// under the nonzero rule, in the reused scratch buffers
+func roundedRectTriangles(x, y, w, h, r float64, clr color.Color) ([]ebiten.Vertex, []uint16) {
+	x0, y0, x1, y1, rf := float32(x), float32(y), float32(x+w), float32(y+h), float32(r)
+	var path vector.Path
+	path.MoveTo(x0+rf, y0)
+	path.ArcTo(x1, y0, x1, y1, rf)
+	path.ArcTo(x1, y1, x0, y1, rf)
+	path.ArcTo(x0, y1, x0, y0, rf)
+	path.ArcTo(x0, y0, x1, y0, rf)
+	path.Close()
+
+	fillVertices, fillIndices = path.AppendVerticesAndIndicesForFilling(fillVertices[:0], fillIndices[:0])
+	cr, cg, cb, ca := clr.RGBA()
+	for i := range fillVertices {
+		fillVertices[i].SrcX, fillVertices[i].SrcY = 1, 1
+		fillVertices[i].ColorR = float32(cr) / 0xffff
+		fillVertices[i].ColorG = float32(cg) / 0xffff
+		fillVertices[i].ColorB = float32(cb) / 0xffff
+		fillVertices[i].ColorA = float32(ca) / 0xffff
+	}
+	return fillVertices, fillIndices
+}
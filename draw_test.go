@@ -0,0 +1,97 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+// rasterize samples the middle of each pixel of a w×h image against
+// triangles filled under the nonzero rule, as the GPU fills them, and returns
+// the image as rows of '#' for covered pixels and '.' for the rest
+func rasterize(vertices []ebiten.Vertex, indices []uint16, w, h int) string {
+	var sb strings.Builder
+	for py := range h {
+		for px := range w {
+			// Nudged off the middle so no sample lies on a shared triangle edge
+			x, y := float32(px)+0.5013, float32(py)+0.5029
+			winding := 0
+			for i := 0; i+2 < len(indices); i += 3 {
+				a, b, c := vertices[indices[i]], vertices[indices[i+1]], vertices[indices[i+2]]
+				d1 := (b.DstX-a.DstX)*(y-a.DstY) - (b.DstY-a.DstY)*(x-a.DstX)
+				d2 := (c.DstX-b.DstX)*(y-b.DstY) - (c.DstY-b.DstY)*(x-b.DstX)
+				d3 := (a.DstX-c.DstX)*(y-c.DstY) - (a.DstY-c.DstY)*(x-c.DstX)
+				switch {
+				case d1 > 0 && d2 > 0 && d3 > 0:
+					winding++
+				case d1 < 0 && d2 < 0 && d3 < 0:
+					winding--
+				}
+			}
+			if winding != 0 {
+				sb.WriteByte('#')
+			} else {
+				sb.WriteByte('.')
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// roundedButtonGolden is a 20×12 rectangle at (2, 1) with corners rounded
+// off by 5 pixels
+const roundedButtonGolden = `
+........................
+.....##############.....
+...#################....
+...##################...
+..####################..
+..####################..
+..####################..
+..####################..
+..####################..
+..####################..
+...##################...
+....################....
+.....##############.....
+........................
+`
+
+func TestRoundedRectGolden(t *testing.T) {
+	vertices, indices := roundedRectTriangles(2, 1, 20, 12, 5, colorButton)
+	if got := rasterize(vertices, indices, 24, 14); got != roundedButtonGolden[1:] {
+		t.Errorf("rounded rectangle drawn as\n%swant\n%s", got, roundedButtonGolden[1:])
+	}
+	for _, v := range vertices {
+		if v.SrcX != 1 || v.SrcY != 1 {
+			t.Fatalf("vertex samples (%g, %g), outside the white pixel", v.SrcX, v.SrcY)
+		}
+	}
+}
+
+// BenchmarkDrawHelpers compares the drawing helpers with the deprecated
+// ebitenutil calls they replaced, reporting allocations per call
+func BenchmarkDrawHelpers(b *testing.B) {
+	dst := ebiten.NewImage(200, 100)
+	defer dst.Deallocate()
+	for _, bm := range []struct {
+		name string
+		draw func()
+	}{
+		{"drawRect", func() { drawRect(dst, 10, 10, 120, 40, colorButton) }},
+		{"ebitenutil.DrawRect", func() { ebitenutil.DrawRect(dst, 10, 10, 120, 40, colorButton) }},
+		{"drawLine", func() { drawLine(dst, 10, 10, 130, 50, colorText) }},
+		{"ebitenutil.DrawLine", func() { ebitenutil.DrawLine(dst, 10, 10, 130, 50, colorText) }},
+		{"drawRoundedRect", func() { drawRoundedRect(dst, 10, 10, 120, 40, buttonCornerRadius, colorButton) }},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				bm.draw()
+			}
+		})
+	}
+}
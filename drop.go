@@ -0,0 +1,84 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Drop animation defaults, in board cells and frames
+const (
+	defaultDropGravity     = 0.02 // Cells per 60 Hz step, per step
+	defaultDropRestitution = 0.3  // Fraction of the speed kept when bouncing
+	dropSettleSpeed        = 0.03 // Bounces slower than this come to rest
+	dropStartY             = -1   // Discs start one cell above the top row
+)
+
+// DropAnimation is a disc falling into place. Positions are measured in
+// cells from the top row, so the disc rests at y == row.
+type DropAnimation struct {
+	col, row int
+	player   int
+	y, vy    float64
+}
+
+// step advances the disc by one frame of simulated gravity, bouncing off its
+// resting place while it is fast enough. It returns true once the disc has
+// settled.
+func (d *DropAnimation) step(gravity, restitution float64, bounce bool) bool {
+	d.vy += gravity
+	d.y += d.vy
+	if d.y < float64(d.row) {
+		return false
+	}
+
+	// Landed: never sink below the cell
+	d.y = float64(d.row)
+	if bounce && d.vy*restitution > dropSettleSpeed {
+		d.vy = -d.vy * restitution
+		return false
+	}
+	d.vy = 0
+	return true
+}
+
+// animateDrop starts the drop animation for the piece just placed in col
+func (g *ConnectFourGame) animateDrop(col int) {
+	g.dropAnim = nil
+	if !g.animationsEnabled() {
+		return
+	}
+	for row := 0; row < Rows; row++ {
+		if g.board.At(row, col) != Empty {
+			g.dropAnim = &DropAnimation{
+				col:    col,
+				row:    row,
+				player: g.board.At(row, col),
+				y:      dropStartY,
+			}
+			return
+		}
+	}
+}
+
+// updateDropAnimation moves the falling disc, ending the animation once it settles
+func (g *ConnectFourGame) updateDropAnimation() {
+	if g.dropAnim == nil {
+		return
+	}
+	if g.dropAnim.step(g.settings.DropGravity, g.settings.DropRestitution, g.settings.DropBounce) {
+		g.dropAnim = nil
+	}
+}
+
+// drawDropAnimation draws the falling disc over a board whose top-left slot is at (originX, originY)
+func (g *ConnectFourGame) drawDropAnimation(screen *ebiten.Image, originX, originY float64) {
+	d := g.dropAnim
+	x := int(originX + float64(d.col)*g.cellSize + g.cellSize/2)
+	y := int(originY + d.y*g.cellSize + g.cellSize/2)
+	var pieceColor color.Color = g.computerColor
+	if d.player == Player {
+		pieceColor = g.playerColor
+	}
+	g.drawSmoothCircle(screen, x, y, g.cellSize*0.38, pieceColor)
+}
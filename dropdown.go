@@ -0,0 +1,154 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// Dropdown shows the current choice of an enumerated setting and opens a
+// list of the options when clicked. While open, the list takes all input.
+type Dropdown struct {
+	x, y, w, h  float64 // Closed box; each option in the list is the same size
+	label       string
+	options     []string
+	selected    int
+	highlighted int // Option under the cursor or picked with the arrow keys
+	open        bool
+	focused     bool
+	onChange    func(int)
+
+	// Cursor position last frame, so that the mouse only takes over the
+	// highlight from the arrow keys when it moves
+	cursorX, cursorY int
+}
+
+// contains reports whether a point is on the closed box
+func (d *Dropdown) contains(x, y float64) bool {
+	return pointInRect(x, y, d.x, d.y, d.w, d.h)
+}
+
+// listY returns the top of the option list, which opens upwards when there
+// isn't room for it below the box
+func (d *Dropdown) listY(screenHeight int) float64 {
+	listH := float64(len(d.options)) * d.h
+	if d.y+d.h+listH > float64(screenHeight) && d.y-listH >= 0 {
+		return d.y - listH
+	}
+	return d.y + d.h
+}
+
+// optionAt returns the option under a point in the open list, or -1
+func (d *Dropdown) optionAt(x, y float64, screenHeight int) int {
+	top := d.listY(screenHeight)
+	for i := range d.options {
+		if pointInRect(x, y, d.x, top+float64(i)*d.h, d.w, d.h) {
+			return i
+		}
+	}
+	return -1
+}
+
+// openList opens the option list with the current choice highlighted
+func (d *Dropdown) openList() {
+	d.open = true
+	d.highlighted = d.selected
+	d.cursorX, d.cursorY = ebiten.CursorPosition()
+}
+
+// moveHighlight moves the highlighted option by delta, stopping at the ends
+func (d *Dropdown) moveHighlight(delta int) {
+	d.highlighted = max(0, min(len(d.options)-1, d.highlighted+delta))
+}
+
+// choose closes the list and selects an option, calling onChange if it changed
+func (d *Dropdown) choose(i int) {
+	d.open = false
+	if i < 0 || i >= len(d.options) || i == d.selected {
+		return
+	}
+	d.selected = i
+	if d.onChange != nil {
+		d.onChange(i)
+	}
+}
+
+// openDropdown returns the dropdown whose list is open, if any
+func (g *ConnectFourGame) openDropdown() *Dropdown {
+	for _, d := range g.dropdowns {
+		if d.open {
+			return d
+		}
+	}
+	return nil
+}
+
+// updateDropdowns handles input while a dropdown's list is open, like a
+// modal: arrows and Enter pick an option, and Escape or a click outside
+// the list closes it. It returns true if a list was open.
+func (g *ConnectFourGame) updateDropdowns() bool {
+	d := g.openDropdown()
+	if d == nil {
+		return false
+	}
+
+	x, y := ebiten.CursorPosition()
+	hovered := d.optionAt(float64(x), float64(y), g.screenHeight)
+	if hovered >= 0 && (x != d.cursorX || y != d.cursorY) {
+		d.highlighted = hovered
+	}
+	d.cursorX, d.cursorY = x, y
+
+	switch {
+	case action(ActionUp):
+		d.moveHighlight(-1)
+	case action(ActionDown):
+		d.moveHighlight(1)
+	case action(ActionActivate):
+		d.choose(d.highlighted)
+	case action(ActionCancel):
+		d.open = false
+	case inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft):
+		if hovered >= 0 {
+			d.choose(hovered)
+		} else {
+			d.open = false
+		}
+	}
+	return true
+}
+
+// drawDropdown renders a dropdown's closed box in the button style
+func (g *ConnectFourGame) drawDropdown(screen *ebiten.Image, d *Dropdown) {
+	if d.focused {
+		drawRect(screen, d.x-2, d.y-2, d.w+4, d.h+4, colorText)
+	}
+	drawRect(screen, d.x, d.y, d.w, d.h, colorButton)
+
+	value := ""
+	if d.selected >= 0 && d.selected < len(d.options) {
+		value = d.options[d.selected]
+	}
+	textY := int(d.y+d.h/2) + 4
+	text.Draw(screen, d.label+": "+value, fontFace, int(d.x+8), textY, colorButtonText)
+	text.Draw(screen, "v", fontFace, int(d.x+d.w-16), textY, colorButtonText)
+}
+
+// drawDropdownList renders the open dropdown's options over everything else
+func (g *ConnectFourGame) drawDropdownList(screen *ebiten.Image) {
+	d := g.openDropdown()
+	if d == nil {
+		return
+	}
+	top := d.listY(g.screenHeight)
+	drawRect(screen, d.x-1, top-1, d.w+2, float64(len(d.options))*d.h+2, colorText)
+	for i, option := range d.options {
+		y := top + float64(i)*d.h
+		bg := colorBackground
+		if i == d.highlighted {
+			bg = colorSlotBg
+		}
+		drawRect(screen, d.x, y, d.w, d.h, bg)
+		text.Draw(screen, option, fontFace, int(d.x+8), int(y+d.h/2)+4, colorText)
+	}
+}
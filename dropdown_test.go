@@ -0,0 +1,98 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// testDropdown returns a dropdown of three options at (100, 200), each
+// 120×20, recording the options chosen
+func testDropdown(chosen *[]int) *Dropdown {
+	return &Dropdown{
+		x: 100, y: 200, w: 120, h: 20,
+		options:  []string{"Easy", "Medium", "Hard"},
+		onChange: func(i int) { *chosen = append(*chosen, i) },
+	}
+}
+
+func TestDropdownOptionAt(t *testing.T) {
+	d := testDropdown(new([]int))
+	for _, tt := range []struct {
+		name         string
+		x, y         float64
+		screenHeight int
+		want         int
+	}{
+		{"first option", 100, 220, 600, 0},
+		{"last pixel of the first option", 219.5, 239.5, 600, 0},
+		{"last option", 150, 270, 600, 2},
+		{"below the list", 150, 280, 600, -1},
+		{"on the closed box", 150, 210, 600, -1},
+		{"left of the list", 99.5, 230, 600, -1},
+		{"right of the list", 220, 230, 600, -1},
+		// With no room below, the list opens upwards ending at the box
+		{"first option opening upwards", 150, 140, 240, 0},
+		{"last option opening upwards", 150, 199.5, 240, 2},
+		{"below an upward list", 150, 200, 240, -1},
+	} {
+		if got := d.optionAt(tt.x, tt.y, tt.screenHeight); got != tt.want {
+			t.Errorf("%s: optionAt(%g, %g) = %d, want %d", tt.name, tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+func TestDropdownOpenSelectClose(t *testing.T) {
+	g := newConnectFourGame(newMemoryStorage())
+	g.screenHeight = 600
+	var chosen []int
+	d := testDropdown(&chosen)
+	d.selected = 1
+	g.dropdowns = []*Dropdown{d}
+	g.setFocus(d)
+
+	// Enter on the focused dropdown opens it at the current choice
+	pressKeys(t, ebiten.KeyEnter)
+	g.updateFocus()
+	if !d.open || d.highlighted != 1 || g.openDropdown() != d {
+		t.Fatalf("Enter left the list open %v with %d highlighted", d.open, d.highlighted)
+	}
+
+	// Arrows move the highlight and stop at the ends
+	for _, key := range []ebiten.Key{ebiten.KeyArrowDown, ebiten.KeyArrowDown} {
+		pressKeys(t, key)
+		g.updateDropdowns()
+	}
+	if d.highlighted != 2 {
+		t.Errorf("highlighted %d after moving past the end, want 2", d.highlighted)
+	}
+
+	// Escape closes the list without choosing
+	pressKeys(t, ebiten.KeyEscape)
+	g.updateDropdowns()
+	if d.open || d.selected != 1 || len(chosen) != 0 {
+		t.Errorf("Escape left open %v, selected %d, chose %v", d.open, d.selected, chosen)
+	}
+	if g.updateDropdowns() {
+		t.Error("updateDropdowns took input with the list closed")
+	}
+
+	// Enter picks the highlighted option
+	d.openList()
+	pressKeys(t, ebiten.KeyArrowUp)
+	g.updateDropdowns()
+	pressKeys(t, ebiten.KeyEnter)
+	g.updateDropdowns()
+	if d.open || d.selected != 0 || len(chosen) != 1 || chosen[0] != 0 {
+		t.Errorf("choosing the first option left open %v, selected %d, chose %v", d.open, d.selected, chosen)
+	}
+
+	// Choosing the current option or one out of range changes nothing
+	for _, i := range []int{0, -1, 3} {
+		d.openList()
+		d.choose(i)
+		if d.open || d.selected != 0 || len(chosen) != 1 {
+			t.Errorf("choose(%d) left open %v, selected %d, chose %v", i, d.open, d.selected, chosen)
+		}
+	}
+}
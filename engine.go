@@ -0,0 +1,138 @@
+package main
+
+import (
+	"log"
+	"math"
+	"math/rand"
+)
+
+// searchComputerMove is the search startComputerMove runs in the
+// background. Tests replace it to hold an answer back until the board has
+// moved on.
+var searchComputerMove = getComputerMoveStats
+
+// EngineResult is a computer move found on a background goroutine
+type EngineResult struct {
+	search int       // Number of the search it answers, see startComputerMove
+	board  GameBoard // Position the move was chosen for
+	column int
+	resign bool
+	stats  SearchStats
+	ok     bool // Stats come from a search rather than the book or a blunder
+}
+
+// startComputerMove picks the computer's column, occasionally blundering on
+// easier levels. With engine resignation on it resigns instead when its
+// search proves every move loses; a merely bad heuristic score never counts.
+//
+// The search runs without holding up Update, so the window keeps drawing and
+// responding meanwhile. Its move arrives on engineResults tagged with the
+// search number; cancelComputerMove moves the number on so late answers are
+// dropped. The answer also carries the position it was found for, so a move
+// is never played on a board that changed without the search being
+// cancelled.
+func (g *ConnectFourGame) startComputerMove() {
+	g.engineSearch++
+	g.engineResult = nil
+	id := g.engineSearch
+
+	// Practice games repeat the recorded reply while the player follows it,
+	// and blunders need no search either
+	if col, ok := g.bookMove(); ok {
+		g.engineResult = &EngineResult{search: id, board: g.board, column: col}
+		return
+	}
+	if rand.Float64() < g.blunderRate {
+		validColumns := getValidColumns(g.board)
+		g.engineResult = &EngineResult{search: id, board: g.board, column: validColumns[rand.Intn(len(validColumns))]}
+		return
+	}
+
+	board, depth, rules := g.BoardSnapshot(), g.aiDepth, currentRules()
+	canResign := g.settings.EngineResign && g.practiceGame == nil
+	go func() {
+		column, stats := searchComputerMove(board, depth, rules)
+		// Only positions that end the game score infinite, so -Inf is a proven loss
+		resign := canResign && math.IsInf(stats.Score, -1)
+		g.engineResults <- EngineResult{search: id, board: board, column: column, resign: resign, stats: stats, ok: true}
+	}()
+}
+
+// pollComputerMove collects a finished search for the current position,
+// dropping answers to searches that have since been cancelled
+func (g *ConnectFourGame) pollComputerMove() {
+	for {
+		select {
+		case result := <-g.engineResults:
+			if result.search != g.engineSearch {
+				continue
+			}
+			if result.ok {
+				g.lastSearch = result.stats
+				g.searchTimes.add(result.stats.Elapsed)
+			}
+			g.engineResult = &result
+		default:
+			return
+		}
+	}
+}
+
+// cancelComputerMove stops waiting for the computer, e.g. when the game is
+// left or restarted. A search still running finishes in the background and
+// its answer is thrown away.
+func (g *ConnectFourGame) cancelComputerMove() {
+	g.computerThinking = false
+	g.thinkingTimer = 0
+	g.engineSearch++
+	g.engineResult = nil
+}
+
+// updateComputerMove starts the computer thinking when it is its turn, and
+// plays its move once the thinking pause is over and the search has
+// answered. Answers are collected every tick so cancelled ones don't pile
+// up.
+func (g *ConnectFourGame) updateComputerMove() {
+	g.pollComputerMove()
+	if g.state != StateGame || !g.gameInProgress || !g.seatIsComputer(g.turn) || g.dropAnim != nil {
+		return
+	}
+	if !g.computerThinking {
+		// Start thinking, searching in the background meanwhile
+		g.computerThinking = true
+		g.thinkingTimer = g.thinkingPause()
+		g.startComputerMove()
+		return
+	}
+
+	// Continue thinking until the timer expires and the search is done
+	g.thinkingTimer -= tickDuration()
+	if g.thinkingTimer > 0 || g.engineResult == nil {
+		return
+	}
+	if g.engineResult.board != g.board {
+		// Found for another position; think again about this one
+		log.Printf("dropping a computer move found for another position")
+		g.cancelComputerMove()
+		return
+	}
+
+	computerCol, resign := g.engineResult.column, g.engineResult.resign
+	g.engineResult = nil
+	g.computerThinking = false
+	if resign {
+		g.endGame(Player)
+		g.gameResult = tr("game.computer_resigns")
+		return
+	}
+	g.setBoard(dropPiece(g.board, computerCol, Computer))
+	g.animateDrop(computerCol)
+	g.moves = append(g.moves, Move{Column: computerCol, Player: Computer})
+
+	// Check if the computer's move ended the game
+	if winner, over := gameOver(g.board); over {
+		g.endGame(winner)
+	} else {
+		g.turn = Player
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+// TestSearchKeepsItsRules changes the game's rules while the computer's
+// search runs on its goroutine. The search must keep to the rules it was
+// started with. Run with -race to check it never reads them meanwhile.
+func TestSearchKeepsItsRules(t *testing.T) {
+	g := newConnectFourGame(newMemoryStorage())
+	g.initializeGame()
+	g.aiDepth, g.blunderRate = 6, 0
+	setVariant(VariantCount)
+	g.setStarter(Computer)
+	defer func() {
+		setVariant(VariantStandard)
+		firstPlayer = Player
+	}()
+	g.setBoard(boardFromMoves(t, "4453322"))
+	rules := currentRules()
+	_, want := getComputerMoveStats(g.board, g.aiDepth, rules)
+
+	g.startComputerMove()
+	for i := 0; g.engineResult == nil; i++ {
+		setVariant(i % numVariants)
+		g.setStarter(Player + i%2)
+		g.pollComputerMove()
+		runtime.Gosched()
+	}
+	if got := g.engineResult.stats.Score; got != want.Score {
+		t.Errorf("search under changing rules scored %v, want %v", got, want.Score)
+	}
+}
+
+// heldCall is a search held back by heldSearch: the board it was started
+// on, and where to send the column it answers with
+type heldCall struct {
+	board  GameBoard
+	answer chan int
+}
+
+// heldSearch stands in for the computer's search until the test ends. Each
+// search is handed over on the channel returned and answers with whatever
+// column the test sends it, once the test is ready.
+func heldSearch(t *testing.T) chan heldCall {
+	calls := make(chan heldCall)
+	saved := searchComputerMove
+	searchComputerMove = func(board GameBoard, depth int, rules Rules) (int, SearchStats) {
+		call := heldCall{board: board, answer: make(chan int)}
+		calls <- call
+		return <-call.answer, SearchStats{Depth: depth}
+	}
+	t.Cleanup(func() { searchComputerMove = saved })
+	return calls
+}
+
+// ticksUntil runs the computer's side of Update until done reports true
+func ticksUntil(t *testing.T, g *ConnectFourGame, done func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(testTimeout)
+	for !done() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the computer")
+		}
+		g.updateComputerMove()
+		runtime.Gosched()
+	}
+}
+
+// answered sends a held search its column and waits for the answer to be
+// waiting for the game, without the game having looked at it yet
+func answered(t *testing.T, g *ConnectFourGame, call heldCall, col int) {
+	t.Helper()
+	call.answer <- col
+	deadline := time.Now().Add(testTimeout)
+	for len(g.engineResults) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the search to answer")
+		}
+		runtime.Gosched()
+	}
+}
+
+// engineTestGame starts a game against the computer with no thinking pause
+func engineTestGame(t *testing.T) *ConnectFourGame {
+	t.Helper()
+	g := newConnectFourGame(newMemoryStorage())
+	g.settings.ReducedMotion = true
+	g.initializeGame()
+	g.state = StateGame
+	g.aiDepth, g.blunderRate = 4, 0
+	g.undoBudget = undoUnlimited
+	return g
+}
+
+// computerToMove has the computer to move after the player's columns,
+// numbered from 1
+func computerToMove(t *testing.T, g *ConnectFourGame, moves string) GameBoard {
+	t.Helper()
+	board := boardFromMoves(t, moves)
+	g.setBoard(board)
+	g.moves = nil
+	for i, ch := range moves {
+		g.moves = append(g.moves, Move{Column: int(ch - '1'), Player: Player + i%2})
+	}
+	g.turn = Computer
+	return board
+}
+
+// TestStaleComputerMoveNeverPlayed has the board change under a search
+// that is still running, without the search being cancelled. The answer
+// it gives for the old board must not be played on the new one.
+func TestStaleComputerMoveNeverPlayed(t *testing.T) {
+	calls := heldSearch(t)
+	g := engineTestGame(t)
+
+	boardA := computerToMove(t, g, "4")
+	g.updateComputerMove()
+	first := <-calls
+	if first.board != boardA {
+		t.Fatalf("search started on\n%v\nwant\n%v", first.board, boardA)
+	}
+
+	// The player's piece moves to another column while the search runs,
+	// and then the search answers with a fine reply for the old board
+	boardB := computerToMove(t, g, "1")
+	answered(t, g, first, 1)
+	g.updateComputerMove()
+	if len(g.moves) != 1 {
+		t.Fatalf("played %v, a move found for another board", g.moves)
+	}
+
+	// The computer thinks again, about the board as it is
+	g.updateComputerMove()
+	second := <-calls
+	if second.board != boardB {
+		t.Fatalf("search restarted on\n%v\nwant the new board\n%v", second.board, boardB)
+	}
+	answered(t, g, second, 6)
+	ticksUntil(t, g, func() bool { return len(g.moves) == 2 })
+	if want := (Move{Column: 6, Player: Computer}); g.moves[1] != want {
+		t.Errorf("computer played %+v, want %+v", g.moves[1], want)
+	}
+	if want := dropPiece(boardB, 6, Computer); g.board != want {
+		t.Errorf("board after the computer's move\n%v\nwant\n%v", g.board, want)
+	}
+}
+
+// TestCancelledComputerMoveNeverPlayed takes a move back while the
+// computer's reply is being searched, and the same move is played again.
+// The first search's answer then matches the board, so only its search
+// number shows it belongs to a search that was cancelled.
+func TestCancelledComputerMoveNeverPlayed(t *testing.T) {
+	calls := heldSearch(t)
+	g := engineTestGame(t)
+
+	computerToMove(t, g, "4")
+	g.updateComputerMove()
+	first := <-calls
+	g.takeBack()
+	if g.computerThinking || len(g.moves) != 0 {
+		t.Fatalf("taking back left the computer thinking, with moves %v", g.moves)
+	}
+
+	computerToMove(t, g, "4")
+	g.updateComputerMove()
+	second := <-calls
+	answered(t, g, first, 2)
+	g.updateComputerMove()
+	if len(g.moves) != 1 || g.engineResult != nil {
+		t.Fatalf("played %v, the answer to a cancelled search", g.moves)
+	}
+
+	answered(t, g, second, 5)
+	ticksUntil(t, g, func() bool { return len(g.moves) == 2 })
+	if want := (Move{Column: 5, Player: Computer}); g.moves[1] != want {
+		t.Errorf("computer played %+v, want %+v", g.moves[1], want)
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"image/color"
+	"math"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Evaluation bar tuning
+const (
+	evalBarDepth      = 6    // Search depth of the background evaluation
+	evalBarMinMoves   = 2    // Moves played before the bar shows an assessment
+	evalBarWidth      = 14   // Unscaled width of the bar
+	winProbabilityMid = 40.0 // Score at which the computer wins about 73% of the time
+)
+
+// WinProbability converts a search score, positive favouring the computer,
+// into the chance the computer goes on to win. Forced wins and losses are
+// certain.
+func WinProbability(score float64) float64 {
+	switch {
+	case math.IsInf(score, 1):
+		return 1
+	case math.IsInf(score, -1):
+		return 0
+	}
+	return 1 / (1 + math.Exp(-score/winProbabilityMid))
+}
+
+// evalBarFill returns the fraction of the bar, measured from the top, given
+// to the computer's color for a win probability
+func evalBarFill(probability float64) float64 {
+	return math.Max(0, math.Min(1, probability))
+}
+
+// EvalBar holds the engine's latest assessment of the game, searched on a
+// background goroutine so drawing never waits for it. Until a newer search
+// finishes the previous score is shown.
+type EvalBar struct {
+	mu        sync.Mutex
+	requested int     // Number of the latest search started
+	score     float64 // Score of the latest search finished, for the computer
+	known     bool    // A score has been found for the current game
+}
+
+// request starts a search of board with toMove to play under rules. The
+// search only sees these copies, never the game itself. Results of earlier
+// searches still running are dropped when they finish.
+func (e *EvalBar) request(board GameBoard, toMove int, rules Rules) {
+	e.mu.Lock()
+	e.requested++
+	id := e.requested
+	e.mu.Unlock()
+
+	go func() {
+		s := &search{rules: rules}
+		_, score := s.minimax(board, evalBarDepth, math.Inf(-1), math.Inf(1), toMove == Computer)
+
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		if id == e.requested {
+			e.score = score
+			e.known = true
+		}
+	}()
+}
+
+// reset forgets the assessment, e.g. when a new game starts
+func (e *EvalBar) reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.requested++
+	e.known = false
+}
+
+// probability returns the computer's chance of winning by the latest
+// search, and false if no search has finished yet
+func (e *EvalBar) probability() (float64, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return WinProbability(e.score), e.known
+}
+
+// evalBarShown reports whether the evaluation bar is on. It is never shown
+// against someone online, where it would be playing for the player.
+func (g *ConnectFourGame) evalBarShown() bool {
+	return g.settings.EvalBar && g.online == nil
+}
+
+// updateEvalBar starts a new evaluation whenever a move is played
+func (g *ConnectFourGame) updateEvalBar() {
+	if !g.evalBarShown() || (g.state != StateGame && g.state != StateGameOver) {
+		return
+	}
+	if g.evalBarGame == g.gameID && g.evalBarPly == len(g.moves) {
+		return
+	}
+	if g.evalBarGame != g.gameID {
+		g.evalBar.reset()
+	}
+	g.evalBarGame = g.gameID
+	g.evalBarPly = len(g.moves)
+	g.evalBar.request(g.BoardSnapshot(), g.turn, currentRules())
+}
+
+// toggleEvalBar shows or hides the evaluation bar during a game, keeping the
+// choice in the settings. Online games have no bar to toggle.
+func (g *ConnectFourGame) toggleEvalBar() {
+	if g.online != nil {
+		return
+	}
+	g.settings.EvalBar = !g.settings.EvalBar
+	g.settingsSaveTimer = settingsSaveDelay
+	g.initUI()
+}
+
+// drawEvalBar draws the bar left of the board, split between the computer's
+// color at the top and the player's at the bottom. It is grey until there is
+// an assessment worth showing.
+func (g *ConnectFourGame) drawEvalBar(screen *ebiten.Image, originX, originY float64) {
+	if !g.evalBarShown() {
+		return
+	}
+	w := evalBarWidth * g.scaleX
+	x := originX - g.labelMargin() - w - 12*g.scaleX
+	h := float64(Rows) * g.cellSize
+
+	probability, known := g.evalBar.probability()
+	if !known || len(g.moves) < evalBarMinMoves {
+		drawRect(screen, x, originY, w, h, colorBoardBg)
+		drawRect(screen, x, originY+h/2-1, w, 2, colorText)
+		return
+	}
+
+	split := h * evalBarFill(probability)
+	drawRect(screen, x, originY, w, split, g.computerColor)
+	drawRect(screen, x, originY+split, w, h-split, g.playerColor)
+	drawRect(screen, x, originY+h/2, w, 1, color.RGBA{0, 0, 0, 90}) // Even chances mark
+}
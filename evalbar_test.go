@@ -0,0 +1,39 @@
+package main
+
+import (
+	"math"
+	"runtime"
+	"testing"
+)
+
+// TestEvalBarKeepsItsSnapshot changes the game while the bar's search runs.
+// The bar must score the position and rules it was handed. Run with -race to
+// check the search never reads the game meanwhile.
+func TestEvalBarKeepsItsSnapshot(t *testing.T) {
+	defer func() {
+		setVariant(VariantStandard)
+		firstPlayer = Player
+	}()
+	board := boardFromMoves(t, "4453322")
+	rules := Rules{Variant: VariantCount, Starter: Computer}
+	s := &search{rules: rules}
+	_, want := s.minimax(board, evalBarDepth, math.Inf(-1), math.Inf(1), true)
+
+	var bar EvalBar
+	bar.request(board, Computer, rules)
+	for i := 0; ; i++ {
+		bar.mu.Lock()
+		known, got := bar.known, bar.score
+		bar.mu.Unlock()
+		if known {
+			if got != want {
+				t.Errorf("bar scored %v, want %v", got, want)
+			}
+			return
+		}
+		setVariant(i % numVariants)
+		firstPlayer = Player + i%2
+		board[Rows-1][i%Columns] = Player + i%2
+		runtime.Gosched()
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"log"
+	"slices"
+	"sort"
+)
+
+// focusable is a widget that can hold the keyboard focus. Tab moves the
+// focus between a screen's widgets in reading order.
+type focusable interface {
+	position() (x, y float64)
+	setFocused(focused bool)
+	isFocused() bool
+}
+
+func (b *Button) position() (float64, float64)    { return b.x, b.y }
+func (b *Button) setFocused(focused bool)         { b.focused = focused }
+func (b *Button) isFocused() bool                 { return b.focused }
+func (t *TextInput) position() (float64, float64) { return t.x, t.y }
+func (t *TextInput) setFocused(focused bool)      { t.focused = focused }
+func (t *TextInput) isFocused() bool              { return t.focused }
+func (s *Slider) position() (float64, float64)    { return s.x, s.y }
+func (s *Slider) setFocused(focused bool)         { s.focused = focused }
+func (s *Slider) isFocused() bool                 { return s.focused }
+func (t *Toggle) position() (float64, float64)    { return t.x, t.y }
+func (t *Toggle) setFocused(focused bool)         { t.focused = focused }
+func (t *Toggle) isFocused() bool                 { return t.focused }
+func (d *Dropdown) position() (float64, float64)  { return d.x, d.y }
+func (d *Dropdown) setFocused(focused bool)       { d.focused = focused }
+func (d *Dropdown) isFocused() bool               { return d.focused }
+
+// focusOrder returns the screen's widgets top to bottom, then left to right
+func (g *ConnectFourGame) focusOrder() []focusable {
+	widgets := []focusable{}
+	for _, input := range g.textInputs {
+		widgets = append(widgets, input)
+	}
+	for _, toggle := range g.toggles {
+		widgets = append(widgets, toggle)
+	}
+	for _, slider := range g.sliders {
+		widgets = append(widgets, slider)
+	}
+	for _, d := range g.dropdowns {
+		widgets = append(widgets, d)
+	}
+	for _, btn := range g.buttons {
+		widgets = append(widgets, btn)
+	}
+	sort.SliceStable(widgets, func(i, j int) bool {
+		xi, yi := widgets[i].position()
+		xj, yj := widgets[j].position()
+		if yi != yj {
+			return yi < yj
+		}
+		return xi < xj
+	})
+	return widgets
+}
+
+// setFocus gives the keyboard focus to one widget, or to none if w is nil
+func (g *ConnectFourGame) setFocus(w focusable) {
+	for _, other := range g.focusOrder() {
+		other.setFocused(other == w)
+	}
+	g.activeInput = nil
+	if input, ok := w.(*TextInput); ok {
+		g.activeInput = input
+		g.resetCaretBlink()
+	}
+}
+
+// focusDefaultInput gives a screen's first text input the focus, so typing
+// goes straight into it after arriving on the screen. The game screen's chat
+// input and the settings' disc labels wait to be clicked, leaving the keys
+// to the game and the other settings until then.
+func (g *ConnectFourGame) focusDefaultInput() {
+	switch g.state {
+	case StateGame, StateGameOver, StateSettings:
+		return
+	}
+	if len(g.textInputs) > 0 {
+		g.setFocus(g.textInputs[0])
+	}
+}
+
+// checkActiveInput drops the focus from an input that isn't on the current
+// screen, so keystrokes can only ever reach this screen's fields
+func (g *ConnectFourGame) checkActiveInput() {
+	if g.activeInput != nil && !slices.Contains(g.textInputs, g.activeInput) {
+		log.Printf("focused input %q is not on this screen", g.activeInput.label)
+		g.activeInput = nil
+	}
+}
+
+// focusedIndex returns the position of the focused widget in order, or -1
+func focusedIndex(order []focusable) int {
+	for i, w := range order {
+		if w.isFocused() {
+			return i
+		}
+	}
+	return -1
+}
+
+// updateFocus moves the focus with Tab and Shift+Tab, and activates the
+// focused button or toggle, or opens the focused dropdown, with Enter or Space. It returns true if a
+// button was activated, since that may have rebuilt the screen.
+func (g *ConnectFourGame) updateFocus() bool {
+	order := g.focusOrder()
+	if len(order) == 0 {
+		return false
+	}
+	current := focusedIndex(order)
+
+	if action(ActionNextField) {
+		next := 0
+		if actionHeld(ActionReverse) {
+			next = len(order) - 1
+			if current >= 0 {
+				next = (current - 1 + len(order)) % len(order)
+			}
+		} else if current >= 0 {
+			next = (current + 1) % len(order)
+		}
+		g.setFocus(order[next])
+		return false
+	}
+
+	if current < 0 {
+		return false
+	}
+	activate := action(ActionActivate)
+	if !activate {
+		return false
+	}
+
+	switch w := order[current].(type) {
+	case *Toggle:
+		w.toggle()
+	case *Dropdown:
+		w.openList()
+	case *Button:
+		if w.disabled {
+			return true
+		}
+		state := g.state
+		w.action()
+
+		// Keep the focus in place when the button rebuilt the same screen
+		if g.state == state {
+			if order = g.focusOrder(); current < len(order) && focusedIndex(order) < 0 {
+				g.setFocus(order[current])
+			}
+		}
+		return true
+	}
+	return false
+}
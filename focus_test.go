@@ -0,0 +1,130 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// holdKeys holds keys down until the test ends
+func holdKeys(t *testing.T, keys ...ebiten.Key) {
+	t.Helper()
+	saved := keyPressed
+	keyPressed = func(key ebiten.Key) bool { return slices.Contains(keys, key) }
+	t.Cleanup(func() { keyPressed = saved })
+}
+
+// loginFocusOrder returns the login screen's widgets in the order Tab
+// should visit them: top to bottom, then left to right
+func loginFocusOrder(g *ConnectFourGame) []focusable {
+	button := func(text string) focusable {
+		for _, b := range g.buttons {
+			if b.text == text {
+				return b
+			}
+		}
+		return nil
+	}
+	return []focusable{
+		button(tr("menu.settings")),
+		g.textInputs[0], // Username
+		g.textInputs[1], // Password
+		g.toggles[0],    // Remember me
+		button(tr("login.login")),
+		button(tr("login.create_account")),
+		button(tr("login.guest")),
+	}
+}
+
+func TestTabTraversal(t *testing.T) {
+	g := newConnectFourGame(newMemoryStorage())
+	g.state = StateLogin
+	g.initUI()
+	want := loginFocusOrder(g)
+	g.setFocus(nil)
+
+	// Tab goes forwards from the top and wraps round to the start
+	for i := range len(want) + 1 {
+		pressKeys(t, ebiten.KeyTab)
+		g.updateFocus()
+		if focused := want[i%len(want)]; !focused.isFocused() {
+			t.Errorf("Tab %d: focus at %d, want %d", i+1, focusedIndex(want), i%len(want))
+		}
+		if n := countFocused(g.focusOrder()); n != 1 {
+			t.Fatalf("Tab %d: %d widgets focused", i+1, n)
+		}
+	}
+
+	// Shift+Tab goes backwards, from the end when nothing is focused
+	holdKeys(t, ebiten.KeyShift)
+	g.setFocus(nil)
+	for i := range len(want) + 1 {
+		pressKeys(t, ebiten.KeyTab)
+		g.updateFocus()
+		if at := len(want) - 1 - i%len(want); !want[at].isFocused() {
+			t.Errorf("Shift+Tab %d: focus at %d, want %d", i+1, focusedIndex(want), at)
+		}
+	}
+
+	// Focusing an input makes it the one typed into
+	g.setFocus(want[2])
+	if g.activeInput != g.textInputs[1] {
+		t.Error("focusing the password input didn't make it active")
+	}
+}
+
+// countFocused returns how many widgets claim the focus
+func countFocused(order []focusable) int {
+	n := 0
+	for _, w := range order {
+		if w.isFocused() {
+			n++
+		}
+	}
+	return n
+}
+
+func TestEnterActivatesFocusedWidget(t *testing.T) {
+	g := newConnectFourGame(newMemoryStorage())
+	g.state = StateLogin
+	g.initUI()
+	order := loginFocusOrder(g)
+	pressKeys(t, ebiten.KeyEnter)
+
+	// On an input Enter presses nothing here; the form submits it
+	g.setFocus(order[1])
+	if g.updateFocus() || g.state != StateLogin {
+		t.Fatalf("Enter on the username input activated a widget, state %d", g.state)
+	}
+
+	// Enter reaches the focused button and no other
+	g.setFocus(order[5])
+	if !g.updateFocus() {
+		t.Error("Enter on Create account didn't report a button press")
+	}
+	if g.state != StateRegister || g.guest {
+		t.Errorf("Enter on Create account went to state %d, guest %v", g.state, g.guest)
+	}
+
+	g.state = StateLogin
+	g.initUI()
+	g.setFocus(loginFocusOrder(g)[6])
+	g.updateFocus()
+	if !g.guest || g.state != StateGameMode {
+		t.Errorf("Enter on Play as Guest went to state %d, guest %v", g.state, g.guest)
+	}
+
+	// A disabled button swallows Enter without acting
+	g.state = StateLogin
+	g.initUI()
+	order = loginFocusOrder(g)
+	pressed := false
+	login := order[4].(*Button)
+	login.disabled, login.action = true, func() { pressed = true }
+	g.setFocus(login)
+	g.updateFocus()
+	if pressed {
+		t.Error("Enter pressed a disabled button")
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"image"
+	"log"
+	"os"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+)
+
+// Font sizes in points at 72 DPI, so one point is one pixel
+const (
+	fontSize      = 13
+	titleFontSize = 40
+)
+
+// fontPath is a TrueType font to use instead of the built-in one; set by the -font flag
+var fontPath string
+
+// Faces used for all interface text. They start as the bitmap basicfont so
+// text still draws if the TrueType fonts can't be loaded.
+var (
+	fontFace  font.Face = basicfont.Face7x13
+	titleFace font.Face = basicfont.Face7x13
+
+	// The bitmap title is drawn scaled up to title size
+	titleScale = 3.0
+
+	// Options reused for every title, so drawing one doesn't allocate new ones
+	titleOp ebiten.DrawImageOptions
+)
+
+// Measured text bounds are kept until the faces change, since most labels
+// are the same every frame. The cache is emptied if it grows past
+// maxBoundsCache, which only varying text such as timers gets near.
+const maxBoundsCache = 1024
+
+type boundsKey struct {
+	face font.Face
+	s    string
+}
+
+var boundsCache = map[boundsKey]image.Rectangle{}
+
+// boundString measures text like text.BoundString, remembering the result
+func boundString(face font.Face, s string) image.Rectangle {
+	key := boundsKey{face, s}
+	if bounds, ok := boundsCache[key]; ok {
+		return bounds
+	}
+	if len(boundsCache) >= maxBoundsCache {
+		clear(boundsCache)
+	}
+	bounds := text.BoundString(face, s)
+	boundsCache[key] = bounds
+	return bounds
+}
+
+// parseFace loads a TrueType or OpenType font at the given size
+func parseFace(data []byte, size float64) (font.Face, error) {
+	f, err := opentype.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return opentype.NewFace(f, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+}
+
+// Font files the interface faces are made from, kept to remake the faces
+// when the display scale changes
+var regularFontData, boldFontData = goregular.TTF, gobold.TTF
+
+// loadFonts picks the font file at path for the interface, or the bundled
+// Go fonts if path is empty. The faces are made by scaleFonts once the
+// display scale is known.
+func loadFonts(path string) {
+	if path == "" {
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Printf("loading font: %v", err)
+		return
+	}
+	regularFontData, boldFontData = data, data
+}
+
+// scaleFonts makes the interface faces for a display with the given number
+// of physical pixels per logical pixel, keeping basicfont for anything that
+// fails to load
+func scaleFonts(scale float64) {
+	if face, err := parseFace(regularFontData, fontSize*scale); err != nil {
+		log.Printf("loading font: %v", err)
+	} else {
+		fontFace = face
+	}
+	if face, err := parseFace(boldFontData, titleFontSize*scale); err != nil {
+		log.Printf("loading title font: %v", err)
+	} else {
+		titleFace = face
+		titleScale = 1
+	}
+	clear(boundsCache)
+}
+
+// textWidth returns the width of a string in the interface font
+func textWidth(s string) float64 {
+	return float64(font.MeasureString(fontFace, s).Ceil())
+}
+
+// drawTitle draws text in the title face, centered on x with its baseline at y
+func drawTitle(screen *ebiten.Image, title string, x, y float64) {
+	bounds := boundString(titleFace, title)
+	op := &titleOp
+	op.GeoM.Reset()
+	op.ColorScale.Reset()
+	op.GeoM.Scale(titleScale, titleScale)
+	op.GeoM.Translate(x-float64(bounds.Dx())*titleScale/2, y)
+	op.ColorScale.ScaleWithColor(colorTitleText)
+	text.DrawWithOptions(screen, title, titleFace, op)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"golang.org/x/image/font/basicfont"
+)
+
+func TestBoundStringCache(t *testing.T) {
+	clear(boundsCache)
+	t.Cleanup(func() { clear(boundsCache) })
+
+	want := boundString(basicfont.Face7x13, "Connect Four")
+	if allocs := testing.AllocsPerRun(100, func() {
+		if boundString(basicfont.Face7x13, "Connect Four") != want {
+			t.Fatal("cached bounds differ from the first measurement")
+		}
+	}); allocs != 0 {
+		t.Errorf("measuring cached text allocates %g times", allocs)
+	}
+
+	// Varying text empties the cache rather than growing it without limit
+	for i := range maxBoundsCache + 10 {
+		boundString(basicfont.Face7x13, fmt.Sprint(i))
+	}
+	if len(boundsCache) > maxBoundsCache {
+		t.Errorf("cache grew to %d entries, limit %d", len(boundsCache), maxBoundsCache)
+	}
+
+	// New faces measure differently, so a change of scale forgets them all
+	scaleFonts(1)
+	t.Cleanup(func() { fontFace, titleFace, titleScale = basicfont.Face7x13, basicfont.Face7x13, 3 })
+	if len(boundsCache) != 0 {
+		t.Errorf("%d bounds kept after the faces changed", len(boundsCache))
+	}
+}
+
+// BenchmarkDrawScreens draws each of the busiest screens offscreen,
+// reporting the allocations a frame makes once caches are warm. Most
+// are made inside ebiten queueing the draw commands.
+func BenchmarkDrawScreens(b *testing.B) {
+	g := newConnectFourGame(newMemoryStorage())
+	g.username = "alice"
+	screen := ebiten.NewImage(g.screenWidth, g.screenHeight)
+	defer screen.Deallocate()
+
+	for _, tc := range []struct {
+		name  string
+		state int
+	}{
+		{"login", StateLogin},
+		{"menu", StateGameMode},
+		{"game", StateGame},
+		{"settings", StateSettings},
+		{"stats", StateStats},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			g.state = tc.state
+			g.initUI()
+			g.frame++
+			g.Draw(screen)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for range b.N {
+				g.frame++
+				g.Draw(screen)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// Game summary card layout, in logical pixels
+const (
+	summaryCardWidth  = 170
+	summaryLineHeight = 18
+	summaryPadding    = 8
+	summaryButtonH    = 28
+)
+
+// SessionScore tallies the games against the computer since logging in
+type SessionScore struct {
+	Wins, Losses, Ties int
+}
+
+// record adds a finished game's winner to the tally
+func (s *SessionScore) record(winner int) {
+	switch winner {
+	case Player:
+		s.Wins++
+	case Computer:
+		s.Losses++
+	default:
+		s.Ties++
+	}
+}
+
+// GameSummary is what the game-over card reports about a finished game
+type GameSummary struct {
+	Moves      int
+	Duration   time.Duration
+	Difficulty int
+	Session    SessionScore
+
+	// Move with the biggest swing in outcome, from a finished analysis of
+	// the game; SwingPly is -1 without one and SwingSize 0 if nothing swung
+	SwingPly    int
+	SwingBefore int
+	SwingAfter  int
+	SwingSize   int
+}
+
+// newGameSummary assembles the summary of a finished game. The analysis only
+// counts if it is complete and of the same moves.
+func newGameSummary(game SavedGame, started time.Time, analysis *Analysis, session SessionScore) GameSummary {
+	summary := GameSummary{
+		Moves:      len(game.Moves),
+		Difficulty: game.Difficulty,
+		Session:    session,
+		SwingPly:   -1,
+	}
+	if !started.IsZero() && game.Played.After(started) {
+		summary.Duration = game.Played.Sub(started).Round(time.Second)
+	}
+	if analysis == nil || !analysis.Done() || len(analysis.results) != len(game.Moves) {
+		return summary
+	}
+	summary.SwingPly = 0
+	for ply, result := range analysis.results {
+		if swing := result.Before - result.After; swing > summary.SwingSize {
+			summary.SwingPly = ply
+			summary.SwingBefore = result.Before
+			summary.SwingAfter = result.After
+			summary.SwingSize = swing
+		}
+	}
+	return summary
+}
+
+// lines returns the summary as the lines of text shown on the card
+func (s GameSummary) lines() []string {
+	lines := []string{
+		tr("summary.moves", s.Moves),
+		tr("summary.duration", formatDuration(s.Duration)),
+		tr("summary.difficulty", difficultyName(s.Difficulty)),
+	}
+	switch {
+	case s.SwingPly < 0:
+		// Not analyzed, so nothing to say about swings
+	case s.SwingSize == 0:
+		lines = append(lines, tr("summary.no_swing"))
+	default:
+		lines = append(lines, tr("summary.swing", s.SwingPly+1, outcomeText(s.SwingBefore), outcomeText(s.SwingAfter)))
+	}
+	return append(lines, tr("summary.session", s.Session.Wins, s.Session.Losses, s.Session.Ties))
+}
+
+// formatDuration writes a game length as minutes and seconds, e.g. "3:07"
+func formatDuration(d time.Duration) string {
+	seconds := int(d.Seconds())
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+// gameSummary summarizes the game just finished
+func (g *ConnectFourGame) gameSummary() GameSummary {
+	return newGameSummary(*g.finishedGame, g.gameStarted, g.analysis, g.session)
+}
+
+// summaryCardRect places the summary card left of the board, or below it
+// when there isn't room beside it, so it never covers the board and the
+// winning line
+func (g *ConnectFourGame) summaryCardRect() (x, y, w, h float64) {
+	padding := float64(g.px(summaryPadding))
+	w = math.Max(summaryCardWidth*g.scaleX, float64(g.px(150)))
+	h = float64(6*g.px(summaryLineHeight)) + padding + 2*(summaryButtonH*g.scaleY+padding)
+
+	left := g.boardOffsetX - g.labelMargin() - 15*g.scaleX
+	if g.evalBarShown() {
+		left -= (evalBarWidth + 12) * g.scaleX
+	}
+	if left-w >= 10 {
+		return left - w, g.boardOffsetY, w, h
+	}
+	boardBottom := g.boardOffsetY + float64(Rows)*g.cellSize
+	if float64(g.screenHeight)-boardBottom-10 >= h {
+		return float64(g.screenWidth)/2 - w/2, boardBottom + 10, w, h
+	}
+	return 10, g.boardOffsetY, w, h
+}
+
+// initGameSummaryUI adds the card's Save Replay and Copy Notation buttons
+func (g *ConnectFourGame) initGameSummaryUI() {
+	if g.finishedGame == nil {
+		return
+	}
+	x, y, w, h := g.summaryCardRect()
+	buttonH := summaryButtonH * g.scaleY
+	padding := float64(g.px(summaryPadding))
+	g.buttons = append(g.buttons, &Button{
+		x:      x + padding,
+		y:      y + h - 2*(buttonH+padding),
+		w:      w - 2*padding,
+		h:      buttonH,
+		text:   tr("summary.save_replay"),
+		action: g.saveReplayFile,
+	})
+	g.buttons = append(g.buttons, &Button{
+		x:      x + padding,
+		y:      y + h - buttonH - padding,
+		w:      w - 2*padding,
+		h:      buttonH,
+		text:   tr("summary.copy_notation"),
+		action: g.copyNotation,
+	})
+}
+
+// drawGameSummary draws the card behind its buttons
+func (g *ConnectFourGame) drawGameSummary(screen *ebiten.Image) {
+	if g.finishedGame == nil {
+		return
+	}
+	x, y, w, h := g.summaryCardRect()
+	drawRect(screen, x, y, w, h, colorSlotBg)
+	lineHeight, padding := g.px(summaryLineHeight), g.px(summaryPadding)
+	text.Draw(screen, tr("summary.title"), fontFace,
+		int(x)+padding, int(y)+lineHeight, colorText)
+	for i, line := range g.gameSummary().lines() {
+		text.Draw(screen, line, fontFace,
+			int(x)+padding, int(y)+(i+2)*lineHeight, colorText)
+	}
+}
+
+// saveReplayFile exports the finished game as a JSON file, the same record
+// the replay viewer reads
+func (g *ConnectFourGame) saveReplayFile() {
+	data, err := json.MarshalIndent(g.finishedGame, "", "  ")
+	if err == nil {
+		var path string
+		path, err = writeReplayFile(replayFileName(g.finishedGame.Played, g.resultSlug()), data)
+		if err == nil {
+			g.showToast(tr("summary.replay_saved", path))
+			return
+		}
+	}
+	log.Printf("saving replay: %v", err)
+	g.showToast(tr("summary.replay_failed"))
+}
+
+// replayFileName builds a file name from the game's end time and result
+func replayFileName(t time.Time, result string) string {
+	return fmt.Sprintf("connectfour-%s-%s.json", t.Format("2006-01-02-150405"), result)
+}
+
+// copyNotation puts the finished game's moves on the clipboard
+func (g *ConnectFourGame) copyNotation() {
+	if err := writeClipboard(moveNotation(g.finishedGame.Moves)); err != nil {
+		log.Printf("copying notation: %v", err)
+		g.showToast(tr("summary.copy_failed"))
+		return
+	}
+	g.showToast(tr("summary.copied"))
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// gameOverButton returns the game-over screen's button reading text
+func gameOverButton(t *testing.T, g *ConnectFourGame, text string) *Button {
+	t.Helper()
+	if g.state != StateGameOver {
+		t.Fatalf("in state %d, not game over", g.state)
+	}
+	for _, b := range g.buttons {
+		if b.text == text {
+			return b
+		}
+	}
+	t.Fatalf("no %q button on the game-over screen", text)
+	return nil
+}
+
+func TestNewGameSummary(t *testing.T) {
+	started := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	game := SavedGame{
+		Played:     started.Add(3*time.Minute + 7400*time.Millisecond),
+		Difficulty: DifficultyHard,
+		Moves:      movesFrom("4453"),
+	}
+	session := SessionScore{Wins: 2, Losses: 1}
+	analyzed := func(results ...MoveAnalysis) *Analysis {
+		return &Analysis{moves: game.Moves, results: results}
+	}
+	even := MoveAnalysis{Before: SolveUnknown, After: SolveUnknown}
+	thrown := MoveAnalysis{Before: SolveWin, After: SolveLoss}
+	slipped := MoveAnalysis{Before: SolveUnknown, After: SolveLoss}
+	base := []string{"Moves: 4", "Duration: 3:07", "Difficulty: Hard"}
+	scoreLine := "Session: 2 W / 1 L / 0 D"
+
+	for _, tt := range []struct {
+		name     string
+		analysis *Analysis
+		swing    string // Swing line, or "" when left out
+		ply      int
+	}{
+		{"not analyzed", nil, "", -1},
+		{"analysis unfinished", analyzed(even, slipped), "", -1},
+		{"analysis of other moves", &Analysis{moves: movesFrom("44"), results: []MoveAnalysis{even, even}}, "", -1},
+		{"nothing swung", analyzed(even, even, even, even), "Biggest swing: none", 0},
+		{"biggest swing wins", analyzed(even, slipped, thrown, slipped),
+			"Biggest swing: move 3, a forced win to a forced loss", 2},
+		{"first of equal swings", analyzed(slipped, even, slipped, even),
+			"Biggest swing: move 1, no forced result to a forced loss", 0},
+	} {
+		summary := newGameSummary(game, started, tt.analysis, session)
+		if summary.Moves != 4 || summary.Duration != 3*time.Minute+7*time.Second ||
+			summary.Difficulty != DifficultyHard || summary.Session != session || summary.SwingPly != tt.ply {
+			t.Errorf("%s: summary %+v", tt.name, summary)
+		}
+		want := slices.Clone(base)
+		if tt.swing != "" {
+			want = append(want, tt.swing)
+		}
+		want = append(want, scoreLine)
+		if got := summary.lines(); !slices.Equal(got, want) {
+			t.Errorf("%s: lines\n got %q\nwant %q", tt.name, got, want)
+		}
+	}
+
+	// Without a start time there's no duration to give
+	if got := newGameSummary(game, time.Time{}, nil, session).Duration; got != 0 {
+		t.Errorf("duration with no start time = %v", got)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	for _, tt := range []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0:00"},
+		{59 * time.Second, "0:59"},
+		{time.Minute, "1:00"},
+		{12*time.Minute + 5*time.Second, "12:05"},
+		{75 * time.Minute, "75:00"},
+	} {
+		if got := formatDuration(tt.d); got != tt.want {
+			t.Errorf("formatDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestGameOverButtons(t *testing.T) {
+	store := newMemoryStorage()
+	g := newConnectFourGame(store)
+	g.username, g.userStore = "alice", store
+	g.profile = Profile{Username: "alice", TutorialDone: true}
+	g.frame = 1
+	g.startNewGame()
+	g.gameStarted = time.Now().Add(-95 * time.Second)
+	g.moves = movesFrom("1212121")
+	g.endGame(Player)
+
+	// The card sums up the game just played, with the session so far
+	summary := g.gameSummary()
+	if summary.Moves != 7 || summary.Duration < 95*time.Second || summary.Duration > 96*time.Second ||
+		summary.Session != (SessionScore{Wins: 1}) || summary.SwingPly != -1 {
+		t.Errorf("summary %+v", summary)
+	}
+	gameOverButton(t, g, tr("summary.save_replay"))
+	gameOverButton(t, g, tr("summary.copy_notation"))
+
+	// Play Again starts a fresh game against the computer
+	g.frame++
+	gameOverButton(t, g, tr("game.play_again")).action()
+	g.cancelComputerMove()
+	if g.state != StateGame || !g.gameInProgress || len(g.moves) != 0 || g.board != (GameBoard{}) {
+		t.Fatalf("Play Again left state %d with %d moves", g.state, len(g.moves))
+	}
+
+	// The session carries on into the next game, and Back to Menu leaves it
+	g.moves = movesFrom("7172737")
+	g.endGame(Computer)
+	if got := g.gameSummary().Session; got != (SessionScore{Wins: 1, Losses: 1}) {
+		t.Errorf("session after a loss = %+v", got)
+	}
+	gameOverButton(t, g, tr("game.back_to_menu")).action()
+	if g.state != StateGameMode {
+		t.Errorf("Back to Menu went to state %d", g.state)
+	}
+}
+
+func TestGameOverRematchOnline(t *testing.T) {
+	g, sent := onlineTestGame(t, Player)
+	sentMessages(t, sent)
+	g.transitionTo(StateGameOver)
+
+	// Online, Play Again asks the same opponent for a rematch
+	gameOverButton(t, g, tr("game.play_again")).action()
+	msgs := sentMessages(t, sent)
+	if len(msgs) != 1 {
+		t.Fatalf("sent %d messages, want a rematch offer", len(msgs))
+	}
+	if _, ok := msgs[0].(RematchMessage); !ok {
+		t.Errorf("sent %T, want a rematch offer", msgs[0])
+	}
+
+	// Once disconnected it goes back to the online screen instead
+	g.online = nil
+	g.transitionTo(StateGameOver)
+	gameOverButton(t, g, tr("game.play_again")).action()
+	if g.state != StateOnline {
+		t.Errorf("rematch with no connection went to state %d", g.state)
+	}
+}
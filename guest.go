@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// guestNumbers is how many four-digit guest names there are
+const guestNumbers = 10000
+
+// newGuestName picks a guest username not used earlier in this session. A
+// few random picks nearly always find one; failing that the free numbers are
+// searched in turn, and once all are taken the names carry on past 9999.
+func (g *ConnectFourGame) newGuestName() string {
+	if g.guestNames == nil {
+		g.guestNames = map[string]bool{}
+	}
+	name := ""
+	for range 100 {
+		if n := fmt.Sprintf("Guest-%04d", rand.Intn(guestNumbers)); !g.guestNames[n] {
+			name = n
+			break
+		}
+	}
+	for i := 0; name == "" && i < guestNumbers; i++ {
+		if n := fmt.Sprintf("Guest-%04d", i); !g.guestNames[n] {
+			name = n
+		}
+	}
+	if name == "" {
+		name = fmt.Sprintf("Guest-%d", len(g.guestNames))
+	}
+	g.guestNames[name] = true
+	return name
+}
+
+// loginAsGuest starts a session under a generated name. Everything the
+// session would save about the user is kept in memory only, so guests never
+// appear on the leaderboard or leave files behind.
+func (g *ConnectFourGame) loginAsGuest() {
+	g.guest = true
+	g.userStore = newMemoryStorage()
+	g.username = g.newGuestName()
+	g.password = ""
+	g.pendingResume = nil
+	g.loadUserAdaptive()
+	g.applyProfile(Profile{Username: g.username})
+	g.transitionTo(StateGameMode)
+}
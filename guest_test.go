@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"regexp"
+	"testing"
+)
+
+func TestGuestNamesAreUnique(t *testing.T) {
+	g := &ConnectFourGame{}
+	valid := regexp.MustCompile(`^Guest-\d{4,}$`)
+	seen := map[string]bool{}
+	for i := range guestNumbers + 3 {
+		name := g.newGuestName()
+		if !valid.MatchString(name) {
+			t.Fatalf("guest %d is named %q", i+1, name)
+		}
+		if seen[name] {
+			t.Fatalf("guest %d is named %q again", i+1, name)
+		}
+		seen[name] = true
+	}
+	for i := range guestNumbers {
+		if name := fmt.Sprintf("Guest-%04d", i); !seen[name] {
+			t.Errorf("%s was never handed out before running out", name)
+		}
+	}
+}
+
+func TestGuestNamesOnceAllAreTaken(t *testing.T) {
+	g := &ConnectFourGame{guestNames: map[string]bool{}}
+	for i := range guestNumbers {
+		g.guestNames[fmt.Sprintf("Guest-%04d", i)] = true
+	}
+	first, second := g.newGuestName(), g.newGuestName()
+	if first != "Guest-10000" || second != "Guest-10001" {
+		t.Errorf("after every four-digit name got %q then %q, want Guest-10000 then Guest-10001",
+			first, second)
+	}
+}
+
+func TestGuestSessionWritesNothing(t *testing.T) {
+	store := newMemoryStorage()
+	g := newConnectFourGame(store)
+	g.frame = 1
+	g.loginAsGuest()
+	before := maps.Clone(store.data)
+
+	g.startNewGame()
+	g.moves = []Move{{Column: 3, Player: Player}, {Column: 4, Player: Computer}}
+	g.endGame(Player)
+
+	if !maps.EqualFunc(store.data, before, bytes.Equal) {
+		var written []string
+		for key := range store.data {
+			if !bytes.Equal(store.data[key], before[key]) {
+				written = append(written, key)
+			}
+		}
+		t.Errorf("a guest game wrote %v", written)
+	}
+
+	// The session still has its stats and the game, marked as a guest's
+	if stats := loadStats(g.userStore, g.username); len(stats.Results) != 1 {
+		t.Errorf("guest session stats hold %d games, want 1", len(stats.Results))
+	}
+	games, err := loadSavedGames(g.gamesStore())
+	if err != nil || len(games) != 1 || !games[0].Guest {
+		t.Errorf("guest session saved %+v, %v; want one guest game", games, err)
+	}
+	if entries := loadLeaderboard(store); len(entries) != 0 {
+		t.Errorf("a guest is on the leaderboard: %+v", entries)
+	}
+}
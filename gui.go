@@ -1,19 +1,16 @@
 package main
 
 import (
-	"fmt"
 	"image/color"
 	"log"
 	"math"
 	"math/rand"
-	"strings"
+	"sync"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/text"
-	"golang.org/x/image/font/basicfont"
 )
 
 // Game state constants
@@ -22,6 +19,21 @@ const (
 	StateGameMode
 	StateGame
 	StateGameOver
+	StatePuzzle
+	StateReplayList
+	StateReplay
+	StateStats
+	StateLeaderboard
+	StateProfile
+	StateAnalysis
+	StateSettings
+	StateRegister
+	StateTournament
+	StateOnline
+	StateKeyBindings
+	StateSpectate
+	StateLoadPosition
+	StateOnlineLeaderboard
 )
 
 // Colors
@@ -37,6 +49,8 @@ var (
 	colorBoardBg    = color.RGBA{180, 180, 180, 255} // Neutral gray for board background
 	colorSlotBg     = color.RGBA{220, 220, 220, 255} // Lighter slots for better contrast
 	colorTitleText  = color.RGBA{50, 50, 220, 255}   // Blue title text
+	colorError      = color.RGBA{210, 30, 30, 255}   // Validation messages
+	colorShadow     = color.RGBA{0, 0, 0, 70}        // Disc shadows
 )
 
 // Button represents a clickable UI element
@@ -44,16 +58,24 @@ type Button struct {
 	x, y, w, h float64
 	text       string
 	action     func()
+	focused    bool // Has the keyboard focus, so Enter or Space presses it
+	disabled   bool // Drawn greyed out, and pressing it does nothing
 }
 
 // TextInput represents a text input field
 type TextInput struct {
-	x, y, w, h float64
-	label      string
-	value      string
-	focused    bool
-	isPassword bool
-	scrollPos  int // New field for text scrolling
+	x, y, w, h  float64
+	label       string
+	placeholder string // Hint shown while the input is empty
+	value       string
+	focused     bool
+	isPassword  bool
+	revealed    bool    // Password shown as plain text
+	cursor      int     // Character index the caret sits before
+	scrollPos   int     // Index of the first visible character
+	err         string  // Validation error shown under the input until it is edited
+	shakeUntil  float64 // animTimer value the validation shake ends at
+	maxLength   int     // Most characters the input takes, 0 for no limit
 }
 
 // FallingDisc represents a decorative animated disc
@@ -68,20 +90,63 @@ type FallingDisc struct {
 // ConnectFourGame is the main game structure
 type ConnectFourGame struct {
 	state          int
-	board          GameBoard
-	turn           int // 1 for player, 2 for computer
+	board          GameBoard    // Only changed through setBoard, see BoardSnapshot
+	boardMu        sync.RWMutex // Guards board against readers on other goroutines
+	turn           int          // 1 for player, 2 for computer
 	gameInProgress bool
 	gameResult     string
+	moves          []Move // Moves played so far in the current game
 	username       string
 	password       string
+	welcomeText    string // Greeting on the game mode screen, composed once
+
+	// Avatar and disc color of the logged-in user
+	profile     Profile
+	playerColor color.RGBA
+
+	// Disc color of the computer, which depends on the difficulty
+	computerColor color.RGBA
+
+	// Computer opponent strength
+	difficulty  int
+	aiDepth     int
+	blunderRate float64
+	lastSearch  SearchStats // Statistics from the computer's latest search
+	searchTimes SearchTimes // Wall time of every search this game, not the thinking delay
+
+	// Two people taking turns at this computer instead of playing the
+	// computer. sideNames are the names for the Player and Computer sides.
+	hotseat     bool
+	sideNames   [2]string
+	seatSwapped bool // The computer's side changed hands during the game
+
+	// Knockout tournament of two-player games, nil until one is started.
+	// tournamentMatch is the match being played, and tournamentPlayers the
+	// names entered on the setup screen.
+	tournament        *Tournament
+	tournamentMatch   *TournamentMatch
+	tournamentPlayers []string
+
+	// Saved game being practiced against, nil in a normal game
+	practiceGame *SavedGame
+	inBook       bool // The game so far matches the practice game
 
 	// UI elements
 	buttons      []*Button
 	textInputs   []*TextInput
+	sliders      []*Slider
+	toggles      []*Toggle
+	dropdowns    []*Dropdown
 	activeInput  *TextInput
+	caretBlink   float64 // animTimer value when the caret blink cycle last restarted
 	screenWidth  int
 	screenHeight int
 
+	// Screen size last reported to Layout
+	layoutWidth  int
+	layoutHeight int
+	resize       ResizeDebouncer
+
 	// For responsive layout
 	baseWidth  int
 	baseHeight int
@@ -93,29 +158,211 @@ type ConnectFourGame struct {
 	boardOffsetX float64
 	boardOffsetY float64
 
+	// Show column letters and row numbers around the board
+	showCoordinates bool
+
 	// Hover effect
 	hoverColumn int
 	isHovering  bool
 
+	// Column picked but not yet confirmed in confirm-move mode, -1 if none
+	pendingColumn int
+
+	// Panning for boards larger than the window
+	panX, panY             float64
+	dragging               bool // Left button went down on the board
+	dragMoved              bool // The cursor moved far enough to count as a drag
+	dragStartX, dragStartY int
+	panStartX, panStartY   float64
+
 	// For computer thinking delay
 	computerThinking bool
-	thinkingTimer    int
+	thinkingTimer    time.Duration // Pause left before the move is played
+
+	// The computer's move is searched in the background; engineSearch
+	// numbers the latest search and engineResult holds its answer until the
+	// thinking pause is over
+	engineSearch  int
+	engineResults chan EngineResult
+	engineResult  *EngineResult
+
+	// Incremented for every new game so a computer move searched for an
+	// earlier game is never applied to the current one
+	gameID int
+
+	// Connection to a game server for online play, the address last used and
+	// whether the current game is against an online opponent
+	online       *OnlineGame
+	onlineAddr   string
+	playedOnline bool
+	spectated    *SpectatedGame // Game being watched as a spectator
+	onlineRating int            // Rating the game server last sent, 0 until it has
+
+	// The game server's leaderboard as last fetched or cached, and the fetch
+	// under way, if any, or why the last one failed
+	onlineBoard      *OnlineLeaderboard
+	onlineBoardFetch <-chan leaderboardFetch
+	onlineBoardErr   error
+
+	// Side to move chosen on the load position screen, and whether the
+	// current game began from a loaded position. Such games don't start
+	// from an empty board, so they aren't saved, resumed or counted.
+	loadPositionMover int
+	fromPosition      bool
+
+	// Takebacks left in the current game, or undoUnlimited, and those used
+	undoBudget int
+	takebacks  int
+
+	// F3 debug overlay
+	showDebugOverlay bool
+	memSampler       memSampler
+
+	// Frame counter, used to ignore a second new-game request in one frame
+	frame            int
+	lastNewGameFrame int
+
+	// Piece currently falling into place, if any
+	dropAnim *DropAnimation
 
 	// Decorative elements
 	fallingDiscs []FallingDisc
 	animTimer    float64
 
-	// For backspace repeat
-	backspacePressed bool
-	backspaceDelay   int
-	backspaceRepeat  int
+	// Frame number and screen size last drawn, so throttled screens can
+	// skip drawing the same frame again
+	drawnFrame              int
+	drawnWidth, drawnHeight int
+	stepDebt                float64 // Base-rate animation steps owed, see baseSteps
 
 	// Pre-rendered circle images for better performance
-	circleImages map[color.RGBA]*ebiten.Image
+	circleImages map[circleKey]*circleTemplate
+	circleOp     ebiten.DrawImageOptions // Reused for every circle drawn
+	circleSize   int                     // Width of the circle templates for board discs
+	deviceScale  float64                 // Physical pixels per logical pixel
+	fontScale    float64                 // Device scale the fonts were made for
+	boardFrame   BoardFrame              // Cached front of the board
+
+	// Decoded avatar icons, by index into avatarNames
+	avatarImages map[int]*ebiten.Image
+
+	// Profile screen choices not yet saved
+	profileDraft Profile
+	profileIsNew bool
+
+	// Short-lived notification shown at the bottom of the screen
+	toastMessage string
+	toastTimer   time.Duration
+
+	// Rating of the player's last move, shown beside the status text
+	moveQuality      int
+	moveQualityTimer time.Duration
+
+	// Time left showing markers over the legal columns, toggled with L
+	legalMovesTimer time.Duration
+
+	// Online chat scrollback and whether its panel is open
+	chatLog  []string
+	chatOpen bool
+
+	// Attract mode's demo game on the menu, nil when not running, and when
+	// and where the last input was, to tell how long the menu has been idle
+	attract                  *AttractDemo
+	attractImage             *ebiten.Image
+	lastInput                time.Time
+	lastCursorX, lastCursorY int
+
+	// Persistent storage for stats and saves
+	storage Storage
+
+	// Storage for the logged in user's stats, profile and unfinished game.
+	// It is storage for registered users and memory for guests.
+	userStore  Storage
+	guest      bool
+	guestNames map[string]bool // Guest names handed out this session
+	rememberMe bool            // Remember the next login between runs
+
+	// App-wide settings, saved shortly after they change
+	settings            Settings
+	settingsSaveTimer   time.Duration
+	settingsReturnState int // Screen to go back to from the settings screen
+
+	// Registration form values last validated
+	registerValues [3]string
+
+	// Stats screen summaries
+	weekStats    StatsSummary
+	allTimeStats StatsSummary
+
+	// Leaderboard screen
+	leaderboard       []LeaderboardEntry
+	leaderboardScroll int
+
+	// Unfinished game the user can pick up again
+	pendingResume *ResumeGame
+
+	// Engine assessment shown beside the board, and the game and move it was
+	// last requested for
+	evalBar     EvalBar
+	evalBarGame int
+	evalBarPly  int
+
+	// Adaptive difficulty level and recent results of the logged in user
+	adaptive AdaptiveState
+
+	// Side that started the last ranked game, Empty before the first
+	lastStarter int
+
+	// Modal dialog capturing input, if one is open
+	dialog *Dialog
+
+	// Guided overlay over the first game, nil when not showing
+	tutorial *Tutorial
+
+	// Puzzle mode
+	puzzles          []*Puzzle
+	puzzle           *Puzzle
+	puzzleStats      PuzzleStats
+	puzzleAnswered   bool
+	puzzleMessage    string
+	puzzleRefutation string            // Computer's answer to a wrong puzzle move
+	puzzleWinIn      int               // Moves the current puzzle's win takes, 0 if unknown
+	puzzleFetch      <-chan puzzleLoad // Puzzle set loading, nil unless under way
+
+	// Post-game analysis
+	analysis       *Analysis
+	analysisScroll int
+
+	// Replay viewer
+	savedGames        []SavedGame
+	replayPage        int
+	replay            *Replay
+	replayGame        *SavedGame
+	replayPlaying     bool
+	replaySpeed       int           // Index into replaySpeeds
+	replayTimer       time.Duration // Time since autoplay's last step
+	replayReturnState int           // Screen the replay viewer's Back button returns to
+
+	// Move log panel beside the board, and the game it shows once finished
+	showMoveLog  bool
+	finishedGame *SavedGame
+	gameStarted  time.Time    // When the current game began, for its duration
+	session      SessionScore // Results since logging in
 }
 
 // Update the NewConnectFourGame function to remove parameters
 func NewConnectFourGame() *ConnectFourGame {
+	// Fall back to in-memory storage so the game stays playable without persistence
+	store, err := newStorage()
+	if err != nil {
+		log.Printf("persistent storage unavailable: %v", err)
+		store = newMemoryStorage()
+	}
+	return newConnectFourGame(store)
+}
+
+// newConnectFourGame creates the game on top of the given storage
+func newConnectFourGame(store Storage) *ConnectFourGame {
 	g := &ConnectFourGame{
 		state:            StateLogin,
 		baseWidth:        800,
@@ -126,14 +373,33 @@ func NewConnectFourGame() *ConnectFourGame {
 		scaleY:           1.0,
 		cellSize:         60,
 		hoverColumn:      -1,
+		pendingColumn:    -1,
 		computerThinking: false,
 		thinkingTimer:    0,
+		engineResults:    make(chan EngineResult, 8),
 		fallingDiscs:     make([]FallingDisc, 20), // Initialize with 20 decorative discs
-		backspacePressed: false,
-		backspaceDelay:   15, // Frames to wait before starting to repeat (250ms)
-		backspaceRepeat:  3,  // Frames between repeats once started (50ms)
-		circleImages:     make(map[color.RGBA]*ebiten.Image),
+		showMoveLog:      true,
+		chatOpen:         true,
+		circleImages:     make(map[circleKey]*circleTemplate),
+		avatarImages:     make(map[int]*ebiten.Image),
+		playerColor:      colorPlayer,
+		computerColor:    colorComputer,
+		replaySpeed:      1,
+		adaptive:         newAdaptiveState(),
+	}
+
+	g.storage = store
+	g.userStore = store
+	g.settings = loadSettings(store)
+	setLanguage(g.settings.Language)
+	applyTheme(g.settings.Theme)
+	applyKeyBindings(g.settings)
+	if debugMode {
+		for language, keys := range missingKeys() {
+			log.Printf("locale %s is missing %d keys: %v", language, len(keys), keys)
+		}
 	}
+	g.setDifficulty(g.settings.Difficulty)
 
 	// Initialize random falling discs
 	rand.Seed(time.Now().UnixNano())
@@ -148,9 +414,13 @@ func NewConnectFourGame() *ConnectFourGame {
 		}
 	}
 
-	g.preRenderCircles()
-	g.updateLayout() // Apply layout with default dimensions
+	g.updateLayout() // Apply layout with default dimensions, rendering the circles and fonts
 	g.initUI()       // Initialize UI with default dimensions
+
+	// A remembered login skips the login screen
+	if username, ok := loadSession(g.storage, time.Now()); ok {
+		g.finishLogin(username, "")
+	}
 	return g
 }
 
@@ -181,76 +451,286 @@ func (g *ConnectFourGame) updateLayout() {
 	g.cellSize = 60 * scaleFactor
 	g.boardOffsetX = float64(g.screenWidth-int(float64(Columns)*g.cellSize)) / 2
 	g.boardOffsetY = float64(g.screenHeight) * 0.25
+	g.clampPan()
+	g.updateDeviceScale(deviceScaleFactor())
+}
+
+// relayout rebuilds the screen's widgets for a new window size, keeping what
+// has been typed and which widget has the focus
+func (g *ConnectFourGame) relayout() {
+	inputs := make([]TextInput, len(g.textInputs))
+	for i, input := range g.textInputs {
+		inputs[i] = *input
+	}
+	focused := focusedIndex(g.focusOrder())
+
+	g.initUI()
+
+	if len(inputs) == len(g.textInputs) {
+		for i, input := range g.textInputs {
+			input.value = inputs[i].value
+			input.cursor = inputs[i].cursor
+			input.scrollPos = inputs[i].scrollPos
+			input.revealed = inputs[i].revealed
+			input.err = inputs[i].err
+		}
+	}
+	if order := g.focusOrder(); focused >= 0 && focused < len(order) {
+		g.setFocus(order[focused])
+	}
 }
 
 // initUI sets up the initial UI elements
 func (g *ConnectFourGame) initUI() {
 	g.buttons = []*Button{}
 	g.textInputs = []*TextInput{}
+	g.sliders = []*Slider{}
+	g.toggles = []*Toggle{}
+	g.dropdowns = []*Dropdown{}
+	g.activeInput = nil
+	g.layoutDialog()
+	g.layoutTutorial()
 
 	switch g.state {
 	case StateLogin:
 		// Username input
 		g.textInputs = append(g.textInputs, &TextInput{
-			x:         float64(g.screenWidth)/2 - 100*g.scaleX,
-			y:         220 * g.scaleY, // Moved down a bit
-			w:         200 * g.scaleX,
-			h:         30 * g.scaleY,
-			label:     "Username:",
-			focused:   true,
-			scrollPos: 0,
+			x:           float64(g.screenWidth)/2 - 100*g.scaleX,
+			y:           220 * g.scaleY, // Moved down a bit
+			w:           200 * g.scaleX,
+			h:           30 * g.scaleY,
+			label:       tr("login.username"),
+			placeholder: tr("login.username_placeholder"),
+			focused:     true,
+			scrollPos:   0,
 		})
 		// Password input
 		g.textInputs = append(g.textInputs, &TextInput{
-			x:          float64(g.screenWidth)/2 - 100*g.scaleX,
-			y:          290 * g.scaleY, // Moved down a bit
-			w:          200 * g.scaleX,
-			h:          30 * g.scaleY,
-			label:      "Password:",
-			isPassword: true,
-			scrollPos:  0,
+			x:           float64(g.screenWidth)/2 - 100*g.scaleX,
+			y:           290 * g.scaleY, // Moved down a bit
+			w:           200 * g.scaleX,
+			h:           30 * g.scaleY,
+			label:       tr("login.password"),
+			placeholder: tr("login.password_placeholder"),
+			isPassword:  true,
+			scrollPos:   0,
+		})
+		// Remember me keeps the user logged in between runs
+		g.toggles = append(g.toggles, &Toggle{
+			x:     float64(g.screenWidth)/2 - 100*g.scaleX,
+			y:     345 * g.scaleY,
+			w:     200 * g.scaleX,
+			h:     18 * g.scaleY,
+			label: tr("login.remember_me"),
+			on:    g.rememberMe,
+			onChange: func(on bool) {
+				g.rememberMe = on
+			},
 		})
 		// Login button
 		g.buttons = append(g.buttons, &Button{
 			x:    float64(g.screenWidth)/2 - 50*g.scaleX,
-			y:    350 * g.scaleY, // Moved down a bit
+			y:    380 * g.scaleY, // Below the remember me toggle
 			w:    100 * g.scaleX,
 			h:    40 * g.scaleY,
-			text: "Login",
+			text: tr("login.login"),
 			action: func() {
-				g.username = g.textInputs[0].value
-				g.password = g.textInputs[1].value
-				g.state = StateGameMode
-				g.initUI()
+				g.login()
+			},
+		})
+		// Guest button, for playing without an account
+		g.buttons = append(g.buttons, &Button{
+			x:    float64(g.screenWidth)/2 - 80*g.scaleX,
+			y:    470 * g.scaleY,
+			w:    160 * g.scaleX,
+			h:    30 * g.scaleY,
+			text: tr("login.guest"),
+			action: func() {
+				g.loginAsGuest()
+			},
+		})
+		// Create account button
+		g.buttons = append(g.buttons, &Button{
+			x:    float64(g.screenWidth)/2 - 80*g.scaleX,
+			y:    430 * g.scaleY,
+			w:    160 * g.scaleX,
+			h:    30 * g.scaleY,
+			text: tr("login.create_account"),
+			action: func() {
+				g.openRegister()
+			},
+		})
+		// Settings button, so accessibility options can be set before logging in
+		g.buttons = append(g.buttons, &Button{
+			x:    float64(g.screenWidth) - 120*g.scaleX,
+			y:    20 * g.scaleY,
+			w:    100 * g.scaleX,
+			h:    30 * g.scaleY,
+			text: tr("menu.settings"),
+			action: func() {
+				g.openSettings()
 			},
 		})
-		g.activeInput = g.textInputs[0]
 
 	case StateGameMode:
+		g.welcomeText = tr("menu.welcome", g.username)
+
+		// Edit profile button
+		g.buttons = append(g.buttons, &Button{
+			x:    float64(g.screenWidth) - 120*g.scaleX,
+			y:    20 * g.scaleY,
+			w:    100 * g.scaleX,
+			h:    30 * g.scaleY,
+			text: tr("menu.edit_profile"),
+			action: func() {
+				g.openProfileEditor(g.profile, false)
+			},
+		})
+		// Settings button
+		g.buttons = append(g.buttons, &Button{
+			x:    float64(g.screenWidth) - 120*g.scaleX,
+			y:    60 * g.scaleY,
+			w:    100 * g.scaleX,
+			h:    30 * g.scaleY,
+			text: tr("menu.settings"),
+			action: func() {
+				g.openSettings()
+			},
+		})
+		// Load position button
+		g.buttons = append(g.buttons, &Button{
+			x:      20 * g.scaleX,
+			y:      20 * g.scaleY,
+			w:      140 * g.scaleX,
+			h:      30 * g.scaleY,
+			text:   tr("menu.load_position"),
+			action: g.openLoadPosition,
+		})
+		// Logout button
+		g.buttons = append(g.buttons, &Button{
+			x:      float64(g.screenWidth) - 120*g.scaleX,
+			y:      100 * g.scaleY,
+			w:      100 * g.scaleX,
+			h:      30 * g.scaleY,
+			text:   tr("menu.logout"),
+			action: g.requestLogout,
+		})
 		// Play against computer button
 		g.buttons = append(g.buttons, &Button{
 			x:    float64(g.screenWidth)/2 - 120*g.scaleX,
-			y:    200 * g.scaleY,
+			y:    180 * g.scaleY,
 			w:    240 * g.scaleX,
 			h:    40 * g.scaleY,
-			text: "Play Against Computer",
+			text: tr("menu.play_computer"),
 			action: func() {
-				g.initializeGame()
-				g.state = StateGame
+				g.discardResume() // Starting afresh declines the unfinished game
+				g.startComputerGame()
+			},
+		})
+		// Difficulty button cycles through the levels
+		g.buttons = append(g.buttons, &Button{
+			x:    float64(g.screenWidth)/2 - 120*g.scaleX,
+			y:    230 * g.scaleY,
+			w:    240 * g.scaleX,
+			h:    40 * g.scaleY,
+			text: tr("menu.difficulty", difficultyName(g.difficulty)),
+			action: func() {
+				g.setDifficulty((g.difficulty + 1) % numDifficulties)
+				g.settings.Difficulty = g.difficulty
+				g.settingsSaveTimer = settingsSaveDelay
 				g.initUI()
 			},
 		})
+		// Puzzles button
+		g.buttons = append(g.buttons, &Button{
+			x:      float64(g.screenWidth)/2 - 120*g.scaleX,
+			y:      280 * g.scaleY,
+			w:      115 * g.scaleX,
+			h:      40 * g.scaleY,
+			text:   tr("menu.puzzles"),
+			action: g.openPuzzles,
+		})
+		// Replays button
+		g.buttons = append(g.buttons, &Button{
+			x:    float64(g.screenWidth)/2 + 5*g.scaleX,
+			y:    280 * g.scaleY,
+			w:    115 * g.scaleX,
+			h:    40 * g.scaleY,
+			text: tr("menu.replays"),
+			action: func() {
+				g.openReplayList()
+			},
+		})
+		// Statistics button
+		g.buttons = append(g.buttons, &Button{
+			x:    float64(g.screenWidth)/2 - 120*g.scaleX,
+			y:    330 * g.scaleY,
+			w:    115 * g.scaleX,
+			h:    40 * g.scaleY,
+			text: tr("menu.statistics"),
+			action: func() {
+				g.openStats()
+			},
+		})
+		// Leaderboard button
+		g.buttons = append(g.buttons, &Button{
+			x:    float64(g.screenWidth)/2 + 5*g.scaleX,
+			y:    330 * g.scaleY,
+			w:    115 * g.scaleX,
+			h:    40 * g.scaleY,
+			text: tr("menu.leaderboard"),
+			action: func() {
+				g.openLeaderboard()
+			},
+		})
 		// Play online button
 		g.buttons = append(g.buttons, &Button{
 			x:    float64(g.screenWidth)/2 - 120*g.scaleX,
-			y:    260 * g.scaleY,
-			w:    240 * g.scaleX,
+			y:    380 * g.scaleY,
+			w:    115 * g.scaleX,
 			h:    40 * g.scaleY,
-			text: "Play Online (Coming Soon)",
+			text: tr("menu.play_online"),
 			action: func() {
-				// No action - feature not implemented
+				g.openOnline()
 			},
 		})
+		// Tournament button
+		g.buttons = append(g.buttons, &Button{
+			x:    float64(g.screenWidth)/2 + 5*g.scaleX,
+			y:    380 * g.scaleY,
+			w:    115 * g.scaleX,
+			h:    40 * g.scaleY,
+			text: tr("menu.tournament"),
+			action: func() {
+				g.openTournament()
+			},
+		})
+		// Resume or discard an unfinished game
+		if g.pendingResume != nil {
+			g.buttons = append(g.buttons, &Button{
+				x:    float64(g.screenWidth)/2 - 120*g.scaleX,
+				y:    430 * g.scaleY,
+				w:    240 * g.scaleX,
+				h:    40 * g.scaleY,
+				text: tr("menu.resume"),
+				action: func() {
+					g.resumeGame(g.pendingResume)
+				},
+			})
+			g.buttons = append(g.buttons, &Button{
+				x:    float64(g.screenWidth)/2 - 120*g.scaleX,
+				y:    480 * g.scaleY,
+				w:    240 * g.scaleX,
+				h:    30 * g.scaleY,
+				text: tr("menu.discard"),
+				action: func() {
+					g.confirm(tr("menu.discard_confirm"), tr("menu.discard_yes"), tr("menu.discard_no"), func() {
+						g.discardResume()
+						g.initUI()
+					})
+				},
+			})
+		}
 
 	case StateGame:
 		// No visible buttons for columns, we'll use hover effect
@@ -260,296 +740,773 @@ func (g *ConnectFourGame) initUI() {
 			y:    20 * g.scaleY,
 			w:    100 * g.scaleX,
 			h:    30 * g.scaleY,
-			text: "Back",
+			text: tr("common.back"),
+			action: func() {
+				if g.tournamentMatch != nil {
+					// The match is abandoned and played again later
+					g.openTournament()
+					return
+				}
+				g.suspendGame()
+				g.transitionTo(StateGameMode)
+			},
+		})
+		// Coordinate labels toggle
+		g.buttons = append(g.buttons, &Button{
+			x:    float64(g.screenWidth) - 120*g.scaleX,
+			y:    60 * g.scaleY,
+			w:    100 * g.scaleX,
+			h:    30 * g.scaleY,
+			text: tr("game.labels", onOff(g.showCoordinates)),
 			action: func() {
-				g.state = StateGameMode
+				g.showCoordinates = !g.showCoordinates
 				g.initUI()
 			},
 		})
+		// Move log toggle
+		g.buttons = append(g.buttons, &Button{
+			x:      float64(g.screenWidth) - 120*g.scaleX,
+			y:      100 * g.scaleY,
+			w:      100 * g.scaleX,
+			h:      30 * g.scaleY,
+			text:   g.moveLogToggleText(),
+			action: g.toggleMoveLog,
+		})
+		// Logout button, on the left as the move log sits below the others
+		g.buttons = append(g.buttons, &Button{
+			x:      20 * g.scaleX,
+			y:      20 * g.scaleY,
+			w:      100 * g.scaleX,
+			h:      30 * g.scaleY,
+			text:   tr("menu.logout"),
+			action: g.requestLogout,
+		})
+		// Hand the computer's side to a friend, or back
+		if g.canSwapSeat() {
+			g.buttons = append(g.buttons, &Button{
+				x:      20 * g.scaleX,
+				y:      100 * g.scaleY,
+				w:      140 * g.scaleX,
+				h:      30 * g.scaleY,
+				text:   g.swapSeatText(),
+				action: g.swapSeat,
+			})
+		}
+		g.initUndoUI()
+		g.initOnlineGameUI()
 
 	case StateGameOver:
 		// Play again button - positioned ABOVE the board
 		g.buttons = append(g.buttons, &Button{
 			x:    float64(g.screenWidth)/2 - 80*g.scaleX,
-			y:    g.boardOffsetY - 100*g.scaleY, // Position above board
+			y:    g.boardOffsetY - 100*g.scaleY - g.labelMargin(), // Position above board
 			w:    160 * g.scaleX,
 			h:    40 * g.scaleY,
-			text: "Play Again",
+			text: tr("game.play_again"),
 			action: func() {
-				g.initializeGame()
-				g.state = StateGame
-				g.initUI()
+				g.startComputerGame()
 			},
 		})
+		if g.playedOnline {
+			// Online games ask the same opponent for a rematch
+			g.buttons[len(g.buttons)-1].action = g.offerRematch
+		}
+		if g.tournamentMatch != nil {
+			// Tournament games go back to the bracket instead
+			g.buttons[len(g.buttons)-1].text = tr("tournament.bracket")
+			g.buttons[len(g.buttons)-1].action = func() {
+				g.openTournament()
+			}
+		}
 		// Back to menu button
 		g.buttons = append(g.buttons, &Button{
 			x:    float64(g.screenWidth)/2 - 80*g.scaleX,
-			y:    g.boardOffsetY - 50*g.scaleY, // Position above board
+			y:    g.boardOffsetY - 50*g.scaleY - g.labelMargin(), // Position above board
 			w:    160 * g.scaleX,
 			h:    40 * g.scaleY,
-			text: "Back to Menu",
+			text: tr("game.back_to_menu"),
 			action: func() {
-				g.state = StateGameMode
-				g.initUI()
+				g.transitionTo(StateGameMode)
 			},
 		})
+		// Screenshot button
+		g.buttons = append(g.buttons, &Button{
+			x:    float64(g.screenWidth) - 160*g.scaleX,
+			y:    20 * g.scaleY,
+			w:    140 * g.scaleX,
+			h:    30 * g.scaleY,
+			text: tr("game.save_screenshot"),
+			action: func() {
+				g.saveScreenshot()
+			},
+		})
+		// Analysis button
+		g.buttons = append(g.buttons, &Button{
+			x:    float64(g.screenWidth) - 160*g.scaleX,
+			y:    60 * g.scaleY,
+			w:    140 * g.scaleX,
+			h:    30 * g.scaleY,
+			text: tr("game.analyze"),
+			action: func() {
+				g.openAnalysis()
+			},
+		})
+		// Move log toggle
+		g.buttons = append(g.buttons, &Button{
+			x:      float64(g.screenWidth) - 160*g.scaleX,
+			y:      100 * g.scaleY,
+			w:      140 * g.scaleX,
+			h:      30 * g.scaleY,
+			text:   g.moveLogToggleText(),
+			action: g.toggleMoveLog,
+		})
+		g.initGameSummaryUI()
+
+	case StatePuzzle:
+		// Back button
+		g.buttons = append(g.buttons, &Button{
+			x:    float64(g.screenWidth) - 120*g.scaleX,
+			y:    20 * g.scaleY,
+			w:    100 * g.scaleX,
+			h:    30 * g.scaleY,
+			text: tr("common.back"),
+			action: func() {
+				g.transitionTo(StateGameMode)
+			},
+		})
+		// Next puzzle button, once the current one has been answered
+		if g.puzzleAnswered {
+			g.buttons = append(g.buttons, &Button{
+				x:    float64(g.screenWidth) - 120*g.scaleX,
+				y:    60 * g.scaleY,
+				w:    100 * g.scaleX,
+				h:    30 * g.scaleY,
+				text: tr("puzzle.next"),
+				action: func() {
+					g.startPuzzle()
+					g.initUI()
+				},
+			})
+		}
+
+	case StateReplayList:
+		g.initReplayListUI()
+
+	case StateReplay:
+		g.initReplayUI()
+
+	case StateLeaderboard:
+		g.initLeaderboardUI()
+
+	case StateProfile:
+		g.initProfileUI()
+
+	case StateAnalysis:
+		g.initAnalysisUI()
+
+	case StateSettings:
+		g.initSettingsUI()
+
+	case StateRegister:
+		g.initRegisterUI()
+
+	case StateTournament:
+		g.initTournamentUI()
+
+	case StateOnline:
+		g.initOnlineUI()
+
+	case StateKeyBindings:
+		g.initKeyBindingsUI()
+
+	case StateSpectate:
+		g.initSpectateUI()
+
+	case StateLoadPosition:
+		g.initLoadPositionUI()
+
+	case StateOnlineLeaderboard:
+		g.initOnlineLeaderboardUI()
+
+	case StateStats:
+		// Back button
+		g.buttons = append(g.buttons, &Button{
+			x:    float64(g.screenWidth) - 120*g.scaleX,
+			y:    20 * g.scaleY,
+			w:    100 * g.scaleX,
+			h:    30 * g.scaleY,
+			text: tr("common.back"),
+			action: func() {
+				g.transitionTo(StateGameMode)
+			},
+		})
+	}
+
+	// Online games have the chat alongside
+	g.initChatUI()
+
+	// Each screen starts with its first input focused, if it has any
+	g.focusDefaultInput()
+}
+
+// transitionTo switches to another screen. Anything tied to the screen being
+// left is reset first: an open dialog, the hover and drag state and, when
+// leaving a game, the computer's pending move, which starts over if the game
+// is picked up again, and the focused input, which belongs to the old
+// screen. initUI then builds the new screen's widgets and focuses its first
+// input.
+func (g *ConnectFourGame) transitionTo(state int) {
+	if g.state == StateGame && state != StateGame {
+		g.cancelComputerMove()
+	}
+	if !isOnlineState(state) {
+		g.closeOnline()
+	}
+	g.dialog = nil
+	g.dragging = false
+	g.dragMoved = false
+	g.isHovering = false
+	g.hoverColumn = -1
+	g.pendingColumn = -1
+
+	g.activeInput = nil
+	g.state = state
+	g.initUI()
+}
+
+// startNewGame begins a fresh game against the computer. Repeated requests
+// in the same frame, e.g. a double click across the state change, only
+// start one game.
+func (g *ConnectFourGame) startNewGame() {
+	if g.lastNewGameFrame == g.frame {
+		return
+	}
+	g.lastNewGameFrame = g.frame
+
+	g.initializeGame()
+	g.transitionTo(StateGame)
+}
+
+// startComputerGame begins a game against the computer. In ranked play the
+// first move alternates between the player and the computer from game to
+// game, so neither keeps the first-move advantage.
+func (g *ConnectFourGame) startComputerGame() {
+	if g.lastNewGameFrame == g.frame {
+		return
+	}
+	g.startNewGame()
+	g.maybeStartTutorial()
+	if !g.settings.AlternateStart {
+		return
+	}
+
+	starter := Player
+	if g.lastStarter == Player {
+		starter = Computer
+	}
+	g.setStarter(starter)
+	g.lastStarter = starter
+	if starter == Player {
+		g.showToast(tr("game.you_start"))
+	} else {
+		g.showToast(tr("game.computer_starts"))
 	}
 }
 
-// initializeGame sets up a new game
+// setStarter gives the first move of the game that is about to begin to side
+func (g *ConnectFourGame) setStarter(side int) {
+	firstPlayer = side
+	g.turn = side
+}
+
+// initializeGame sets up a new game, abandoning any computer move still
+// being worked out for the previous one
 func (g *ConnectFourGame) initializeGame() {
-	g.board = GameBoard{}
+	g.gameID++
+	g.dragging = false
+	g.dragMoved = false
 	g.gameInProgress = true
-	g.turn = Player
 	g.gameResult = ""
 	g.hoverColumn = -1
 	g.isHovering = false
-	g.computerThinking = false
-	g.thinkingTimer = 0
+	g.searchTimes = SearchTimes{}
+	g.practiceGame = nil
+	g.inBook = false
+	g.hotseat = false
+	g.seatSwapped = false
+	g.closeOnline()
+	g.playedOnline = false
+	g.tournamentMatch = nil
+	g.fromPosition = false
+	g.undoBudget = g.settings.UndoLimit
+	g.takebacks = 0
+	g.moveQualityTimer = 0
+	g.gameStarted = time.Now()
+	firstPlayer = Player
+	setVariant(g.settings.Variant)
+	g.clearBoard()
+}
+
+// clearBoard empties the board and hands the move to the side that starts
+func (g *ConnectFourGame) clearBoard() {
+	g.turn = firstPlayer
+	g.moves = nil
+	g.cancelComputerMove()
+	g.dropAnim = nil
+	g.pendingColumn = -1
+
+	// A zero GameBoard is empty
+	g.setBoard(GameBoard{})
+}
+
+// login accepts the entered credentials and moves on to the game mode screen
+func (g *ConnectFourGame) login() {
+	usernameInput, passwordInput := g.textInputs[0], g.textInputs[1]
+	account, hasAccount := loadAccount(g.storage, usernameInput.value)
+	errs := validateLogin(usernameInput.value, passwordInput.value, hasAccount)
+	if len(errs) == 0 && hasAccount && !account.checkPassword(passwordInput.value) {
+		errs[fieldPassword] = "login.error.wrong_password"
+	}
+	if len(errs) > 0 {
+		if key, ok := errs[fieldUsername]; ok {
+			g.showInputError(usernameInput, loginErrorText(key))
+		}
+		if key, ok := errs[fieldPassword]; ok {
+			g.showInputError(passwordInput, loginErrorText(key))
+		}
+		return
+	}
+	g.finishLogin(usernameInput.value, passwordInput.value)
+}
 
-	// Initialize the board to empty
-	for row := range g.board {
-		for col := range g.board[row] {
-			g.board[row][col] = Empty
+// finishLogin signs in a user whose details have been checked
+func (g *ConnectFourGame) finishLogin(username, password string) {
+	g.guest = false
+	g.userStore = g.storage
+	g.username = username
+	g.password = password
+	if g.rememberMe {
+		if err := createSession(g.storage, username, time.Now()); err != nil {
+			log.Printf("remembering login: %v", err)
 		}
 	}
+	g.pendingResume = loadResume(g.userStore, g.username)
+
+	// New usernames set up a profile before continuing
+	g.loadUserAdaptive()
+	profile, found := loadProfile(g.userStore, g.username)
+	g.applyProfile(profile)
+	if !found {
+		g.openProfileEditor(profile, true)
+		return
+	}
+	g.transitionTo(StateGameMode)
+}
+
+// endGame finishes the current game with the given winner (Empty for a tie)
+// and records it for the replay viewer
+func (g *ConnectFourGame) endGame(winner int) {
+	if g.hotseat || g.seatSwapped || g.online != nil {
+		g.endTwoPlayerGame(winner)
+		return
+	}
+	if g.fromPosition {
+		// Not recorded, as the moves don't start from an empty board
+		g.gameResult = (&SavedGame{Winner: winner}).resultText()
+		g.finishedGame = nil
+		g.gameInProgress = false
+		g.transitionTo(StateGameOver)
+		return
+	}
+
+	record := SavedGame{
+		Username:   g.username,
+		Played:     time.Now(),
+		Difficulty: g.difficulty,
+		Winner:     winner,
+		Moves:      g.moves,
+		Guest:      g.guest,
+		Variant:    gameVariant,
+	}
+	if err := saveGame(g.gamesStore(), record); err != nil {
+		log.Printf("saving game: %v", err)
+	}
+	g.finishedGame = &record
+
+	result := GameResult{
+		Played:     record.Played,
+		Difficulty: g.difficulty,
+		Winner:     winner,
+		Moves:      len(g.moves),
+		Starter:    firstPlayer,
+	}
+	if err := recordResult(g.userStore, g.username, result); err != nil {
+		log.Printf("saving stats: %v", err)
+	}
+	deleteResume(g.userStore, g.username)
+	g.recordAdaptiveResult(winner)
+	g.session.record(winner)
+
+	g.gameResult = record.resultText()
+	g.gameInProgress = false
+	g.transitionTo(StateGameOver)
+}
+
+// canPlayerMove is the single authority on whether the player may drop a
+// piece in the current game right now
+func (g *ConnectFourGame) canPlayerMove() bool {
+	return g.state == StateGame && g.gameInProgress && !g.seatIsComputer(g.turn) && !g.seatIsRemote(g.turn) &&
+		!g.computerThinking && g.dialog == nil && g.dropAnim == nil
+}
+
+// boardInputActive reports whether the board currently accepts column picks.
+// It doesn't while the chat has the keys, so typing a number there drops
+// nothing.
+func (g *ConnectFourGame) boardInputActive() bool {
+	if g.activeInput != nil {
+		return false
+	}
+	switch g.state {
+	case StateGame:
+		return g.canPlayerMove()
+	case StatePuzzle:
+		return g.puzzle != nil && !g.puzzleAnswered
+	}
+	return false
 }
 
 // Update is called every frame to update the game state
 func (g *ConnectFourGame) Update() error {
-	// Check if window size changed and update layout
-	if w, h := ebiten.WindowSize(); w != g.screenWidth || h != g.screenHeight {
-		g.screenWidth = w
-		g.screenHeight = h
-		g.updateLayout()
-		g.initUI()
+	// Keep an unfinished game when the window is closed mid-game
+	if ebiten.IsWindowBeingClosed() {
+		g.suspendGame()
+		if g.settingsSaveTimer > 0 {
+			g.flushSettings()
+		}
+		return ebiten.Termination
 	}
 
+	// Remember the window size for next time
+	g.trackWindowSettings()
+
+	// Update the layout once the window size stops changing
+	g.applyWindowSize()
+
 	// Rest of the Update function remains unchanged
 	// ...
 
+	// Slow down on quiet screens, and back up once something is happening
+	g.updateTickRate()
+
+	// Count down the toast notification and move quality tag
+	countDown(&g.toastTimer)
+	countDown(&g.moveQualityTimer)
+
+	// F11 switches fullscreen on and off; the layout follows on the next frame
+	if action(ActionFullscreen) {
+		g.toggleFullscreen()
+	}
+
+	// Save a screenshot of the board with P, unless typing in the chat
+	if (g.state == StateGame || g.state == StateGameOver) && g.activeInput == nil && action(ActionScreenshot) {
+		g.saveScreenshot()
+	}
+
 	// Update animation timer and falling discs
-	g.animTimer += 1.0 / 60.0
-	if g.state == StateLogin {
-		for i := range g.fallingDiscs {
-			disc := &g.fallingDiscs[i]
-			disc.y += disc.speed
-			if disc.y > float64(g.screenHeight) {
-				disc.y = -float64(disc.size)
-				disc.x = float64(rand.Intn(g.screenWidth))
-				disc.color = g.randomDiscColor()
-				disc.opacity = uint8(100 + rand.Intn(155))
-			}
+	// Falling discs and drops move in steps tuned for 60 ticks a second
+	g.frame++
+	g.animTimer += tickSeconds()
+	focused := ebiten.IsFocused()
+	for range g.baseSteps() {
+		if hasMenuBackground(g.state) {
+			g.updateFallingDiscs(focused)
 		}
+		g.updateDropAnimation()
+	}
+	g.updateEvalBar()
+
+	// The menu's demo game runs while nobody touches anything, and the
+	// input that stops it does nothing else
+	if g.updateAttract() {
+		return nil
+	}
+
+	// Drag the board around when it doesn't fit in the window
+	if g.updatePan() {
+		g.handleBoardClick()
+	}
+
+	// A modal dialog takes all input until it is answered
+	if g.dialog != nil {
+		g.updateDialog()
+		return nil
+	}
+
+	// So does the tutorial, until it is finished or skipped
+	if g.tutorial != nil {
+		g.updateTutorial()
+		return nil
+	}
+
+	// And getting an online game's connection back, until it is back or
+	// given up
+	if g.reconnecting() {
+		g.updateOnline()
+		return nil
+	}
+
+	// An open dropdown list takes all input until it closes
+	if g.updateDropdowns() {
+		return nil
+	}
+
+	// Developer shortcuts and the F3 overlay
+	g.updateDebugKeys()
+	g.updateDebugOverlay()
+
+	// Point out the legal columns with L
+	g.updateLegalMoves()
+
+	// Drop a piece with the number keys, take back a move and show the
+	// evaluation bar
+	g.updateGameKeys()
+
+	// Drag sliders and nudge the focused one with the arrow keys
+	g.updateSliders()
+
+	// Scroll the leaderboard
+	if g.state == StateLeaderboard {
+		g.updateLeaderboard()
+	}
+
+	// Step through replays with the keyboard and autoplay
+	if g.state == StateReplay {
+		g.updateReplay()
+	}
+
+	// Check the registration form as it is filled in
+	if g.state == StateRegister {
+		g.updateRegister()
+	}
+
+	// Take up disc labels as they are typed
+	if g.state == StateSettings {
+		g.updateDiscLabels()
+	}
+
+	// Analyze the finished game a move at a time
+	if g.state == StateAnalysis {
+		g.updateAnalysis()
 	}
 
 	// Handle mouse for hover effects in game state
-	if g.state == StateGame && g.gameInProgress && g.turn == Player {
+	if g.boardInputActive() {
 		x, y := ebiten.CursorPosition()
+		originX, originY := g.boardOrigin()
+		g.hoverColumn = g.columnAt(float64(x), float64(y), originX, originY)
+		g.isHovering = g.hoverColumn >= 0
+	}
 
-		// Check if mouse is over the board area
-		if y >= int(g.boardOffsetY) && y < int(g.boardOffsetY)+int(float64(Rows)*g.cellSize) {
-			g.isHovering = false
-			g.hoverColumn = -1
-
-			for col := 0; col < Columns; col++ {
-				colX := int(g.boardOffsetX) + int(float64(col)*g.cellSize)
-				if x >= colX && x < colX+int(g.cellSize) {
-					g.hoverColumn = col
-					g.isHovering = true
-					break
-				}
-			}
-		} else {
-			g.isHovering = false
-			g.hoverColumn = -1
-		}
+	// Keyboard focus and activation
+	if g.updateFocus() {
+		return nil
 	}
 
 	// Handle mouse clicks
 	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
 		x, y := ebiten.CursorPosition()
 
-		// Check if we're in game state and clicking on the board
-		if g.state == StateGame && g.gameInProgress && g.turn == Player &&
-			g.isHovering && g.hoverColumn >= 0 && g.hoverColumn < Columns {
-			if g.board[0][g.hoverColumn] == Empty {
-				// Player move
-				g.board = dropPiece(g.board, g.hoverColumn, Player)
-
-				// Check for win or tie
-				if checkWin(g.board, Player) {
-					g.gameResult = "You Won!"
-					g.gameInProgress = false
-					g.state = StateGameOver
-					g.initUI()
-				} else if isBoardFull(g.board) {
-					g.gameResult = "It's a Tie!"
-					g.gameInProgress = false
-					g.state = StateGameOver
-					g.initUI()
-				} else {
-					g.turn = Computer
-				}
-			}
+		// Board clicks wait for the button release while the board can be
+		// panned, so that a drag doesn't drop a piece
+		if !g.panEnabled() {
+			g.handleBoardClick()
 		}
 
 		// Check button clicks
 		for _, btn := range g.buttons {
-			if float64(x) >= btn.x && float64(x) < btn.x+btn.w &&
-				float64(y) >= btn.y && float64(y) < btn.y+btn.h {
-				btn.action()
+			if btn.containsPoint(float64(x), float64(y)) {
+				if !btn.disabled {
+					btn.action()
+				}
 				return nil
 			}
 		}
 
+		// Check toggle clicks
+		for _, toggle := range g.toggles {
+			if toggle.contains(float64(x), float64(y)) {
+				g.setFocus(toggle)
+				toggle.toggle()
+				return nil
+			}
+		}
+
+		// Check dropdown clicks
+		for _, d := range g.dropdowns {
+			if d.contains(float64(x), float64(y)) {
+				g.setFocus(d)
+				d.openList()
+				return nil
+			}
+		}
+
+		// Finished games can be replayed from a move in the move log
+		if g.state == StateGameOver && g.handleMoveLogClick(x, y) {
+			return nil
+		}
+
 		// Check text input focus
+		clickedInput := false
 		for _, input := range g.textInputs {
-			if float64(x) >= input.x && float64(x) < input.x+input.w &&
-				float64(y) >= input.y && float64(y) < input.y+input.h {
-				// Set this input as active
-				g.activeInput = input
-
-				// Only this input should be focused
-				for _, otherInput := range g.textInputs {
-					otherInput.focused = (otherInput == input)
+			if input.containsPoint(float64(x), float64(y)) {
+				// Set this input as active, and the only focused widget,
+				// with the cursor where it was clicked
+				g.setFocus(input)
+				if input.revealContains(float64(x), float64(y)) {
+					input.toggleReveal()
+				} else {
+					input.setCursor(input.cursorAt(float64(x)))
 				}
+				clickedInput = true
 				break
 			}
 		}
+
+		// Clicking anywhere else in the game leaves the chat
+		if !clickedInput && g.activeInput != nil && (g.state == StateGame || g.state == StateGameOver) {
+			g.setFocus(nil)
+		}
 	}
 
 	// Handle keyboard input for text fields
-	if g.activeInput != nil {
-		// Handle text input
-		runes := ebiten.InputChars()
-		if len(runes) > 0 {
-			g.activeInput.value += string(runes)
+	g.updateActiveInput()
 
-			// Update scroll position if needed
-			g.updateTextScroll(g.activeInput)
-		}
+	// Moves and news from the game server in online play, and its leaderboard
+	g.updateOnline()
+	g.updateOnlineLeaderboard()
 
-		// Handle backspace with key repeat
-		if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) {
-			g.backspacePressed = true
-			g.backspaceDelay = 15 // Reset delay counter
+	// The puzzle set, once it has loaded
+	g.updatePuzzleFetch()
 
-			// Process the first backspace immediately
-			if len(g.activeInput.value) > 0 {
-				g.activeInput.value = g.activeInput.value[:len(g.activeInput.value)-1]
+	// Computer move logic
+	g.updateComputerMove()
 
-				// Update scroll position when deleting
-				if g.activeInput.scrollPos > 0 {
-					g.activeInput.scrollPos = max(0, g.activeInput.scrollPos-1)
-				}
-			}
-		} else if ebiten.IsKeyPressed(ebiten.KeyBackspace) && g.backspacePressed {
-			// Key is being held down
-			if g.backspaceDelay > 0 {
-				g.backspaceDelay--
-			} else {
-				// After delay, start repeating at the defined interval
-				g.backspaceRepeat--
-				if g.backspaceRepeat <= 0 {
-					g.backspaceRepeat = 3 // Reset repeat counter (3 frames ≈ 50ms at 60fps)
-
-					if len(g.activeInput.value) > 0 {
-						g.activeInput.value = g.activeInput.value[:len(g.activeInput.value)-1]
-
-						// Update scroll position when deleting
-						if g.activeInput.scrollPos > 0 {
-							g.activeInput.scrollPos = max(0, g.activeInput.scrollPos-1)
-						}
-					}
-				}
-			}
-		} else if inpututil.IsKeyJustReleased(ebiten.KeyBackspace) {
-			g.backspacePressed = false
-		}
+	return nil
+}
 
-		// Handle tab to switch inputs
-		if inpututil.IsKeyJustPressed(ebiten.KeyTab) && len(g.textInputs) > 1 {
-			for i, input := range g.textInputs {
-				if input == g.activeInput {
-					g.activeInput.focused = false
-					g.activeInput = g.textInputs[(i+1)%len(g.textInputs)]
-					g.activeInput.focused = true
-					break
-				}
-			}
-		}
+// hasMenuBackground reports whether a screen shows the falling discs
+func hasMenuBackground(state int) bool {
+	switch state {
+	case StateLogin, StateGameMode, StateSettings:
+		return true
+	}
+	return false
+}
 
-		// Handle enter key
-		if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
-			if g.state == StateLogin {
-				g.username = g.textInputs[0].value
-				g.password = g.textInputs[1].value
-				g.state = StateGameMode
-				g.initUI()
-			}
+// updateFallingDiscs moves the decorative menu background discs. They hold
+// still while the window isn't focused and carry on from the same place
+// when it is again.
+func (g *ConnectFourGame) updateFallingDiscs(focused bool) {
+	if !g.decorationsActive(focused) {
+		return
+	}
+	for i := range g.fallingDiscs {
+		disc := &g.fallingDiscs[i]
+		disc.y += disc.speed
+		if disc.y > float64(g.screenHeight) {
+			disc.y = -float64(disc.size)
+			disc.x = float64(rand.Intn(g.screenWidth))
+			disc.color = g.randomDiscColor()
+			disc.opacity = uint8(100 + rand.Intn(155))
 		}
 	}
+}
 
-	// Computer move logic
-	if g.state == StateGame && g.gameInProgress && g.turn == Computer {
-		if !g.computerThinking {
-			// Start thinking
-			g.computerThinking = true
-			g.thinkingTimer = 18 // 18 frames = ~300ms at 60fps
-		} else {
-			// Continue thinking until timer expires
-			g.thinkingTimer--
-			if g.thinkingTimer <= 0 {
-				// Make move after thinking
-				computerCol := getComputerMove(g.board, 5)
-				g.board = dropPiece(g.board, computerCol, Computer)
-				g.computerThinking = false
-
-				// Check if computer won
-				if checkWin(g.board, Computer) {
-					g.gameResult = "Computer Won!"
-					g.gameInProgress = false
-					g.state = StateGameOver
-					g.initUI()
-				} else if isBoardFull(g.board) {
-					g.gameResult = "It's a Tie!"
-					g.gameInProgress = false
-					g.state = StateGameOver
-					g.initUI()
-				} else {
-					g.turn = Player
-				}
+// handleBoardClick applies a click on the hovered board column
+func (g *ConnectFourGame) handleBoardClick() {
+	if g.isHovering && g.hoverColumn >= 0 && g.hoverColumn < Columns {
+		g.chooseColumn(g.hoverColumn)
+	} else {
+		// Clicking away from the board cancels an unconfirmed move
+		g.pendingColumn = -1
+	}
+}
+
+// chooseColumn plays col, or in confirm-move mode selects it first and only
+// plays it once it is chosen a second time
+func (g *ConnectFourGame) chooseColumn(col int) {
+	if g.settings.ConfirmMoves && g.state == StateGame {
+		if g.pendingColumn != col {
+			g.pendingColumn = -1
+			if g.canPlayerMove() && !g.board.columnFull(col) {
+				g.pendingColumn = col
 			}
+			return
 		}
+		g.pendingColumn = -1
 	}
-
-	return nil
+	if g.warnBlunder(col) {
+		return
+	}
+	g.dropInColumn(col)
 }
 
-// updateTextScroll updates the text scroll position when input exceeds visible space
-func (g *ConnectFourGame) updateTextScroll(input *TextInput) {
-	// Calculate the visible width of the text field
-	visibleWidth := int(input.w) - 10 // 10 pixels for padding
-
-	// Calculate the text width (approximate)
-	displayText := input.value
-	if input.isPassword {
-		displayText = strings.Repeat("*", len(input.value))
+// dropInColumn plays the player's piece in col, from either a click or a key press
+func (g *ConnectFourGame) dropInColumn(col int) {
+	if g.board.columnFull(col) {
+		return
 	}
 
-	// Each character is approximately 7 pixels wide
-	textWidth := len(displayText) * 7
+	// Check if we're in game state
+	if g.canPlayerMove() {
+		// Player move, or either side's in a two-player game
+		mover := Player
+		switch {
+		case g.hotseat:
+			mover = g.turn
+		case g.online == nil:
+			// Rating moves online would be the engine playing for the player
+			g.rateMove(col)
+		}
+		g.setBoard(dropPiece(g.board, col, mover))
+		g.animateDrop(col)
+		g.moves = append(g.moves, Move{Column: col, Player: mover})
+		if g.online != nil {
+			g.online.send(MoveMessage{Column: col})
+		}
+
+		// Check for win or tie
+		if winner, over := gameOver(g.board); over {
+			g.endGame(winner)
+		} else {
+			g.turn = opponentOf(mover)
+		}
+	}
 
-	// If text exceeds visible width, adjust scroll position
-	if textWidth > visibleWidth {
-		// Set scroll position to show the end of the text
-		input.scrollPos = len(displayText) - (visibleWidth / 7)
+	// Check if we're solving a puzzle
+	if g.state == StatePuzzle && g.boardInputActive() {
+		g.answerPuzzle(col)
 	}
 }
 
+// resetCaretBlink restarts the caret blink cycle so the caret shows solid after input
+func (g *ConnectFourGame) resetCaretBlink() {
+	g.caretBlink = g.animTimer
+}
+
+// caretVisible reports whether the blinking caret is in its visible phase
+func (g *ConnectFourGame) caretVisible() bool {
+	// Visible for the first 500ms of every second since the last input
+	return math.Mod(g.animTimer-g.caretBlink, 1.0) < 0.5
+}
+
 // Draw renders the game screen
 func (g *ConnectFourGame) Draw(screen *ebiten.Image) {
+	// Quiet screens only redraw after an update
+	if g.skipDraw(screen) {
+		return
+	}
+
 	// Clear screen
 	screen.Fill(colorBackground)
 
@@ -561,16 +1518,82 @@ func (g *ConnectFourGame) Draw(screen *ebiten.Image) {
 		g.drawGameModeScreen(screen)
 	case StateGame, StateGameOver:
 		g.drawGameScreen(screen)
+	case StatePuzzle:
+		g.drawPuzzleScreen(screen)
+	case StateReplayList:
+		g.drawReplayListScreen(screen)
+	case StateReplay:
+		g.drawReplayScreen(screen)
+	case StateStats:
+		g.drawStatsScreen(screen)
+	case StateLeaderboard:
+		g.drawLeaderboardScreen(screen)
+	case StateProfile:
+		g.drawProfileScreen(screen)
+	case StateAnalysis:
+		g.drawAnalysisScreen(screen)
+	case StateSettings:
+		g.drawSettingsScreen(screen)
+	case StateRegister:
+		g.drawRegisterScreen(screen)
+	case StateTournament:
+		g.drawTournamentScreen(screen)
+	case StateOnline:
+		g.drawOnlineScreen(screen)
+	case StateKeyBindings:
+		g.drawKeyBindingsScreen(screen)
+	case StateSpectate:
+		g.drawSpectateScreen(screen)
+	case StateLoadPosition:
+		g.drawLoadPositionScreen(screen)
+	case StateOnlineLeaderboard:
+		g.drawOnlineLeaderboardScreen(screen)
 	}
+
+	g.drawTutorial(screen)
+	g.drawReconnecting(screen)
+	g.drawDialog(screen)
+	g.drawToast(screen)
+	g.drawDebugOverlay(screen)
 }
 
-// Update the drawLoginScreen function with larger title
-func (g *ConnectFourGame) drawLoginScreen(screen *ebiten.Image) {
-	// Draw animated background discs
+// How long a toast notification stays up
+const toastDuration = 3 * time.Second
+
+// showToast displays a short notification for a few seconds
+func (g *ConnectFourGame) showToast(message string) {
+	g.toastMessage = message
+	g.toastTimer = toastDuration
+}
+
+// drawToast renders the current notification, if any, near the bottom of the screen
+func (g *ConnectFourGame) drawToast(screen *ebiten.Image) {
+	if g.toastTimer <= 0 {
+		return
+	}
+
+	bounds := boundString(fontFace, g.toastMessage)
+	w := float64(bounds.Dx() + g.px(20))
+	h := float64(g.px(30))
+	x := float64(g.screenWidth)/2 - w/2
+	y := float64(g.screenHeight) - h - 20*g.scaleY
+
+	drawRect(screen, x, y, w, h, color.RGBA{40, 40, 40, 220})
+	text.Draw(screen, g.toastMessage, fontFace,
+		int(x)+g.px(10), int(y+h/2)+g.px(4), colorButtonText)
+}
+
+// drawFallingDiscs draws the animated menu background
+func (g *ConnectFourGame) drawFallingDiscs(screen *ebiten.Image) {
 	for _, disc := range g.fallingDiscs {
 		g.drawSmoothCircle(screen, int(disc.x), int(disc.y), disc.size/2,
 			color.RGBA{disc.color.R, disc.color.G, disc.color.B, disc.opacity})
 	}
+}
+
+// Update the drawLoginScreen function with larger title
+func (g *ConnectFourGame) drawLoginScreen(screen *ebiten.Image) {
+	g.drawFallingDiscs(screen)
 
 	// Draw decorative board image in background
 	boardSize := 300 * g.scaleX
@@ -578,33 +1601,22 @@ func (g *ConnectFourGame) drawLoginScreen(screen *ebiten.Image) {
 	boardY := 50 * g.scaleY
 
 	// Draw board outline
-	ebitenutil.DrawRect(screen, boardX, boardY, boardSize, boardSize,
+	drawRect(screen, boardX, boardY, boardSize, boardSize,
 		color.RGBA{100, 100, 180, 100})
 
-	// Draw title at 3x size
-	title := "CONNECT FOUR"
-	titleFont := basicfont.Face7x13
-
-	// Create a temporary image for the title text
-	titleImg := ebiten.NewImage(titleFont.Metrics().Height.Round()*len(title), titleFont.Metrics().Height.Round()*2)
-
-	// Draw the title to the temporary image
-	text.Draw(titleImg, title, titleFont, 0, titleFont.Metrics().Height.Round(), colorTitleText)
-
-	// Draw main text with an additional offset to the right
-	mainOp := &ebiten.DrawImageOptions{}
-	mainOp.GeoM.Scale(3.0, 3.0) // 3x size
-	mainOp.GeoM.Translate(
-		float64(g.screenWidth)/2-float64(titleFont.Metrics().Height.Round()*len(title))*1.5+110, // Added 110px offset to the right
-		float64(120*g.scaleY)-float64(titleFont.Metrics().Height.Round())*1.5,
-	)
-	screen.DrawImage(titleImg, mainOp)
+	// Draw title
+	drawTitle(screen, tr("login.title"), float64(g.screenWidth)/2, 120*g.scaleY+20)
 
 	// Draw text inputs
 	for _, input := range g.textInputs {
 		g.drawTextInput(screen, input)
 	}
 
+	// Draw toggles
+	for _, toggle := range g.toggles {
+		g.drawToggle(screen, toggle)
+	}
+
 	// Draw buttons
 	for _, btn := range g.buttons {
 		g.drawButton(screen, btn)
@@ -613,16 +1625,20 @@ func (g *ConnectFourGame) drawLoginScreen(screen *ebiten.Image) {
 
 // drawGameModeScreen renders the game mode selection UI
 func (g *ConnectFourGame) drawGameModeScreen(screen *ebiten.Image) {
+	g.drawFallingDiscs(screen)
+	g.drawAttract(screen)
+
 	// Welcome message
-	welcome := fmt.Sprintf("Welcome, %s", g.username)
-	welcomeBounds := text.BoundString(basicfont.Face7x13, welcome)
-	text.Draw(screen, welcome, basicfont.Face7x13,
-		g.screenWidth/2-welcomeBounds.Dx()/2, int(100*g.scaleY), colorText)
+	welcome := g.welcomeText
+	welcomeBounds := boundString(fontFace, welcome)
+	welcomeX := g.screenWidth/2 - welcomeBounds.Dx()/2
+	text.Draw(screen, welcome, fontFace, welcomeX, int(100*g.scaleY), colorText)
+	g.drawAvatar(screen, g.profile.Avatar, float64(welcomeX)-28, 100*g.scaleY-18, 24)
 
 	// Subtitle
-	subtitle := "Select Game Mode:"
-	subtitleBounds := text.BoundString(basicfont.Face7x13, subtitle)
-	text.Draw(screen, subtitle, basicfont.Face7x13,
+	subtitle := tr("menu.select_mode")
+	subtitleBounds := boundString(fontFace, subtitle)
+	text.Draw(screen, subtitle, fontFace,
 		g.screenWidth/2-subtitleBounds.Dx()/2, int(150*g.scaleY), colorText)
 
 	// Draw buttons
@@ -640,242 +1656,274 @@ func (g *ConnectFourGame) drawGameScreen(screen *ebiten.Image) {
 	if g.state == StateGameOver {
 		statusText = g.gameResult
 		statusY = int(g.boardOffsetY - 130*g.scaleY)
+	} else if g.hotseat {
+		statusText = tr("game.turn_of", g.sideNames[g.turn-Player])
+		statusY = int(100 * g.scaleY)
+	} else if len(g.moves) == 0 && g.turn == Computer {
+		statusText = tr("game.computer_starts")
+		statusY = int(100 * g.scaleY)
 	} else if g.turn == Player {
-		statusText = "Your turn - select a column"
+		statusText = tr("game.your_turn")
 		statusY = int(100 * g.scaleY)
 	} else {
-		statusText = "Computer is thinking..."
+		statusText = tr("game.thinking")
 		statusY = int(100 * g.scaleY)
 	}
 
-	statusBounds := text.BoundString(basicfont.Face7x13, statusText)
-	text.Draw(screen, statusText, basicfont.Face7x13,
+	statusBounds := boundString(fontFace, statusText)
+	text.Draw(screen, statusText, fontFace,
 		g.screenWidth/2-statusBounds.Dx()/2, statusY, colorText)
+	g.drawMoveQuality(screen, g.screenWidth/2+statusBounds.Dx()/2, statusY)
+	g.drawOnlineClock(screen, g.screenWidth/2-statusBounds.Dx()/2, statusY)
+
+	// Show the adaptive level quietly in the corner, below the Undo and
+	// seat buttons
+	if g.difficulty == DifficultyAdaptive && !g.hotseat && g.practiceGame == nil {
+		text.Draw(screen, g.adaptiveLevelText(), fontFace,
+			int(20*g.scaleX), int(150*g.scaleY), colorText)
+	}
+
+	// Show whether a practice game is still following the recording
+	if practice := g.practiceStatus(); practice != "" {
+		practiceBounds := boundString(fontFace, practice)
+		text.Draw(screen, practice, fontFace,
+			g.screenWidth/2-practiceBounds.Dx()/2, statusY+20, colorText)
+		statusY += 20
+	}
+	g.drawFoursTally(screen, statusY+20)
+
+	g.drawBoard(screen)
+	originX, originY := g.boardOrigin()
+	g.drawLegalMoves(screen, originX, originY)
+	g.drawEvalBar(screen, originX, originY)
+	g.drawMoveLog(screen)
+	g.drawChat(screen)
+	if g.state == StateGameOver {
+		g.drawGameSummary(screen)
+	}
+
+	// Draw buttons
+	for _, btn := range g.buttons {
+		g.drawButton(screen, btn)
+	}
+}
+
+// drawBoard renders the current board at its on-screen position with the hover preview
+func (g *ConnectFourGame) drawBoard(screen *ebiten.Image) {
+	originX, originY := g.boardOrigin()
 
-	// Draw board background (gray border)
-	boardWidth := float64(Columns) * g.cellSize
-	boardHeight := float64(Rows) * g.cellSize
-	ebitenutil.DrawRect(screen,
-		g.boardOffsetX-4, g.boardOffsetY-4,
-		boardWidth+8, boardHeight+8,
-		colorBoardBg)
-
-	// Draw board background (solid color)
-	ebitenutil.DrawRect(screen,
-		g.boardOffsetX, g.boardOffsetY,
-		boardWidth, boardHeight,
-		color.RGBA{160, 160, 160, 255}) // Darker gray background for contrast
-
-	// Draw board with proper spacing between circles
+	// A falling disc is drawn separately until it settles in its cell
+	board := g.board
+	if g.dropAnim != nil {
+		board.Set(g.dropAnim.row, g.dropAnim.col, Empty)
+	}
+	// Pieces go behind the frame, so a falling disc slides down behind it
+	g.drawBoardSlots(screen, board, originX, originY, g.cellSize)
+	if g.dropAnim != nil {
+		g.drawDropAnimation(screen, originX, originY)
+	}
+	g.drawBoardFrame(screen, originX, originY, g.cellSize)
+	g.drawDiscLabels(screen, board, originX, originY)
+	g.drawColumnHighlight(screen, originX, originY)
+	if g.dropAnim == nil && g.settings.HighlightLastMove {
+		g.drawLastMoveHighlight(screen, originX, originY)
+	}
+	if g.showCoordinates {
+		g.drawCoordinates(screen, originX, originY, g.cellSize)
+	}
+	if g.settings.CapacityBars {
+		g.drawCapacityBars(screen, originX, originY)
+	}
+	g.drawThreatGhosts(screen, originX, originY)
+
+	// Draw the selected column in confirm-move mode more strongly than a hover
+	if g.canPlayerMove() && g.pendingColumn >= 0 {
+		x := int(originX + float64(g.pendingColumn)*g.cellSize + g.cellSize/2)
+		y := int(originY + g.cellSize/2) // Top row
+		pending := g.hoverColor()
+		pending.A = 170
+		g.drawSmoothCircle(screen, x, y, g.cellSize*0.4, pending)
+	}
+
+	// Draw hover effect, a ghost where the piece would land with the column
+	// highlight on and a circle over the top row without it
+	if g.settings.ColumnHighlight {
+		if g.hoverColumn != g.pendingColumn {
+			g.drawLandingGhost(screen, originX, originY)
+		}
+	} else if g.boardInputActive() && g.isHovering && g.hoverColumn >= 0 && g.hoverColumn != g.pendingColumn {
+		if !g.board.columnFull(g.hoverColumn) {
+			x := int(originX + float64(g.hoverColumn)*g.cellSize + g.cellSize/2)
+			y := int(originY + g.cellSize/2) // Top row
+			radius := g.cellSize * 0.4
+			g.drawSmoothCircle(screen, x, y, radius, g.hoverColor())
+		}
+	}
+}
+
+// lastMoveCell returns the cell of the most recently placed piece, which is
+// the top piece in the column of the last move
+func lastMoveCell(board GameBoard, moves []Move) (row, col int, ok bool) {
+	if len(moves) == 0 {
+		return 0, 0, false
+	}
+	col = moves[len(moves)-1].Column
+	for row = 0; row < Rows; row++ {
+		if board.At(row, col) != Empty {
+			return row, col, true
+		}
+	}
+	return 0, 0, false
+}
+
+// drawLastMoveHighlight rings the most recently placed piece
+func (g *ConnectFourGame) drawLastMoveHighlight(screen *ebiten.Image, originX, originY float64) {
+	row, col, ok := lastMoveCell(g.board, g.moves)
+	if !ok {
+		return
+	}
+	pieceColor := g.playerColor
+	if g.board.At(row, col) == Computer {
+		pieceColor = g.computerColor
+	}
+	x := int(originX + float64(col)*g.cellSize + g.cellSize/2)
+	y := int(originY + float64(row)*g.cellSize + g.cellSize/2)
+	ring := colorBackground
+	ring.A = 160
+	g.drawSmoothCircle(screen, x, y, g.cellSize*0.3, ring)
+	g.drawSmoothCircle(screen, x, y, g.cellSize*0.24, pieceColor)
+}
+
+// drawBoardAt renders a board and its pieces onto target, with the top-left
+// cell at (offsetX, offsetY)
+func (g *ConnectFourGame) drawBoardAt(target *ebiten.Image, board GameBoard, offsetX, offsetY, cellSize float64) {
+	g.drawBoardSlots(target, board, offsetX, offsetY, cellSize)
+	g.drawBoardFrame(target, offsetX, offsetY, cellSize)
+}
+
+// drawBoardSlots renders what shows through a board's holes, the backing and
+// the pieces, to be covered by the board frame
+func (g *ConnectFourGame) drawBoardSlots(target *ebiten.Image, board GameBoard, offsetX, offsetY, cellSize float64) {
 	for row := 0; row < Rows; row++ {
 		for col := 0; col < Columns; col++ {
-			x := int(g.boardOffsetX + float64(col)*g.cellSize + g.cellSize/2)
-			y := int(g.boardOffsetY + float64(row)*g.cellSize + g.cellSize/2)
+			x := int(offsetX + float64(col)*cellSize + cellSize/2)
+			y := int(offsetY + float64(row)*cellSize + cellSize/2)
 
-			// First draw white background hole (slightly larger)
-			g.drawSmoothCircle(screen, x, y, g.cellSize*0.42, colorSlotBg)
+			// Backing slightly larger than the hole, so its edge stays hidden
+			g.drawSmoothCircle(target, x, y, cellSize*(boardHoleRadius+0.02), colorSlotBg)
 
 			// Then draw game piece if not empty
-			if g.board[row][col] != Empty {
+			if piece := board.At(row, col); piece != Empty {
 				var pieceColor color.Color
-				if g.board[row][col] == Player {
-					pieceColor = colorPlayer
+				if piece == Player {
+					pieceColor = g.playerColor
 				} else {
-					pieceColor = colorComputer
+					pieceColor = g.computerColor
 				}
-				g.drawSmoothCircle(screen, x, y, g.cellSize*0.38, pieceColor)
+				if g.settings.DiscShadows {
+					// Offset down and right, staying inside the hole
+					offset := int(cellSize * 0.04)
+					g.drawSmoothCircle(target, x+offset, y+offset, cellSize*0.38, colorShadow)
+				}
+				g.drawSmoothCircle(target, x, y, cellSize*0.38, pieceColor)
 			}
 		}
 	}
-
-	// Draw hover effect
-	if g.state == StateGame && g.isHovering && g.hoverColumn >= 0 && g.turn == Player {
-		if g.board[0][g.hoverColumn] == Empty {
-			x := int(g.boardOffsetX + float64(g.hoverColumn)*g.cellSize + g.cellSize/2)
-			y := int(g.boardOffsetY + g.cellSize/2) // Top row
-			radius := g.cellSize * 0.4
-			g.drawSmoothCircle(screen, x, y, radius, colorHover)
-		}
-	}
-
-	// Draw buttons
-	for _, btn := range g.buttons {
-		g.drawButton(screen, btn)
-	}
 }
 
 // drawButton renders a button on the screen
 func (g *ConnectFourGame) drawButton(screen *ebiten.Image, btn *Button) {
-	// Draw button background
-	ebitenutil.DrawRect(screen, btn.x, btn.y,
-		btn.w, btn.h, colorButton)
+	// Outline the button with the keyboard focus
+	radius := float64(g.px(buttonCornerRadius))
+	if btn.focused {
+		drawRoundedRect(screen, btn.x-2, btn.y-2, btn.w+4, btn.h+4, radius+2, colorText)
+	}
+
+	// Draw button background, greyed out when disabled
+	background, textColor := colorButton, colorButtonText
+	if btn.disabled {
+		background, textColor = colorEmpty, colorText
+	}
+	drawRoundedRect(screen, btn.x, btn.y, btn.w, btn.h, radius, background)
 
 	// Draw button text
-	textBounds := text.BoundString(basicfont.Face7x13, btn.text)
-	text.Draw(screen, btn.text, basicfont.Face7x13,
+	textBounds := boundString(fontFace, btn.text)
+	text.Draw(screen, btn.text, fontFace,
 		int(btn.x+btn.w/2)-textBounds.Dx()/2,
-		int(btn.y+btn.h/2)+textBounds.Dy()/4, colorButtonText)
+		int(btn.y+btn.h/2)+textBounds.Dy()/4, textColor)
 }
 
 // drawTextInput renders a text input field with scrolling text
 func (g *ConnectFourGame) drawTextInput(screen *ebiten.Image, input *TextInput) {
+	active := input == g.activeInput
+
+	// Draw a shaking input at its shaken position
+	if dx := g.shakeOffset(input); dx != 0 {
+		shaken := *input
+		shaken.x += dx
+		input = &shaken
+	}
+
+	// Draw the validation error under the input
+	if input.err != "" {
+		text.Draw(screen, input.err, fontFace,
+			int(input.x), int(input.y+input.h+15), colorError)
+	}
+
 	// Draw label
-	text.Draw(screen, input.label, basicfont.Face7x13,
+	text.Draw(screen, input.label, fontFace,
 		int(input.x), int(input.y-5), colorText)
 
 	// Draw input background (white with blue border if focused)
-	bgColor := color.RGBA{240, 240, 240, 255}
+	bgColor := colorBackground
 	borderColor := color.RGBA{180, 180, 180, 255}
-	if input == g.activeInput {
+	if active {
 		borderColor = color.RGBA{100, 100, 220, 255}
+	} else if input.err != "" {
+		borderColor = colorError
 	}
 
 	// Draw border
-	ebitenutil.DrawRect(screen, input.x-1, input.y-1,
+	drawRect(screen, input.x-1, input.y-1,
 		input.w+2, input.h+2, borderColor)
 	// Draw background
-	ebitenutil.DrawRect(screen, input.x, input.y,
+	drawRect(screen, input.x, input.y,
 		input.w, input.h, bgColor)
 
 	// Draw text or placeholder with scrolling
-	displayValue := input.value
-	if input.isPassword && len(input.value) > 0 {
-		displayValue = strings.Repeat("*", len(input.value))
-	}
-
-	if len(displayValue) > 0 {
-		// Calculate visible portion of text based on scroll position
-		startPos := min(input.scrollPos, len(displayValue))
-		visibleText := displayValue[startPos:]
-
-		// Calculate max visible characters
-		maxVisibleChars := int(input.w-10) / 7 // Assuming 7 pixels per character
-		if len(visibleText) > maxVisibleChars {
-			visibleText = visibleText[:maxVisibleChars]
-		}
-
-		text.Draw(screen, visibleText, basicfont.Face7x13,
-			int(input.x+5), int(input.y+input.h/2+5), colorText)
+	displayRunes := []rune(input.displayText())
+	if len(displayRunes) > 0 {
+		// Show the characters from the scroll position that fit
+		startPos := min(input.scrollPos, len(displayRunes))
+		text.Draw(screen, string(displayRunes[startPos:input.visibleEnd()]), fontFace,
+			int(input.x+inputPadding), int(input.y+input.h/2+5), colorText)
 	} else {
-		placeholder := fmt.Sprintf("Enter %s", strings.ToLower(input.label[:len(input.label)-1]))
-		text.Draw(screen, placeholder, basicfont.Face7x13,
-			int(input.x+5), int(input.y+input.h/2+5), color.RGBA{180, 180, 180, 255})
-	}
-
-	// Draw cursor ONLY if this is the active input
-	if input == g.activeInput {
-		// Calculate cursor position based on visible text
-		cursorPos := len(displayValue) - input.scrollPos
-		cursorPos = min(cursorPos, int(input.w-10)/7) // Don't go outside visible area
-
-		textWidth := cursorPos * 7
-		ebitenutil.DrawLine(screen,
-			input.x+5+float64(textWidth), input.y+5,
-			input.x+5+float64(textWidth), input.y+input.h-5,
-			color.RGBA{0, 0, 0, 255})
-	}
-}
-
-// drawSmoothCircle draws an anti-aliased circle
-func (g *ConnectFourGame) drawSmoothCircle(screen *ebiten.Image, centerX, centerY int, radius float64, clr color.Color) {
-	// Convert the color to RGBA
-	rr, gg, bb, aa := extractRGBA(clr)
-	rgba := color.RGBA{R: rr, G: gg, B: bb, A: aa}
-
-	// Try to get the pre-rendered circle
-	circleImg, exists := g.circleImages[rgba]
-
-	if !exists {
-		// If we don't have this color pre-rendered, create a one-time template
-		// (This should rarely happen since we pre-render common colors)
-		size := 64
-		circleImg = ebiten.NewImage(size, size)
-		circleImg.Fill(color.RGBA{0, 0, 0, 0})
-
-		center := float64(size) / 2
-		templateRadius := center - 1
-
-		for y := 0; y < size; y++ {
-			for x := 0; x < size; x++ {
-				dx := float64(x) - center
-				dy := float64(y) - center
-				dist := math.Sqrt(dx*dx + dy*dy)
-
-				if dist <= templateRadius-2 {
-					circleImg.Set(x, y, rgba)
-				} else if dist <= templateRadius {
-					t := (templateRadius - dist) / 2
-					t = t * t * (3 - 2*t)
-					alpha := uint8(float64(rgba.A) * t)
-					if alpha > 0 {
-						circleImg.Set(x, y, color.RGBA{rgba.R, rgba.G, rgba.B, alpha})
-					}
-				}
-			}
-		}
+		text.Draw(screen, input.placeholder, fontFace,
+			int(input.x+inputPadding), int(input.y+input.h/2+5), color.RGBA{180, 180, 180, 255})
+	}
 
-		// Save for future use
-		g.circleImages[rgba] = circleImg
+	// Draw cursor ONLY if this is the active input, blinking on and off
+	if active && g.caretVisible() {
+		cursorX := input.x + inputPadding + input.caretOffset()
+		drawLine(screen, cursorX, input.y+5, cursorX, input.y+input.h-5, colorText)
 	}
 
-	// Draw the template with appropriate scaling
-	op := &ebiten.DrawImageOptions{}
-	scale := (radius * 2) / float64(circleImg.Bounds().Dx())
-	op.GeoM.Scale(scale, scale)
-	op.GeoM.Translate(float64(centerX)-radius, float64(centerY)-radius)
-	op.Filter = ebiten.FilterLinear
-	screen.DrawImage(circleImg, op)
+	if input.isPassword {
+		g.drawRevealButton(screen, input, bgColor)
+	}
 }
 
-// preRenderCircles pre-renders circles for common colors
-func (g *ConnectFourGame) preRenderCircles() {
-	// Define the colors we'll need circles for
-	colors := []color.RGBA{
-		colorPlayer,
-		colorComputer,
-		colorSlotBg,
-		{255, 50, 50, 50}, // hover color
-	}
-
-	// Use a much higher resolution template for better quality
-	size := 128 // Double the previous size for better quality
-
-	for _, clr := range colors {
-		img := ebiten.NewImage(size, size)
-		img.Fill(color.RGBA{0, 0, 0, 0}) // transparent background
-
-		center := float64(size) / 2
-		radius := center - 2 // leave a 2px border to avoid clipping
-
-		// Use a wider anti-aliasing region for smoother circles
-		aaWidth := 4.0 // 4px wide anti-aliasing border
-
-		for y := 0; y < size; y++ {
-			for x := 0; x < size; x++ {
-				dx := float64(x) - center
-				dy := float64(y) - center
-				dist := math.Sqrt(dx*dx + dy*dy)
-
-				if dist <= radius-aaWidth {
-					// Solid inner part
-					img.Set(x, y, clr)
-				} else if dist <= radius {
-					// Anti-aliased edge with smoother transition
-					t := 1.0 - (dist-(radius-aaWidth))/aaWidth
-
-					// Apply smoothstep function for better transition
-					t = t * t * (3 - 2*t)
-
-					r, g, b, a := clr.R, clr.G, clr.B, clr.A
-					alpha := uint8(float64(a) * t)
-					if alpha > 0 {
-						img.Set(x, y, color.RGBA{r, g, b, alpha})
-					}
-				}
-			}
-		}
-
-		g.circleImages[clr] = img
+// drawRevealButton draws the eye icon at the right end of a password input,
+// struck through while the password is masked
+func (g *ConnectFourGame) drawRevealButton(screen *ebiten.Image, input *TextInput, bgColor color.RGBA) {
+	size := input.h
+	centerX := input.x + input.w - size/2
+	centerY := input.y + size/2
+	g.drawSmoothCircle(screen, int(centerX), int(centerY), size*0.3, colorText)
+	g.drawSmoothCircle(screen, int(centerX), int(centerY), size*0.22, bgColor)
+	g.drawSmoothCircle(screen, int(centerX), int(centerY), size*0.1, colorText)
+	if !input.revealed {
+		drawLine(screen, centerX-size*0.35, centerY+size*0.35, centerX+size*0.35, centerY-size*0.35, colorText)
 	}
 }
 
@@ -900,21 +1948,31 @@ func max(a, b int) int {
 	return b
 }
 
-// Layout returns the game's screen dimensions
+// Layout returns the game's screen dimensions in physical pixels, so the
+// screen matches the display on HiDPI monitors. Cursor positions come in
+// the same pixels, keeping hit-testing in step with drawing.
 func (g *ConnectFourGame) Layout(outsideWidth, outsideHeight int) (int, int) {
-	return outsideWidth, outsideHeight // Make the game fully resizable
+	scale := deviceScaleFactor()
+	g.layoutWidth = int(math.Round(float64(outsideWidth) * scale))
+	g.layoutHeight = int(math.Round(float64(outsideHeight) * scale))
+	return g.layoutWidth, g.layoutHeight // Make the game fully resizable
 }
 
 // Update the RunEbitenGUI function to remove maximization
 func RunEbitenGUI() {
-	// Set window properties
-	ebiten.SetWindowSize(800, 600)
-	ebiten.SetWindowTitle("Connect Four")
-	ebiten.SetWindowResizable(true)
+	loadFonts(fontPath)
 
 	// Create the game with default dimensions
 	game := NewConnectFourGame()
 
+	// Set window properties, restoring the size from last time
+	applyWindowSettings(game.settings)
+	applyTimingSettings(game.settings)
+	ebiten.SetScreenClearedEveryFrame(false) // Draw fills the screen itself, or keeps the last frame
+	ebiten.SetWindowTitle(tr("app.title"))
+	ebiten.SetWindowResizable(true)
+	ebiten.SetWindowClosingHandled(true) // Lets Update save an unfinished game first
+
 	// Run the game
 	if err := ebiten.RunGame(game); err != nil {
 		log.Fatal(err)
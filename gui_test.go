@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCanPlayerMove(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		hotseat bool
+		online  bool
+		turn    int
+		over    bool
+		want    bool
+	}{
+		{"player's turn against the computer", false, false, Player, false, true},
+		{"computer's turn", false, false, Computer, false, false},
+		{"hotseat, first player's turn", true, false, Player, false, true},
+		{"hotseat, second player's turn", true, false, Computer, false, true},
+		{"game over", false, false, Player, true, false},
+		{"hotseat game over", true, false, Computer, true, false},
+		{"online, my turn", false, true, Player, false, true},
+		{"online, opponent's turn", false, true, Computer, false, false},
+		{"online game over", false, true, Player, true, false},
+	} {
+		g := newConnectFourGame(newMemoryStorage())
+		g.frame = 1
+		g.startNewGame()
+		g.hotseat = tt.hotseat
+		if tt.online {
+			g.online = &OnlineGame{codec: newWireCodec(&bytes.Buffer{}), done: make(chan struct{})}
+		}
+		g.turn = tt.turn
+		g.gameInProgress = !tt.over
+		if got := g.canPlayerMove(); got != tt.want {
+			t.Errorf("%s: canPlayerMove = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import "math/rand"
+
+// Seed for the Zobrist keys. It is fixed so hashes are the same on every
+// run and can be stored alongside cached results.
+const zobristSeed = 0x436f6e6e656374
+
+// Zobrist keys, one per cell and piece. Empty cells contribute nothing.
+var zobristKeys [Rows][Columns][3]uint64
+
+func init() {
+	r := rand.New(rand.NewSource(zobristSeed))
+	for row := range zobristKeys {
+		for col := range zobristKeys[row] {
+			zobristKeys[row][col][Player] = r.Uint64()
+			zobristKeys[row][col][Computer] = r.Uint64()
+		}
+	}
+}
+
+// HashBoard returns a Zobrist hash of the board. Equal boards always hash
+// equal; different boards collide only by chance.
+func HashBoard(board GameBoard) uint64 {
+	var hash uint64
+	for row := 0; row < Rows; row++ {
+		for col := 0; col < Columns; col++ {
+			if piece := board[row][col]; piece == Player || piece == Computer {
+				hash ^= zobristKeys[row][col][piece]
+			}
+		}
+	}
+	return hash
+}
+
+// hashDrop updates a HashBoard hash for piece being dropped into row, col,
+// so a game can keep its hash move by move instead of rehashing the board
+func hashDrop(hash uint64, row, col, piece int) uint64 {
+	return hash ^ zobristKeys[row][col][piece]
+}
+
+// HashBoardSymmetric returns the smaller of the hashes of the board and its
+// mirror image, so a position and its reflection share one hash
+func HashBoardSymmetric(board GameBoard) uint64 {
+	hash, mirrored := HashBoard(board), HashBoard(mirrorBoard(board))
+	if mirrored < hash {
+		return mirrored
+	}
+	return hash
+}
+
+// mirrorBoard reflects the board left to right
+func mirrorBoard(board GameBoard) GameBoard {
+	var mirrored GameBoard
+	for row := 0; row < Rows; row++ {
+		for col := 0; col < Columns; col++ {
+			mirrored[row][Columns-1-col] = board[row][col]
+		}
+	}
+	return mirrored
+}
@@ -0,0 +1,50 @@
+package main
+
+import "testing"
+
+func TestHashDropMatchesHashBoard(t *testing.T) {
+	for _, moves := range []string{"4453", "1234567123456712345671234567", "444444333333"} {
+		var board GameBoard
+		var hash uint64
+		side := Player
+		for i, ch := range moves {
+			col := int(ch - '1')
+			hash = hashDrop(hash, board.columnSpace(col)-1, col, side)
+			board = dropPiece(board, col, side)
+			side = opponentOf(side)
+			if full := HashBoard(board); hash != full {
+				t.Fatalf("%s after %d moves: incremental hash %x, full hash %x", moves, i+1, hash, full)
+			}
+		}
+	}
+}
+
+func TestHashBoardAcrossMoveOrders(t *testing.T) {
+	// Every position reachable in a few moves, by every order of reaching it
+	const plies = 6
+	seen := map[uint64]GameBoard{}
+	var walk func(board GameBoard, side, depth int)
+	walk = func(board GameBoard, side, depth int) {
+		hash := HashBoard(board)
+		if other, ok := seen[hash]; ok && other != board {
+			t.Fatalf("hash %x shared by\n%s and\n%s", hash, FormatBoard(other), FormatBoard(board))
+		}
+		seen[hash] = board
+		if depth == plies {
+			return
+		}
+		for _, col := range getValidColumns(board) {
+			walk(dropPiece(board, col, side), opponentOf(side), depth+1)
+		}
+	}
+	walk(GameBoard{}, Player, 0)
+
+	// Transpositions of the same moves land on the same hash
+	if a, b := HashBoard(boardFromMoves(t, "1234")), HashBoard(boardFromMoves(t, "3214")); a != b {
+		t.Errorf("transposed move orders hash %x and %x", a, b)
+	}
+	if a, b := HashBoard(boardFromMoves(t, "1234")), HashBoard(boardFromMoves(t, "2143")); a == b {
+		t.Errorf("different positions share hash %x", a)
+	}
+	t.Logf("%d positions, no collisions", len(seen))
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// deviceScaleFactor returns how many physical pixels make up one logical
+// pixel on the monitor the game is shown on
+func deviceScaleFactor() float64 {
+	if monitor := ebiten.Monitor(); monitor != nil {
+		if scale := monitor.DeviceScaleFactor(); scale > 0 {
+			return scale
+		}
+	}
+	return 1
+}
+
+// px converts a size in logical pixels into the physical pixels the screen
+// is drawn in. Layout that follows the window through scaleX and scaleY is
+// already physical; this is for fixed sizes such as text line heights.
+func (g *ConnectFourGame) px(logical int) int {
+	return int(math.Round(float64(logical) * g.deviceScale))
+}
+
+// circleTemplateSize returns the template size for discs in the board's
+// holes at the current cell size
+func (g *ConnectFourGame) circleTemplateSize() int {
+	return circleBucket(2 * boardHoleRadius * g.cellSize)
+}
+
+// updateDeviceScale remakes the fonts and circle templates after a change of
+// display scale or cell size, so text and discs stay crisp
+func (g *ConnectFourGame) updateDeviceScale(scale float64) {
+	if scale != g.fontScale {
+		scaleFonts(scale)
+		g.fontScale = scale
+	}
+	g.deviceScale = scale
+	if size := g.circleTemplateSize(); size != g.circleSize {
+		g.evictCircleSize(g.circleSize)
+		g.circleSize = size
+		g.preRenderCircles()
+		g.preRenderCircle(g.playerColor)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Opacity of the band behind the hovered column
+const columnHighlightAlpha = 60
+
+// columnHighlightRect returns the band covering a whole column of a board
+// drawn at (originX, originY)
+func columnHighlightRect(col int, originX, originY, cellSize float64) (x, y, w, h float64) {
+	return originX + float64(col)*cellSize, originY, cellSize, float64(Rows) * cellSize
+}
+
+// landingRow returns the row a piece dropped in col would land in, or -1 if
+// the column is full
+func landingRow(board GameBoard, col int) int {
+	for row := Rows - 1; row >= 0; row-- {
+		if board.At(row, col) == Empty {
+			return row
+		}
+	}
+	return -1
+}
+
+// hoveredColumn returns the column under the cursor while the player can
+// drop a piece in it
+func (g *ConnectFourGame) hoveredColumn() (int, bool) {
+	col := g.hoverColumn
+	if !g.boardInputActive() || !g.isHovering || col < 0 || col >= Columns || g.board.columnFull(col) {
+		return 0, false
+	}
+	return col, true
+}
+
+// drawColumnHighlight shades the hovered column behind the slots
+func (g *ConnectFourGame) drawColumnHighlight(screen *ebiten.Image, originX, originY float64) {
+	col, ok := g.hoveredColumn()
+	if !ok || !g.settings.ColumnHighlight {
+		return
+	}
+	x, y, w, h := columnHighlightRect(col, originX, originY, g.cellSize)
+	band := g.hoverColor()
+	band.A = columnHighlightAlpha
+	drawRect(screen, x, y, w, h, band)
+}
+
+// drawLandingGhost shows a see-through piece where a drop in the hovered
+// column would land
+func (g *ConnectFourGame) drawLandingGhost(screen *ebiten.Image, originX, originY float64) {
+	col, ok := g.hoveredColumn()
+	if !ok {
+		return
+	}
+	row := landingRow(g.board, col)
+	x := int(originX + float64(col)*g.cellSize + g.cellSize/2)
+	y := int(originY + float64(row)*g.cellSize + g.cellSize/2)
+	g.drawSmoothCircle(screen, x, y, g.cellSize*0.38, g.hoverColor())
+}
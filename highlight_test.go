@@ -0,0 +1,75 @@
+package main
+
+import "testing"
+
+func TestColumnHighlightRect(t *testing.T) {
+	for _, tt := range []struct {
+		col              int
+		originX, originY float64
+		cellSize         float64
+		x, y, w, h       float64
+	}{
+		{0, 190, 150, 60, 190, 150, 60, 360},
+		{3, 190, 150, 60, 370, 150, 60, 360},
+		{Columns - 1, 190, 150, 60, 550, 150, 60, 360},
+		{2, 0, 0, 90, 180, 0, 90, 540},
+		{5, 33.5, 12.25, 47.5, 271, 12.25, 47.5, 285},
+	} {
+		x, y, w, h := columnHighlightRect(tt.col, tt.originX, tt.originY, tt.cellSize)
+		if x != tt.x || y != tt.y || w != tt.w || h != tt.h {
+			t.Errorf("column %d at (%g, %g) size %g: band (%g, %g, %g, %g), want (%g, %g, %g, %g)",
+				tt.col, tt.originX, tt.originY, tt.cellSize, x, y, w, h, tt.x, tt.y, tt.w, tt.h)
+		}
+
+		// The band covers exactly the points hit-testing as its column
+		g := &ConnectFourGame{cellSize: tt.cellSize}
+		for _, p := range [][2]float64{{x, y}, {x + w - 0.5, y + h - 0.5}, {x + w/2, y + h/2}} {
+			if got := g.columnAt(p[0], p[1], tt.originX, tt.originY); got != tt.col {
+				t.Errorf("point (%g, %g) in column %d's band hit-tests as column %d", p[0], p[1], tt.col, got)
+			}
+		}
+		if got := g.columnAt(x+w, y, tt.originX, tt.originY); got == tt.col {
+			t.Errorf("point right of column %d's band hit-tests as it", tt.col)
+		}
+	}
+}
+
+func TestHoveredColumn(t *testing.T) {
+	g := keysTestGame(t)
+	g.setBoard(boardFromMoves(t, "111111"))
+	g.isHovering = true
+
+	for _, tt := range []struct {
+		name    string
+		col     int
+		ok      bool
+		landing int
+	}{
+		{"empty column", 3, true, Rows - 1},
+		{"full column", 0, false, -1},
+		{"off the board", Columns, false, -1},
+	} {
+		g.hoverColumn = tt.col
+		col, ok := g.hoveredColumn()
+		if ok != tt.ok || ok && col != tt.col {
+			t.Errorf("%s: hoveredColumn = %d, %v", tt.name, col, ok)
+		}
+		if tt.col < Columns {
+			if got := landingRow(g.board, tt.col); got != tt.landing {
+				t.Errorf("%s: lands in row %d, want %d", tt.name, got, tt.landing)
+			}
+		}
+	}
+
+	// Nothing is highlighted while the player can't move
+	g.hoverColumn = 3
+	g.isHovering = false
+	if _, ok := g.hoveredColumn(); ok {
+		t.Error("column highlighted without the cursor over the board")
+	}
+	g.isHovering = true
+	g.dialog = &Dialog{}
+	if _, ok := g.hoveredColumn(); ok {
+		t.Error("column highlighted under a dialog")
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+// pointInRect is the hit test shared by every widget: whether a point lies
+// in the rectangle at (x, y) of size w×h, left and top edges included. All
+// coordinates are in screen pixels, the same space the cursor is reported in,
+// so widgets laid out at any scale are tested the same way.
+func pointInRect(px, py, x, y, w, h float64) bool {
+	return px >= x && px < x+w && py >= y && py < y+h
+}
+
+// columnAt returns the board column under a point, the whole height of the
+// board, or -1 if the point is off the board. The board's top left corner
+// is at (originX, originY).
+func (g *ConnectFourGame) columnAt(x, y, originX, originY float64) int {
+	for col := range Columns {
+		if pointInRect(x, y, originX+float64(col)*g.cellSize, originY, g.cellSize, float64(Rows)*g.cellSize) {
+			return col
+		}
+	}
+	return -1
+}
+
+// cellAt returns the row and column of the board cell under a point, and
+// false if the point is off the board
+func (g *ConnectFourGame) cellAt(x, y, originX, originY float64) (row, col int, ok bool) {
+	col = g.columnAt(x, y, originX, originY)
+	if col < 0 {
+		return 0, 0, false
+	}
+	for row := range Rows {
+		if pointInRect(x, y, originX+float64(col)*g.cellSize, originY+float64(row)*g.cellSize, g.cellSize, g.cellSize) {
+			return row, col, true
+		}
+	}
+	return 0, 0, false
+}
+
+// containsPoint reports whether a point is on the button
+func (b *Button) containsPoint(x, y float64) bool {
+	return pointInRect(x, y, b.x, b.y, b.w, b.h)
+}
+
+// containsPoint reports whether a point is on the input box
+func (t *TextInput) containsPoint(x, y float64) bool {
+	return pointInRect(x, y, t.x, t.y, t.w, t.h)
+}
@@ -0,0 +1,77 @@
+package main
+
+import "testing"
+
+// Scale factors the hit tests are checked at: a standard display and the
+// common high-DPI ones
+var hitTestScales = []float64{1.0, 1.5, 2.0}
+
+func TestHitTestsAtScale(t *testing.T) {
+	// A widget at (100, 50), 200×40 in logical pixels. Points are given in
+	// logical pixels too and scaled with the layout, as the cursor is.
+	for _, tt := range []struct {
+		name   string
+		px, py float64
+		want   bool
+	}{
+		{"top left corner", 100, 50, true},
+		{"centre", 200, 70, true},
+		{"inside bottom right", 299.5, 89.5, true},
+		{"right edge", 300, 70, false},
+		{"bottom edge", 200, 90, false},
+		{"left of the widget", 99.5, 70, false},
+		{"above the widget", 200, 49.5, false},
+	} {
+		for _, scale := range hitTestScales {
+			x, y, w, h := 100*scale, 50*scale, 200*scale, 40*scale
+			px, py := tt.px*scale, tt.py*scale
+
+			if got := pointInRect(px, py, x, y, w, h); got != tt.want {
+				t.Errorf("%s at %gx: pointInRect = %v, want %v", tt.name, scale, got, tt.want)
+			}
+			b := &Button{x: x, y: y, w: w, h: h}
+			if got := b.containsPoint(px, py); got != tt.want {
+				t.Errorf("%s at %gx: button containsPoint = %v, want %v", tt.name, scale, got, tt.want)
+			}
+			in := &TextInput{x: x, y: y, w: w, h: h}
+			if got := in.containsPoint(px, py); got != tt.want {
+				t.Errorf("%s at %gx: input containsPoint = %v, want %v", tt.name, scale, got, tt.want)
+			}
+			d := &Dropdown{x: x, y: y, w: w, h: h}
+			if got := d.contains(px, py); got != tt.want {
+				t.Errorf("%s at %gx: dropdown contains = %v, want %v", tt.name, scale, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestColumnAtScale(t *testing.T) {
+	// A board at (40, 80) with 60 pixel cells, in logical pixels
+	for _, tt := range []struct {
+		name     string
+		px, py   float64
+		col, row int
+	}{
+		{"first cell", 40, 80, 0, 0},
+		{"second column", 100, 100, 1, 0},
+		{"bottom right cell", 459.5, 439.5, Columns - 1, Rows - 1},
+		{"last pixel before a column", 99.5, 200, 0, 2},
+		{"left of the board", 39.5, 200, -1, -1},
+		{"right of the board", 460, 200, -1, -1},
+		{"above the board", 200, 79.5, -1, -1},
+		{"below the board", 200, 440, -1, -1},
+	} {
+		for _, scale := range hitTestScales {
+			g := &ConnectFourGame{cellSize: 60 * scale}
+			px, py := tt.px*scale, tt.py*scale
+			if got := g.columnAt(px, py, 40*scale, 80*scale); got != tt.col {
+				t.Errorf("%s at %gx: columnAt = %d, want %d", tt.name, scale, got, tt.col)
+			}
+			row, col, ok := g.cellAt(px, py, 40*scale, 80*scale)
+			if ok != (tt.row >= 0) || ok && (row != tt.row || col != tt.col) {
+				t.Errorf("%s at %gx: cellAt = (%d, %d, %v), want row %d column %d",
+					tt.name, scale, row, col, ok, tt.row, tt.col)
+			}
+		}
+	}
+}
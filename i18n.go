@@ -0,0 +1,129 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+)
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// Languages shipped with the game, in the order the settings screen cycles
+// through them. English is the fallback for anything missing elsewhere.
+var languages = []string{"en", "es"}
+
+const fallbackLanguage = "en"
+
+// Catalog maps string keys to translated text. Text may contain fmt verbs
+// that are filled in from the arguments given to tr.
+type Catalog map[string]string
+
+// Active and fallback catalogs
+var (
+	catalog         Catalog
+	fallbackCatalog Catalog
+	currentLanguage string
+	reportedMissing = map[string]bool{}
+)
+
+// loadCatalog reads the embedded catalog for a language
+func loadCatalog(language string) (Catalog, error) {
+	data, err := localeFiles.ReadFile("locales/" + language + ".json")
+	if err != nil {
+		return nil, err
+	}
+	var c Catalog
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("locale %s: %w", language, err)
+	}
+	return c, nil
+}
+
+// setLanguage switches the catalog used by tr, falling back to English for
+// unknown languages
+func setLanguage(language string) {
+	if fallbackCatalog == nil {
+		c, err := loadCatalog(fallbackLanguage)
+		if err != nil {
+			log.Printf("loading fallback locale: %v", err)
+			c = Catalog{}
+		}
+		fallbackCatalog = c
+	}
+
+	c, err := loadCatalog(language)
+	if err != nil {
+		log.Printf("loading locale: %v", err)
+		language, c = fallbackLanguage, fallbackCatalog
+	}
+	catalog = c
+	currentLanguage = language
+}
+
+// tr returns the text for key in the current language, formatted with args.
+// Missing keys fall back to English, and then to the key itself.
+func tr(key string, args ...any) string {
+	if catalog == nil {
+		setLanguage(fallbackLanguage)
+	}
+	text, ok := catalog[key]
+	if !ok {
+		text, ok = fallbackCatalog[key]
+		if !reportedMissing[key] {
+			reportedMissing[key] = true
+			log.Printf("locale %s is missing %q", currentLanguage, key)
+		}
+		if !ok {
+			text = key
+		}
+	}
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// missingKeys returns, per shipped language, the keys that English has but
+// that language lacks
+func missingKeys() map[string][]string {
+	missing := map[string][]string{}
+	english, err := loadCatalog(fallbackLanguage)
+	if err != nil {
+		log.Printf("loading fallback locale: %v", err)
+		return missing
+	}
+	for _, language := range languages {
+		c, err := loadCatalog(language)
+		if err != nil {
+			log.Printf("loading locale: %v", err)
+			continue
+		}
+		for key := range english {
+			if _, ok := c[key]; !ok {
+				missing[language] = append(missing[language], key)
+			}
+		}
+		sort.Strings(missing[language])
+	}
+	return missing
+}
+
+// languageName returns a language's name in that language, e.g. "Español"
+func languageName(language string) string {
+	c, err := loadCatalog(language)
+	if err != nil || c["language.name"] == "" {
+		return language
+	}
+	return c["language.name"]
+}
+
+// onOff returns the localized on/off state for toggle buttons
+func onOff(on bool) string {
+	if on {
+		return tr("common.on")
+	}
+	return tr("common.off")
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"path"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestLocalesComplete checks every catalog in locales/ against English, so a
+// string added in one language and forgotten in another fails the build
+// rather than falling back at run time
+func TestLocalesComplete(t *testing.T) {
+	english, err := loadCatalog(fallbackLanguage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		language := strings.TrimSuffix(entry.Name(), path.Ext(entry.Name()))
+		t.Run(language, func(t *testing.T) {
+			if !slices.Contains(languages, language) {
+				t.Errorf("locale %s is not in languages", language)
+			}
+			c, err := loadCatalog(language)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for key := range english {
+				if c[key] == "" {
+					t.Errorf("missing %q", key)
+				}
+			}
+			for key := range c {
+				if _, ok := english[key]; !ok {
+					t.Errorf("%q is not in English", key)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// Named keyboard actions. Input handling asks whether an action happened
+// rather than looking at keys, so the keys can be rebound in the settings
+// file under "key_bindings", e.g. {"screenshot": ["F12"]}, by ebiten key
+// name.
+const (
+	ActionSubmit       = "submit"        // Submit a form or confirm a dialog
+	ActionCancel       = "cancel"        // Dismiss a dialog, list or tutorial
+	ActionActivate     = "activate"      // Press the focused control
+	ActionNextField    = "next_field"    // Move the focus on
+	ActionReverse      = "reverse"       // Held with next_field to move the focus back
+	ActionUp           = "up"            // Scroll up or pick the previous option
+	ActionDown         = "down"          // Scroll down or pick the next option
+	ActionLeft         = "left"          // Step back, or move the caret or a slider left
+	ActionRight        = "right"         // Step forward, or move the caret or a slider right
+	ActionBackspace    = "backspace"     // Delete the character before the caret
+	ActionDelete       = "delete"        // Delete the character after the caret
+	ActionHome         = "home"          // Move the caret to the start
+	ActionEnd          = "end"           // Move the caret to the end
+	ActionFullscreen   = "fullscreen"    // Switch fullscreen on and off
+	ActionScreenshot   = "screenshot"    // Save a screenshot of the board
+	ActionLegalMoves   = "legal_moves"   // Mark the columns with room
+	ActionUndo         = "undo"          // Take back a move
+	ActionHint         = "hint"          // Show or hide the evaluation bar
+	ActionPause        = "pause"         // Play or pause a replay
+	ActionDebugOverlay = "debug_overlay" // Show or hide the developer overlay
+	ActionDrop1        = "drop_1"        // Drop a piece in the first column, and so on
+	ActionDrop2        = "drop_2"
+	ActionDrop3        = "drop_3"
+	ActionDrop4        = "drop_4"
+	ActionDrop5        = "drop_5"
+	ActionDrop6        = "drop_6"
+	ActionDrop7        = "drop_7"
+)
+
+// actions lists the actions other than the column drops, in the order the
+// key bindings screen shows them
+var actions = []string{
+	ActionSubmit, ActionCancel, ActionActivate, ActionNextField, ActionReverse,
+	ActionUp, ActionDown, ActionLeft, ActionRight,
+	ActionBackspace, ActionDelete, ActionHome, ActionEnd,
+	ActionFullscreen, ActionScreenshot, ActionLegalMoves,
+	ActionUndo, ActionHint, ActionPause, ActionDebugOverlay,
+}
+
+// dropActions drop a piece in each column, from the left. The key bindings
+// screen shows them together on one line.
+var dropActions = []string{
+	ActionDrop1, ActionDrop2, ActionDrop3, ActionDrop4, ActionDrop5, ActionDrop6, ActionDrop7,
+}
+
+// defaultKeyBindings are the keys each action has unless rebound
+var defaultKeyBindings = map[string][]ebiten.Key{
+	ActionSubmit:       {ebiten.KeyEnter},
+	ActionCancel:       {ebiten.KeyEscape},
+	ActionActivate:     {ebiten.KeyEnter, ebiten.KeySpace},
+	ActionNextField:    {ebiten.KeyTab},
+	ActionReverse:      {ebiten.KeyShift},
+	ActionUp:           {ebiten.KeyArrowUp},
+	ActionDown:         {ebiten.KeyArrowDown},
+	ActionLeft:         {ebiten.KeyArrowLeft},
+	ActionRight:        {ebiten.KeyArrowRight},
+	ActionBackspace:    {ebiten.KeyBackspace},
+	ActionDelete:       {ebiten.KeyDelete},
+	ActionHome:         {ebiten.KeyHome},
+	ActionEnd:          {ebiten.KeyEnd},
+	ActionFullscreen:   {ebiten.KeyF11},
+	ActionScreenshot:   {ebiten.KeyP},
+	ActionLegalMoves:   {ebiten.KeyL},
+	ActionUndo:         {ebiten.KeyU},
+	ActionHint:         {ebiten.KeyH},
+	ActionPause:        {ebiten.KeySpace},
+	ActionDebugOverlay: {ebiten.KeyF3},
+	ActionDrop1:        {ebiten.Key1},
+	ActionDrop2:        {ebiten.Key2},
+	ActionDrop3:        {ebiten.Key3},
+	ActionDrop4:        {ebiten.Key4},
+	ActionDrop5:        {ebiten.Key5},
+	ActionDrop6:        {ebiten.Key6},
+	ActionDrop7:        {ebiten.Key7},
+}
+
+// errNoKeys rejects a binding that would leave an action with no keys
+var errNoKeys = errors.New("no keys given")
+
+// Keys bound to each action, the defaults with any valid custom bindings
+var keyBindings = defaultKeyBindings
+
+// parseKeyBindings builds the bindings in effect from the custom bindings
+// in the settings. An unknown action is ignored, and an action with no keys
+// or a key name that isn't recognized keeps its default keys.
+func parseKeyBindings(custom map[string][]string) map[string][]ebiten.Key {
+	bindings := make(map[string][]ebiten.Key, len(defaultKeyBindings))
+	for name, keys := range defaultKeyBindings {
+		bindings[name] = keys
+	}
+	for name, names := range custom {
+		if _, ok := defaultKeyBindings[name]; !ok {
+			log.Printf("key bindings: unknown action %q", name)
+			continue
+		}
+		keys, err := parseKeys(names)
+		if err != nil {
+			log.Printf("key bindings: %s: %v", name, err)
+			continue
+		}
+		bindings[name] = keys
+	}
+	return bindings
+}
+
+// parseKeys turns key names into keys, failing if there are none or any
+// name isn't a key
+func parseKeys(names []string) ([]ebiten.Key, error) {
+	if len(names) == 0 {
+		return nil, errNoKeys
+	}
+	keys := make([]ebiten.Key, 0, len(names))
+	for _, name := range names {
+		var key ebiten.Key
+		if err := key.UnmarshalText([]byte(name)); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// applyKeyBindings makes the settings' key bindings the ones in effect
+func applyKeyBindings(settings Settings) {
+	keyBindings = parseKeyBindings(settings.KeyBindings)
+}
+
+// keyJustPressed and keyPressed are where actions learn of key presses.
+// Tests replace them to press and hold keys.
+var (
+	keyJustPressed = inpututil.IsKeyJustPressed
+	keyPressed     = ebiten.IsKeyPressed
+)
+
+// action reports whether a key bound to the named action was just pressed
+func action(name string) bool {
+	for _, key := range keyBindings[name] {
+		if keyJustPressed(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// actionRepeated is action, also true on the repeats of a held key
+func actionRepeated(name string) bool {
+	for _, key := range keyBindings[name] {
+		if keyRepeated(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// actionHeld reports whether a key bound to the named action is held down
+func actionHeld(name string) bool {
+	for _, key := range keyBindings[name] {
+		if keyPressed(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// updateGameKeys drops a piece with the number keys, takes back a move and
+// shows or hides the evaluation bar. Keys typed into the chat do none of
+// these.
+func (g *ConnectFourGame) updateGameKeys() {
+	if g.boardInputActive() {
+		for col, name := range dropActions {
+			if action(name) {
+				g.chooseColumn(col)
+				break
+			}
+		}
+	}
+	if g.activeInput != nil {
+		return
+	}
+	if action(ActionUndo) {
+		g.takeBack()
+	}
+	if (g.state == StateGame || g.state == StateGameOver) && action(ActionHint) {
+		g.toggleEvalBar()
+	}
+}
+
+// keyNames lists the keys bound to actions for display, e.g. "Enter, Space"
+func keyNames(names ...string) string {
+	keys := []string{}
+	for _, name := range names {
+		for _, key := range keyBindings[name] {
+			keys = append(keys, key.String())
+		}
+	}
+	return strings.Join(keys, ", ")
+}
+
+// openKeyBindings shows the key bindings screen from the settings
+func (g *ConnectFourGame) openKeyBindings() {
+	g.flushSettings()
+	g.transitionTo(StateKeyBindings)
+}
+
+// initKeyBindingsUI adds the key bindings screen's back button, which
+// returns to the settings
+func (g *ConnectFourGame) initKeyBindingsUI() {
+	g.buttons = append(g.buttons, &Button{
+		x:    float64(g.screenWidth) - 120*g.scaleX,
+		y:    20 * g.scaleY,
+		w:    100 * g.scaleX,
+		h:    30 * g.scaleY,
+		text: tr("common.back"),
+		action: func() {
+			g.transitionTo(StateSettings)
+		},
+	})
+}
+
+// drawKeyBindingsScreen lists every action with the keys bound to it, the
+// column drops last
+func (g *ConnectFourGame) drawKeyBindingsScreen(screen *ebiten.Image) {
+	title := tr("keys.title")
+	titleBounds := boundString(fontFace, title)
+	text.Draw(screen, title, fontFace,
+		g.screenWidth/2-titleBounds.Dx()/2, int(70*g.scaleY), colorText)
+
+	lineHeight := g.px(21)
+	left, right := g.screenWidth/2-int(220*g.scaleX), g.screenWidth/2+int(20*g.scaleX)
+	rows := [][2]string{}
+	for _, name := range actions {
+		rows = append(rows, [2]string{tr("keys." + name), keyNames(name)})
+	}
+	rows = append(rows, [2]string{tr("keys.drop"), keyNames(dropActions...)})
+	for i, row := range rows {
+		y := int(105*g.scaleY) + i*lineHeight
+		text.Draw(screen, row[0], fontFace, left, y, colorText)
+		text.Draw(screen, row[1], fontFace, right, y, colorText)
+	}
+	custom := tr("keys.custom")
+	customBounds := boundString(fontFace, custom)
+	text.Draw(screen, custom, fontFace,
+		g.screenWidth/2-customBounds.Dx()/2, int(105*g.scaleY)+len(rows)*lineHeight+lineHeight/2, colorText)
+
+	for _, btn := range g.buttons {
+		g.drawButton(screen, btn)
+	}
+}
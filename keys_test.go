@@ -0,0 +1,188 @@
+package main
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// pressKeys has keys read as just pressed until the test ends
+func pressKeys(t *testing.T, keys ...ebiten.Key) {
+	t.Helper()
+	saved := keyJustPressed
+	keyJustPressed = func(key ebiten.Key) bool { return slices.Contains(keys, key) }
+	t.Cleanup(func() { keyJustPressed = saved })
+}
+
+// bindKeys makes custom bindings the ones in effect until the test ends
+func bindKeys(t *testing.T, custom map[string][]string) {
+	t.Helper()
+	keyBindings = parseKeyBindings(custom)
+	t.Cleanup(func() { keyBindings = defaultKeyBindings })
+}
+
+func TestEveryActionIsListed(t *testing.T) {
+	listed := slices.Concat(actions, dropActions)
+	for name := range defaultKeyBindings {
+		if !slices.Contains(listed, name) {
+			t.Errorf("action %q isn't on the key bindings screen", name)
+		}
+	}
+	for _, name := range listed {
+		if len(defaultKeyBindings[name]) == 0 {
+			t.Errorf("action %q has no default keys", name)
+		}
+	}
+	if len(dropActions) != Columns {
+		t.Errorf("%d drop actions for %d columns", len(dropActions), Columns)
+	}
+}
+
+func TestParseKeyBindings(t *testing.T) {
+	got := parseKeyBindings(map[string][]string{
+		ActionScreenshot: {"F12"},
+		ActionDrop1:      {"A", "Numpad1"},
+		ActionUndo:       {},               // No keys keeps the default
+		ActionHint:       {"H", "NotAKey"}, // Nor does a key that doesn't exist
+		"teleport":       {"T"},            // Unknown actions are ignored
+	})
+	want := map[string][]ebiten.Key{
+		ActionScreenshot: {ebiten.KeyF12},
+		ActionDrop1:      {ebiten.KeyA, ebiten.KeyNumpad1},
+		ActionUndo:       defaultKeyBindings[ActionUndo],
+		ActionHint:       defaultKeyBindings[ActionHint],
+		ActionDrop2:      defaultKeyBindings[ActionDrop2],
+	}
+	for name, keys := range want {
+		if !reflect.DeepEqual(got[name], keys) {
+			t.Errorf("%s bound to %v, want %v", name, got[name], keys)
+		}
+	}
+	if _, ok := got["teleport"]; ok {
+		t.Error("an unknown action was bound")
+	}
+	if len(got) != len(defaultKeyBindings) {
+		t.Errorf("%d actions bound, want %d", len(got), len(defaultKeyBindings))
+	}
+}
+
+func TestActionFollowsBindings(t *testing.T) {
+	bindKeys(t, map[string][]string{ActionDrop3: {"Q"}})
+	pressKeys(t, ebiten.Key3)
+	if action(ActionDrop3) {
+		t.Error("a key no longer bound still acts")
+	}
+	pressKeys(t, ebiten.KeyQ)
+	if !action(ActionDrop3) || action(ActionDrop4) {
+		t.Error("the rebound key doesn't act, or acts for another action")
+	}
+}
+
+// keysTestGame starts a two-player game, so every drop is the keyboard's
+func keysTestGame(t *testing.T) *ConnectFourGame {
+	t.Helper()
+	g := newConnectFourGame(newMemoryStorage())
+	g.frame = 1
+	g.settings.ConfirmMoves = false
+	g.startNewGame()
+	g.hotseat = true
+	return g
+}
+
+func TestDropKeys(t *testing.T) {
+	g := keysTestGame(t)
+	bindKeys(t, map[string][]string{ActionDrop5: {"Numpad5"}})
+
+	pressKeys(t, ebiten.Key1)
+	g.updateGameKeys()
+	g.dropAnim = nil
+	pressKeys(t, ebiten.KeyNumpad5)
+	g.updateGameKeys()
+
+	want := []Move{{Column: 0, Player: Player}, {Column: 4, Player: Computer}}
+	if !reflect.DeepEqual(g.moves, want) {
+		t.Errorf("keys played %v, want %v", g.moves, want)
+	}
+}
+
+func TestUndoAndHintKeys(t *testing.T) {
+	g := keysTestGame(t)
+	g.undoBudget = undoUnlimited
+	pressKeys(t, ebiten.Key4)
+	g.updateGameKeys()
+	g.dropAnim = nil
+
+	pressKeys(t, ebiten.KeyU)
+	g.updateGameKeys()
+	if len(g.moves) != 0 {
+		t.Errorf("undo key left moves %v", g.moves)
+	}
+
+	shown := g.settings.EvalBar
+	pressKeys(t, ebiten.KeyH)
+	g.updateGameKeys()
+	if g.settings.EvalBar == shown {
+		t.Error("hint key didn't toggle the evaluation bar")
+	}
+	if g.settingsSaveTimer == 0 {
+		t.Error("the evaluation bar's new setting isn't going to be saved")
+	}
+}
+
+func TestChatKeepsKeysFromTheBoard(t *testing.T) {
+	g, sent := onlineTestGame(t, Player)
+	g.chatOpen = true
+	g.initUI()
+	if g.activeInput != nil {
+		t.Fatal("the chat took the keys before it was clicked")
+	}
+	if len(g.textInputs) != 1 {
+		t.Fatalf("game screen has %d inputs, want the chat's", len(g.textInputs))
+	}
+	g.setFocus(g.textInputs[0])
+
+	shown := g.settings.EvalBar
+	for _, key := range []ebiten.Key{ebiten.Key1, ebiten.Key4, ebiten.Key7, ebiten.KeyU, ebiten.KeyH} {
+		pressKeys(t, key)
+		g.updateGameKeys()
+	}
+	if len(g.moves) != 0 || g.board != (GameBoard{}) {
+		t.Errorf("typing in the chat played %v", g.moves)
+	}
+	if g.settings.EvalBar != shown {
+		t.Error("typing in the chat toggled the evaluation bar")
+	}
+	if got := sentMessages(t, sent); len(got) != 0 {
+		t.Errorf("typing in the chat sent %v", got)
+	}
+
+	// Once the chat lets go, the keys play again
+	g.setFocus(nil)
+	pressKeys(t, ebiten.Key4)
+	g.updateGameKeys()
+	if want := []Move{{Column: 3, Player: Player}}; !reflect.DeepEqual(g.moves, want) {
+		t.Errorf("after leaving the chat the keys played %v, want %v", g.moves, want)
+	}
+	if got := sentMessages(t, sent); !reflect.DeepEqual(got, []wireMessage{MoveMessage{Column: 3}}) {
+		t.Errorf("after leaving the chat the client sent %v", got)
+	}
+}
+
+func TestTabReachesTheChat(t *testing.T) {
+	g, _ := onlineTestGame(t, Player)
+	g.chatOpen = true
+	g.initUI()
+	for range len(g.focusOrder()) {
+		pressKeys(t, ebiten.KeyTab)
+		g.updateFocus()
+		if g.activeInput != nil {
+			if g.boardInputActive() {
+				t.Error("the board takes keys while the chat is focused")
+			}
+			return
+		}
+	}
+	t.Error("tabbing never reached the chat")
+}
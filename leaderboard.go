@@ -0,0 +1,234 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// Local Elo ratings: every user starts at initialRating and each game against
+// the computer counts as a game against an opponent of the difficulty's rating
+const (
+	initialRating   = 1200
+	ratingK         = 32 // Maximum rating change per game
+	leaderboardRows = 10 // Entries visible at once
+)
+
+// Rating assigned to the computer at each difficulty. Adaptive games count as
+// medium, which is about where the adaptive levels average out.
+var difficultyRatings = [numDifficulties]float64{1000, 1400, 1800, 1400}
+
+// LeaderboardEntry is one ranked user
+type LeaderboardEntry struct {
+	Rank     int
+	Username string
+	Rating   float64
+	Games    int
+	Wins     int // Only known for online players
+	Avatar   int
+}
+
+// updateRating returns a new Elo rating after a game against an opponent,
+// where score is 1 for a win, 0.5 for a draw and 0 for a loss
+func updateRating(rating, opponentRating, score float64) float64 {
+	expected := 1 / (1 + math.Pow(10, (opponentRating-rating)/400))
+	return rating + ratingK*(score-expected)
+}
+
+// ratingFor replays a user's results in order to compute their current rating
+func ratingFor(results []GameResult) float64 {
+	rating := float64(initialRating)
+	for _, result := range results {
+		if result.Difficulty < 0 || result.Difficulty >= numDifficulties {
+			continue
+		}
+		score := 0.5
+		switch result.Winner {
+		case Player:
+			score = 1
+		case Computer:
+			score = 0
+		}
+		rating = updateRating(rating, difficultyRatings[result.Difficulty], score)
+	}
+	return rating
+}
+
+// rankEntries sorts entries by rating, then games played, then name, and
+// assigns ranks with equal ratings sharing a rank
+func rankEntries(entries []LeaderboardEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if math.Round(a.Rating) != math.Round(b.Rating) {
+			return a.Rating > b.Rating
+		}
+		if a.Games != b.Games {
+			return a.Games > b.Games
+		}
+		return a.Username < b.Username
+	})
+	for i := range entries {
+		if i > 0 && math.Round(entries[i].Rating) == math.Round(entries[i-1].Rating) {
+			entries[i].Rank = entries[i-1].Rank
+		} else {
+			entries[i].Rank = i + 1
+		}
+	}
+}
+
+// loadLeaderboard builds the ranked list of every user with saved stats
+func loadLeaderboard(store Storage) []LeaderboardEntry {
+	keys, err := store.List("stats")
+	if err != nil {
+		log.Printf("loading leaderboard: %v", err)
+		return nil
+	}
+
+	entries := []LeaderboardEntry{}
+	for _, key := range keys {
+		data, err := store.Load(key)
+		if err != nil {
+			log.Printf("skipping stats %s: %v", key, err)
+			continue
+		}
+		var stats UserStats
+		if err := json.Unmarshal(data, &stats); err != nil {
+			log.Printf("skipping stats %s: %v", key, err)
+			continue
+		}
+		if len(stats.Results) == 0 {
+			continue
+		}
+		username := stats.Username
+		if username == "" {
+			// Stats saved before usernames were recorded
+			username = strings.TrimSuffix(path.Base(key), ".json")
+		}
+		profile, _ := loadProfile(store, username)
+		entries = append(entries, LeaderboardEntry{
+			Username: username,
+			Rating:   ratingFor(stats.Results),
+			Games:    len(stats.Results),
+			Avatar:   profile.Avatar,
+		})
+	}
+
+	rankEntries(entries)
+	return entries
+}
+
+// openLeaderboard loads the rankings and shows the leaderboard screen
+func (g *ConnectFourGame) openLeaderboard() {
+	g.leaderboard = loadLeaderboard(g.storage)
+	g.leaderboardScroll = 0
+	g.transitionTo(StateLeaderboard)
+}
+
+// scrollLeaderboard moves the visible window of entries, keeping it in range
+func (g *ConnectFourGame) scrollLeaderboard(delta int) {
+	maxScroll := max(0, len(g.leaderboard)-leaderboardRows)
+	g.leaderboardScroll = max(0, min(maxScroll, g.leaderboardScroll+delta))
+}
+
+// updateLeaderboard scrolls the list with the mouse wheel and arrow keys
+func (g *ConnectFourGame) updateLeaderboard() {
+	if _, dy := ebiten.Wheel(); dy > 0 {
+		g.scrollLeaderboard(-1)
+	} else if dy < 0 {
+		g.scrollLeaderboard(1)
+	}
+	if action(ActionUp) {
+		g.scrollLeaderboard(-1)
+	}
+	if action(ActionDown) {
+		g.scrollLeaderboard(1)
+	}
+}
+
+// initLeaderboardUI creates the back and scroll buttons
+func (g *ConnectFourGame) initLeaderboardUI() {
+	// Back button
+	g.buttons = append(g.buttons, &Button{
+		x:    float64(g.screenWidth) - 120*g.scaleX,
+		y:    20 * g.scaleY,
+		w:    100 * g.scaleX,
+		h:    30 * g.scaleY,
+		text: tr("common.back"),
+		action: func() {
+			g.transitionTo(StateGameMode)
+		},
+	})
+
+	if len(g.leaderboard) > leaderboardRows {
+		g.buttons = append(g.buttons, &Button{
+			x:    float64(g.screenWidth)/2 - 110*g.scaleX,
+			y:    480 * g.scaleY,
+			w:    100 * g.scaleX,
+			h:    30 * g.scaleY,
+			text: tr("common.up"),
+			action: func() {
+				g.scrollLeaderboard(-leaderboardRows)
+			},
+		})
+		g.buttons = append(g.buttons, &Button{
+			x:    float64(g.screenWidth)/2 + 10*g.scaleX,
+			y:    480 * g.scaleY,
+			w:    100 * g.scaleX,
+			h:    30 * g.scaleY,
+			text: tr("common.down"),
+			action: func() {
+				g.scrollLeaderboard(leaderboardRows)
+			},
+		})
+	}
+}
+
+// drawLeaderboardScreen renders the visible part of the rankings
+func (g *ConnectFourGame) drawLeaderboardScreen(screen *ebiten.Image) {
+	title := tr("leaderboard.title")
+	if len(g.leaderboard) == 0 {
+		title = tr("leaderboard.empty")
+	}
+	titleBounds := boundString(fontFace, title)
+	text.Draw(screen, title, fontFace,
+		g.screenWidth/2-titleBounds.Dx()/2, int(80*g.scaleY), colorText)
+
+	if len(g.leaderboard) > 0 {
+		columnX := []int{
+			g.screenWidth/2 - int(200*g.scaleX),
+			g.screenWidth/2 - int(130*g.scaleX),
+			g.screenWidth/2 + int(60*g.scaleX),
+			g.screenWidth/2 + int(140*g.scaleX),
+		}
+		rows := [][]string{{tr("leaderboard.rank"), tr("leaderboard.name"), tr("leaderboard.rating"), tr("leaderboard.games")}}
+		end := min(len(g.leaderboard), g.leaderboardScroll+leaderboardRows)
+		for _, entry := range g.leaderboard[g.leaderboardScroll:end] {
+			rows = append(rows, []string{
+				fmt.Sprint(entry.Rank), entry.Username, fmt.Sprintf("%.0f", entry.Rating), fmt.Sprint(entry.Games),
+			})
+		}
+		for i, row := range rows {
+			y := int((130 + float64(i)*30) * g.scaleY)
+			for col, cell := range row {
+				text.Draw(screen, cell, fontFace, columnX[col], y, colorText)
+			}
+			// Avatar just left of the name, skipping the header row
+			if i > 0 {
+				entry := g.leaderboard[g.leaderboardScroll+i-1]
+				g.drawAvatar(screen, entry.Avatar, float64(columnX[1])-22, float64(y)-14, 18)
+			}
+		}
+	}
+
+	// Draw buttons
+	for _, btn := range g.buttons {
+		g.drawButton(screen, btn)
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestUpdateRating(t *testing.T) {
+	tests := []struct {
+		rating, opponent, score float64
+		want                    float64
+	}{
+		{1200, 1200, 1, 1216},
+		{1200, 1200, 0, 1184},
+		{1200, 1200, 0.5, 1200},
+		// Expected scores of 0.2403 and 0.7597 for a 200 point gap
+		{1200, 1400, 1, 1224.31},
+		{1200, 1400, 0, 1192.31},
+		{1200, 1000, 0, 1175.69},
+		{1200, 1000, 0.5, 1191.69},
+		// A 400 point favourite is expected to score 10/11
+		{1600, 1200, 1, 1602.91},
+	}
+	for _, tt := range tests {
+		got := updateRating(tt.rating, tt.opponent, tt.score)
+		if math.Abs(got-tt.want) > 0.01 {
+			t.Errorf("updateRating(%v, %v, %v) = %.2f, want %.2f", tt.rating, tt.opponent, tt.score, got, tt.want)
+		}
+	}
+}
+
+func TestRatingFor(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []GameResult
+		want    float64
+	}{
+		{"no games", nil, initialRating},
+		{"win on easy", []GameResult{{Difficulty: 0, Winner: Player}}, 1207.69},
+		{"loss on hard", []GameResult{{Difficulty: 2, Winner: Computer}}, 1199.02},
+		{"draw on medium", []GameResult{{Difficulty: 1, Winner: Empty}}, 1208.31},
+		{"unknown difficulty skipped", []GameResult{{Difficulty: 9, Winner: Player}, {Difficulty: -1, Winner: Player}}, initialRating},
+		{"replayed in order", []GameResult{
+			{Difficulty: 1, Winner: Player},
+			{Difficulty: 1, Winner: Computer},
+		}, updateRating(updateRating(initialRating, 1400, 1), 1400, 0)},
+	}
+	for _, tt := range tests {
+		if got := ratingFor(tt.results); math.Abs(got-tt.want) > 0.01 {
+			t.Errorf("%s: ratingFor = %.2f, want %.2f", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRankEntries(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []LeaderboardEntry
+		order   []string
+		ranks   []int
+	}{
+		{
+			name: "by rating",
+			entries: []LeaderboardEntry{
+				{Username: "cy", Rating: 1100},
+				{Username: "al", Rating: 1300},
+				{Username: "bo", Rating: 1200},
+			},
+			order: []string{"al", "bo", "cy"},
+			ranks: []int{1, 2, 3},
+		},
+		{
+			name: "equal ratings share a rank",
+			entries: []LeaderboardEntry{
+				{Username: "dee", Rating: 1100},
+				{Username: "bo", Rating: 1250, Games: 3},
+				{Username: "al", Rating: 1250, Games: 7},
+				{Username: "cy", Rating: 1300},
+			},
+			order: []string{"cy", "al", "bo", "dee"},
+			ranks: []int{1, 2, 2, 4},
+		},
+		{
+			name: "ratings that round the same tie",
+			entries: []LeaderboardEntry{
+				{Username: "bo", Rating: 1200.4, Games: 2},
+				{Username: "al", Rating: 1199.6, Games: 2},
+			},
+			order: []string{"al", "bo"},
+			ranks: []int{1, 1},
+		},
+		{
+			name: "three way tie after a leader",
+			entries: []LeaderboardEntry{
+				{Username: "cy", Rating: 1200},
+				{Username: "bo", Rating: 1200},
+				{Username: "lead", Rating: 1400},
+				{Username: "al", Rating: 1200},
+				{Username: "last", Rating: 900},
+			},
+			order: []string{"lead", "al", "bo", "cy", "last"},
+			ranks: []int{1, 2, 2, 2, 5},
+		},
+	}
+	for _, tt := range tests {
+		rankEntries(tt.entries)
+		var order []string
+		var ranks []int
+		for _, entry := range tt.entries {
+			order = append(order, entry.Username)
+			ranks = append(ranks, entry.Rank)
+		}
+		if !slices.Equal(order, tt.order) || !slices.Equal(ranks, tt.ranks) {
+			t.Errorf("%s: got %v ranked %v, want %v ranked %v", tt.name, order, ranks, tt.order, tt.ranks)
+		}
+	}
+}
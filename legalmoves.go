@@ -0,0 +1,48 @@
+package main
+
+import (
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// How long the legal move markers stay up after pressing L
+const legalMovesShown = 2 * time.Second
+
+// updateLegalMoves shows the legal move markers when L is pressed, hides
+// them on a second press and counts down while they're shown
+func (g *ConnectFourGame) updateLegalMoves() {
+	if g.state != StateGame {
+		g.legalMovesTimer = 0
+		return
+	}
+	if action(ActionLegalMoves) && g.activeInput == nil {
+		if g.legalMovesTimer > 0 {
+			g.legalMovesTimer = 0
+		} else {
+			g.legalMovesTimer = legalMovesShown
+		}
+		return
+	}
+	countDown(&g.legalMovesTimer)
+}
+
+// drawLegalMoves marks every column that still has room, above the column
+// space gauges and coordinate labels, so where a piece can go is clear
+// without hovering
+func (g *ConnectFourGame) drawLegalMoves(screen *ebiten.Image, originX, originY float64) {
+	if g.legalMovesTimer <= 0 {
+		return
+	}
+	y := originY - boardFrameBorder - float64(g.px(16))
+	if g.settings.CapacityBars {
+		y -= float64(g.px(8))
+	}
+	if g.showCoordinates {
+		y -= float64(g.px(14))
+	}
+	for _, col := range getValidColumns(g.board) {
+		x := originX + float64(col)*g.cellSize + g.cellSize/2
+		g.drawSmoothCircle(screen, int(x), int(y), g.cellSize*0.12, g.hoverColor())
+	}
+}
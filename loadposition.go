@@ -0,0 +1,148 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// Reasons a pasted position can't be played from, besides those of
+// ParseBoard
+var (
+	errPositionFull = errors.New("position has no empty cells")
+	errWrongMover   = errors.New("side to move has more pieces")
+)
+
+// openLoadPosition shows the screen for starting a game from a pasted
+// position string
+func (g *ConnectFourGame) openLoadPosition() {
+	g.loadPositionMover = Player
+	g.transitionTo(StateLoadPosition)
+}
+
+// initLoadPositionUI lays out the position input, the choice of the side to
+// move and the start and back buttons
+func (g *ConnectFourGame) initLoadPositionUI() {
+	g.buttons = append(g.buttons, &Button{
+		x:    float64(g.screenWidth) - 120*g.scaleX,
+		y:    20 * g.scaleY,
+		w:    100 * g.scaleX,
+		h:    30 * g.scaleY,
+		text: tr("common.back"),
+		action: func() {
+			g.transitionTo(StateGameMode)
+		},
+	})
+
+	g.textInputs = append(g.textInputs, &TextInput{
+		x:           float64(g.screenWidth)/2 - 220*g.scaleX,
+		y:           180 * g.scaleY,
+		w:           440 * g.scaleX,
+		h:           30 * g.scaleY,
+		label:       tr("position.label"),
+		placeholder: FormatBoard(GameBoard{}),
+	})
+
+	// The side to move cycles between the two sides
+	moverButton := &Button{
+		x:    float64(g.screenWidth)/2 - 120*g.scaleX,
+		y:    260 * g.scaleY,
+		w:    240 * g.scaleX,
+		h:    40 * g.scaleY,
+		text: g.moverText(),
+	}
+	moverButton.action = func() {
+		g.loadPositionMover = opponentOf(g.loadPositionMover)
+		moverButton.text = g.moverText()
+	}
+	g.buttons = append(g.buttons, moverButton)
+	g.buttons = append(g.buttons, &Button{
+		x:      float64(g.screenWidth)/2 - 120*g.scaleX,
+		y:      310 * g.scaleY,
+		w:      240 * g.scaleX,
+		h:      40 * g.scaleY,
+		text:   tr("position.start"),
+		action: g.loadPosition,
+	})
+}
+
+// moverText labels the button choosing the side to move
+func (g *ConnectFourGame) moverText() string {
+	if g.loadPositionMover == Computer {
+		return tr("position.mover", tr("game.computer"))
+	}
+	return tr("position.mover", tr("position.you"))
+}
+
+// checkMover rejects a side to move that has already played more pieces
+// than the other, or a board with no move left to make
+func checkMover(board GameBoard, mover int) error {
+	if isBoardFull(board) {
+		return errPositionFull
+	}
+	counts := map[int]int{}
+	for row := 0; row < Rows; row++ {
+		for col := 0; col < Columns; col++ {
+			counts[board.At(row, col)]++
+		}
+	}
+	if counts[mover] > counts[opponentOf(mover)] {
+		return errWrongMover
+	}
+	return nil
+}
+
+// positionErrorText explains why a pasted position was refused
+func positionErrorText(err error) string {
+	switch {
+	case errors.Is(err, errFloatingPiece):
+		return tr("position.error.floating")
+	case errors.Is(err, errPieceCount):
+		return tr("position.error.count")
+	case errors.Is(err, errBothWon), errors.Is(err, errPositionWon):
+		return tr("position.error.won")
+	case errors.Is(err, errPositionFull):
+		return tr("position.error.full")
+	case errors.Is(err, errWrongMover):
+		return tr("position.error.mover")
+	}
+	return tr("position.error.format")
+}
+
+// loadPosition starts a game from the entered position with the chosen side
+// to move, or shows what is wrong with it
+func (g *ConnectFourGame) loadPosition() {
+	input := g.textInputs[0]
+	board, err := ParseBoard(input.value)
+	if err == nil {
+		err = checkMover(board, g.loadPositionMover)
+	}
+	if err != nil {
+		g.showInputError(input, positionErrorText(err))
+		return
+	}
+
+	// The side to move started the game only if both sides have played
+	// as many pieces
+	g.startNewGame()
+	g.setBoard(board)
+	g.setStarter(firstMover(board, g.loadPositionMover))
+	g.turn = g.loadPositionMover
+	g.fromPosition = true
+}
+
+// drawLoadPositionScreen renders the load position form
+func (g *ConnectFourGame) drawLoadPositionScreen(screen *ebiten.Image) {
+	title := tr("position.title")
+	titleBounds := boundString(fontFace, title)
+	text.Draw(screen, title, fontFace,
+		g.screenWidth/2-titleBounds.Dx()/2, int(110*g.scaleY), colorText)
+
+	for _, input := range g.textInputs {
+		g.drawTextInput(screen, input)
+	}
+	for _, btn := range g.buttons {
+		g.drawButton(screen, btn)
+	}
+}
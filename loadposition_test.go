@@ -0,0 +1,35 @@
+package main
+
+import "testing"
+
+func TestLoadPositionSides(t *testing.T) {
+	t.Cleanup(func() { firstPlayer = Player })
+	for _, tt := range []struct {
+		name     string
+		position string
+		mover    int
+		starter  int
+	}{
+		{"empty board", "......./......./......./......./......./.......", Player, Player},
+		{"empty board, computer to move", "......./......./......./......./......./.......", Computer, Computer},
+		{"player a piece ahead", "......./......./......./......./......./...X...", Computer, Player},
+		{"computer a piece ahead", "......./......./......./......./......./...O...", Player, Computer},
+		{"as many pieces each", "......./......./......./......./......./...XO..", Player, Player},
+		{"as many each, computer to move", "......./......./......./......./......./...XO..", Computer, Computer},
+	} {
+		// The game started from the position has the chosen side to move,
+		// and remembers who moved first for the parity threats and the stats
+		board := positionBoard(t, tt.position)
+		g := newConnectFourGame(newMemoryStorage())
+		g.frame = 1
+		g.openLoadPosition()
+		g.textInputs[0].value = tt.position
+		g.loadPositionMover = tt.mover
+		g.loadPosition()
+		g.cancelComputerMove()
+		if g.board != board || g.turn != tt.mover || firstPlayer != tt.starter {
+			t.Errorf("%s: loaded with %d to move and %d starting, want %d and %d",
+				tt.name, g.turn, firstPlayer, tt.mover, tt.starter)
+		}
+	}
+}
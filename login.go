@@ -0,0 +1,99 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Username length limits, in characters
+const (
+	minUsernameLength = 1
+	maxUsernameLength = 20
+)
+
+// How long a field shakes after failing validation
+const shakeDuration = 333 * time.Millisecond
+
+// Login and registration form fields that validation errors are reported against
+const (
+	fieldUsername = "username"
+	fieldPassword = "password"
+	fieldConfirm  = "confirm"
+)
+
+// usernameError returns the catalog key of what is wrong with a username, or
+// "" if it is acceptable
+func usernameError(username string) string {
+	length := utf8.RuneCountInString(username)
+	switch {
+	case length < minUsernameLength:
+		return "login.error.username_required"
+	case length > maxUsernameLength:
+		return "login.error.username_too_long"
+	case strings.TrimSpace(username) != username:
+		return "login.error.username_spaces"
+	}
+	return ""
+}
+
+// validateLogin checks the login form, returning the catalog key of an error
+// message for each field that is wrong. The password is only checked when
+// passwordRequired is set.
+func validateLogin(username, password string, passwordRequired bool) map[string]string {
+	errs := map[string]string{}
+	if key := usernameError(username); key != "" {
+		errs[fieldUsername] = key
+	}
+	if passwordRequired && password == "" {
+		errs[fieldPassword] = "login.error.password_required"
+	}
+	return errs
+}
+
+// validateRegistration checks the registration form like validateLogin,
+// also requiring an unused username, a long enough password and a matching
+// confirmation
+func validateRegistration(store Storage, username, password, confirm string) map[string]string {
+	errs := map[string]string{}
+	if key := usernameError(username); key != "" {
+		errs[fieldUsername] = key
+	} else if accountExists(store, username) {
+		errs[fieldUsername] = "register.error.username_taken"
+	}
+	if utf8.RuneCountInString(password) < minPasswordLength {
+		errs[fieldPassword] = "register.error.password_too_short"
+	}
+	if confirm != password {
+		errs[fieldConfirm] = "register.error.confirm_mismatch"
+	}
+	return errs
+}
+
+// loginErrorText returns the message for an error key from validateLogin or
+// validateRegistration
+func loginErrorText(key string) string {
+	switch key {
+	case "login.error.username_too_long":
+		return tr(key, maxUsernameLength)
+	case "register.error.password_too_short":
+		return tr(key, minPasswordLength)
+	}
+	return tr(key)
+}
+
+// showInputError marks an input as invalid and shakes it
+func (g *ConnectFourGame) showInputError(input *TextInput, message string) {
+	input.err = message
+	input.shakeUntil = g.animTimer + shakeDuration.Seconds()
+}
+
+// shakeOffset returns how far to draw an input from its place while it shakes
+func (g *ConnectFourGame) shakeOffset(input *TextInput) float64 {
+	seconds := input.shakeUntil - g.animTimer
+	if seconds <= 0 || !g.animationsEnabled() {
+		return 0
+	}
+	return 5 * math.Sin(seconds*72) * seconds / shakeDuration.Seconds() // 72 radians a second
+}
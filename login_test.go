@@ -0,0 +1,46 @@
+package main
+
+import (
+	"maps"
+	"strings"
+	"testing"
+)
+
+func TestValidateLogin(t *testing.T) {
+	tests := []struct {
+		name               string
+		username, password string
+		passwordRequired   bool
+		want               map[string]string
+	}{
+		{"valid", "alice", "hunter22", true, map[string]string{}},
+		{"no password needed", "alice", "", false, map[string]string{}},
+		{"longest username", strings.Repeat("a", maxUsernameLength), "", false, map[string]string{}},
+		{"multi-byte username at the limit", strings.Repeat("ñ", maxUsernameLength), "", false, map[string]string{}},
+		{"empty username", "", "hunter22", true, map[string]string{
+			fieldUsername: "login.error.username_required",
+		}},
+		{"username too long", strings.Repeat("a", maxUsernameLength+1), "hunter22", true, map[string]string{
+			fieldUsername: "login.error.username_too_long",
+		}},
+		{"leading space", " alice", "hunter22", true, map[string]string{
+			fieldUsername: "login.error.username_spaces",
+		}},
+		{"trailing space", "alice ", "", false, map[string]string{
+			fieldUsername: "login.error.username_spaces",
+		}},
+		{"missing password", "alice", "", true, map[string]string{
+			fieldPassword: "login.error.password_required",
+		}},
+		{"both wrong", "", "", true, map[string]string{
+			fieldUsername: "login.error.username_required",
+			fieldPassword: "login.error.password_required",
+		}},
+	}
+	for _, tt := range tests {
+		got := validateLogin(tt.username, tt.password, tt.passwordRequired)
+		if !maps.Equal(got, tt.want) {
+			t.Errorf("%s: validateLogin = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
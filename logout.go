@@ -0,0 +1,53 @@
+package main
+
+// requestLogout logs out, first asking to abandon a game in progress
+func (g *ConnectFourGame) requestLogout() {
+	if g.state == StateGame && g.gameInProgress && len(g.moves) > 0 {
+		g.confirm(tr("logout.abandon_confirm"), tr("logout.abandon_yes"), tr("register.cancel"), g.logout)
+		return
+	}
+	g.logout()
+}
+
+// logout forgets the current user, including any remembered login, and
+// returns to an empty login form. Nothing loaded for the user survives, so
+// the next user can't see or add to it.
+func (g *ConnectFourGame) logout() {
+	deleteSession(g.storage)
+	deleteServerTokens(g.storage, g.username)
+	g.rememberMe = false
+
+	// Abandons the current game, cancelling any computer move being searched
+	g.initializeGame()
+	g.gameInProgress = false
+	g.finishedGame = nil
+
+	g.closeOnline()
+	g.onlineRating = 0
+	g.chatLog = nil
+
+	g.username = ""
+	g.password = ""
+	g.guest = false
+	g.userStore = g.storage
+	g.pendingResume = nil
+	g.lastStarter = Empty
+	g.adaptive = newAdaptiveState()
+	g.session = SessionScore{}
+	g.tutorial = nil
+	g.applyProfile(Profile{})
+
+	g.weekStats = StatsSummary{}
+	g.allTimeStats = StatsSummary{}
+	g.puzzle = nil
+	g.puzzleStats = PuzzleStats{}
+	g.analysis = nil
+	g.tournament = nil
+	g.tournamentPlayers = nil
+	g.savedGames = nil
+	g.replay = nil
+	g.replayGame = nil
+
+	g.transitionTo(StateLogin)
+	g.setFocus(g.textInputs[0])
+}
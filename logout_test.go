@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLogoutForgetsTheUser(t *testing.T) {
+	g, _ := onlineTestGame(t, Player)
+	g.username, g.password, g.rememberMe = "alice", "hunter22", true
+	if err := registerAccount(g.storage, "alice", "hunter22"); err != nil {
+		t.Fatal(err)
+	}
+	if err := createSession(g.storage, "alice", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	saveServerToken(g.storage, "alice", "pipe", "1711972800.5f0c")
+	g.onlineRating = 1216
+	g.receiveChat("good luck")
+
+	g.logout()
+
+	if _, ok := loadSession(g.storage, time.Now()); ok {
+		t.Error("the remembered login survived logging out")
+	}
+	if tokens := loadServerTokens(g.storage, "alice"); len(tokens) != 0 {
+		t.Errorf("the game server's session tokens survived: %v", tokens)
+	}
+	if g.username != "" || g.password != "" || g.guest || g.rememberMe {
+		t.Errorf("still signed in as %q (guest %v, remembered %v)", g.username, g.guest, g.rememberMe)
+	}
+	if g.online != nil || g.onlineRating != 0 || len(g.chatLog) != 0 {
+		t.Errorf("online state survived: connection %v, rating %d, chat %v", g.online, g.onlineRating, g.chatLog)
+	}
+	if g.state != StateLogin || g.gameInProgress {
+		t.Errorf("logged out to state %s, game in progress %v", stateName(g.state), g.gameInProgress)
+	}
+
+	// The account itself stays, to log in again
+	if !accountExists(g.storage, "alice") {
+		t.Error("logging out deleted the account")
+	}
+}
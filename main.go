@@ -1,10 +1,40 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"log"
 )
 
 func main() {
+	flag.BoolVar(&debugMode, "debug", false, "enable developer shortcuts (Shift+Backspace clears the board)")
+	flag.StringVar(&fontPath, "font", "", "TrueType font file for interface text, instead of the bundled Go font")
+	flag.StringVar(&puzzlePath, "puzzles", "", "JSON puzzle file to practise with, instead of the bundled puzzles")
+	serveAddr := flag.String("serve", "", "serve the engine's HTTP API on this address instead of opening the game, e.g. :8000")
+	gameAddr := flag.String("gameserver", "", "run a server for online play on this address instead of opening the game, e.g. :9090")
+	moveTime := flag.Duration("movetime", defaultMoveTime, "time each player has for a move on -gameserver, or 0 for no limit")
+	benchPath := flag.String("benchpositions", "", "time the computer's search on each position in this file, one per line, and print a CSV and histogram")
+	benchDepth := flag.Int("benchdepth", difficultyDepths[DifficultyHard], "search depth for -benchpositions")
+	flag.Parse()
+
+	// The API runs on its own, without the GUI
+	if *serveAddr != "" {
+		log.Fatal(serveAPI(*serveAddr))
+	}
+
+	// So does the online play server
+	if *gameAddr != "" {
+		log.Fatal(serveGames(*gameAddr, *moveTime))
+	}
+
+	// And the search benchmark
+	if *benchPath != "" {
+		if err := runBenchPositions(*benchPath, *benchDepth); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	RunEbitenGUI()
 }
 
@@ -0,0 +1,117 @@
+package main
+
+import "time"
+
+// Matchmaking: waiting players are paired on a regular tick. Rated players
+// are first only paired with others close to their rating, and the gap
+// allowed widens the longer they wait, so nobody waits for ever. Casual
+// players are paired with any other casual player.
+const (
+	matchmakingTick  = time.Second
+	baseRatingGap    = 100 // Widest rating gap paired on joining the queue
+	ratingGapPerTick = 25  // How much wider the gap gets each tick of waiting
+)
+
+// queueEntry is a player waiting for an opponent
+type queueEntry struct {
+	peer   *netPeer
+	rated  bool // Wants a rated game, so only paired with rated players
+	rating int  // 0 when the player has no rating
+	joined int  // Tick the player joined the queue
+	told   int  // Queue position last sent to the player
+
+	// Closing stop ends the goroutine watching for the player leaving,
+	// which closes done once it has
+	stop chan struct{}
+	done chan struct{}
+}
+
+// matchQueue holds the players waiting for an opponent in arrival order
+type matchQueue struct {
+	entries []*queueEntry
+}
+
+// add puts a player at the back of the queue at tick now
+func (q *matchQueue) add(e *queueEntry, now int) {
+	e.joined = now
+	q.entries = append(q.entries, e)
+}
+
+// remove takes a player out of the queue, if it is there
+func (q *matchQueue) remove(p *netPeer) *queueEntry {
+	for i, e := range q.entries {
+		if e.peer == p {
+			q.entries = append(q.entries[:i], q.entries[i+1:]...)
+			return e
+		}
+	}
+	return nil
+}
+
+// allowedGap returns the rating gap a player who joined at tick joined
+// accepts at tick now
+func allowedGap(joined, now int) int {
+	return baseRatingGap + max(0, now-joined)*ratingGapPerTick
+}
+
+// ratingGap returns how far apart two players' ratings are, 0 if either
+// player has no rating
+func ratingGap(a, b *queueEntry) int {
+	if a.rating == 0 || b.rating == 0 {
+		return 0
+	}
+	return abs(a.rating - b.rating)
+}
+
+// acceptable reports whether a and b may be paired at tick now. Rated and
+// casual players are never paired together. The player who has waited
+// longer decides, so the gap a long wait has earned is used.
+func acceptable(a, b *queueEntry, now int) bool {
+	return a.rated == b.rated && ratingGap(a, b) <= allowedGap(min(a.joined, b.joined), now)
+}
+
+// pair takes the pairs to play at tick now out of the queue. Players are
+// considered longest waiting first, each paired with the closest rated
+// acceptable opponent, or the earliest arrival of those equally close. The
+// result only depends on the queue and now.
+func (q *matchQueue) pair(now int) [][2]*queueEntry {
+	pairs := [][2]*queueEntry{}
+	paired := make(map[*queueEntry]bool)
+	for i, e := range q.entries {
+		if paired[e] {
+			continue
+		}
+		var best *queueEntry
+		for _, other := range q.entries[i+1:] {
+			if paired[other] || !acceptable(e, other, now) {
+				continue
+			}
+			if best == nil || ratingGap(e, other) < ratingGap(e, best) {
+				best = other
+			}
+		}
+		if best != nil {
+			paired[e], paired[best] = true, true
+			pairs = append(pairs, [2]*queueEntry{e, best})
+		}
+	}
+
+	waiting := q.entries[:0]
+	for _, e := range q.entries {
+		if !paired[e] {
+			waiting = append(waiting, e)
+		}
+	}
+	q.entries = waiting
+	return pairs
+}
+
+// announce tells each waiting player its place in the queue, if it changed
+func (q *matchQueue) announce() {
+	for i, e := range q.entries {
+		if e.told != i+1 {
+			e.told = i + 1
+			e.peer.send(QueueMessage{Position: e.told})
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import "testing"
+
+// waiting returns a queue entry for a new player with the given rating
+func waiting(rated bool, rating, joined int) *queueEntry {
+	return &queueEntry{peer: &netPeer{}, rated: rated, rating: rating, joined: joined}
+}
+
+func TestAllowedGapWidensWithWaiting(t *testing.T) {
+	for _, tt := range []struct {
+		joined, now, want int
+	}{
+		{0, 0, baseRatingGap},
+		{0, 1, baseRatingGap + ratingGapPerTick},
+		{3, 7, baseRatingGap + 4*ratingGapPerTick},
+		{5, 4, baseRatingGap}, // A clock behind the join never narrows it
+	} {
+		if got := allowedGap(tt.joined, tt.now); got != tt.want {
+			t.Errorf("allowedGap(%d, %d) = %d, want %d", tt.joined, tt.now, got, tt.want)
+		}
+	}
+}
+
+func TestAcceptable(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		a, b *queueEntry
+		now  int
+		want bool
+	}{
+		{"casual players", waiting(false, 0, 0), waiting(false, 0, 0), 0, true},
+		{"rated with casual", waiting(true, 1200, 0), waiting(false, 1200, 0), 10, false},
+		{"close ratings", waiting(true, 1200, 0), waiting(true, 1300, 0), 0, true},
+		{"too far apart", waiting(true, 1200, 0), waiting(true, 1301, 0), 0, false},
+		{"far apart after waiting", waiting(true, 1200, 0), waiting(true, 1301, 0), 1, true},
+		{"the longer wait counts", waiting(true, 1200, 8), waiting(true, 1400, 0), 8, true},
+		{"one unrated", waiting(true, 0, 0), waiting(true, 2000, 0), 0, true},
+	} {
+		if got := acceptable(tt.a, tt.b, tt.now); got != tt.want {
+			t.Errorf("%s: acceptable = %v, want %v", tt.name, got, tt.want)
+		}
+		if got := acceptable(tt.b, tt.a, tt.now); got != tt.want {
+			t.Errorf("%s, either way round: acceptable = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// queued returns a queue holding entries in order, and the entries
+func queued(entries ...*queueEntry) (*matchQueue, []*queueEntry) {
+	return &matchQueue{entries: append([]*queueEntry{}, entries...)}, entries
+}
+
+func TestPairLeavesALonePlayerWaiting(t *testing.T) {
+	q, e := queued(waiting(false, 0, 0))
+	for now := range 100 {
+		if pairs := q.pair(now); len(pairs) != 0 {
+			t.Fatalf("tick %d: a lone player was paired: %v", now, pairs)
+		}
+	}
+	if len(q.entries) != 1 || q.entries[0] != e[0] {
+		t.Errorf("queue = %v, want the player still waiting", q.entries)
+	}
+}
+
+func TestPairOddQueue(t *testing.T) {
+	q, e := queued(waiting(false, 0, 0), waiting(false, 0, 0), waiting(false, 0, 0))
+	pairs := q.pair(0)
+	if len(pairs) != 1 || pairs[0] != [2]*queueEntry{e[0], e[1]} {
+		t.Fatalf("pairs = %v, want the first two players", pairs)
+	}
+	if len(q.entries) != 1 || q.entries[0] != e[2] {
+		t.Errorf("queue = %v, want the third player left waiting", q.entries)
+	}
+}
+
+func TestPairIsFirstComeFirstServed(t *testing.T) {
+	q, e := queued(waiting(false, 0, 0), waiting(false, 0, 1), waiting(false, 0, 2),
+		waiting(false, 0, 3), waiting(false, 0, 4))
+	pairs := q.pair(4)
+	want := [][2]*queueEntry{{e[0], e[1]}, {e[2], e[3]}}
+	if len(pairs) != len(want) {
+		t.Fatalf("%d pairs, want %d", len(pairs), len(want))
+	}
+	for i := range want {
+		if pairs[i] != want[i] {
+			t.Errorf("pair %d is not the next two in arrival order", i+1)
+		}
+	}
+	if len(q.entries) != 1 || q.entries[0] != e[4] {
+		t.Errorf("queue = %v, want the last arrival waiting", q.entries)
+	}
+}
+
+func TestPairPrefersTheClosestRating(t *testing.T) {
+	first := waiting(true, 1500, 0)
+	far := waiting(true, 1590, 0)
+	near := waiting(true, 1520, 0)
+	tied := waiting(true, 1480, 0)
+	q, _ := queued(first, far, near, tied)
+	pairs := q.pair(0)
+	if len(pairs) == 0 || pairs[0] != [2]*queueEntry{first, near} {
+		t.Fatalf("pairs = %v, want the longest waiting with the closest rating", pairs)
+	}
+
+	// Among those equally close, the earlier arrival plays
+	early, late := waiting(true, 1450, 0), waiting(true, 1550, 0)
+	q, _ = queued(first, early, late)
+	if pairs := q.pair(0); len(pairs) != 1 || pairs[0][1] != early {
+		t.Errorf("pairs = %v, want the earlier of two equally close players", pairs)
+	}
+}
+
+func TestPairWidensTheGapOverTime(t *testing.T) {
+	low, high := waiting(true, 1000, 0), waiting(true, 1300, 0)
+	q, _ := queued(low, high)
+	// 300 apart needs (300-100)/25 = 8 ticks of waiting
+	for now := 0; now < 8; now++ {
+		if pairs := q.pair(now); len(pairs) != 0 {
+			t.Fatalf("tick %d: paired 300 apart too soon", now)
+		}
+	}
+	if pairs := q.pair(8); len(pairs) != 1 {
+		t.Errorf("tick 8: still not paired, want the widened gap to allow it")
+	}
+}
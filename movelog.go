@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// Move log panel layout, in logical pixels
+const (
+	moveLogWidth      = 150
+	moveLogTop        = 140
+	moveLogLineHeight = 16
+	moveLogPadding    = 6
+	moveLogCharWidth  = 7 // Width of a basicfont character
+)
+
+// moveLogEntries names each move with its mover and the cell it landed in,
+// e.g. "You D1". Moves that don't fit on the board end the log.
+func moveLogEntries(moves []Move) []string {
+	var board GameBoard
+	entries := []string{}
+	for _, move := range moves {
+		if move.Column < 0 || move.Column >= Columns || board.columnFull(move.Column) {
+			break
+		}
+		row := 0
+		for row+1 < Rows && board.At(row+1, move.Column) == Empty {
+			row++
+		}
+		board.Set(row, move.Column, move.Player)
+
+		mover := tr("common.you")
+		if move.Player == Computer {
+			mover = tr("common.cpu")
+		}
+		entries = append(entries, mover+" "+cellName(row, move.Column))
+	}
+	return entries
+}
+
+// moveLogLines pairs up the entries into numbered lines, e.g. "1. You D1  CPU D2"
+func moveLogLines(entries []string) []string {
+	lines := []string{}
+	for i := 0; i < len(entries); i += 2 {
+		line := fmt.Sprintf("%d. %s", i/2+1, entries[i])
+		if i+1 < len(entries) {
+			line += "  " + entries[i+1]
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// moveLogRect returns the panel's area, and false if the log is switched off
+// or the window is too narrow to show it beside the board
+func (g *ConnectFourGame) moveLogRect() (x, y, w, h float64, ok bool) {
+	w = float64(g.px(moveLogWidth))
+	x = float64(g.screenWidth) - w - 20*g.scaleX
+	y = moveLogTop * g.scaleY
+	h = float64(g.screenHeight) - y - 20*g.scaleY
+	boardRight := g.boardOffsetX + float64(Columns)*g.cellSize
+	ok = g.showMoveLog && x >= boardRight+20 && h >= float64(2*g.px(moveLogLineHeight))
+	return x, y, w, h, ok
+}
+
+// moveLogFirstLine returns the first line shown, keeping the latest move in view
+func (g *ConnectFourGame) moveLogFirstLine(lines int, h float64) int {
+	visible := int(h)/g.px(moveLogLineHeight) - 1 // One line for the heading
+	return max(0, lines-visible)
+}
+
+// toggleMoveLog shows or hides the move log panel
+func (g *ConnectFourGame) toggleMoveLog() {
+	g.showMoveLog = !g.showMoveLog
+	g.initUI()
+}
+
+// moveLogToggleText returns the label of the move log toggle button
+func (g *ConnectFourGame) moveLogToggleText() string {
+	return tr("game.moves_toggle", onOff(g.showMoveLog))
+}
+
+// handleMoveLogClick opens the replay viewer at the clicked move of the
+// finished game, reporting whether the click hit a move
+func (g *ConnectFourGame) handleMoveLogClick(clickX, clickY int) bool {
+	x, y, w, h, ok := g.moveLogRect()
+	if !ok || g.finishedGame == nil || !pointInRect(float64(clickX), float64(clickY), x, y, w, h) {
+		return false
+	}
+
+	// Lines start below the heading
+	entries := moveLogEntries(g.finishedGame.Moves)
+	line := (clickY-int(y))/g.px(moveLogLineHeight) - 1
+	if line < 0 {
+		return false
+	}
+	line += g.moveLogFirstLine(len(moveLogLines(entries)), h)
+
+	ply := line * 2
+	if ply >= len(entries) {
+		return false
+	}
+
+	// Clicks past the first move, e.g. "3. You C2", pick the second
+	firstWidth := len(fmt.Sprintf("%d. %s", line+1, entries[ply])) + 1
+	if float64(clickX) >= x+float64(g.px(moveLogPadding)+firstWidth*g.px(moveLogCharWidth)) && ply+1 < len(entries) {
+		ply++
+	}
+
+	// Show the position just after the clicked move
+	g.openReplayAt(g.finishedGame, ply+1, StateGameOver)
+	return true
+}
+
+// drawMoveLog renders the panel listing the moves so far
+func (g *ConnectFourGame) drawMoveLog(screen *ebiten.Image) {
+	x, y, w, h, ok := g.moveLogRect()
+	if !ok {
+		return
+	}
+
+	drawRect(screen, x, y, w, h, colorSlotBg)
+	lineHeight, padding := g.px(moveLogLineHeight), g.px(moveLogPadding)
+	text.Draw(screen, tr("game.moves_heading"), fontFace, int(x)+padding, int(y)+lineHeight-g.px(3), colorText)
+
+	entries := moveLogEntries(g.moves)
+	lines := moveLogLines(entries)
+	first := g.moveLogFirstLine(len(lines), h)
+	for i := first; i < len(lines); i++ {
+		lineY := int(y) + (i-first+2)*lineHeight - g.px(3)
+		text.Draw(screen, lines[i], fontFace, int(x)+padding, lineY, colorText)
+	}
+}
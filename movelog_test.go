@@ -0,0 +1,79 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMoveLogNotation(t *testing.T) {
+	entries := moveLogEntries(movesFrom("44531"))
+	want := []string{"You D1", "CPU D2", "You E1", "CPU C1", "You A1"}
+	if !slices.Equal(entries, want) {
+		t.Errorf("moveLogEntries = %q, want %q", entries, want)
+	}
+	lines := moveLogLines(entries)
+	wantLines := []string{"1. You D1  CPU D2", "2. You E1  CPU C1", "3. You A1"}
+	if !slices.Equal(lines, wantLines) {
+		t.Errorf("moveLogLines = %q, want %q", lines, wantLines)
+	}
+	if got := moveNotation(movesFrom("44531")); got != "D D E C A" {
+		t.Errorf("moveNotation = %q", got)
+	}
+
+	// The log ends at the first move that doesn't fit
+	full := append(movesFrom("1111111"), movesFrom("2")...)
+	if got := moveLogEntries(full); len(got) != Rows || got[Rows-1] != "CPU A6" {
+		t.Errorf("log of a move into a full column: %q", got)
+	}
+	offBoard := append(movesFrom("4"), Move{Column: Columns, Player: Computer})
+	if got := moveLogEntries(offBoard); len(got) != 1 {
+		t.Errorf("log of a move off the board: %q", got)
+	}
+	if got := moveLogLines(nil); len(got) != 0 {
+		t.Errorf("empty log has lines %q", got)
+	}
+}
+
+func TestMoveLogJumpsToPly(t *testing.T) {
+	g := newConnectFourGame(newMemoryStorage())
+	g.screenWidth, g.screenHeight = 1200, 800
+	g.updateLayout()
+	g.showMoveLog = true
+	g.state = StateGameOver
+	g.finishedGame = &SavedGame{Winner: Player, Moves: movesFrom("1213141")}
+
+	x, y, _, _, ok := g.moveLogRect()
+	if !ok {
+		t.Fatal("move log not shown beside the board")
+	}
+	lineHeight := float64(g.px(moveLogLineHeight))
+	// Where each line's two moves start, as in "1. You A1  CPU B1"
+	firstX := x + float64(g.px(moveLogPadding)+g.px(moveLogCharWidth))
+	secondX := x + float64(g.px(moveLogPadding)+len("1. You A1  ")*g.px(moveLogCharWidth))
+
+	for _, tt := range []struct {
+		name string
+		x, y float64
+		ply  int // Ply the replay opens at, or -1 for no jump
+	}{
+		{"first move", firstX, y + 1.5*lineHeight, 1},
+		{"second move", secondX, y + 1.5*lineHeight, 2},
+		{"third move", firstX, y + 2.5*lineHeight, 3},
+		{"last move", firstX, y + 4.5*lineHeight, 7},
+		{"right of the last move", secondX, y + 4.5*lineHeight, 7},
+		{"past the last line", firstX, y + 5.5*lineHeight, -1},
+		{"heading", firstX, y + 0.5*lineHeight, -1},
+		{"left of the panel", x - 1, y + 1.5*lineHeight, -1},
+	} {
+		g.state = StateGameOver
+		g.replay = nil
+		jumped := g.handleMoveLogClick(int(tt.x), int(tt.y))
+		if jumped != (tt.ply >= 0) {
+			t.Errorf("%s: jumped %v", tt.name, jumped)
+			continue
+		}
+		if jumped && (g.state != StateReplay || g.replay.Ply() != tt.ply || g.replayReturnState != StateGameOver) {
+			t.Errorf("%s: state %d at ply %d, want the replay at ply %d", tt.name, g.state, g.replay.Ply(), tt.ply)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// How the player's move compares with the best one available
+const (
+	qualityGood       = iota // As good as any other move
+	qualityInaccuracy        // Lets a forced win slip
+	qualityBlunder           // Loses by force when something else didn't
+)
+
+// Move quality tag tuning
+const (
+	moveQualityDepth      = 4                       // Shallower than assist mode as it runs on every move
+	moveQualityNodeBudget = 15000                   // Per column, keeping the check within a frame
+	moveQualityShown      = 1500 * time.Millisecond // How long the tag stays up
+)
+
+// Catalog key and color of the tag for each quality
+var moveQualityTags = [...]struct {
+	key   string
+	color color.RGBA
+}{
+	qualityGood:       {"quality.good", color.RGBA{40, 150, 70, 255}},
+	qualityInaccuracy: {"quality.inaccuracy", color.RGBA{220, 140, 20, 255}},
+	qualityBlunder:    {"quality.blunder", colorError},
+}
+
+// moveQuality rates the player dropping in col by the solver's results
+// under rules for every move in the position. It reports false when any
+// search ran out of budget, as the rating could then be wrong.
+func moveQuality(board GameBoard, col int, rules Rules) (int, bool) {
+	chosen := SolveLoss
+	best := SolveLoss
+	for _, other := range getValidColumns(board) {
+		outcome, ok := moveOutcome(board, other, moveQualityDepth, moveQualityNodeBudget, rules)
+		if !ok {
+			return 0, false
+		}
+		if other == col {
+			chosen = outcome
+		}
+		if outcome > best {
+			best = outcome
+		}
+	}
+
+	switch {
+	case chosen == best:
+		return qualityGood, true
+	case chosen == SolveLoss:
+		return qualityBlunder, true
+	default:
+		return qualityInaccuracy, true
+	}
+}
+
+// rateMove shows the quality tag for the player's move about to be played in col
+func (g *ConnectFourGame) rateMove(col int) {
+	g.moveQualityTimer = 0
+	if quality, ok := moveQuality(g.board, col, currentRules()); ok {
+		g.moveQuality = quality
+		g.moveQualityTimer = moveQualityShown
+	}
+}
+
+// drawMoveQuality draws the tag for the player's last move beside the status text
+func (g *ConnectFourGame) drawMoveQuality(screen *ebiten.Image, statusRight, statusY int) {
+	if g.moveQualityTimer <= 0 || g.state != StateGame {
+		return
+	}
+	tag := moveQualityTags[g.moveQuality]
+	text.Draw(screen, tr(tag.key), fontFace, statusRight+int(15*g.scaleX), statusY, tag.color)
+}
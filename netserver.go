@@ -0,0 +1,686 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net"
+	"sync"
+	"time"
+)
+
+// helloTimeout is how long a new client has to introduce itself
+const helloTimeout = 10 * time.Second
+
+// defaultMoveTime is how long each player has for a move unless the server
+// is told otherwise
+const defaultMoveTime = 30 * time.Second
+
+// serverConfig is how long the game server gives players and waits on
+// their connections
+type serverConfig struct {
+	moveTime         time.Duration // For each move, or 0 for no limit
+	heartbeatTimeout time.Duration // Silence after which a client has dropped
+	reconnectGrace   time.Duration // How long a dropped player's seat is held
+}
+
+// newServerConfig returns the usual timings, with moveTime for each move
+func newServerConfig(moveTime time.Duration) serverConfig {
+	return serverConfig{moveTime: moveTime, heartbeatTimeout: heartbeatTimeout, reconnectGrace: reconnectGrace}
+}
+
+// gameServer is what the game server's goroutines share. Closing quit
+// shuts it down, and running counts the goroutines still to finish.
+type gameServer struct {
+	serverConfig
+	logins  *serverLogins
+	ratings *ratingBook
+	hub     *spectatorHub
+	desk    *resumeDesk
+	quit    chan struct{}
+	running sync.WaitGroup
+
+	mu    sync.Mutex
+	conns map[net.Conn]bool // Clients still connected, to hang up on when shutting down
+}
+
+// serverConn is a client's connection, which the server forgets once it
+// is closed
+type serverConn struct {
+	net.Conn
+	server *gameServer
+}
+
+func (c serverConn) Close() error {
+	c.server.mu.Lock()
+	delete(c.server.conns, c)
+	c.server.mu.Unlock()
+	return c.Conn.Close()
+}
+
+// goRun runs f in a goroutine the server waits for when shutting down
+func (s *gameServer) goRun(f func()) {
+	s.running.Add(1)
+	go func() {
+		defer s.running.Done()
+		f()
+	}()
+}
+
+// track remembers a client's connection until it is closed
+func (s *gameServer) track(conn net.Conn) net.Conn {
+	tracked := serverConn{Conn: conn, server: s}
+	s.mu.Lock()
+	s.conns[tracked] = true
+	s.mu.Unlock()
+	return tracked
+}
+
+// shutDown ends every match and hangs up on every client, then waits for
+// the server's goroutines to finish
+func (s *gameServer) shutDown() {
+	close(s.quit)
+	s.mu.Lock()
+	conns := make([]net.Conn, 0, len(s.conns))
+	for conn := range s.conns {
+		conns = append(conns, conn)
+	}
+	s.mu.Unlock()
+	for _, conn := range conns {
+		conn.Close()
+	}
+	s.running.Wait()
+}
+
+// errNotSignedIn turns away a client whose session token or password doesn't
+// sign in the player it names
+var errNotSignedIn = errors.New("couldn't sign in the player")
+
+// Reasons the server rejects a move
+var (
+	errNotYourTurn = errors.New("it is not your turn")
+	errOffBoard    = errors.New("that column is off the board")
+	errBadColumn   = errors.New("that column is full")
+	errGameOver    = errors.New("the game is over")
+)
+
+// rejectCode returns the reject reason sent to the client for an error
+// from netMatch.play
+func rejectCode(err error) string {
+	switch err {
+	case errNotYourTurn:
+		return rejectNotYourTurn
+	case errOffBoard:
+		return rejectOffBoard
+	case errBadColumn:
+		return rejectColumnFull
+	}
+	return rejectGameOver
+}
+
+// netPeer is a client connected to the game server
+type netPeer struct {
+	conn      net.Conn
+	codec     *wireCodec
+	hello     HelloMessage
+	account   string           // Account the client proved it is signed in to, empty for a guest
+	spectator bool             // Came to watch games rather than play
+	standings bool             // Came for the leaderboard only
+	resume    string           // Came to rejoin the game with this token
+	messages  chan wireMessage // Closed once the client disconnects
+	done      chan struct{}    // Closed once the server is finished with the client
+	closeOnce sync.Once
+	writeMu   sync.Mutex // Pings are answered from the reading goroutine
+	chat      chatLimiter
+}
+
+// greetPeer waits for a new client's hello, or its request to spectate, for
+// the leaderboard or to rejoin a game, then starts reading its messages. A
+// hello signing in is only taken if logins issued its session token for its
+// name, or its password is right, in which case the client is sent a token
+// for next time. A client silent for the server's heartbeatTimeout is taken
+// to have gone.
+func (s *gameServer) greetPeer(conn net.Conn) (*netPeer, error) {
+	p := &netPeer{
+		conn:     conn,
+		codec:    newWireCodec(conn),
+		messages: make(chan wireMessage),
+		done:     make(chan struct{}),
+	}
+	conn.SetReadDeadline(time.Now().Add(helloTimeout))
+	msg, err := p.codec.read()
+	if err != nil {
+		return nil, err
+	}
+	switch msg := msg.(type) {
+	case HelloMessage:
+		switch {
+		case msg.Token != "":
+			if !s.logins.check(msg.Name, msg.Token, time.Now()) {
+				return nil, errNotSignedIn
+			}
+			p.account = msg.Name
+		case msg.Password != "":
+			token, ok := s.logins.signIn(msg.Name, msg.Password, time.Now())
+			if !ok {
+				return nil, errNotSignedIn
+			}
+			p.account = msg.Name
+			p.send(SignedInMessage{Token: token})
+		}
+		msg.Password = ""
+		p.hello = msg
+	case SpectateMessage:
+		p.hello = HelloMessage{Name: msg.Name}
+		p.spectator = true
+	case StandingsMessage:
+		p.hello = HelloMessage{Name: msg.Name}
+		p.standings = true
+	case ResumeMessage:
+		p.resume = msg.Token
+	default:
+		return nil, errors.New("expected hello, got " + msg.wireType())
+	}
+
+	s.goRun(func() {
+		defer close(p.messages)
+		for {
+			conn.SetReadDeadline(time.Now().Add(s.heartbeatTimeout))
+			msg, err := p.codec.read()
+			if _, ok := msg.(MoveMessage); ok && errors.Is(err, errColumnRange) {
+				// Passed on for the game to refuse, so the client hears why
+				err = nil
+			}
+			if errors.Is(err, errBadMessage) {
+				log.Printf("from %s: %v", conn.RemoteAddr(), err)
+				continue
+			}
+			if err != nil {
+				return
+			}
+			if ping, ok := msg.(PingMessage); ok {
+				p.send(PongMessage{Nonce: ping.Nonce})
+				continue
+			}
+			select {
+			case p.messages <- msg:
+			case <-p.done:
+				return
+			}
+		}
+	})
+	return p, nil
+}
+
+// send writes a message to the client. A failed write shows up as the
+// client disconnecting, so the error is only logged. Nothing is sent to a
+// nil client, which stands for one away reconnecting.
+func (p *netPeer) send(msg wireMessage) {
+	if p == nil {
+		return
+	}
+	p.writeMu.Lock()
+	defer p.writeMu.Unlock()
+	if err := p.codec.write(msg); err != nil {
+		log.Printf("sending to %s: %v", p.conn.RemoteAddr(), err)
+	}
+}
+
+// inbox returns the client's messages, or nil for a client away
+// reconnecting, which never has any
+func (p *netPeer) inbox() <-chan wireMessage {
+	if p == nil {
+		return nil
+	}
+	return p.messages
+}
+
+// rated reports whether the client asked for rated games. Ratings are kept
+// by account, so a client not signed in to one can only play casual games.
+func (p *netPeer) rated() bool {
+	return p.hello.Rated && p.account != ""
+}
+
+// close disconnects the client, if there is one
+func (p *netPeer) close() {
+	if p == nil {
+		return
+	}
+	p.closeOnce.Do(func() {
+		close(p.done)
+		p.conn.Close()
+	})
+}
+
+// netMatch is the server's own copy of a game, which every move is checked
+// against before it is passed on
+type netMatch struct {
+	board     GameBoard
+	hash      uint64 // HashBoard of board, kept up as moves are played
+	moves     []int  // Columns played, for clients that lose track
+	turn      int
+	over      bool
+	ending    GameOverMessage // How the game ended, once over
+	drawOffer int             // Side with a draw on offer, or Empty
+
+	// Rated games update the ratings of the players' accounts when they end
+	players  [2]string
+	accounts [2]string
+	ratings  *ratingBook // Nil for a casual game
+
+	// The side to move loses once clock runs out. Each move has moveTime,
+	// or as long as it takes if moveTime is 0. The clock stands still
+	// while either client is away, with clockLeft to go.
+	moveTime  time.Duration
+	clock     *time.Timer
+	clockEnds time.Time     // When the running clock runs out
+	clockLeft time.Duration // Time on the clock while it stands still
+
+	// A client that loses the connection mid-game has reconnectGrace to
+	// come back, timed for each seat while it is away
+	reconnectGrace time.Duration
+	away           [2]*time.Timer
+
+	// The game as spectators see it
+	hub  *spectatorHub
+	live *liveGame
+}
+
+// play applies side's move if it is legal. Clients are never trusted, so
+// everything is checked here whatever they checked themselves.
+func (m *netMatch) play(side, col int) error {
+	switch {
+	case m.over:
+		return errGameOver
+	case side != m.turn:
+		return errNotYourTurn
+	case col < 0 || col >= Columns:
+		return errOffBoard
+	case m.board.columnFull(col):
+		return errBadColumn
+	}
+	m.hash = hashDrop(m.hash, m.board.columnSpace(col)-1, col, side)
+	m.board = dropPiece(m.board, col, side)
+	m.moves = append(m.moves, col)
+	m.turn = opponentOf(side)
+	m.drawOffer = Empty
+	m.startClock()
+	return nil
+}
+
+// startClock gives the side to move its time for the move, starting the
+// clock unless a client is away
+func (m *netMatch) startClock() {
+	if m.moveTime <= 0 {
+		return
+	}
+	m.stopClock()
+	m.clockLeft = m.moveTime
+	m.resumeClock()
+}
+
+// stopClock stands the clock still with the time it has left
+func (m *netMatch) stopClock() {
+	if m.clock == nil {
+		return
+	}
+	m.clock.Stop()
+	m.clock = nil
+	m.clockLeft = time.Until(m.clockEnds)
+}
+
+// resumeClock starts a clock that was standing still, once neither client
+// is away
+func (m *netMatch) resumeClock() {
+	if m.moveTime <= 0 || m.over || m.clock != nil || m.away[0] != nil || m.away[1] != nil {
+		return
+	}
+	m.clockEnds = time.Now().Add(m.clockLeft)
+	m.clock = time.NewTimer(m.clockLeft)
+}
+
+// timeLeft returns the milliseconds the side to move has, as sent to the
+// clients, or 0 without a clock. Time running out is sent as 1, as 0 means
+// there is no clock.
+func (m *netMatch) timeLeft() int {
+	if m.moveTime <= 0 {
+		return 0
+	}
+	left := m.clockLeft
+	if m.clock != nil {
+		left = time.Until(m.clockEnds)
+	}
+	return max(1, int(left/time.Millisecond))
+}
+
+// timeUp returns a channel that fires when the side to move runs out of
+// time, or nil if it can't
+func (m *netMatch) timeUp() <-chan time.Time {
+	if m.clock == nil || m.over {
+		return nil
+	}
+	return m.clock.C
+}
+
+// result reports whether the game is over, who won and why
+func (m *netMatch) result() (over bool, end GameOverMessage) {
+	for _, side := range []int{Player, Computer} {
+		if checkWin(m.board, side) {
+			return true, GameOverMessage{Winner: side, Reason: gameOverFour}
+		}
+	}
+	return isBoardFull(m.board), GameOverMessage{Winner: Empty, Reason: gameOverFull}
+}
+
+// end finishes the game, telling the clients still there and the
+// spectators how. A rated game's ratings are updated and sent to the
+// clients still there. Ending a game that is already over does nothing, so
+// it is only told and rated once.
+func (m *netMatch) end(peers [2]*netPeer, end GameOverMessage) {
+	if m.over {
+		return
+	}
+	m.over = true
+	m.ending = end
+	m.stopClock()
+	for _, p := range peers {
+		if p != nil {
+			p.send(end)
+		}
+	}
+	if m.ratings != nil {
+		after := m.ratings.record(m.accounts, end.Winner)
+		for i, p := range peers {
+			if p != nil {
+				p.send(RatingMessage{Rating: roundRating(after[i])})
+			}
+		}
+	}
+	m.hub.finish(m.live, end)
+}
+
+// serveGames runs the online play server on addr until it fails, giving
+// players moveTime for each move
+func serveGames(addr string, moveTime time.Duration) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	log.Printf("game server listening on %s", ln.Addr())
+
+	// Without storage the ratings last as long as the server
+	store, err := newStorage()
+	if err != nil {
+		log.Printf("persistent storage unavailable: %v", err)
+		store = newMemoryStorage()
+	}
+	// Players sign in to accounts held here, not on their own machines
+	logins, err := loadServerLogins(store)
+	if err != nil {
+		return err
+	}
+	return runGameServer(ln, logins, loadRatingBook(store), newServerConfig(moveTime))
+}
+
+// runGameServer queues clients as they connect and plays a match between
+// each pair matchmaking makes. A client that leaves while waiting is
+// forgotten. Spectators are handed a game to watch instead, clients
+// asking for the leaderboard are sent it from ratings, and clients that
+// lost their connection are handed back to their game. Clients asking for
+// rated games are told their rating on arrival. Players sign in through
+// logins, and config sets the timings. Once ln is closed the server shuts
+// down, returning when everything it started has finished.
+func runGameServer(ln net.Listener, logins *serverLogins, ratings *ratingBook, config serverConfig) error {
+	s := &gameServer{
+		serverConfig: config,
+		logins:       logins,
+		ratings:      ratings,
+		hub:          &spectatorHub{},
+		desk:         &resumeDesk{},
+		quit:         make(chan struct{}),
+		conns:        make(map[net.Conn]bool),
+	}
+	defer s.shutDown()
+	lobby := make(chan *netPeer)
+	s.goRun(func() { s.runMatchmaking(lobby, matchmakingTick) })
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+		conn = s.track(conn)
+		s.goRun(func() {
+			p, err := s.greetPeer(conn)
+			if err != nil {
+				log.Printf("greeting %s: %v", conn.RemoteAddr(), err)
+				conn.Close()
+				return
+			}
+			if p.spectator {
+				s.hub.watch(p)
+				return
+			}
+			if p.standings {
+				p.send(ratings.standings(p.hello.Name))
+				p.close()
+				return
+			}
+			if p.resume != "" {
+				if !s.desk.rejoin(p) {
+					p.send(GameOverMessage{Winner: Empty, Reason: gameOverGone})
+					p.close()
+				}
+				return
+			}
+			if p.rated() {
+				p.send(RatingMessage{Rating: roundRating(ratings.rating(p.account))})
+			}
+			select {
+			case lobby <- p:
+			case <-s.quit:
+				p.close()
+			}
+		})
+	}
+}
+
+// runMatchmaking queues the clients arriving from lobby and pairs them
+// every tick, starting a match for each pair. Rated players are queued with
+// their rating. Once the server shuts down, the clients still waiting are
+// hung up on.
+func (s *gameServer) runMatchmaking(lobby <-chan *netPeer, tick time.Duration) {
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	queue := &matchQueue{}
+	left := make(chan *netPeer)
+	now := 0
+	for {
+		select {
+		case p := <-lobby:
+			e := &queueEntry{peer: p, stop: make(chan struct{}), done: make(chan struct{})}
+			if p.rated() {
+				e.rated, e.rating = true, roundRating(s.ratings.rating(p.account))
+			}
+			s.goRun(func() { watchQueued(e, left) })
+			queue.add(e, now)
+			queue.announce()
+		case p := <-left:
+			if e := queue.remove(p); e != nil {
+				<-e.done
+				p.close()
+				queue.announce()
+			}
+		case <-ticker.C:
+			now++
+			for _, pair := range queue.pair(now) {
+				// The match reads the clients' messages from here on
+				for _, e := range pair {
+					close(e.stop)
+					<-e.done
+				}
+				// Only rated players are paired with each other
+				var book *ratingBook
+				if pair[0].rated {
+					book = s.ratings
+				}
+				s.goRun(func() { s.playNetMatch(pair[0].peer, pair[1].peer, book) })
+			}
+			queue.announce()
+		case <-s.quit:
+			for _, e := range queue.entries {
+				close(e.stop)
+				<-e.done
+				e.peer.close()
+			}
+			return
+		}
+	}
+}
+
+// watchQueued reports on left when a queued client disconnects, until its
+// entry is stopped
+func watchQueued(e *queueEntry, left chan<- *netPeer) {
+	defer close(e.done)
+	for {
+		select {
+		case _, ok := <-e.peer.messages:
+			// Nothing but pings is expected before the match starts
+			if !ok {
+				select {
+				case left <- e.peer:
+				case <-e.stop:
+				}
+				return
+			}
+		case <-e.stop:
+			return
+		}
+	}
+}
+
+// playNetMatch plays games between two clients until one of them leaves,
+// relaying moves, chat and draw offers. The first client plays first, and the sides swap
+// for each rematch both clients ask for. Every game is rated with ratings,
+// unless it is nil. Clients that lose their connection can rejoin through
+// the server's desk. The match ends when the server shuts down.
+func (s *gameServer) playNetMatch(first, second *netPeer, ratings *ratingBook) {
+	peers := [2]*netPeer{first, second}
+	seats := s.desk.open()
+	defer func() {
+		s.desk.close(seats)
+		for _, p := range peers {
+			p.close()
+		}
+	}()
+
+	for {
+		if !s.playNetGame(&peers, seats, ratings) {
+			return
+		}
+		peers[0], peers[1] = peers[1], peers[0]
+		seats.tokens[0], seats.tokens[1] = seats.tokens[1], seats.tokens[0]
+	}
+}
+
+// playNetGame plays one game, peers[0] moving first, and waits for both to
+// offer a rematch. It reports false if a client left instead. Spectators
+// are sent the game's moves until it ends. A client that loses the
+// connection mid-game has reconnectGrace to rejoin with its token from
+// seats, taking its place in peers again. One that leaves for good, or
+// runs out of time, loses, so either costs a rated game as much as
+// resigning it.
+func (s *gameServer) playNetGame(peers *[2]*netPeer, seats *resumeSeats, ratings *ratingBook) bool {
+	match := &netMatch{turn: Player, hub: s.hub, moveTime: s.moveTime, reconnectGrace: s.reconnectGrace}
+	match.startClock()
+	for i, p := range peers {
+		p.send(MatchFoundMessage{Side: Player + i, Opponent: peers[1-i].hello.Name, TimeLeft: match.timeLeft(), Resume: seats.tokens[i]})
+	}
+
+	match.players = [2]string{peers[0].hello.Name, peers[1].hello.Name}
+	match.accounts = [2]string{peers[0].account, peers[1].account}
+	if match.accounts[0] != "" && match.accounts[1] != "" && match.accounts[0] != match.accounts[1] {
+		// Playing yourself on one account can't be rated
+		match.ratings = ratings
+	}
+	match.live = s.hub.begin(match.players)
+	var rematch [2]bool
+	for !rematch[0] || !rematch[1] {
+		var (
+			msg wireMessage
+			ok  bool
+			i   int
+		)
+		select {
+		case msg, ok = <-peers[0].inbox():
+			i = 0
+		case msg, ok = <-peers[1].inbox():
+			i = 1
+		case p := <-seats.rejoins:
+			match.rejoin(peers, seats, p)
+			continue
+		case <-match.timeUp():
+			match.end(*peers, GameOverMessage{Winner: opponentOf(match.turn), Reason: gameOverTime})
+			continue
+		case <-match.graceUp(0):
+			match.abandon(*peers, 0)
+			return false
+		case <-match.graceUp(1):
+			match.abandon(*peers, 1)
+			return false
+		case <-s.quit:
+			return false
+		}
+		side, other := Player+i, peers[1-i]
+		if !ok && !match.over {
+			// Gone mid-game, but the client may yet come back
+			match.leave(peers, i)
+			continue
+		}
+		if !ok {
+			// Leaving once the game is over ends the match, and no more
+			return false
+		}
+
+		switch msg := msg.(type) {
+		case MoveMessage:
+			if err := match.play(side, msg.Column); err != nil {
+				peers[i].send(MoveRejectMessage{Column: msg.Column, Code: rejectCode(err), Reason: err.Error()})
+				continue
+			}
+			hash := match.hash
+			peers[i].send(MoveAckMessage{Column: msg.Column, Hash: hash, TimeLeft: match.timeLeft()})
+			other.send(MoveMessage{Column: msg.Column, Side: side, Hash: hash, TimeLeft: match.timeLeft()})
+			s.hub.played(match.live, msg.Column, side)
+			if over, end := match.result(); over {
+				match.end(*peers, end)
+			}
+		case ResignMessage:
+			match.end(*peers, GameOverMessage{Winner: opponentOf(side), Reason: gameOverResign})
+		case DrawOfferMessage:
+			switch {
+			case match.over:
+			case match.drawOffer == opponentOf(side):
+				match.end(*peers, GameOverMessage{Winner: Empty, Reason: gameOverDraw})
+			case match.drawOffer == Empty:
+				match.drawOffer = side
+				other.send(msg)
+			}
+		case SyncMessage:
+			peers[i].send(SyncMessage{Moves: match.moves, TimeLeft: match.timeLeft()})
+		case ChatMessage:
+			// Floods are dropped and long lines cut short. The game's
+			// spectators hear it too.
+			if peers[i].chat.allow(time.Now()) {
+				line := truncateText(msg.Text, maxChatLength)
+				other.send(ChatMessage{Text: line})
+				s.hub.chatted(match.live, line, side)
+			}
+		case RematchMessage:
+			if match.over && !rematch[i] {
+				rematch[i] = true
+				other.send(msg)
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,445 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testTimeout bounds every wait in the network tests
+const testTimeout = 10 * time.Second
+
+// pipeListener hands the game server in-memory connections, so the tests
+// need no network
+type pipeListener struct {
+	conns chan net.Conn
+	done  chan struct{}
+	once  sync.Once
+}
+
+func newPipeListener() *pipeListener {
+	return &pipeListener{conns: make(chan net.Conn), done: make(chan struct{})}
+}
+
+func (l *pipeListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *pipeListener) Close() error {
+	l.once.Do(func() { close(l.done) })
+	return nil
+}
+
+func (l *pipeListener) Addr() net.Addr { return pipeAddr{} }
+
+// dial connects to the server listening on l
+func (l *pipeListener) dial() (net.Conn, error) {
+	client, server := net.Pipe()
+	select {
+	case l.conns <- server:
+		return client, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+type pipeAddr struct{}
+
+func (pipeAddr) Network() string { return "pipe" }
+func (pipeAddr) String() string  { return "pipe" }
+
+// testLogins holds the accounts and key of the test servers, as if their
+// players had signed in to them before
+var testLogins = newMemoryStorage()
+
+// signedIn introduces name to a test server asking for rated games, with a
+// session token the server issued at now
+func signedIn(t *testing.T, name string, now time.Time) HelloMessage {
+	t.Helper()
+	logins, err := loadServerLogins(testLogins)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return HelloMessage{Name: name, Rated: true, Token: issueSessionToken(logins.secret, name, now)}
+}
+
+// startTestServer runs a game server with its own ratings until the test
+// ends, giving moveTime for each move
+func startTestServer(t *testing.T, moveTime time.Duration) (*pipeListener, *ratingBook) {
+	t.Helper()
+	return startConfiguredServer(t, newServerConfig(moveTime))
+}
+
+// startConfiguredServer runs a game server with its own ratings and
+// config's timings until the test ends
+func startConfiguredServer(t *testing.T, config serverConfig) (*pipeListener, *ratingBook) {
+	t.Helper()
+	ratings := loadRatingBook(newMemoryStorage())
+	logins, err := loadServerLogins(testLogins)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return serveTest(t, logins, ratings, config), ratings
+}
+
+// serveTest runs a game server until the test ends, then waits for it to
+// shut down, so nothing it started outlives the test
+func serveTest(t *testing.T, logins *serverLogins, ratings *ratingBook, config serverConfig) *pipeListener {
+	ln := newPipeListener()
+	served := make(chan struct{})
+	go func() {
+		runGameServer(ln, logins, ratings, config)
+		close(served)
+	}()
+	t.Cleanup(func() {
+		ln.Close()
+		<-served
+	})
+	return ln
+}
+
+// testClient is a scripted client. Messages are read as they arrive, as a
+// real connection would buffer them, so the server never waits on the test.
+type testClient struct {
+	t        *testing.T
+	conn     net.Conn
+	codec    *wireCodec
+	messages chan wireMessage
+	found    MatchFoundMessage // How the match started, once it has
+}
+
+// connect dials the server and sends first, the client's introduction
+func connect(t *testing.T, ln *pipeListener, first wireMessage) *testClient {
+	t.Helper()
+	conn, err := ln.dial()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &testClient{t: t, conn: conn, codec: newWireCodec(conn), messages: make(chan wireMessage, 256)}
+	go func() {
+		defer close(c.messages)
+		for {
+			msg, err := c.codec.read()
+			if err != nil && msg == nil {
+				return
+			}
+			c.messages <- msg
+		}
+	}()
+	t.Cleanup(func() { conn.Close() })
+	c.send(first)
+	return c
+}
+
+// send writes a message, failing the test if it can't
+func (c *testClient) send(msg wireMessage) {
+	c.t.Helper()
+	if err := c.codec.write(msg); err != nil {
+		c.t.Fatalf("sending %s: %v", msg.wireType(), err)
+	}
+}
+
+// sendRaw writes a frame of data as is, for messages the codec would refuse
+// to send
+func (c *testClient) sendRaw(data string) {
+	c.t.Helper()
+	if _, err := c.conn.Write(frame(data)); err != nil {
+		c.t.Fatalf("sending %s: %v", data, err)
+	}
+}
+
+// next returns the next message other than a queue update, or nil once the
+// server has hung up
+func (c *testClient) next() wireMessage {
+	c.t.Helper()
+	timeout := time.After(testTimeout)
+	for {
+		select {
+		case msg, ok := <-c.messages:
+			if !ok {
+				return nil
+			}
+			if _, queued := msg.(QueueMessage); queued {
+				continue
+			}
+			return msg
+		case <-timeout:
+			c.t.Fatal("timed out waiting for the server")
+		}
+	}
+}
+
+// expect reads the next message, failing the test unless it is a T
+func expect[T wireMessage](c *testClient) T {
+	c.t.Helper()
+	msg := c.next()
+	got, ok := msg.(T)
+	if !ok {
+		var want T
+		c.t.Fatalf("got %#v, want a %s message", msg, want.wireType())
+	}
+	return got
+}
+
+// startMatch connects two casual players and returns them in side order
+func startMatch(t *testing.T, ln *pipeListener) [2]*testClient {
+	t.Helper()
+	return startMatchAs(t, ln, HelloMessage{Name: "alice"}, HelloMessage{Name: "bob"})
+}
+
+// startMatchAs connects two players introduced as helloA and helloB and
+// returns them in side order. Players signed in for rated games are told
+// their ratings first.
+func startMatchAs(t *testing.T, ln *pipeListener, helloA, helloB HelloMessage) [2]*testClient {
+	t.Helper()
+	a := connect(t, ln, helloA)
+	b := connect(t, ln, helloB)
+	if helloA.Rated && helloA.Token != "" {
+		expect[RatingMessage](a)
+	}
+	if helloB.Rated && helloB.Token != "" {
+		expect[RatingMessage](b)
+	}
+	a.found, b.found = expect[MatchFoundMessage](a), expect[MatchFoundMessage](b)
+	if a.found.Side == b.found.Side {
+		t.Fatalf("both players got side %d", a.found.Side)
+	}
+	if a.found.Side == Player {
+		return [2]*testClient{a, b}
+	}
+	return [2]*testClient{b, a}
+}
+
+// playMove has the client on side play col and checks both clients hear of
+// it with the hash of board after the move
+func playMove(t *testing.T, clients [2]*testClient, board *GameBoard, side, col int) {
+	t.Helper()
+	mover, other := clients[side-Player], clients[Computer-side]
+	mover.send(MoveMessage{Column: col})
+	*board = dropPiece(*board, col, side)
+	hash := HashBoard(*board)
+
+	ack := expect[MoveAckMessage](mover)
+	if ack.Column != col || ack.Hash != hash {
+		t.Fatalf("ack %+v, want column %d hash %x", ack, col, hash)
+	}
+	move := expect[MoveMessage](other)
+	if move.Column != col || move.Side != side || move.Hash != hash {
+		t.Fatalf("opponent heard %+v, want column %d by %d hash %x", move, col, side, hash)
+	}
+}
+
+func TestScriptedOnlineGame(t *testing.T) {
+	ln, _ := startTestServer(t, 0)
+	clients := startMatch(t, ln)
+
+	chat := "good luck"
+	clients[0].send(ChatMessage{Text: chat})
+	if got := expect[ChatMessage](clients[1]); got.Text != chat {
+		t.Errorf("chat arrived as %q", got.Text)
+	}
+
+	var board GameBoard
+	for i, col := range []int{0, 1, 0, 1, 0, 1} {
+		playMove(t, clients, &board, Player+i%2, col)
+	}
+	clients[0].send(MoveMessage{Column: 0})
+	expect[MoveAckMessage](clients[0])
+	expect[MoveMessage](clients[1])
+
+	for i, c := range clients {
+		end := expect[GameOverMessage](c)
+		if end.Winner != Player || end.Reason != gameOverFour {
+			t.Errorf("client %d: game ended %+v, want a four for the first player", i, end)
+		}
+	}
+
+	// Both asking for a rematch starts another game with the sides swapped
+	clients[0].send(RematchMessage{})
+	expect[RematchMessage](clients[1])
+	clients[1].send(RematchMessage{})
+	expect[RematchMessage](clients[0])
+	if found := expect[MatchFoundMessage](clients[1]); found.Side != Player {
+		t.Errorf("rematch gave the second player side %d, want to move first", found.Side)
+	}
+	expect[MatchFoundMessage](clients[0])
+}
+
+func TestIllegalMovesRejected(t *testing.T) {
+	ln, _ := startTestServer(t, 0)
+	clients := startMatch(t, ln)
+
+	reject := func(c *testClient, code string) {
+		t.Helper()
+		got := expect[MoveRejectMessage](c)
+		if got.Code != code {
+			t.Errorf("rejected with %q (%s), want %q", got.Code, got.Reason, code)
+		}
+	}
+
+	// Out of turn
+	clients[1].send(MoveMessage{Column: 3})
+	reject(clients[1], rejectNotYourTurn)
+
+	// Off the board, which the client's codec wouldn't even send
+	clients[0].sendRaw(`{"type":"move","version":1,"payload":{"column":7}}`)
+	reject(clients[0], rejectOffBoard)
+	clients[0].sendRaw(`{"type":"move","version":1,"payload":{"column":-1}}`)
+	reject(clients[0], rejectOffBoard)
+
+	// Into a full column
+	var board GameBoard
+	for i := range Rows {
+		playMove(t, clients, &board, Player+i%2, 2)
+	}
+	clients[0].send(MoveMessage{Column: 2})
+	reject(clients[0], rejectColumnFull)
+
+	// After the game is over
+	clients[0].send(ResignMessage{})
+	for _, c := range clients {
+		if end := expect[GameOverMessage](c); end.Winner != Computer || end.Reason != gameOverResign {
+			t.Errorf("resigning ended the game %+v", end)
+		}
+	}
+	clients[1].send(MoveMessage{Column: 0})
+	reject(clients[1], rejectGameOver)
+
+	// None of the refused moves reached the board
+	clients[1].send(SyncMessage{})
+	if sync := expect[SyncMessage](clients[1]); len(sync.Moves) != Rows {
+		t.Errorf("server played %v, want only the %d moves accepted", sync.Moves, Rows)
+	}
+}
+
+func TestDrawByAgreement(t *testing.T) {
+	ln, _ := startTestServer(t, 0)
+	clients := startMatch(t, ln)
+	var board GameBoard
+	playMove(t, clients, &board, Player, 3)
+
+	// An offer lapses once either player moves
+	clients[0].send(DrawOfferMessage{})
+	expect[DrawOfferMessage](clients[1])
+	playMove(t, clients, &board, Computer, 3)
+	clients[1].send(DrawOfferMessage{})
+	expect[DrawOfferMessage](clients[0])
+
+	clients[0].send(DrawOfferMessage{})
+	for _, c := range clients {
+		if end := expect[GameOverMessage](c); end.Winner != Empty || end.Reason != gameOverDraw {
+			t.Errorf("accepting the draw ended the game %+v", end)
+		}
+	}
+
+	clients[1].send(SyncMessage{})
+	if sync := expect[SyncMessage](clients[1]); !reflect.DeepEqual(sync.Moves, []int{3, 3}) {
+		t.Errorf("synced moves %v, want [3 3]", sync.Moves)
+	}
+}
+
+func TestSlowMoverForfeits(t *testing.T) {
+	ln, _ := startTestServer(t, 100*time.Millisecond)
+	clients := startMatch(t, ln)
+	var board GameBoard
+	playMove(t, clients, &board, Player, 3)
+
+	// The second player never answers
+	for i, c := range clients {
+		if end := expect[GameOverMessage](c); end.Winner != Player || end.Reason != gameOverTime {
+			t.Errorf("client %d: game ended %+v, want the second player out of time", i, end)
+		}
+	}
+	clients[1].send(MoveMessage{Column: 3})
+	if reject := expect[MoveRejectMessage](clients[1]); reject.Code != rejectGameOver {
+		t.Errorf("a move after running out of time was refused with %q", reject.Code)
+	}
+}
+
+func TestClockCountdownPayloads(t *testing.T) {
+	const moveTime = 10 * time.Second
+	full := int(moveTime / time.Millisecond)
+	ln, _ := startTestServer(t, moveTime)
+	clients := startMatch(t, ln)
+	for i, c := range clients {
+		if c.found.TimeLeft <= full-200 || c.found.TimeLeft > full {
+			t.Errorf("client %d started with %d ms, want the full %d", i, c.found.TimeLeft, full)
+		}
+	}
+
+	// Time spent thinking comes off the clock the server reports, and
+	// each move starts it again for the other side
+	time.Sleep(200 * time.Millisecond)
+	clients[1].send(SyncMessage{})
+	if sync := expect[SyncMessage](clients[1]); sync.TimeLeft > full-200 || sync.TimeLeft <= 0 {
+		t.Errorf("sync 200 ms in says %d ms left, want under %d", sync.TimeLeft, full-200)
+	}
+	clients[0].send(MoveMessage{Column: 3})
+	ack, move := expect[MoveAckMessage](clients[0]), expect[MoveMessage](clients[1])
+	for _, left := range []int{ack.TimeLeft, move.TimeLeft} {
+		if left <= full-200 || left > full {
+			t.Errorf("after a move %d ms left, want the full %d", left, full)
+		}
+	}
+}
+
+func TestServerDropsOversizedMessages(t *testing.T) {
+	ln, _ := startTestServer(t, 0)
+	conn, err := ln.dial()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// A hello longer than any message is cut off rather than read
+	go func() {
+		conn.Write(binary.BigEndian.AppendUint32(nil, 1<<30))
+		chunk := []byte(strings.Repeat("a", 1024))
+		for {
+			if _, err := conn.Write(chunk); err != nil {
+				return
+			}
+		}
+	}()
+	conn.SetReadDeadline(time.Now().Add(testTimeout))
+	if _, err := newWireCodec(conn).read(); !errors.Is(err, io.EOF) {
+		t.Errorf("server answered an endless hello with %v, want it to hang up", err)
+	}
+}
+
+func TestHelloTokensAreChecked(t *testing.T) {
+	ln, _ := startTestServer(t, 0)
+	signed := signedIn(t, "alice", time.Now())
+	for _, tc := range []struct {
+		name  string
+		hello HelloMessage
+	}{
+		{"another player's token", HelloMessage{Name: "mallory", Rated: true, Token: signed.Token}},
+		{"a forged signature", HelloMessage{Name: "alice", Rated: true, Token: signed.Token[:len(signed.Token)-2] + "00"}},
+		{"an expired token", signedIn(t, "alice", time.Now().Add(-sessionLifetime-time.Minute))},
+		{"a token signed elsewhere", func() HelloMessage {
+			secret, _ := sessionSecret(newMemoryStorage())
+			return HelloMessage{Name: "alice", Rated: true, Token: issueSessionToken(secret, "alice", time.Now())}
+		}()},
+		{"a garbled token", HelloMessage{Name: "alice", Token: "garbage"}},
+	} {
+		if msg := connect(t, ln, tc.hello).next(); msg != nil {
+			t.Errorf("%s: server answered %#v, want it to hang up", tc.name, msg)
+		}
+	}
+
+	// Signed in, or as a guest without a token, casual players are paired
+	signed.Rated = false
+	startMatchAs(t, ln, signed, HelloMessage{Name: "Guest-3817"})
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// Cells are named by column letter (A on the left) and row number (1 at the
+// bottom), e.g. "D1" for the first piece dropped in the centre column.
+
+// Extra space kept clear above the board for the column letters
+const coordinateMargin = 16
+
+// columnName returns the letter naming a 0-based column
+func columnName(col int) string {
+	return string(rune('A' + col))
+}
+
+// rowName returns the number naming a 0-based row counted from the top
+func rowName(row int) string {
+	return fmt.Sprint(Rows - row)
+}
+
+// cellName returns the name of a cell, e.g. "D1"
+func cellName(row, col int) string {
+	return columnName(col) + rowName(row)
+}
+
+// columnNames formats 0-based columns as a human readable list of column letters
+func columnNames(cols []int) string {
+	names := make([]string, len(cols))
+	for i, col := range cols {
+		names[i] = columnName(col)
+	}
+	return strings.Join(names, ", ")
+}
+
+// moveNotation writes a game as the columns played in order, e.g. "D D C E"
+func moveNotation(moves []Move) string {
+	names := make([]string, len(moves))
+	for i, move := range moves {
+		names[i] = columnName(move.Column)
+	}
+	return strings.Join(names, " ")
+}
+
+// labelMargin returns the space to keep free above the board for coordinate labels
+func (g *ConnectFourGame) labelMargin() float64 {
+	if g.showCoordinates {
+		return coordinateMargin
+	}
+	return 0
+}
+
+// drawCoordinates labels the columns along the top of the board and the rows along its left side
+func (g *ConnectFourGame) drawCoordinates(screen *ebiten.Image, offsetX, offsetY, cellSize float64) {
+	face := fontFace
+	for col := 0; col < Columns; col++ {
+		label := columnName(col)
+		bounds := boundString(face, label)
+		x := int(offsetX+float64(col)*cellSize+cellSize/2) - bounds.Dx()/2
+		text.Draw(screen, label, face, x, int(offsetY)-8, colorText)
+	}
+	for row := 0; row < Rows; row++ {
+		label := rowName(row)
+		bounds := boundString(face, label)
+		y := int(offsetY+float64(row)*cellSize+cellSize/2) + bounds.Dy()/2
+		text.Draw(screen, label, face, int(offsetX)-10-bounds.Dx(), y, colorText)
+	}
+}
@@ -0,0 +1,769 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"math"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// Online play client settings
+const (
+	defaultOnlineAddr  = "localhost:9090"
+	onlineDialTimeout  = 10 * time.Second
+	onlineClockWarning = 10 // Seconds left when the move clock turns red
+)
+
+// netEvent is something that happened on the connection to the game server:
+// the connection opening, a message arriving, or the connection ending
+type netEvent struct {
+	conn net.Conn // Set when the connection opens
+	msg  wireMessage
+	err  error // Set when the connection ends
+}
+
+// OnlineGame is the connection to a game server, from connecting through
+// waiting for an opponent to the end of the game. The connection is read on
+// a background goroutine and its events handled in Update.
+type OnlineGame struct {
+	addr      string
+	config    clientConfig
+	conn      net.Conn // Nil until connected
+	codec     *wireCodec
+	side      int           // Side the server gave this client, 0 until the match starts
+	position  int           // Place in the server's queue for an opponent, 0 until told
+	spectator bool          // Watching games rather than playing
+	deadline  time.Time     // When the side to move runs out of time, zero unless the clock runs
+	paused    time.Duration // Time left on a clock standing still while someone is away
+	away      bool          // The opponent has lost the connection for now
+	resume    string        // Token for rejoining the game, once it has started
+	signingIn bool          // Sent a token or password the server hasn't answered yet
+	giveUp    time.Time     // When to stop reconnecting, zero unless reconnecting
+	events    chan netEvent
+	done      chan struct{} // Closed when the connection is given up
+	closeOnce sync.Once
+	writeMu   sync.Mutex // Heartbeats are sent from their own goroutine
+}
+
+// clientConfig is how a client reaches the game server and how long it
+// waits on it
+type clientConfig struct {
+	dial              func(addr string) (net.Conn, error)
+	heartbeatInterval time.Duration // How often to ping the server
+	heartbeatTimeout  time.Duration // Silence after which the server has dropped
+	reconnectRetry    time.Duration // Pause between attempts to reconnect
+	reconnectTimeout  time.Duration // How long to keep trying to rejoin a game
+}
+
+// newClientConfig returns the usual way of reaching a game server, over
+// TCP with the usual timings
+func newClientConfig() clientConfig {
+	return clientConfig{
+		dial: func(addr string) (net.Conn, error) {
+			return net.DialTimeout("tcp", addr, onlineDialTimeout)
+		},
+		heartbeatInterval: heartbeatInterval,
+		heartbeatTimeout:  heartbeatTimeout,
+		reconnectRetry:    reconnectRetry,
+		reconnectTimeout:  reconnectTimeout,
+	}
+}
+
+// connectOnline starts connecting to a game server as config says
+func connectOnline(addr string, config clientConfig) *OnlineGame {
+	o := &OnlineGame{addr: addr, config: config, events: make(chan netEvent), done: make(chan struct{})}
+	go o.connect(time.Time{})
+	return o
+}
+
+// connect dials the server, trying again until retryUntil if it can't get
+// through, then reads the connection until it ends. A server silent for
+// the config's heartbeatTimeout is taken to have gone.
+func (o *OnlineGame) connect(retryUntil time.Time) {
+	conn, err := o.config.dial(o.addr)
+	for err != nil && time.Now().Before(retryUntil) {
+		select {
+		case <-time.After(o.config.reconnectRetry):
+		case <-o.done:
+			return
+		}
+		conn, err = o.config.dial(o.addr)
+	}
+	if err != nil {
+		o.post(netEvent{err: err})
+		return
+	}
+	if !o.post(netEvent{conn: conn}) {
+		conn.Close()
+		return
+	}
+	codec := newWireCodec(conn)
+	for {
+		conn.SetReadDeadline(time.Now().Add(o.config.heartbeatTimeout))
+		msg, err := codec.read()
+		if errors.Is(err, errBadMessage) {
+			log.Printf("from game server: %v", err)
+			continue
+		}
+		if err != nil {
+			o.post(netEvent{err: err})
+			return
+		}
+		if !o.post(netEvent{msg: msg}) {
+			return
+		}
+	}
+}
+
+// post hands an event to Update, or reports false if the connection has
+// been given up meanwhile
+func (o *OnlineGame) post(e netEvent) bool {
+	select {
+	case o.events <- e:
+		return true
+	case <-o.done:
+		return false
+	}
+}
+
+// send writes a message to the server, unless there is no connection
+func (o *OnlineGame) send(msg wireMessage) {
+	o.writeMu.Lock()
+	defer o.writeMu.Unlock()
+	if o.codec == nil {
+		return
+	}
+	if err := o.codec.write(msg); err != nil {
+		log.Printf("sending to game server: %v", err)
+	}
+}
+
+// startClock notes the time the side to move has left, as the server last
+// sent it in milliseconds. The clock stands still while the opponent is
+// away, as the server's does.
+func (o *OnlineGame) startClock(ms int) {
+	left := time.Duration(ms) * time.Millisecond
+	o.deadline, o.paused = time.Time{}, 0
+	switch {
+	case ms <= 0:
+	case o.away:
+		o.paused = left
+	default:
+		o.deadline = time.Now().Add(left)
+	}
+}
+
+// clockLeft returns the time the side to move has left, and false if there
+// is no clock to show
+func (o *OnlineGame) clockLeft() (time.Duration, bool) {
+	if o.paused > 0 {
+		return o.paused, true
+	}
+	if o.deadline.IsZero() {
+		return 0, false
+	}
+	return time.Until(o.deadline), true
+}
+
+// hangUp closes a connection that has failed, ready to open another
+func (o *OnlineGame) hangUp() {
+	o.writeMu.Lock()
+	defer o.writeMu.Unlock()
+	if o.conn != nil {
+		o.conn.Close()
+	}
+	o.conn, o.codec = nil, nil
+}
+
+// close hangs up, leaving the reading goroutine to finish on its own
+func (o *OnlineGame) close() {
+	o.closeOnce.Do(func() {
+		close(o.done)
+		if o.conn != nil {
+			o.conn.Close()
+		}
+	})
+}
+
+// localSide converts a side as the server numbers them into the board's:
+// this client always plays Player and the opponent Computer
+func (o *OnlineGame) localSide(side int) int {
+	if side == o.side {
+		return Player
+	}
+	return Computer
+}
+
+// serverBoard returns board with the sides numbered as the server numbers
+// them, for checking against the server's hashes
+func (o *OnlineGame) serverBoard(board GameBoard) GameBoard {
+	if o.side == Player {
+		return board
+	}
+	for row := range Rows {
+		for col := range Columns {
+			if piece := board.At(row, col); piece != Empty {
+				board.Set(row, col, opponentOf(piece))
+			}
+		}
+	}
+	return board
+}
+
+// seatIsRemote reports whether side is played by someone over the network
+func (g *ConnectFourGame) seatIsRemote(side int) bool {
+	return g.online != nil && side == Computer
+}
+
+// isOnlineState reports whether a screen keeps the connection open
+func isOnlineState(state int) bool {
+	switch state {
+	case StateOnline, StateGame, StateGameOver, StateSpectate:
+		return true
+	}
+	return false
+}
+
+// openOnline shows the online play screen
+func (g *ConnectFourGame) openOnline() {
+	g.closeOnline()
+	g.transitionTo(StateOnline)
+}
+
+// connectToServer connects to the address entered on the online screen
+func (g *ConnectFourGame) connectToServer() {
+	if g.online != nil {
+		return
+	}
+	g.onlineAddr = g.textInputs[0].value
+	if g.onlineAddr == "" {
+		g.onlineAddr = defaultOnlineAddr
+	}
+	g.online = connectOnline(g.onlineAddr, newClientConfig())
+	g.chatLog = nil
+	g.initUI()
+}
+
+// closeOnline hangs up on the game server, if connected
+func (g *ConnectFourGame) closeOnline() {
+	if g.online != nil {
+		g.online.close()
+		g.online = nil
+	}
+}
+
+// updateOnline handles whatever has arrived from the game server, and gives
+// up reconnecting once it has taken too long
+func (g *ConnectFourGame) updateOnline() {
+	g.updateReconnecting()
+	for g.online != nil {
+		select {
+		case e := <-g.online.events:
+			g.handleNetEvent(e)
+		default:
+			return
+		}
+	}
+}
+
+// onlineHello introduces the player to the game server. Asking for rated
+// games signs the player in with the session token the server issued them
+// or, until it has issued one, with the secret kept for their account on
+// that server. The password they logged in with is never sent. Guests, and
+// players with no secret to sign in with, play casual games.
+func (g *ConnectFourGame) onlineHello() HelloMessage {
+	hello := HelloMessage{Name: g.username}
+	if !g.settings.RatedOnline || g.guest {
+		return hello
+	}
+	if token := loadServerTokens(g.storage, g.username)[g.onlineAddr]; token != "" {
+		hello.Rated, hello.Token = true, token
+	} else if secret, err := serverAccountSecret(g.storage, g.username, g.onlineAddr); err == nil {
+		hello.Rated, hello.Password = true, secret
+	} else {
+		log.Printf("no account secret for %s: %v; playing casual games", g.onlineAddr, err)
+	}
+	return hello
+}
+
+// handleNetEvent acts on one event from the game server
+func (g *ConnectFourGame) handleNetEvent(e netEvent) {
+	o := g.online
+	switch {
+	case e.conn != nil:
+		o.writeMu.Lock()
+		o.conn = e.conn
+		o.codec = newWireCodec(e.conn)
+		o.writeMu.Unlock()
+		switch {
+		case g.reconnecting():
+			o.send(ResumeMessage{Token: o.resume})
+		case o.spectator:
+			o.send(SpectateMessage{Name: g.username})
+		default:
+			hello := g.onlineHello()
+			o.signingIn = hello.Token != "" || hello.Password != ""
+			o.send(hello)
+		}
+		go o.heartbeat(newWireCodec(e.conn))
+		g.initUI()
+		return
+	case e.err != nil:
+		g.onlineDisconnected(e.err)
+		return
+	case o.spectator:
+		g.handleSpectatorMessage(e.msg)
+		return
+	}
+
+	o.signingIn = false
+	switch msg := e.msg.(type) {
+	case SignedInMessage:
+		saveServerToken(g.storage, g.username, g.onlineAddr, msg.Token)
+
+	case QueueMessage:
+		o.position = msg.Position
+
+	case MatchFoundMessage:
+		g.startOnlineGame(msg.Side, msg.Opponent)
+		o.startClock(msg.TimeLeft)
+		o.resume = msg.Resume
+
+	case ResumedMessage:
+		g.resumeOnlineGame(msg)
+
+	case AwayMessage:
+		o.away = msg.Away
+		o.startClock(msg.TimeLeft)
+		if g.state == StateGame && g.gameInProgress {
+			if msg.Away {
+				g.showToast(tr("online.opponent_away"))
+			} else {
+				g.showToast(tr("online.opponent_back"))
+			}
+		}
+
+	case MoveMessage:
+		if o.localSide(msg.Side) == Computer {
+			g.playOpponentMove(msg.Column)
+			g.checkSync(msg.Hash)
+			o.startClock(msg.TimeLeft)
+		}
+
+	case MoveAckMessage:
+		g.checkSync(msg.Hash)
+		o.startClock(msg.TimeLeft)
+
+	case MoveRejectMessage:
+		log.Printf("game server rejected a move: %s", msg.Reason)
+		g.revertRejectedMove(msg.Column)
+
+	case SyncMessage:
+		g.syncOnlineGame(msg.Moves)
+		o.startClock(msg.TimeLeft)
+
+	case GameOverMessage:
+		// Ordinary endings are seen on the board as the last move lands
+		switch msg.Reason {
+		case gameOverGone:
+			g.leaveOnlineGame(tr("online.reconnect_failed"))
+		case gameOverLeft:
+			if g.state == StateGame && g.gameInProgress {
+				g.leaveOnlineGame(tr("online.opponent_left"))
+			} else {
+				g.closeOnline()
+				g.showToast(tr("online.opponent_left"))
+			}
+		case gameOverResign, gameOverDraw:
+			if g.state == StateGame && g.gameInProgress {
+				winner := Empty
+				if msg.Winner != Empty {
+					winner = o.localSide(msg.Winner)
+				}
+				if winner == Player {
+					g.showToast(tr("online.opponent_resigned"))
+				}
+				g.endGame(winner)
+			}
+		case gameOverTime:
+			if g.state == StateGame && g.gameInProgress {
+				winner := o.localSide(msg.Winner)
+				if winner == Player {
+					g.showToast(tr("online.opponent_out_of_time"))
+				} else {
+					g.showToast(tr("online.out_of_time"))
+				}
+				g.endGame(winner)
+			}
+		}
+
+	case RatingMessage:
+		// The first rating arrives on connecting, later ones after each game
+		if g.onlineRating > 0 && msg.Rating != g.onlineRating {
+			g.showToast(tr("online.rating_change", msg.Rating, msg.Rating-g.onlineRating))
+		}
+		g.onlineRating = msg.Rating
+
+	case DrawOfferMessage:
+		g.showToast(tr("online.draw_offered"))
+
+	case ChatMessage:
+		g.receiveChat(msg.Text)
+
+	case RematchMessage:
+		g.showToast(tr("online.rematch_offered"))
+
+	case PingMessage:
+		o.send(PongMessage{Nonce: msg.Nonce})
+	}
+}
+
+// onlineDisconnected handles the connection ending, which only matters to
+// the player while connecting, waiting or playing. A game in progress is
+// picked up again if the connection can be got back in time.
+func (g *ConnectFourGame) onlineDisconnected(err error) {
+	switch {
+	case g.reconnecting() && time.Now().Before(g.online.giveUp):
+		log.Printf("reconnecting to %s: %v", g.online.addr, err)
+		g.online.hangUp()
+		go g.online.connect(g.online.giveUp)
+	case g.reconnecting():
+		log.Printf("reconnecting to %s: %v", g.online.addr, err)
+		g.leaveOnlineGame(tr("online.reconnect_failed"))
+	case g.online.conn == nil:
+		log.Printf("connecting to %s: %v", g.online.addr, err)
+		g.closeOnline()
+		g.showToast(tr("online.failed", g.onlineAddr))
+		g.initUI()
+	case g.online.signingIn:
+		// Hanging up without a word means the server refused to sign the
+		// player in, so a token it issued is no good any more
+		log.Printf("signing in to %s: %v", g.online.addr, err)
+		saveServerToken(g.storage, g.username, g.onlineAddr, "")
+		g.closeOnline()
+		g.showToast(tr("online.sign_in_failed"))
+		g.initUI()
+	case g.state == StateSpectate:
+		g.spectated = nil
+		g.openOnline()
+		g.showToast(tr("online.disconnected"))
+	case g.state == StateOnline:
+		g.closeOnline()
+		g.showToast(tr("online.disconnected"))
+		g.initUI()
+	case g.state == StateGame && g.gameInProgress && g.online.resume != "":
+		g.startReconnecting(err)
+	case g.state == StateGame && g.gameInProgress:
+		g.leaveOnlineGame(tr("online.disconnected"))
+	default:
+		g.closeOnline()
+	}
+}
+
+// leaveOnlineGame hangs up and returns to the menu with a message
+func (g *ConnectFourGame) leaveOnlineGame(message string) {
+	g.closeOnline()
+	g.gameInProgress = false
+	g.transitionTo(StateGameMode)
+	g.showToast(message)
+}
+
+// startOnlineGame begins the game once the server has found an opponent
+func (g *ConnectFourGame) startOnlineGame(side int, opponent string) {
+	online := g.online
+	g.online = nil // Keeps the connection open through initializeGame
+	g.startNewGame()
+	setVariant(VariantStandard) // The server plays by the standard rules
+	g.online = online
+	g.playedOnline = true
+	online.side = side
+	if opponent == "" {
+		opponent = tr("online.opponent")
+	}
+	g.sideNames = [2]string{g.username, opponent}
+	if side != Player {
+		firstPlayer = Computer
+		g.turn = Computer
+	}
+	g.initUI()
+}
+
+// playOpponentMove plays a move that arrived from the other client
+func (g *ConnectFourGame) playOpponentMove(col int) {
+	if g.state != StateGame || !g.gameInProgress || g.turn != Computer || g.board.columnFull(col) {
+		return
+	}
+	g.setBoard(dropPiece(g.board, col, Computer))
+	g.animateDrop(col)
+	g.moves = append(g.moves, Move{Column: col, Player: Computer})
+	if winner, over := gameOver(g.board); over {
+		g.endGame(winner)
+	} else {
+		g.turn = Player
+	}
+}
+
+// checkSync compares the board with the server's hash of its own after the
+// latest move, and asks for the server's moves if they differ. Servers that
+// send no hash aren't checked.
+func (g *ConnectFourGame) checkSync(hash uint64) {
+	if hash == 0 || g.state != StateGame || !g.gameInProgress {
+		return
+	}
+	if HashBoard(g.online.serverBoard(g.board)) != hash {
+		log.Printf("board out of step with the game server, resyncing")
+		g.online.send(SyncMessage{})
+	}
+}
+
+// revertRejectedMove takes back the player's move in col, which was shown
+// before the server had accepted it. Anything else rejected means the
+// boards disagree, so the server's moves are asked for.
+func (g *ConnectFourGame) revertRejectedMove(col int) {
+	if g.state != StateGame || !g.gameInProgress {
+		return
+	}
+	last := len(g.moves) - 1
+	if last < 0 || g.moves[last] != (Move{Column: col, Player: Player}) {
+		g.online.send(SyncMessage{})
+		return
+	}
+	g.moves = g.moves[:last]
+	var board GameBoard
+	for _, move := range g.moves {
+		board = dropPiece(board, move.Column, move.Player)
+	}
+	g.setBoard(board)
+	g.dropAnim = nil
+	g.turn = Player
+	g.showToast(tr("online.move_rejected"))
+}
+
+// syncOnlineGame replaces the game with the server's copy, given as the
+// columns played from the start. Moves that don't fit the board or come
+// after the game was won, or a position that fails ValidateBoard, mean the
+// two can't be reconciled, so the game is given up.
+func (g *ConnectFourGame) syncOnlineGame(cols []int) {
+	if g.state != StateGame || !g.gameInProgress {
+		return
+	}
+	var board GameBoard
+	moves := []Move{}
+	side := Player
+	for _, col := range cols {
+		if board.columnFull(col) || validatePosition(board) != nil {
+			log.Printf("game server sent an impossible move in column %d", col)
+			g.leaveOnlineGame(tr("online.rejected"))
+			return
+		}
+		mover := g.online.localSide(side)
+		board = dropPiece(board, col, mover)
+		moves = append(moves, Move{Column: col, Player: mover})
+		side = opponentOf(side)
+	}
+	if err := ValidateBoard(board); err != nil {
+		log.Printf("game server sent an impossible position: %v", err)
+		g.leaveOnlineGame(tr("online.rejected"))
+		return
+	}
+	g.setBoard(board)
+	g.moves = moves
+	g.turn = g.online.localSide(side)
+	if winner, over := gameOver(g.board); over {
+		g.endGame(winner)
+	}
+}
+
+// drawOnlineClock shows the seconds the side to move has left, beside the
+// status text ending at statusLeft, in the error color for the last few
+func (g *ConnectFourGame) drawOnlineClock(screen *ebiten.Image, statusLeft, statusY int) {
+	if g.online == nil || g.state != StateGame || !g.gameInProgress {
+		return
+	}
+	remaining, ok := g.online.clockLeft()
+	if !ok {
+		return
+	}
+	left := max(0, int(math.Ceil(remaining.Seconds())))
+	clock := tr("online.clock", left)
+	clr := colorText
+	if left <= onlineClockWarning {
+		clr = colorError
+	}
+	clockBounds := boundString(fontFace, clock)
+	text.Draw(screen, clock, fontFace, statusLeft-int(15*g.scaleX)-clockBounds.Dx(), statusY, clr)
+}
+
+// resignOnline gives up the online game in progress. The server ends it.
+func (g *ConnectFourGame) resignOnline() {
+	g.online.send(ResignMessage{})
+}
+
+// offerDraw offers the opponent a draw, or accepts the one they offered
+func (g *ConnectFourGame) offerDraw() {
+	g.online.send(DrawOfferMessage{})
+	g.showToast(tr("online.draw_sent"))
+}
+
+// initOnlineGameUI adds the buttons to resign or offer a draw to an online
+// game in progress, left of the board below the logout button
+func (g *ConnectFourGame) initOnlineGameUI() {
+	if g.online == nil || !g.gameInProgress {
+		return
+	}
+	g.buttons = append(g.buttons, &Button{
+		x:      20 * g.scaleX,
+		y:      60 * g.scaleY,
+		w:      100 * g.scaleX,
+		h:      30 * g.scaleY,
+		text:   tr("online.resign"),
+		action: g.resignOnline,
+	})
+	g.buttons = append(g.buttons, &Button{
+		x:      130 * g.scaleX,
+		y:      60 * g.scaleY,
+		w:      100 * g.scaleX,
+		h:      30 * g.scaleY,
+		text:   tr("online.offer_draw"),
+		action: g.offerDraw,
+	})
+}
+
+// offerRematch asks the opponent for another game, or looks for a new
+// opponent if the connection has gone
+func (g *ConnectFourGame) offerRematch() {
+	if g.online == nil {
+		g.openOnline()
+		return
+	}
+	g.online.send(RematchMessage{})
+	g.showToast(tr("online.rematch_sent"))
+}
+
+// initOnlineUI lays out the online play screen: the server address, the
+// choice of rated or casual games and buttons to connect to play or to
+// watch or to see the leaderboard, or to give up while connecting or waiting
+func (g *ConnectFourGame) initOnlineUI() {
+	// Back button
+	g.buttons = append(g.buttons, &Button{
+		x:    float64(g.screenWidth) - 120*g.scaleX,
+		y:    20 * g.scaleY,
+		w:    100 * g.scaleX,
+		h:    30 * g.scaleY,
+		text: tr("common.back"),
+		action: func() {
+			g.closeOnline()
+			g.transitionTo(StateGameMode)
+		},
+	})
+
+	if g.online != nil {
+		g.buttons = append(g.buttons, &Button{
+			x:    float64(g.screenWidth)/2 - 60*g.scaleX,
+			y:    260 * g.scaleY,
+			w:    120 * g.scaleX,
+			h:    40 * g.scaleY,
+			text: tr("online.cancel"),
+			action: func() {
+				g.closeOnline()
+				g.initUI()
+			},
+		})
+		return
+	}
+
+	addr := g.onlineAddr
+	if addr == "" {
+		addr = defaultOnlineAddr
+	}
+	g.textInputs = append(g.textInputs, &TextInput{
+		x:           float64(g.screenWidth)/2 - 100*g.scaleX,
+		y:           180 * g.scaleY,
+		w:           200 * g.scaleX,
+		h:           30 * g.scaleY,
+		label:       tr("online.address"),
+		placeholder: defaultOnlineAddr,
+		value:       addr,
+		cursor:      len([]rune(addr)),
+	})
+	g.toggles = append(g.toggles, &Toggle{
+		x:     float64(g.screenWidth)/2 - 100*g.scaleX,
+		y:     218 * g.scaleY,
+		w:     200 * g.scaleX,
+		h:     20 * g.scaleY,
+		label: tr("online.rated"),
+		on:    g.settings.RatedOnline,
+		onChange: func(on bool) {
+			g.settings.RatedOnline = on
+			g.flushSettings()
+		},
+	})
+	g.buttons = append(g.buttons, &Button{
+		x:      float64(g.screenWidth)/2 - 60*g.scaleX,
+		y:      250 * g.scaleY,
+		w:      120 * g.scaleX,
+		h:      40 * g.scaleY,
+		text:   tr("online.connect"),
+		action: g.connectToServer,
+	})
+	g.buttons = append(g.buttons, &Button{
+		x:      float64(g.screenWidth)/2 - 60*g.scaleX,
+		y:      300 * g.scaleY,
+		w:      120 * g.scaleX,
+		h:      40 * g.scaleY,
+		text:   tr("online.watch"),
+		action: g.spectateServer,
+	})
+	g.buttons = append(g.buttons, &Button{
+		x:      float64(g.screenWidth)/2 - 60*g.scaleX,
+		y:      350 * g.scaleY,
+		w:      120 * g.scaleX,
+		h:      40 * g.scaleY,
+		text:   tr("online.leaderboard"),
+		action: g.openOnlineLeaderboard,
+	})
+}
+
+// drawOnlineScreen renders the online play screen
+func (g *ConnectFourGame) drawOnlineScreen(screen *ebiten.Image) {
+	title := tr("online.title")
+	titleBounds := boundString(fontFace, title)
+	text.Draw(screen, title, fontFace,
+		g.screenWidth/2-titleBounds.Dx()/2, int(110*g.scaleY), colorText)
+
+	if g.onlineRating > 0 {
+		rating := tr("online.your_rating", g.onlineRating)
+		ratingBounds := boundString(fontFace, rating)
+		text.Draw(screen, rating, fontFace,
+			g.screenWidth/2-ratingBounds.Dx()/2, int(140*g.scaleY), colorText)
+	}
+
+	if g.online != nil {
+		status := tr("online.connecting", g.online.addr)
+		if g.online.position > 0 {
+			status = tr("online.queue_position", g.online.position)
+		} else if g.online.spectator && g.online.conn != nil {
+			status = tr("online.waiting_game")
+		} else if g.online.conn != nil {
+			status = tr("online.waiting")
+		}
+		statusBounds := boundString(fontFace, status)
+		text.Draw(screen, status, fontFace,
+			g.screenWidth/2-statusBounds.Dx()/2, int(220*g.scaleY), colorText)
+	}
+
+	for _, input := range g.textInputs {
+		g.drawTextInput(screen, input)
+	}
+	for _, toggle := range g.toggles {
+		g.drawToggle(screen, toggle)
+	}
+	for _, btn := range g.buttons {
+		g.drawButton(screen, btn)
+	}
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// onlineTestGame starts an online game on side, as the server numbers
+// them, with what the client sends kept in the buffer returned
+func onlineTestGame(t *testing.T, side int) (*ConnectFourGame, *bytes.Buffer) {
+	t.Helper()
+	t.Cleanup(func() { firstPlayer = Player })
+	var sent bytes.Buffer
+	g := newConnectFourGame(newMemoryStorage())
+	g.frame = 1
+	g.online = &OnlineGame{codec: newWireCodec(&sent), done: make(chan struct{})}
+	g.handleNetEvent(netEvent{msg: MatchFoundMessage{Side: side, Opponent: "bob"}})
+	if g.state != StateGame || !g.gameInProgress {
+		t.Fatalf("match found left the game in state %d", g.state)
+	}
+	return g, &sent
+}
+
+// sentMessages returns the messages the client has sent since last asked
+func sentMessages(t *testing.T, sent *bytes.Buffer) []wireMessage {
+	t.Helper()
+	var msgs []wireMessage
+	codec := newWireCodec(sent)
+	for {
+		msg, err := codec.read()
+		if err == io.EOF {
+			return msgs
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		msgs = append(msgs, msg)
+	}
+}
+
+// serverHash is the hash the server sends after cols are played
+func serverHash(cols ...int) uint64 {
+	var board GameBoard
+	for i, col := range cols {
+		board = dropPiece(board, col, Player+i%2)
+	}
+	return HashBoard(board)
+}
+
+func TestOnlineRejectedMoveIsTakenBack(t *testing.T) {
+	g, sent := onlineTestGame(t, Player)
+	g.dropInColumn(3)
+	g.dropAnim = nil
+	if got := sentMessages(t, sent); !reflect.DeepEqual(got, []wireMessage{MoveMessage{Column: 3}}) {
+		t.Fatalf("dropping sent %v", got)
+	}
+
+	g.handleNetEvent(netEvent{msg: MoveRejectMessage{Column: 3, Code: rejectNotYourTurn}})
+	if len(g.moves) != 0 || g.board != (GameBoard{}) || g.turn != Player {
+		t.Errorf("after the rejection: moves %v, turn %d", g.moves, g.turn)
+	}
+	if got := sentMessages(t, sent); len(got) != 0 {
+		t.Errorf("taking back the rejected move sent %v", got)
+	}
+}
+
+func TestOnlineUnexpectedRejectionResyncs(t *testing.T) {
+	for _, reject := range []MoveRejectMessage{
+		{Column: 5, Code: rejectColumnFull},     // Not the move last played
+		{Column: Columns, Code: rejectOffBoard}, // Not a move this client could send
+		{Column: 3, Code: rejectNotYourTurn},    // The opponent's move, not ours
+	} {
+		g, sent := onlineTestGame(t, Computer)
+		g.handleNetEvent(netEvent{msg: MoveMessage{Column: 3, Side: Player, Hash: serverHash(3)}})
+		g.dropAnim = nil
+		g.handleNetEvent(netEvent{msg: reject})
+		if got := sentMessages(t, sent); !reflect.DeepEqual(got, []wireMessage{SyncMessage{}}) {
+			t.Errorf("%s rejecting column %d sent %v, want a sync request", reject.Code, reject.Column, got)
+		}
+		if len(g.moves) != 1 {
+			t.Errorf("%s rejecting column %d changed the moves to %v", reject.Code, reject.Column, g.moves)
+		}
+	}
+}
+
+func TestOnlineRejectionAfterTheGame(t *testing.T) {
+	g, sent := onlineTestGame(t, Player)
+	g.dropInColumn(3)
+	g.dropAnim = nil
+	sentMessages(t, sent)
+	g.handleNetEvent(netEvent{msg: GameOverMessage{Winner: Computer, Reason: gameOverResign}})
+	if g.gameInProgress {
+		t.Fatal("resigning didn't end the game")
+	}
+
+	g.handleNetEvent(netEvent{msg: MoveRejectMessage{Column: 3, Code: rejectGameOver}})
+	if len(g.moves) != 1 {
+		t.Errorf("a rejection after the game took back %v", g.moves)
+	}
+	if got := sentMessages(t, sent); len(got) != 0 {
+		t.Errorf("a rejection after the game sent %v", got)
+	}
+}
+
+func TestOnlineDesyncResyncs(t *testing.T) {
+	// Playing second, so the client's sides are the server's swapped
+	g, sent := onlineTestGame(t, Computer)
+	g.handleNetEvent(netEvent{msg: MoveMessage{Column: 3, Side: Player, Hash: serverHash(3)}})
+	g.dropAnim = nil
+	if got := sentMessages(t, sent); len(got) != 0 {
+		t.Fatalf("a board in step sent %v", got)
+	}
+
+	// A piece the server never saw puts the boards out of step
+	g.dropInColumn(0)
+	g.dropAnim = nil
+	board := g.board
+	board.Set(Rows-1, 6, Player)
+	g.setBoard(board)
+	g.handleNetEvent(netEvent{msg: MoveAckMessage{Column: 0, Hash: serverHash(3, 0)}})
+	if got := sentMessages(t, sent); !reflect.DeepEqual(got, []wireMessage{MoveMessage{Column: 0}, SyncMessage{}}) {
+		t.Fatalf("after the desync the client sent %v, want its move and a sync request", got)
+	}
+
+	g.handleNetEvent(netEvent{msg: SyncMessage{Moves: []int{3, 0}}})
+	if HashBoard(g.online.serverBoard(g.board)) != serverHash(3, 0) {
+		t.Errorf("resynced board\n%v\ndoesn't match the server's", g.board)
+	}
+	want := []Move{{Column: 3, Player: Computer}, {Column: 0, Player: Player}}
+	if !reflect.DeepEqual(g.moves, want) || g.turn != Computer {
+		t.Errorf("resynced to moves %v with %d to move, want %v with the opponent to move", g.moves, g.turn, want)
+	}
+
+	// Back in step, the next move needs no resync
+	g.handleNetEvent(netEvent{msg: MoveMessage{Column: 3, Side: Player, Hash: serverHash(3, 0, 3)}})
+	if got := sentMessages(t, sent); len(got) != 0 {
+		t.Errorf("a board back in step sent %v", got)
+	}
+}
+
+func TestOnlineUnreconcilableSyncLeaves(t *testing.T) {
+	g, _ := onlineTestGame(t, Player)
+	cols := make([]int, Rows+1) // One more than column 0 holds
+	g.handleNetEvent(netEvent{msg: SyncMessage{Moves: cols}})
+	if g.online != nil || g.state != StateGameMode {
+		t.Errorf("an impossible sync left the game in state %d", g.state)
+	}
+}
+
+func TestOnlineHelloSignsIn(t *testing.T) {
+	g := newConnectFourGame(newMemoryStorage())
+	g.username, g.password, g.onlineAddr = "alice", "hunter22", "pipe"
+
+	// The first time, the account is signed in to with a secret of its own,
+	// never the local password
+	first := g.onlineHello()
+	if !first.Rated || first.Token != "" || first.Password == "" || first.Password == g.password {
+		t.Errorf("signing in for the first time sent %+v, want the account's secret", first)
+	}
+	if again := g.onlineHello(); again != first {
+		t.Errorf("signing in again sent %+v, want the same secret as %+v", again, first)
+	}
+	saveServerToken(g.storage, "alice", "pipe", "1711972800.5f0c")
+	if hello := g.onlineHello(); hello != (HelloMessage{Name: "alice", Rated: true, Token: "1711972800.5f0c"}) {
+		t.Errorf("signing in with an issued token sent %+v", hello)
+	}
+	g.onlineAddr = "elsewhere:9090"
+	if hello := g.onlineHello(); hello.Token != "" || hello.Password == "" || hello.Password == first.Password {
+		t.Errorf("another server was sent %+v, want a secret of its own", hello)
+	}
+	g.settings.RatedOnline = false
+	if hello := g.onlineHello(); hello != (HelloMessage{Name: "alice"}) {
+		t.Errorf("asking for casual games sent %+v", hello)
+	}
+	g.settings.RatedOnline, g.guest = true, true
+	if hello := g.onlineHello(); hello != (HelloMessage{Name: "alice"}) {
+		t.Errorf("a guest sent %+v, want no token", hello)
+	}
+}
+
+func TestOnlineSyncRejectsImpossibleGames(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		moves []int
+	}{
+		{"a column overfilled", []int{0, 0, 0, 0, 0, 0, 0}},
+		{"moves after a four", []int{0, 1, 0, 1, 0, 1, 0, 1}},
+	} {
+		g, _ := onlineTestGame(t, Player)
+		g.handleNetEvent(netEvent{msg: SyncMessage{Moves: tc.moves}})
+		if g.online != nil || g.state != StateGameMode || g.toastMessage != tr("online.rejected") {
+			t.Errorf("%s: synced to state %d with toast %q, want the game given up", tc.name, g.state, g.toastMessage)
+		}
+	}
+
+	// A game ending in a four is fine
+	g, _ := onlineTestGame(t, Player)
+	g.handleNetEvent(netEvent{msg: SyncMessage{Moves: []int{0, 1, 0, 1, 0, 1, 0}}})
+	if g.online == nil || len(g.moves) != 7 || g.gameInProgress {
+		t.Errorf("a won game synced to %v, in progress %v", g.moves, g.gameInProgress)
+	}
+}
+
+func TestOnlineSignInWithSeparateStores(t *testing.T) {
+	// The server's accounts and key are its own, not the client's
+	logins, err := loadServerLogins(newMemoryStorage())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln := serveTest(t, logins, loadRatingBook(newMemoryStorage()), newServerConfig(0))
+
+	g := newConnectFourGame(newMemoryStorage())
+	g.username, g.password, g.onlineAddr = "alice", "hunter22", "pipe"
+	g.online = &OnlineGame{codec: newWireCodec(&bytes.Buffer{}), done: make(chan struct{})}
+
+	// A token the client signed itself is refused
+	secret, err := sessionSecret(g.storage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged := HelloMessage{Name: "alice", Rated: true, Token: issueSessionToken(secret, "alice", time.Now())}
+	if msg := connect(t, ln, forged).next(); msg != nil {
+		t.Errorf("a client-signed token was answered with %#v", msg)
+	}
+
+	// The first time the account's secret signs in, and the server issues
+	// a token
+	hello := g.onlineHello()
+	if hello.Password == "" || hello.Password == g.password || hello.Token != "" {
+		t.Fatalf("first hello %+v, want the account's secret", hello)
+	}
+	c := connect(t, ln, hello)
+	g.handleNetEvent(netEvent{msg: expect[SignedInMessage](c)})
+	expect[RatingMessage](c)
+
+	// From then on the issued token does
+	hello = g.onlineHello()
+	if hello.Token == "" || hello.Password != "" {
+		t.Fatalf("second hello %+v, want the issued token", hello)
+	}
+	expect[RatingMessage](connect(t, ln, hello))
+
+	// Someone else can't take the name with another password
+	if msg := connect(t, ln, HelloMessage{Name: "alice", Rated: true, Password: "guess"}).next(); msg != nil {
+		t.Errorf("a wrong password was answered with %#v", msg)
+	}
+}
+
+func TestOnlineRefusedTokenIsForgotten(t *testing.T) {
+	g := newConnectFourGame(newMemoryStorage())
+	g.username, g.onlineAddr = "alice", "pipe"
+	saveServerToken(g.storage, "alice", "pipe", "1711972800.5f0c")
+	g.state = StateOnline
+
+	conn, server := net.Pipe()
+	defer server.Close()
+	g.online = &OnlineGame{addr: "pipe", config: newClientConfig(), events: make(chan netEvent), done: make(chan struct{})}
+	go io.Copy(io.Discard, server)
+	g.handleNetEvent(netEvent{conn: conn})
+	if !g.online.signingIn {
+		t.Fatal("sending the token didn't wait for the server's answer")
+	}
+	g.handleNetEvent(netEvent{err: io.EOF})
+	if g.online != nil || g.toastMessage != tr("online.sign_in_failed") {
+		t.Errorf("hanging up on the token left online %v, toast %q", g.online, g.toastMessage)
+	}
+	if tokens := loadServerTokens(g.storage, "alice"); len(tokens) != 0 {
+		t.Errorf("refused token kept: %v", tokens)
+	}
+}
+
+func TestOnlineGamesHaveNoEngineAids(t *testing.T) {
+	g, _ := onlineTestGame(t, Player)
+	g.settings.AssistMode, g.settings.EvalBar = true, true
+	pressKeys(t, ebiten.KeyH)
+
+	// The opponent threatens four along the bottom, so anywhere but 3 loses
+	var board GameBoard
+	for col := range 3 {
+		board.Set(Rows-1, col, Computer)
+		board.Set(Rows-2, col, Player)
+	}
+	g.setBoard(board)
+	if !isBlunder(board, 6, currentRules()) {
+		t.Fatal("test position has no blunder to warn about")
+	}
+
+	if g.assistActive() {
+		t.Error("assist mode is helping in an online game")
+	}
+	if g.warnBlunder(6) || g.dialog != nil {
+		t.Error("warned about a blunder in an online game")
+	}
+	g.evalBarGame = -1
+	g.updateEvalBar()
+	if g.evalBarShown() || g.evalBarGame != -1 {
+		t.Error("the evaluation bar ran in an online game")
+	}
+	g.updateGameKeys()
+	if !g.settings.EvalBar {
+		t.Error("the hint key toggled the evaluation bar in an online game")
+	}
+	g.dropInColumn(6)
+	if g.moveQualityTimer != 0 {
+		t.Error("rated the player's move in an online game")
+	}
+
+	// The same game against the computer gets all of them
+	g.online = nil
+	g.setBoard(board)
+	g.turn, g.dropAnim = Player, nil
+	if !g.assistActive() || !g.evalBarShown() {
+		t.Error("engine aids are off against the computer")
+	}
+}
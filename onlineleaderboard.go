@@ -0,0 +1,268 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// onlineLeaderboardKey is where the last leaderboard fetched is kept, so the
+// screen has something to show when the server can't be reached
+const onlineLeaderboardKey = "online_leaderboard.json"
+
+// leaderboardTimeout is how long the server has to answer once connected
+const leaderboardTimeout = 10 * time.Second
+
+// OnlineLeaderboard is a leaderboard fetched from a game server
+type OnlineLeaderboard struct {
+	Addr    string             `json:"addr"`
+	Fetched time.Time          `json:"fetched"`
+	Board   LeaderboardMessage `json:"board"`
+}
+
+// leaderboardFetch is a game server's leaderboard, or why it couldn't be had
+type leaderboardFetch struct {
+	board LeaderboardMessage
+	err   error
+}
+
+// fetchOnlineLeaderboard asks the game server at addr for its leaderboard,
+// with name's standing, in the background. The answer arrives on the
+// channel returned.
+func fetchOnlineLeaderboard(addr, name string) <-chan leaderboardFetch {
+	result := make(chan leaderboardFetch, 1)
+	go func() {
+		board, err := requestLeaderboard(addr, name)
+		result <- leaderboardFetch{board: board, err: err}
+	}()
+	return result
+}
+
+// requestLeaderboard connects to the game server at addr and asks for its
+// leaderboard
+func requestLeaderboard(addr, name string) (LeaderboardMessage, error) {
+	conn, err := net.DialTimeout("tcp", addr, onlineDialTimeout)
+	if err != nil {
+		return LeaderboardMessage{}, err
+	}
+	defer conn.Close()
+	return askLeaderboard(conn, name)
+}
+
+// askLeaderboard sends a StandingsMessage on a connection to a game server
+// and waits for the LeaderboardMessage answering it, skipping anything else
+func askLeaderboard(conn net.Conn, name string) (LeaderboardMessage, error) {
+	conn.SetDeadline(time.Now().Add(leaderboardTimeout))
+
+	codec := newWireCodec(conn)
+	if err := codec.write(StandingsMessage{Name: name}); err != nil {
+		return LeaderboardMessage{}, err
+	}
+	for {
+		msg, err := codec.read()
+		if errors.Is(err, errBadMessage) {
+			log.Printf("from game server: %v", err)
+			continue
+		}
+		if err != nil {
+			return LeaderboardMessage{}, err
+		}
+		if board, ok := msg.(LeaderboardMessage); ok {
+			return board, nil
+		}
+	}
+}
+
+// loadOnlineLeaderboard reads the leaderboard last fetched from addr, or
+// returns nil if there is none
+func loadOnlineLeaderboard(store Storage, addr string) *OnlineLeaderboard {
+	data, err := store.Load(onlineLeaderboardKey)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("loading online leaderboard: %v", err)
+		}
+		return nil
+	}
+	var cached OnlineLeaderboard
+	if err := json.Unmarshal(data, &cached); err != nil {
+		log.Printf("loading online leaderboard: %v", err)
+		return nil
+	}
+	if cached.Addr != addr {
+		return nil
+	}
+	return &cached
+}
+
+// saveOnlineLeaderboard keeps a fetched leaderboard for when the server
+// can't be reached, replacing any other server's
+func saveOnlineLeaderboard(store Storage, board *OnlineLeaderboard) error {
+	data, err := json.MarshalIndent(board, "", "  ")
+	if err != nil {
+		return err
+	}
+	return store.Save(onlineLeaderboardKey, data)
+}
+
+// openOnlineLeaderboard shows the leaderboard of the server whose address
+// is entered on the online screen, as last fetched, and fetches it afresh
+func (g *ConnectFourGame) openOnlineLeaderboard() {
+	g.onlineAddr = g.textInputs[0].value
+	if g.onlineAddr == "" {
+		g.onlineAddr = defaultOnlineAddr
+	}
+	g.onlineBoard = loadOnlineLeaderboard(g.storage, g.onlineAddr)
+	g.onlineBoardFetch = nil
+	g.refreshOnlineLeaderboard()
+	g.transitionTo(StateOnlineLeaderboard)
+}
+
+// refreshOnlineLeaderboard fetches the leaderboard again, unless a fetch is
+// already under way
+func (g *ConnectFourGame) refreshOnlineLeaderboard() {
+	if g.onlineBoardFetch != nil {
+		return
+	}
+	g.onlineBoardErr = nil
+	g.onlineBoardFetch = fetchOnlineLeaderboard(g.onlineAddr, g.username)
+}
+
+// updateOnlineLeaderboard takes in a fetched leaderboard, keeping it for
+// later, or notes why the fetch failed
+func (g *ConnectFourGame) updateOnlineLeaderboard() {
+	if g.onlineBoardFetch == nil {
+		return
+	}
+	var result leaderboardFetch
+	select {
+	case result = <-g.onlineBoardFetch:
+	default:
+		return
+	}
+	g.onlineBoardFetch = nil
+
+	g.onlineBoardErr = result.err
+	if result.err != nil {
+		log.Printf("fetching online leaderboard: %v", result.err)
+	} else {
+		g.onlineBoard = &OnlineLeaderboard{Addr: g.onlineAddr, Fetched: time.Now(), Board: result.board}
+		if err := saveOnlineLeaderboard(g.storage, g.onlineBoard); err != nil {
+			log.Printf("saving online leaderboard: %v", err)
+		}
+	}
+	if g.state == StateOnlineLeaderboard {
+		g.initUI()
+	}
+}
+
+// initOnlineLeaderboardUI adds the back button and the refresh button,
+// disabled while a fetch is under way
+func (g *ConnectFourGame) initOnlineLeaderboardUI() {
+	g.buttons = append(g.buttons, &Button{
+		x:      float64(g.screenWidth) - 120*g.scaleX,
+		y:      20 * g.scaleY,
+		w:      100 * g.scaleX,
+		h:      30 * g.scaleY,
+		text:   tr("common.back"),
+		action: g.openOnline,
+	})
+	g.buttons = append(g.buttons, &Button{
+		x:    20 * g.scaleX,
+		y:    20 * g.scaleY,
+		w:    100 * g.scaleX,
+		h:    30 * g.scaleY,
+		text: tr("online_board.refresh"),
+		action: func() {
+			g.refreshOnlineLeaderboard()
+			g.initUI()
+		},
+		disabled: g.onlineBoardFetch != nil,
+	})
+}
+
+// onlineBoardStatus describes where the leaderboard shown came from
+func (g *ConnectFourGame) onlineBoardStatus() string {
+	switch {
+	case g.onlineBoardFetch != nil:
+		return tr("online_board.loading")
+	case g.onlineBoardErr != nil && g.onlineBoard == nil:
+		return tr("online_board.unreachable", g.onlineAddr)
+	case g.onlineBoardErr != nil:
+		return tr("online_board.cached", g.onlineBoard.Fetched.Local().Format("2006-01-02 15:04"))
+	case g.onlineBoard != nil && len(g.onlineBoard.Board.Top) == 0:
+		return tr("online_board.empty")
+	}
+	return ""
+}
+
+// winRate formats the share of a standing's games that were won
+func winRate(standing OnlineStanding) string {
+	if standing.Games == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d%%", standing.Wins*100/standing.Games)
+}
+
+// drawOnlineLeaderboardScreen renders the server's top players and the
+// player's own standing, with the fetch's progress or failure
+func (g *ConnectFourGame) drawOnlineLeaderboardScreen(screen *ebiten.Image) {
+	title := tr("online_board.title", g.onlineAddr)
+	titleBounds := boundString(fontFace, title)
+	text.Draw(screen, title, fontFace,
+		g.screenWidth/2-titleBounds.Dx()/2, int(80*g.scaleY), colorText)
+
+	if status := g.onlineBoardStatus(); status != "" {
+		statusColor := colorText
+		if g.onlineBoardErr != nil {
+			statusColor = colorError
+		}
+		statusBounds := boundString(fontFace, status)
+		text.Draw(screen, status, fontFace,
+			g.screenWidth/2-statusBounds.Dx()/2, int(105*g.scaleY), statusColor)
+	}
+
+	if g.onlineBoard != nil && len(g.onlineBoard.Board.Top) > 0 {
+		board := g.onlineBoard.Board
+		columnX := []int{
+			g.screenWidth/2 - int(240*g.scaleX),
+			g.screenWidth/2 - int(170*g.scaleX),
+			g.screenWidth/2 + int(20*g.scaleX),
+			g.screenWidth/2 + int(100*g.scaleX),
+			g.screenWidth/2 + int(170*g.scaleX),
+		}
+		rows := [][]string{{
+			tr("leaderboard.rank"), tr("leaderboard.name"), tr("leaderboard.rating"),
+			tr("leaderboard.games"), tr("online_board.win_rate"),
+		}}
+		for _, standing := range board.Top {
+			rows = append(rows, []string{
+				fmt.Sprint(standing.Rank), standing.Name, fmt.Sprint(standing.Rating),
+				fmt.Sprint(standing.Games), winRate(standing),
+			})
+		}
+		for i, row := range rows {
+			y := int((140 + float64(i)*28) * g.scaleY)
+			for col, cell := range row {
+				text.Draw(screen, cell, fontFace, columnX[col], y, colorText)
+			}
+		}
+
+		if you := board.Player; you != nil {
+			line := tr("online_board.you", you.Rank, you.Rating, you.Games, winRate(*you))
+			lineBounds := boundString(fontFace, line)
+			text.Draw(screen, line, fontFace,
+				g.screenWidth/2-lineBounds.Dx()/2, int((140+float64(len(rows))*28+20)*g.scaleY), colorText)
+		}
+	}
+
+	for _, btn := range g.buttons {
+		g.drawButton(screen, btn)
+	}
+}
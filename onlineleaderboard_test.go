@@ -0,0 +1,180 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStandingsOrderAndTies(t *testing.T) {
+	book := loadRatingBook(newMemoryStorage())
+	book.records = map[string]onlineRecord{
+		"cy":   {Rating: 1250, Games: 3, Wins: 2},
+		"al":   {Rating: 1250.2, Games: 7, Wins: 4},
+		"lead": {Rating: 1400, Games: 9, Wins: 8},
+		"dee":  {Rating: 1100, Games: 2},
+	}
+	for i := range maxLeaderboardSize {
+		book.records[string(rune('m'+i))] = onlineRecord{Rating: 1200, Games: 1}
+	}
+	book.records["zed"] = onlineRecord{Rating: 900.4, Games: 4, Wins: 1}
+
+	got := book.standings("zed")
+	want := []OnlineStanding{
+		{Rank: 1, Name: "lead", Rating: 1400, Games: 9, Wins: 8},
+		{Rank: 2, Name: "al", Rating: 1250, Games: 7, Wins: 4}, // More games breaks the tie
+		{Rank: 2, Name: "cy", Rating: 1250, Games: 3, Wins: 2},
+	}
+	for i := range maxLeaderboardSize - len(want) {
+		want = append(want, OnlineStanding{Rank: 4, Name: string(rune('m' + i)), Rating: 1200, Games: 1})
+	}
+	if !reflect.DeepEqual(got.Top, want) {
+		t.Errorf("top players\n got %+v\nwant %+v", got.Top, want)
+	}
+	you := OnlineStanding{Rank: 15, Name: "zed", Rating: 900, Games: 4, Wins: 1}
+	if got.Player == nil || *got.Player != you {
+		t.Errorf("own standing %+v, want %+v", got.Player, you)
+	}
+	if err := got.validate(); err != nil {
+		t.Errorf("standings don't validate: %v", err)
+	}
+
+	for _, name := range []string{"", "nobody"} {
+		if got := book.standings(name); got.Player != nil {
+			t.Errorf("standings(%q) gave a standing %+v", name, got.Player)
+		}
+	}
+	if got := loadRatingBook(newMemoryStorage()).standings("al"); got.Top == nil || len(got.Top) != 0 {
+		t.Errorf("an empty book's top is %#v, want an empty list", got.Top)
+	}
+}
+
+func TestLeaderboardFromServer(t *testing.T) {
+	ln, ratings := startTestServer(t, 0)
+	ratings.record([2]string{"alice", "bob"}, Player)
+
+	conn, err := ln.dial()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	got, err := askLeaderboard(conn, "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Top) != 2 || got.Top[0].Name != "alice" || got.Top[1].Name != "bob" {
+		t.Errorf("server ranked %+v, want alice then bob", got.Top)
+	}
+	if got.Player == nil || got.Player.Rank != 2 {
+		t.Errorf("bob's standing %+v, want rank 2", got.Player)
+	}
+}
+
+// stubServer answers a StandingsMessage on a connection with respond
+func stubServer(t *testing.T, respond func(*wireCodec)) net.Conn {
+	t.Helper()
+	client, server := net.Pipe()
+	t.Cleanup(func() { client.Close() })
+	go func() {
+		defer server.Close()
+		codec := newWireCodec(server)
+		msg, err := codec.read()
+		if err != nil {
+			t.Errorf("stub server read: %v", err)
+			return
+		}
+		if standings, ok := msg.(StandingsMessage); !ok || standings.Name != "alice" {
+			t.Errorf("stub server was sent %#v", msg)
+			return
+		}
+		respond(codec)
+	}()
+	return client
+}
+
+func TestAskLeaderboard(t *testing.T) {
+	board := LeaderboardMessage{Top: []OnlineStanding{{Rank: 1, Name: "bob", Rating: 1300, Games: 1, Wins: 1}}}
+
+	// Anything before the leaderboard is skipped
+	conn := stubServer(t, func(c *wireCodec) {
+		c.write(QueueMessage{Position: 1})
+		c.w.Write(frame(`{"type":"teleport","version":1}`))
+		c.write(board)
+	})
+	if got, err := askLeaderboard(conn, "alice"); err != nil || !reflect.DeepEqual(got, board) {
+		t.Errorf("askLeaderboard = %+v, %v, want %+v", got, err, board)
+	}
+
+	// A server that hangs up without answering is an error
+	conn = stubServer(t, func(*wireCodec) {})
+	if _, err := askLeaderboard(conn, "alice"); err == nil {
+		t.Error("askLeaderboard of a server that hung up succeeded")
+	}
+
+	// As is one on another protocol version
+	conn = stubServer(t, func(c *wireCodec) {
+		c.w.Write(frame(`{"type":"leaderboard","version":2,"payload":{"top":[]}}`))
+	})
+	if _, err := askLeaderboard(conn, "alice"); !errors.Is(err, errWrongVersion) {
+		t.Errorf("askLeaderboard of another version = %v", err)
+	}
+}
+
+func TestOnlineLeaderboardCache(t *testing.T) {
+	store := newMemoryStorage()
+	g := newConnectFourGame(store)
+	g.onlineAddr = "games.example:9090"
+	fetched := func(board LeaderboardMessage, err error) {
+		result := make(chan leaderboardFetch, 1)
+		result <- leaderboardFetch{board: board, err: err}
+		g.onlineBoardFetch = result
+		g.updateOnlineLeaderboard()
+	}
+
+	// With nothing fetched before, a failure leaves nothing to show
+	down := io.ErrUnexpectedEOF
+	fetched(LeaderboardMessage{}, down)
+	if g.onlineBoard != nil || g.onlineBoardStatus() != tr("online_board.unreachable", g.onlineAddr) {
+		t.Errorf("failed first fetch shows %+v, %q", g.onlineBoard, g.onlineBoardStatus())
+	}
+
+	board := LeaderboardMessage{Top: []OnlineStanding{{Rank: 1, Name: "bob", Rating: 1300, Games: 1, Wins: 1}}}
+	fetched(board, nil)
+	if g.onlineBoard == nil || !reflect.DeepEqual(g.onlineBoard.Board, board) || g.onlineBoardErr != nil {
+		t.Fatalf("fetched leaderboard shows %+v, %v", g.onlineBoard, g.onlineBoardErr)
+	}
+	if g.onlineBoardStatus() != "" {
+		t.Errorf("fresh leaderboard has status %q", g.onlineBoardStatus())
+	}
+
+	// The last leaderboard fetched is kept for that server alone
+	cached := loadOnlineLeaderboard(store, g.onlineAddr)
+	if cached == nil || !reflect.DeepEqual(cached.Board, board) || time.Since(cached.Fetched) > time.Minute {
+		t.Errorf("cached %+v", cached)
+	}
+	if other := loadOnlineLeaderboard(store, "elsewhere:9090"); other != nil {
+		t.Errorf("another server's cache is %+v", other)
+	}
+
+	// Failing later keeps showing the last one fetched
+	fetched(LeaderboardMessage{}, down)
+	if g.onlineBoard == nil || !reflect.DeepEqual(g.onlineBoard.Board, board) {
+		t.Errorf("failed refetch shows %+v", g.onlineBoard)
+	}
+	if want := tr("online_board.cached", g.onlineBoard.Fetched.Local().Format("2006-01-02 15:04")); g.onlineBoardStatus() != want {
+		t.Errorf("failed refetch status %q, want %q", g.onlineBoardStatus(), want)
+	}
+}
+
+func TestOnlineLeaderboardUnreadableCache(t *testing.T) {
+	store := newMemoryStorage()
+	if err := store.Save(onlineLeaderboardKey, []byte("{not json")); err != nil {
+		t.Fatal(err)
+	}
+	if cached := loadOnlineLeaderboard(store, defaultOnlineAddr); cached != nil {
+		t.Errorf("unreadable cache loaded as %+v", cached)
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log"
+	"math"
+	"sync"
+)
+
+// ratingsKey is where the game server keeps its players' ratings
+const ratingsKey = "online_ratings.json"
+
+// onlineRecord is a player's rated games on the game server
+type onlineRecord struct {
+	Rating float64 `json:"rating"`
+	Games  int     `json:"games"`
+	Wins   int     `json:"wins"`
+}
+
+// ratingBook holds the game server's Elo ratings by account, with the same
+// formula as the local leaderboard. Accounts are named by their usernames,
+// which the server has checked the players are signed in as. Matches finish on their own
+// goroutines, so everything in it is guarded by mu.
+type ratingBook struct {
+	mu      sync.Mutex
+	store   Storage
+	records map[string]onlineRecord
+}
+
+// loadRatingBook reads the ratings saved in store, starting afresh if there
+// are none or they can't be read
+func loadRatingBook(store Storage) *ratingBook {
+	book := &ratingBook{store: store, records: map[string]onlineRecord{}}
+	data, err := store.Load(ratingsKey)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("loading ratings: %v", err)
+		}
+		return book
+	}
+	if err := json.Unmarshal(data, &book.records); err != nil {
+		log.Printf("reading ratings: %v", err)
+		book.records = map[string]onlineRecord{}
+	}
+	return book
+}
+
+// rating returns an account's rating, initialRating for a new player
+func (b *ratingBook) rating(account string) float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.ratingLocked(account)
+}
+
+// ratingLocked is rating for callers already holding mu
+func (b *ratingBook) ratingLocked(account string) float64 {
+	if record, ok := b.records[account]; ok {
+		return record.Rating
+	}
+	return initialRating
+}
+
+// record rates one finished game between accounts, in side order, and
+// saves the result. Both ratings change together, each from the other's
+// rating before the game, so a game is counted whole or not at all.
+func (b *ratingBook) record(accounts [2]string, winner int) [2]float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	scores := [2]float64{0.5, 0.5}
+	switch winner {
+	case Player:
+		scores = [2]float64{1, 0}
+	case Computer:
+		scores = [2]float64{0, 1}
+	}
+	before := [2]float64{b.ratingLocked(accounts[0]), b.ratingLocked(accounts[1])}
+	after := [2]float64{
+		updateRating(before[0], before[1], scores[0]),
+		updateRating(before[1], before[0], scores[1]),
+	}
+	for i, account := range accounts {
+		record := b.records[account]
+		record.Rating = after[i]
+		record.Games++
+		if scores[i] == 1 {
+			record.Wins++
+		}
+		b.records[account] = record
+	}
+
+	data, err := json.MarshalIndent(b.records, "", "  ")
+	if err == nil {
+		err = b.store.Save(ratingsKey, data)
+	}
+	if err != nil {
+		log.Printf("saving ratings: %v", err)
+	}
+	return after
+}
+
+// standings returns the top rated players and, if name has played rated
+// games, that player's standing. Players are ranked as on the local
+// leaderboard.
+func (b *ratingBook) standings(name string) LeaderboardMessage {
+	b.mu.Lock()
+	entries := make([]LeaderboardEntry, 0, len(b.records))
+	for player, record := range b.records {
+		entries = append(entries, LeaderboardEntry{
+			Username: player,
+			Rating:   record.Rating,
+			Games:    record.Games,
+			Wins:     record.Wins,
+		})
+	}
+	b.mu.Unlock()
+	rankEntries(entries)
+
+	msg := LeaderboardMessage{Top: []OnlineStanding{}}
+	for i, entry := range entries {
+		standing := OnlineStanding{
+			Rank:   entry.Rank,
+			Name:   entry.Username,
+			Rating: roundRating(entry.Rating),
+			Games:  entry.Games,
+			Wins:   entry.Wins,
+		}
+		if i < maxLeaderboardSize {
+			msg.Top = append(msg.Top, standing)
+		}
+		if name != "" && entry.Username == name {
+			msg.Player = &standing
+		}
+	}
+	return msg
+}
+
+// roundRating rounds a rating for sending, never below zero
+func roundRating(rating float64) int {
+	return max(0, int(math.Round(rating)))
+}
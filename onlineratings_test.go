@@ -0,0 +1,150 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRatingBookRecord(t *testing.T) {
+	store := newMemoryStorage()
+	book := loadRatingBook(store)
+	near := func(got, want float64) bool { return math.Abs(got-want) < 0.01 }
+
+	// Two new players: the winner takes 16 points from the loser
+	after := book.record([2]string{"alice", "bob"}, Player)
+	if !near(after[0], 1216) || !near(after[1], 1184) {
+		t.Errorf("even game rated %.2f, want 1216 and 1184", after)
+	}
+
+	// A 32 point favourite is expected to score 0.5460, so a draw costs it
+	// 1.47 points
+	after = book.record([2]string{"bob", "alice"}, Empty)
+	if !near(after[0], 1185.47) || !near(after[1], 1214.53) {
+		t.Errorf("draw rated %.2f, want 1185.47 and 1214.53", after)
+	}
+
+	// An upset by a 200 point underdog, expected to score 0.2403
+	book.records["carol"] = onlineRecord{Rating: 1400}
+	after = book.record([2]string{"carol", "dave"}, Computer)
+	if !near(after[0], 1375.69) || !near(after[1], 1224.31) {
+		t.Errorf("upset rated %.2f, want 1375.69 and 1224.31", after)
+	}
+
+	// Every game is counted once for both players, and kept
+	want := map[string]onlineRecord{
+		"alice": {Rating: 1214.53, Games: 2, Wins: 1},
+		"bob":   {Rating: 1185.47, Games: 2},
+		"carol": {Rating: 1375.69, Games: 1},
+		"dave":  {Rating: 1224.31, Games: 1, Wins: 1},
+	}
+	saved := loadRatingBook(store)
+	for name, record := range want {
+		got := saved.records[name]
+		if !near(got.Rating, record.Rating) || got.Games != record.Games || got.Wins != record.Wins {
+			t.Errorf("%s saved as %+v, want %+v", name, got, record)
+		}
+	}
+}
+
+// ratedPlayers are two players signed in and asking for rated games
+func ratedPlayers(t *testing.T) [2]HelloMessage {
+	return [2]HelloMessage{signedIn(t, "alice", time.Now()), signedIn(t, "bob", time.Now())}
+}
+
+// gamesRated returns how many rated games name has played
+func gamesRated(ratings *ratingBook, name string) int {
+	if standing := ratings.standings(name).Player; standing != nil {
+		return standing.Games
+	}
+	return 0
+}
+
+func TestRatedGameEndsOnce(t *testing.T) {
+	ln, ratings := startTestServer(t, 0)
+	players := ratedPlayers(t)
+	clients := startMatchAs(t, ln, players[0], players[1])
+	clients[0].send(ResignMessage{})
+	for _, c := range clients {
+		expect[GameOverMessage](c)
+		expect[RatingMessage](c)
+	}
+
+	// The winner leaving afterwards ends the match, and nothing else
+	clients[1].conn.Close()
+	if msg := clients[0].next(); msg != nil {
+		t.Errorf("after the winner left the loser was sent %#v", msg)
+	}
+	for _, name := range []string{"alice", "bob"} {
+		if games := gamesRated(ratings, name); games != 1 {
+			t.Errorf("%s has %d rated games, want 1", name, games)
+		}
+	}
+}
+
+func TestAbandonedRatedGameLosesAfterGrace(t *testing.T) {
+	config := newServerConfig(0)
+	config.reconnectGrace = 300 * time.Millisecond
+	ln, ratings := startConfiguredServer(t, config)
+	players := ratedPlayers(t)
+	clients := startMatchAs(t, ln, players[0], players[1])
+	leaver, stayer := clients[1].found.Opponent, clients[0].found.Opponent
+
+	// Dropping out isn't a loss while there is time to come back
+	clients[0].conn.Close()
+	expect[AwayMessage](clients[1])
+	if games := gamesRated(ratings, leaver); games != 0 {
+		t.Errorf("dropping out was rated at once: %d games", games)
+	}
+	back := connect(t, ln, ResumeMessage{Token: clients[0].found.Resume})
+	expect[ResumedMessage](back)
+	expect[AwayMessage](clients[1])
+	if games := gamesRated(ratings, leaver); games != 0 {
+		t.Errorf("coming back was rated: %d games", games)
+	}
+
+	// Staying away is
+	back.conn.Close()
+	expect[AwayMessage](clients[1])
+	if end := expect[GameOverMessage](clients[1]); end.Winner != Computer || end.Reason != gameOverLeft {
+		t.Errorf("game ended %+v, want the player still there winning", end)
+	}
+	if rating := expect[RatingMessage](clients[1]); rating.Rating != 1216 {
+		t.Errorf("winner's rating %d, want 1216", rating.Rating)
+	}
+	if standing := ratings.standings(leaver).Player; standing == nil || standing.Games != 1 || standing.Rating != 1184 {
+		t.Errorf("the player who left stands %+v, want one game lost at 1184", standing)
+	}
+	if games := gamesRated(ratings, stayer); games != 1 {
+		t.Errorf("the player who stayed has %d rated games, want 1", games)
+	}
+}
+
+func TestRatingsNeedASignedInAccount(t *testing.T) {
+	ln, ratings := startTestServer(t, 0)
+
+	// Asking for rated games under alice's name without her token only
+	// gets casual ones, paired with a casual player
+	clients := startMatchAs(t, ln, HelloMessage{Name: "alice", Rated: true}, HelloMessage{Name: "bob"})
+	clients[0].send(ResignMessage{})
+	for _, c := range clients {
+		expect[GameOverMessage](c)
+	}
+	clients[1].conn.Close()
+	if msg := clients[0].next(); msg != nil {
+		t.Errorf("after a casual game the player was sent %#v", msg)
+	}
+	if top := ratings.standings("").Top; len(top) != 0 {
+		t.Errorf("a game without signed in accounts was rated: %v", top)
+	}
+
+	// Signed in to one account on both sides is no rated game either
+	clients = startMatchAs(t, ln, signedIn(t, "carol", time.Now()), signedIn(t, "carol", time.Now()))
+	clients[0].send(ResignMessage{})
+	for _, c := range clients {
+		expect[GameOverMessage](c)
+	}
+	if games := gamesRated(ratings, "carol"); games != 0 {
+		t.Errorf("playing yourself was rated: %d games", games)
+	}
+}
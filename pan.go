@@ -0,0 +1,104 @@
+package main
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Space kept between an oversized board and the window edge, and how far the
+// cursor must move before a press on the board counts as a drag
+const (
+	panMargin     = 20
+	dragThreshold = 5
+)
+
+// boardOrigin returns the on-screen position of the top-left slot, including any pan offset
+func (g *ConnectFourGame) boardOrigin() (float64, float64) {
+	return g.boardOffsetX + g.panX, g.boardOffsetY + g.panY
+}
+
+// panLimits returns the allowed range of pan offsets on each axis. Axes where
+// the board fits in the window have a range of zero.
+func (g *ConnectFourGame) panLimits() (minX, maxX, minY, maxY float64) {
+	boardWidth := float64(Columns) * g.cellSize
+	boardHeight := float64(Rows) * g.cellSize
+	screenWidth := float64(g.screenWidth)
+	screenHeight := float64(g.screenHeight)
+
+	if boardWidth+2*panMargin > screenWidth {
+		minX = screenWidth - panMargin - boardWidth - g.boardOffsetX
+		maxX = panMargin - g.boardOffsetX
+	}
+	if g.boardOffsetY+boardHeight+panMargin > screenHeight {
+		minY = math.Min(0, screenHeight-panMargin-boardHeight-g.boardOffsetY)
+	}
+	return minX, maxX, minY, maxY
+}
+
+// panEnabled reports whether the board is larger than the window in either direction
+func (g *ConnectFourGame) panEnabled() bool {
+	minX, maxX, minY, maxY := g.panLimits()
+	return minX != maxX || minY != maxY
+}
+
+// clampPan keeps the pan offset within the board's edges
+func (g *ConnectFourGame) clampPan() {
+	minX, maxX, minY, maxY := g.panLimits()
+	g.panX = math.Max(minX, math.Min(maxX, g.panX))
+	g.panY = math.Max(minY, math.Min(maxY, g.panY))
+}
+
+// updatePan drags the board with the mouse while it doesn't fit in the window.
+// It returns true when a press on the board was released without dragging,
+// which should be handled as a click.
+func (g *ConnectFourGame) updatePan() bool {
+	if !g.panEnabled() {
+		g.panX, g.panY = 0, 0
+		g.dragging = false
+		return false
+	}
+
+	x, y := ebiten.CursorPosition()
+
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		originX, originY := g.boardOrigin()
+		if pointInRect(float64(x), float64(y), originX, originY,
+			float64(Columns)*g.cellSize, float64(Rows)*g.cellSize) {
+			g.dragging = true
+			g.dragMoved = false
+			g.dragStartX, g.dragStartY = x, y
+			g.panStartX, g.panStartY = g.panX, g.panY
+		}
+		return false
+	}
+
+	if !g.dragging {
+		return false
+	}
+
+	if inpututil.IsMouseButtonJustReleased(ebiten.MouseButtonLeft) {
+		g.dragging = false
+		return !g.dragMoved
+	}
+
+	dx, dy := x-g.dragStartX, y-g.dragStartY
+	if abs(dx)+abs(dy) > dragThreshold {
+		g.dragMoved = true
+	}
+	if g.dragMoved {
+		g.panX = g.panStartX + float64(dx)
+		g.panY = g.panStartY + float64(dy)
+		g.clampPan()
+	}
+	return false
+}
+
+// abs returns the absolute value of an int
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
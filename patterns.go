@@ -0,0 +1,150 @@
+package main
+
+// Named tactical patterns the engine can point out on a board. The set is
+// kept small and each is recognized by a simple, documented rule:
+//
+//   - Seven: five pieces in the shape of the numeral 7, a row of three with
+//     a diagonal of three running down from one end. Extended, the row and
+//     the diagonal complete in the same column, one cell above the other.
+//   - Double threat: two or more cells the side could win on right now, so
+//     the opponent can only block one of them.
+//   - Claimeven: a threat on an even row (counting from 1 at the bottom)
+//     held by the side that moved second, with the cell below it still
+//     empty. By answering every move in the same column the second player
+//     is bound to get that cell.
+const (
+	PatternSeven        = "seven"
+	PatternDoubleThreat = "double_threat"
+	PatternClaimeven    = "claimeven"
+)
+
+// PatternMatch is a named pattern found for one side
+type PatternMatch struct {
+	Name string
+	Side int
+	Col  int // Column the pattern is about, e.g. where its threats are
+}
+
+// DetectPatterns returns the named patterns present for each side, with
+// toMove to play next
+func DetectPatterns(board GameBoard, toMove int) []PatternMatch {
+	matches := []PatternMatch{}
+	for _, side := range []int{Player, Computer} {
+		matches = append(matches, findSevens(board, side)...)
+		if cols := immediateWins(board, side); len(cols) >= 2 {
+			matches = append(matches, PatternMatch{Name: PatternDoubleThreat, Side: side, Col: cols[0]})
+		}
+		if side != firstMover(board, toMove) {
+			matches = append(matches, findClaimevens(board, side)...)
+		}
+	}
+	return matches
+}
+
+// hasPattern reports whether matches include the named pattern for side
+func hasPattern(matches []PatternMatch, name string, side int) bool {
+	for _, m := range matches {
+		if m.Name == name && m.Side == side {
+			return true
+		}
+	}
+	return false
+}
+
+// newPatterns returns the names of side's patterns present after a move but
+// not before it
+func newPatterns(before, after []PatternMatch, side int) []string {
+	names := []string{}
+	for _, name := range []string{PatternSeven, PatternDoubleThreat, PatternClaimeven} {
+		if hasPattern(after, name, side) && !hasPattern(before, name, side) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// findSevens finds 7 shapes for side in either orientation. The corner of
+// the 7 is at (row, col); its bar runs away from the side the threats are on.
+func findSevens(board GameBoard, side int) []PatternMatch {
+	matches := []PatternMatch{}
+	for row := 0; row < Rows; row++ {
+		for col := 0; col < Columns; col++ {
+			for _, d := range []int{-1, 1} {
+				cells := [][2]int{
+					{row, col}, {row, col + d}, {row, col + 2*d}, // Bar
+					{row + 1, col + d}, {row + 2, col + 2*d}, // Diagonal down from the corner
+				}
+				if ownsAll(board, side, cells) {
+					matches = append(matches, PatternMatch{Name: PatternSeven, Side: side, Col: col - d})
+				}
+			}
+		}
+	}
+	return matches
+}
+
+// ownsAll reports whether side has a piece in every one of cells
+func ownsAll(board GameBoard, side int, cells [][2]int) bool {
+	for _, cell := range cells {
+		if board.At(cell[0], cell[1]) != side {
+			return false
+		}
+	}
+	return true
+}
+
+// isThreat reports whether a piece for side in the empty cell (row, col)
+// would make four in a row, whether or not the cell can be played yet
+func isThreat(board GameBoard, side, row, col int) bool {
+	if row < 0 || row >= Rows || col < 0 || col >= Columns || board[row][col] != Empty {
+		return false
+	}
+	board[row][col] = side
+	return checkWin(board, side)
+}
+
+// immediateWins returns the columns where side would win with its next piece
+func immediateWins(board GameBoard, side int) []int {
+	cols := []int{}
+	for _, col := range getValidColumns(board) {
+		if checkWin(dropPiece(board, col, side), side) {
+			cols = append(cols, col)
+		}
+	}
+	return cols
+}
+
+// findClaimevens finds side's threats on even rows that can't be played yet
+func findClaimevens(board GameBoard, side int) []PatternMatch {
+	matches := []PatternMatch{}
+	for row := 0; row < Rows-1; row++ {
+		if (Rows-row)%2 != 0 {
+			continue
+		}
+		for col := 0; col < Columns; col++ {
+			if board[row+1][col] == Empty && isThreat(board, side, row, col) {
+				matches = append(matches, PatternMatch{Name: PatternClaimeven, Side: side, Col: col})
+			}
+		}
+	}
+	return matches
+}
+
+// firstMover works out which side made the first move: the side with more
+// pieces, or with equal counts the side to move
+func firstMover(board GameBoard, toMove int) int {
+	counts := map[int]int{}
+	for row := 0; row < Rows; row++ {
+		for col := 0; col < Columns; col++ {
+			counts[board[row][col]]++
+		}
+	}
+	switch {
+	case counts[Player] > counts[Computer]:
+		return Player
+	case counts[Computer] > counts[Player]:
+		return Computer
+	default:
+		return toMove
+	}
+}
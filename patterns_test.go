@@ -0,0 +1,104 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// positionBoard parses a position string, top row first, failing the test
+// if it isn't a position real play could reach
+func positionBoard(t *testing.T, position string) GameBoard {
+	t.Helper()
+	board, err := ParseBoard(position)
+	if err != nil {
+		t.Fatalf("position %q: %v", position, err)
+	}
+	return board
+}
+
+func TestDetectPatterns(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		position string
+		toMove   int
+		want     []PatternMatch
+	}{
+		{"empty board", "......./......./......./......./......./.......", Player, []PatternMatch{}},
+
+		// Bar along C3 to E3, diagonal from C3 down to E1, both complete in column B
+		{"seven", "......./......./......./..XXX../..OXO../..OOX..", Computer,
+			[]PatternMatch{{PatternSeven, Player, 1}}},
+		{"mirrored seven", "......./......./......./..XXX../..OXO../..XOO..", Computer,
+			[]PatternMatch{{PatternSeven, Player, 5}}},
+		{"seven missing its diagonal", "......./......./......./..XXX../..OOO../..OOX..", Player,
+			[]PatternMatch{}},
+
+		// B1 to D1 with A1 and E1 both open
+		{"double threat", "......./......./......./......./.OO..../.XXX...", Computer,
+			[]PatternMatch{{PatternDoubleThreat, Player, 0}}},
+		{"single threat", "......./......./......./......./.OO..../.XXXO..", Player,
+			[]PatternMatch{}},
+
+		// The computer moved second and threatens A2 with A1 still empty
+		{"claimeven", "......./......./......./......./.OOOX../.XXOX..", Player,
+			[]PatternMatch{{PatternClaimeven, Computer, 0}}},
+		// The same threat held by the side that moved first is no claimeven
+		{"threat for the first mover", "......./......./......./......./.OOOX../.XXOX..", Computer,
+			[]PatternMatch{}},
+		// With A1 filled the threat can be played at once, so it is just a threat
+		{"threat ready to play", "......./......./......./......./.OOOX../XXXOX..", Computer,
+			[]PatternMatch{}},
+	} {
+		board := positionBoard(t, tt.position)
+		if got := DetectPatterns(board, tt.toMove); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: DetectPatterns = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNewPatterns(t *testing.T) {
+	seven := PatternMatch{PatternSeven, Player, 1}
+	threat := PatternMatch{PatternDoubleThreat, Player, 0}
+	theirs := PatternMatch{PatternClaimeven, Computer, 0}
+
+	for _, tt := range []struct {
+		name          string
+		before, after []PatternMatch
+		want          []string
+	}{
+		{"nothing", nil, nil, []string{}},
+		{"new pattern", nil, []PatternMatch{seven}, []string{PatternSeven}},
+		{"already there", []PatternMatch{seven}, []PatternMatch{seven}, []string{}},
+		{"listed in order", []PatternMatch{seven}, []PatternMatch{threat, seven, {PatternClaimeven, Player, 3}},
+			[]string{PatternDoubleThreat, PatternClaimeven}},
+		{"the other side's", nil, []PatternMatch{theirs}, []string{}},
+	} {
+		if got := newPatterns(tt.before, tt.after, Player); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: newPatterns = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAnalysisNamesPatterns(t *testing.T) {
+	// The player's D1 leaves B1 to D1 open at both ends
+	a := NewAnalysis(movesFrom("22334"))
+	for !a.Done() {
+		a.Step()
+	}
+	for ply, result := range a.results {
+		want := []string{}
+		if ply == 4 {
+			want = []string{PatternDoubleThreat}
+		}
+		if !slices.Equal(result.Patterns, want) {
+			t.Errorf("move %d patterns = %q, want %q", ply+1, result.Patterns, want)
+		}
+	}
+	if line := a.results[4].describe(4); !strings.HasSuffix(line, " - sets up a double threat") {
+		t.Errorf("description %q doesn't name the double threat", line)
+	}
+	if line := a.results[3].describe(3); strings.Contains(line, "sets up") {
+		t.Errorf("description %q names a pattern that isn't there", line)
+	}
+}
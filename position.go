@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Position strings describe a board row by row from top to bottom, with rows
+// separated by '/' and each cell written as '.' (empty), 'X' (player) or
+// 'O' (computer), e.g. "......./......./......./...X.../..OX.../.XOOX..".
+
+// errPositionWon is returned for positions where the game is already over
+var errPositionWon = errors.New("position already contains four in a row")
+
+// Reasons ValidateBoard rejects a board
+var (
+	errInvalidCell   = errors.New("invalid cell value")
+	errFloatingPiece = errors.New("piece above an empty cell")
+	errPieceCount    = errors.New("piece counts differ by more than one")
+	errBothWon       = errors.New("both sides have four in a row")
+)
+
+// ParseBoard converts a position string into a GameBoard. Positions where
+// either side already has four in a row are rejected.
+func ParseBoard(s string) (GameBoard, error) {
+	var board GameBoard
+
+	rows := strings.Split(strings.TrimSpace(s), "/")
+	if len(rows) != Rows {
+		return board, fmt.Errorf("position has %d rows, expected %d", len(rows), Rows)
+	}
+
+	for row, line := range rows {
+		if len(line) != Columns {
+			return board, fmt.Errorf("row %d has %d cells, expected %d", row+1, len(line), Columns)
+		}
+		for col, ch := range line {
+			switch ch {
+			case '.':
+				board[row][col] = Empty
+			case 'X', 'x':
+				board[row][col] = Player
+			case 'O', 'o':
+				board[row][col] = Computer
+			default:
+				return board, fmt.Errorf("row %d has invalid cell %q", row+1, ch)
+			}
+		}
+	}
+
+	if err := ValidateBoard(board); err != nil {
+		return board, err
+	}
+	if err := validatePosition(board); err != nil {
+		return board, err
+	}
+	return board, nil
+}
+
+// ValidateBoard checks that a board from outside the game could have come
+// from real play: every cell is empty or holds a piece, no piece floats above
+// an empty cell, the sides have played the same number of pieces give or take
+// one, and at most one side has four in a row. Every board read from a file,
+// the API or another player goes through it.
+func ValidateBoard(board GameBoard) error {
+	counts := map[int]int{}
+	for row := 0; row < Rows; row++ {
+		for col := 0; col < Columns; col++ {
+			cell := board[row][col]
+			switch cell {
+			case Empty:
+				continue
+			case Player, Computer:
+				counts[cell]++
+			default:
+				return fmt.Errorf("%w %d at %s", errInvalidCell, cell, cellName(row, col))
+			}
+			if row+1 < Rows && board[row+1][col] == Empty {
+				return fmt.Errorf("%w at %s", errFloatingPiece, cellName(row, col))
+			}
+		}
+	}
+	if diff := counts[Player] - counts[Computer]; diff > 1 || diff < -1 {
+		return fmt.Errorf("%w: %d X and %d O", errPieceCount, counts[Player], counts[Computer])
+	}
+	if checkWin(board, Player) && checkWin(board, Computer) {
+		return errBothWon
+	}
+	return nil
+}
+
+// validatePosition reports an error if either side has already won, since
+// play can't sensibly continue from such a position
+func validatePosition(board GameBoard) error {
+	if checkWin(board, Player) || checkWin(board, Computer) {
+		return errPositionWon
+	}
+	return nil
+}
+
+// FormatBoard converts a GameBoard into a position string
+func FormatBoard(board GameBoard) string {
+	var sb strings.Builder
+	for row := 0; row < Rows; row++ {
+		if row > 0 {
+			sb.WriteByte('/')
+		}
+		for col := 0; col < Columns; col++ {
+			switch board[row][col] {
+			case Player:
+				sb.WriteByte('X')
+			case Computer:
+				sb.WriteByte('O')
+			default:
+				sb.WriteByte('.')
+			}
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidatePositionRejectsWon(t *testing.T) {
+	tests := []struct {
+		name     string
+		position string
+		want     error
+	}{
+		{"empty", "......./......./......./......./......./.......", nil},
+		{"three in a row", "......./......./......./......./OO...../XXX....", nil},
+		{"player won across", "......./......./......./......./OOO..../XXXX...", errPositionWon},
+		{"computer won down", "......./......./..O..../..OX.../..OX.../.XOX...", errPositionWon},
+		{"player won diagonally", "......./......./...X.../..XO.../.XOO.../XOOX.X.", errPositionWon},
+	}
+	for _, tt := range tests {
+		var board GameBoard
+		for i, ch := range tt.position {
+			row, col := i/(Columns+1), i%(Columns+1)
+			switch ch {
+			case 'X':
+				board[row][col] = Player
+			case 'O':
+				board[row][col] = Computer
+			}
+		}
+		if err := validatePosition(board); !errors.Is(err, tt.want) {
+			t.Errorf("%s: validatePosition = %v, want %v", tt.name, err, tt.want)
+		}
+		if _, err := ParseBoard(tt.position); !errors.Is(err, tt.want) {
+			t.Errorf("%s: ParseBoard = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestParseBoardFailures(t *testing.T) {
+	tests := []struct {
+		name     string
+		position string
+		want     error // nil when only an error is expected
+	}{
+		{"too few rows", "......./......./......./......./.......", nil},
+		{"too many rows", "......./......./......./......./......./......./.......", nil},
+		{"short row", "......./......./......./......./......./......", nil},
+		{"long row", "......./......./......./......./......./........", nil},
+		{"bad cell", "......./......./......./......./......./...Z...", nil},
+		{"floating piece", "......./......./......./...X.../......./...O...", errFloatingPiece},
+		{"too many X", "......./......./......./......./......./XXX....", errPieceCount},
+		{"too many O", "......./......./......./......./......./OO.....", errPieceCount},
+		{"both won", "......./......./......./......./OOOO.../XXXX...", errBothWon},
+	}
+	for _, tt := range tests {
+		_, err := ParseBoard(tt.position)
+		if err == nil || (tt.want != nil && !errors.Is(err, tt.want)) {
+			t.Errorf("%s: ParseBoard = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestValidateBoardFailures(t *testing.T) {
+	var invalid GameBoard
+	invalid[Rows-1][0] = 3
+
+	floating := boardFromMoves(t, "44")
+	floating[Rows-1][3], floating[Rows-3][3] = Empty, floating[Rows-1][3]
+
+	var uneven GameBoard
+	uneven[Rows-1][0], uneven[Rows-1][1] = Computer, Computer
+
+	var bothWon GameBoard
+	for col := range 4 {
+		bothWon[Rows-1][col] = Player
+		bothWon[Rows-2][col] = Computer
+	}
+
+	tests := []struct {
+		name  string
+		board GameBoard
+		want  error
+	}{
+		{"empty", GameBoard{}, nil},
+		{"played", boardFromMoves(t, "4453"), nil},
+		{"invalid cell", invalid, errInvalidCell},
+		{"floating piece", floating, errFloatingPiece},
+		{"piece count", uneven, errPieceCount},
+		{"both won", bothWon, errBothWon},
+	}
+	for _, tt := range tests {
+		if err := ValidateBoard(tt.board); !errors.Is(err, tt.want) {
+			t.Errorf("%s: ValidateBoard = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
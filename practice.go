@@ -0,0 +1,53 @@
+package main
+
+// Practice mode replays a saved game against the player: the computer plays
+// the moves it made in that game for as long as the player repeats theirs,
+// and searches for its own moves once the game leaves the recorded line
+// ("book").
+
+// startPractice starts a game that follows a saved game's moves
+func (g *ConnectFourGame) startPractice(game *SavedGame) {
+	g.replayPlaying = false
+	g.startNewGame()
+	if len(game.Moves) > 0 {
+		g.setStarter(game.Moves[0].Player)
+	}
+	g.setDifficulty(game.Difficulty)
+	g.practiceGame = game
+	g.inBook = true
+}
+
+// bookMove returns the computer's recorded reply if the game so far matches
+// the practice game, leaving book otherwise
+func (g *ConnectFourGame) bookMove() (int, bool) {
+	if g.practiceGame == nil || !g.inBook {
+		return 0, false
+	}
+	recorded := g.practiceGame.Moves
+	ply := len(g.moves)
+	inBook := ply < len(recorded) && recorded[ply].Player == Computer
+	for i := 0; inBook && i < ply; i++ {
+		inBook = g.moves[i] == recorded[i]
+	}
+	if inBook {
+		col := recorded[ply].Column
+		if col >= 0 && col < Columns && !g.board.columnFull(col) {
+			return col, true
+		}
+	}
+
+	g.inBook = false
+	g.showToast(tr("practice.left_book"))
+	return 0, false
+}
+
+// practiceStatus describes whether a practice game is still following the recording
+func (g *ConnectFourGame) practiceStatus() string {
+	if g.practiceGame == nil {
+		return ""
+	}
+	if g.inBook {
+		return tr("practice.in_book", g.practiceGame.Played.Local().Format("2006-01-02 15:04"))
+	}
+	return tr("practice.out_of_book")
+}
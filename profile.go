@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"image/color"
+	"image/png"
+	"io/fs"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+//go:embed avatars/*.png
+var avatarFiles embed.FS
+
+// Avatars users can pick from, matching the files in avatars/
+var avatarNames = []string{"smiley", "star", "heart", "crown", "robot", "cat"}
+
+// Disc colors users can pick for their pieces
+var discColors = []color.RGBA{
+	{255, 50, 50, 255},   // Red
+	{250, 140, 30, 255},  // Orange
+	{230, 200, 40, 255},  // Yellow
+	{50, 180, 80, 255},   // Green
+	{160, 70, 200, 255},  // Purple
+	{240, 110, 170, 255}, // Pink
+}
+
+// Catalog keys naming each of discColors
+var discColorKeys = []string{
+	"color.red", "color.orange", "color.yellow", "color.green", "color.purple", "color.pink",
+}
+
+// Profile holds a user's appearance choices
+type Profile struct {
+	Username  string `json:"username"`
+	Avatar    int    `json:"avatar"`
+	DiscColor int    `json:"disc_color"`
+
+	// The tutorial has been finished or skipped, so it no longer shows by itself
+	TutorialDone bool `json:"tutorial_done,omitempty"`
+}
+
+// profileKey returns the storage key holding a user's profile
+func profileKey(username string) string {
+	return "profiles/" + userFileName(username) + ".json"
+}
+
+// loadProfile reads a user's profile, reporting whether one was saved.
+// Out-of-range choices, e.g. from older versions, fall back to the defaults.
+func loadProfile(store Storage, username string) (Profile, bool) {
+	profile := Profile{Username: username}
+	data, err := store.Load(profileKey(username))
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("loading profile: %v", err)
+		}
+		return profile, false
+	}
+	if err := json.Unmarshal(data, &profile); err != nil {
+		log.Printf("loading profile: %v", err)
+		return Profile{Username: username}, false
+	}
+
+	profile.Username = username
+	if profile.Avatar < 0 || profile.Avatar >= len(avatarNames) {
+		profile.Avatar = 0
+	}
+	if profile.DiscColor < 0 || profile.DiscColor >= len(discColors) {
+		profile.DiscColor = 0
+	}
+	return profile, true
+}
+
+// saveProfile writes a user's profile to storage
+func saveProfile(store Storage, profile Profile) error {
+	data, err := json.MarshalIndent(profile, "", "  ")
+	if err != nil {
+		return err
+	}
+	return store.Save(profileKey(profile.Username), data)
+}
+
+// applyProfile makes a profile the active one and uses its disc color
+func (g *ConnectFourGame) applyProfile(profile Profile) {
+	g.profile = profile
+	g.playerColor = discColors[profile.DiscColor]
+	g.preRenderCircle(g.playerColor)
+	g.updateComputerColor()
+}
+
+// hoverColor returns the translucent version of the player's color used for the hover preview
+func (g *ConnectFourGame) hoverColor() color.RGBA {
+	clr := g.playerColor
+	if g.hotseat && g.turn == Computer {
+		clr = g.computerColor
+	}
+	return color.RGBA{clr.R, clr.G, clr.B, colorHover.A}
+}
+
+// openProfileEditor shows the profile screen, starting from the current choices
+func (g *ConnectFourGame) openProfileEditor(profile Profile, isNew bool) {
+	g.profileDraft = profile
+	g.profileIsNew = isNew
+	g.transitionTo(StateProfile)
+}
+
+// avatarImage returns the decoded image for an avatar, loading it on first use
+func (g *ConnectFourGame) avatarImage(avatar int) *ebiten.Image {
+	if avatar < 0 || avatar >= len(avatarNames) {
+		avatar = 0
+	}
+	if img, ok := g.avatarImages[avatar]; ok {
+		return img
+	}
+
+	var img *ebiten.Image
+	data, err := avatarFiles.ReadFile("avatars/" + avatarNames[avatar] + ".png")
+	if err == nil {
+		decoded, decodeErr := png.Decode(bytes.NewReader(data))
+		if decodeErr == nil {
+			img = ebiten.NewImageFromImage(decoded)
+		}
+		err = decodeErr
+	}
+	if err != nil {
+		log.Printf("loading avatar %s: %v", avatarNames[avatar], err)
+		img = ebiten.NewImage(16, 16)
+		img.Fill(colorEmpty)
+	}
+	g.avatarImages[avatar] = img
+	return img
+}
+
+// drawAvatar renders an avatar scaled to a square of the given size
+func (g *ConnectFourGame) drawAvatar(screen *ebiten.Image, avatar int, x, y, size float64) {
+	img := g.avatarImage(avatar)
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(size/float64(img.Bounds().Dx()), size/float64(img.Bounds().Dy()))
+	op.GeoM.Translate(x, y)
+	screen.DrawImage(img, op)
+}
+
+// initProfileUI creates the avatar and color pickers and the save button
+func (g *ConnectFourGame) initProfileUI() {
+	size := 50 * g.scaleX
+	gap := 15 * g.scaleX
+
+	rowWidth := float64(len(avatarNames))*size + float64(len(avatarNames)-1)*gap
+	for i := range avatarNames {
+		avatar := i
+		g.buttons = append(g.buttons, &Button{
+			x: float64(g.screenWidth)/2 - rowWidth/2 + float64(i)*(size+gap),
+			y: 170 * g.scaleY,
+			w: size,
+			h: size,
+			action: func() {
+				g.profileDraft.Avatar = avatar
+			},
+		})
+	}
+
+	rowWidth = float64(len(discColors))*size + float64(len(discColors)-1)*gap
+	for i := range discColors {
+		discColor := i
+		g.buttons = append(g.buttons, &Button{
+			x: float64(g.screenWidth)/2 - rowWidth/2 + float64(i)*(size+gap),
+			y: 290 * g.scaleY,
+			w: size,
+			h: size,
+			action: func() {
+				g.profileDraft.DiscColor = discColor
+			},
+		})
+	}
+
+	// Save button
+	g.buttons = append(g.buttons, &Button{
+		x:    float64(g.screenWidth)/2 - 60*g.scaleX,
+		y:    390 * g.scaleY,
+		w:    120 * g.scaleX,
+		h:    40 * g.scaleY,
+		text: tr("profile.save"),
+		action: func() {
+			if err := saveProfile(g.userStore, g.profileDraft); err != nil {
+				log.Printf("saving profile: %v", err)
+			}
+			g.applyProfile(g.profileDraft)
+			g.transitionTo(StateGameMode)
+		},
+	})
+}
+
+// drawProfileScreen renders the avatar and disc color pickers
+func (g *ConnectFourGame) drawProfileScreen(screen *ebiten.Image) {
+	title := tr("profile.edit_title")
+	if g.profileIsNew {
+		title = tr("profile.new_title", g.username)
+	}
+	titleBounds := boundString(fontFace, title)
+	text.Draw(screen, title, fontFace,
+		g.screenWidth/2-titleBounds.Dx()/2, int(90*g.scaleY), colorText)
+
+	for i, label := range []string{tr("profile.avatar"), tr("profile.disc_color")} {
+		labelBounds := boundString(fontFace, label)
+		text.Draw(screen, label, fontFace,
+			g.screenWidth/2-labelBounds.Dx()/2, int((150+float64(i)*120)*g.scaleY), colorText)
+	}
+
+	// The first buttons are the avatars, then the colors, then save
+	for i, btn := range g.buttons {
+		switch {
+		case i < len(avatarNames):
+			g.drawPickerCell(screen, btn, i == g.profileDraft.Avatar)
+			g.drawAvatar(screen, i, btn.x+btn.w*0.1, btn.y+btn.h*0.1, btn.w*0.8)
+		case i < len(avatarNames)+len(discColors):
+			colorIndex := i - len(avatarNames)
+			g.drawPickerCell(screen, btn, colorIndex == g.profileDraft.DiscColor)
+			g.drawSmoothCircle(screen, int(btn.x+btn.w/2), int(btn.y+btn.h/2), btn.w*0.4, discColors[colorIndex])
+		default:
+			g.drawButton(screen, btn)
+		}
+	}
+}
+
+// drawPickerCell draws the background of a picker choice, outlined when selected
+func (g *ConnectFourGame) drawPickerCell(screen *ebiten.Image, btn *Button, selected bool) {
+	if selected {
+		drawRect(screen, btn.x-3, btn.y-3, btn.w+6, btn.h+6, colorButton)
+	}
+	drawRect(screen, btn.x, btn.y, btn.w, btn.h, colorSlotBg)
+}
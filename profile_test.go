@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+)
+
+func TestNewUserCreatesProfile(t *testing.T) {
+	store := newMemoryStorage()
+	g := newConnectFourGame(store)
+	g.finishLogin("alice", "")
+
+	// A first login opens the editor on the defaults
+	if g.state != StateProfile || !g.profileIsNew {
+		t.Fatalf("first login went to state %d, new profile %v", g.state, g.profileIsNew)
+	}
+	if want := (Profile{Username: "alice"}); g.profileDraft != want {
+		t.Errorf("new profile starts as %+v, want %+v", g.profileDraft, want)
+	}
+	if g.playerColor != discColors[0] {
+		t.Errorf("new user plays in %v, want the first disc color", g.playerColor)
+	}
+
+	// Picking an avatar and a color, then saving, keeps them
+	g.buttons[3].action()                  // Fourth avatar
+	g.buttons[len(avatarNames)+2].action() // Third color
+	g.buttons[len(g.buttons)-1].action()   // Save
+	if g.state != StateGameMode {
+		t.Errorf("saving the profile went to state %d", g.state)
+	}
+	if g.profile.Avatar != 3 || g.profile.DiscColor != 2 || g.playerColor != discColors[2] {
+		t.Errorf("saved profile applied as %+v in %v", g.profile, g.playerColor)
+	}
+	saved, found := loadProfile(store, "alice")
+	if !found || saved.Avatar != 3 || saved.DiscColor != 2 {
+		t.Errorf("stored profile %+v, found %v", saved, found)
+	}
+
+	// Logging in again goes straight to the menu with it
+	again := newConnectFourGame(store)
+	again.finishLogin("alice", "")
+	if again.state != StateGameMode || again.profile != saved {
+		t.Errorf("second login went to state %d with profile %+v", again.state, again.profile)
+	}
+}
+
+func TestProfileDefaults(t *testing.T) {
+	store := newMemoryStorage()
+	for _, tt := range []struct {
+		name      string
+		file      string
+		avatar    int
+		discColor int
+		found     bool
+	}{
+		{"saved choices", `{"avatar": 2, "disc_color": 4}`, 2, 4, true},
+		{"avatar out of range", `{"avatar": 99, "disc_color": 1}`, 0, 1, true},
+		{"negative color", `{"avatar": 5, "disc_color": -1}`, 5, 0, true},
+		{"corrupt file", `{"avatar": `, 0, 0, false},
+	} {
+		store.Save(profileKey("bob"), []byte(tt.file))
+		profile, found := loadProfile(store, "bob")
+		if profile.Avatar != tt.avatar || profile.DiscColor != tt.discColor || found != tt.found || profile.Username != "bob" {
+			t.Errorf("%s: loaded %+v, found %v", tt.name, profile, found)
+		}
+	}
+
+	// Every avatar has its picture, and unknown ones show the default
+	for _, name := range avatarNames {
+		data, err := avatarFiles.ReadFile("avatars/" + name + ".png")
+		if err == nil {
+			_, err = png.Decode(bytes.NewReader(data))
+		}
+		if err != nil {
+			t.Errorf("avatar %s: %v", name, err)
+		}
+	}
+	g := newConnectFourGame(store)
+	if g.avatarImage(-1) != g.avatarImage(0) || g.avatarImage(len(avatarNames)) != g.avatarImage(0) {
+		t.Error("unknown avatars don't show the default")
+	}
+}
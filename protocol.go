@@ -0,0 +1,670 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"unicode/utf8"
+)
+
+// protocolVersion is the online play protocol spoken by this build. Peers on
+// another version are turned away rather than misunderstood.
+const protocolVersion = 1
+
+// Limits on text carried by messages. Chat up to maxChatWireLength is
+// accepted but the server only passes on the first maxChatLength characters.
+const (
+	maxWireNameLength = 32
+	maxChatLength     = 200
+	maxChatWireLength = 2000
+	maxResumeLength   = 64
+	maxTokenLength    = 96
+	maxPasswordLength = 128
+)
+
+// maxLeaderboardSize is the most players a LeaderboardMessage lists
+const maxLeaderboardSize = 10
+
+// maxMessageSize is the longest frame's JSON, in bytes, with room
+// for the longest chat however its characters are escaped. A peer sending
+// more is cut off rather than buffered without end.
+const maxMessageSize = 16 << 10
+
+// Message types of the online play protocol. Every message travels as a
+// Message, its fields the payload, framed by its length.
+const (
+	wireHello       = "hello"       // Client: first message, introducing the player
+	wireSpectate    = "spectate"    // Client: first message instead of hello, to watch a game
+	wireSpectating  = "spectating"  // Server: a game to watch, its players and moves so far
+	wireRating      = "rating"      // Server: the player's current rating
+	wireSignedIn    = "signed_in"   // Server: a session token for signing in from now on
+	wireStandings   = "standings"   // Client: first message instead of hello, to fetch the leaderboard
+	wireResume      = "resume"      // Client: first message instead of hello, to rejoin a game after losing the connection
+	wireResumed     = "resumed"     // Server: the game rejoined, with its moves so far
+	wireLeaderboard = "leaderboard" // Server: the top rated players, before hanging up
+	wireQueue       = "queue"       // Server: the client's place in the queue for an opponent
+	wireMatchFound  = "match_found" // Server: an opponent was found and a game starts
+	wireMove        = "move"        // Client: play a column. Server: the opponent played one
+	wireMoveAck     = "move_ack"    // Server: the client's move was accepted
+	wireMoveReject  = "move_reject" // Server: the client's move was refused, with the reason
+	wireAway        = "away"        // Server: the opponent lost the connection, or came back
+	wireGameOver    = "game_over"   // Server: the game has ended
+	wireChat        = "chat"        // Either: a line of chat, passed on to the opponent
+	wireRematch     = "rematch"     // Client: offer to play again. Server: the opponent offers
+	wireResign      = "resign"      // Client: give up the game
+	wireDrawOffer   = "draw_offer"  // Client: offer a draw, or accept one. Server: the opponent offers
+	wireSync        = "sync"        // Client: ask for the game so far. Server: the moves so far
+	wirePing        = "ping"        // Either: check the connection is alive
+	wirePong        = "pong"        // Either: answer to a ping
+)
+
+// Reasons a game ends, in GameOverMessage
+const (
+	gameOverFour   = "four"          // Winner made four in a row
+	gameOverFull   = "full"          // The board filled up with no winner
+	gameOverLeft   = "opponent_left" // The other player disconnected; the one remaining wins
+	gameOverResign = "resign"        // The loser resigned
+	gameOverDraw   = "draw"          // Both players agreed to a draw
+	gameOverTime   = "time"          // The side to move ran out of time
+	gameOverGone   = "gone"          // Sent for a resume: the game is no longer there to rejoin
+)
+
+// Reasons the server refuses a move, in MoveRejectMessage
+const (
+	rejectNotYourTurn = "not_your_turn"
+	rejectOffBoard    = "off_board"   // The column is outside the board
+	rejectColumnFull  = "column_full" // The column has no room left
+	rejectGameOver    = "game_over"
+)
+
+// Errors reading messages. A bad message leaves the connection usable, as
+// the whole frame was read; anything else means it has failed.
+var (
+	errBadMessage   = errors.New("bad message")
+	errUnknownType  = fmt.Errorf("%w: unknown type", errBadMessage)
+	errWrongVersion = errors.New("unsupported protocol version")
+	errColumnRange  = fmt.Errorf("%w: column out of range", errBadMessage)
+	errSideRange    = fmt.Errorf("%w: invalid side", errBadMessage)
+	errQueueRange   = fmt.Errorf("%w: invalid queue position", errBadMessage)
+	errRatingRange  = fmt.Errorf("%w: invalid rating", errBadMessage)
+	errTimeRange    = fmt.Errorf("%w: invalid time left", errBadMessage)
+	errStanding     = fmt.Errorf("%w: invalid leaderboard standing", errBadMessage)
+	errTooManyRanks = fmt.Errorf("%w: leaderboard too long", errBadMessage)
+	errTooManyMoves = fmt.Errorf("%w: more moves than the board holds", errBadMessage)
+	errRejectCode   = fmt.Errorf("%w: unknown reject code", errBadMessage)
+	errTextTooLong  = fmt.Errorf("%w: text too long", errBadMessage)
+	errResumeToken  = fmt.Errorf("%w: invalid resume token", errBadMessage)
+	errSessionToken = fmt.Errorf("%w: invalid session token", errBadMessage)
+	errEmptyText    = fmt.Errorf("%w: text is empty", errBadMessage)
+)
+
+// errMessageTooLarge fails a connection whose peer sends a message over
+// maxMessageSize
+var errMessageTooLarge = errors.New("message too large")
+
+// wireMessage is one message of the protocol
+type wireMessage interface {
+	wireType() string
+	validate() error
+}
+
+// HelloMessage introduces the player when a client connects. To play rated
+// games the client signs in as Name, with a session token the server issued
+// it or, the first time, with the password for Name's account on the server,
+// which is created if Name has none. Clients send a secret kept for that
+// server, never the password the player logs in to their machine with. The server hangs up on a client that
+// fails to sign in; a client that doesn't try plays casual games only.
+type HelloMessage struct {
+	Name     string `json:"name"`
+	Rated    bool   `json:"rated,omitempty"` // Play rated games, which need signing in
+	Token    string `json:"token,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// SignedInMessage hands a client that signed in with its password the
+// session token to sign in with from then on
+type SignedInMessage struct {
+	Token string `json:"token"`
+}
+
+// RatingMessage tells a client the rating the server holds for its name,
+// on connecting and after each rated game
+type RatingMessage struct {
+	Rating int `json:"rating"`
+}
+
+// StandingsMessage asks for the leaderboard of rated players rather than a
+// game. The server answers with a LeaderboardMessage and hangs up.
+type StandingsMessage struct {
+	Name string `json:"name"` // Player whose own standing is wanted, if any
+}
+
+// OnlineStanding is a rated player's place on the server's leaderboard
+type OnlineStanding struct {
+	Rank   int    `json:"rank"`
+	Name   string `json:"name"`
+	Rating int    `json:"rating"`
+	Games  int    `json:"games"`
+	Wins   int    `json:"wins"`
+}
+
+// LeaderboardMessage lists the top rated players, best first with equal
+// ratings sharing a rank, and the asking player's standing if they have one
+type LeaderboardMessage struct {
+	Top    []OnlineStanding `json:"top"`
+	Player *OnlineStanding  `json:"player,omitempty"`
+}
+
+// SpectateMessage asks to watch games rather than play. The server answers
+// with a SpectatingMessage once there is a game to watch, and then passes on
+// its moves and its GameOverMessage.
+type SpectateMessage struct {
+	Name string `json:"name"`
+}
+
+// SpectatingMessage starts a spectator watching a game. Players are named in
+// side order, and Moves are the columns played so far with the first by
+// Player.
+type SpectatingMessage struct {
+	Players [2]string `json:"players"`
+	Moves   []int     `json:"moves,omitempty"`
+}
+
+// ResumeMessage rejoins the game the client lost its connection to, using
+// the token it was given when the game started. The server answers with a
+// ResumedMessage, or a GameOverMessage with the reason gameOverGone if the
+// game has ended or the token is unknown, and hangs up.
+type ResumeMessage struct {
+	Token string `json:"token"`
+}
+
+// ResumedMessage hands a reconnected client back its game: the columns
+// played so far, with the first by Player, and the hash of the server's
+// board and time left as in MoveMessage. The client checks they agree with
+// its own before carrying on.
+type ResumedMessage struct {
+	Moves    []int  `json:"moves,omitempty"`
+	Hash     uint64 `json:"hash,omitempty"`
+	TimeLeft int    `json:"time_left,omitempty"`
+}
+
+// AwayMessage tells a client its opponent has lost the connection and has
+// a while to come back, or that they are back. The move clock stands still
+// with TimeLeft milliseconds on it while they are away, and carries on from
+// there once they are back.
+type AwayMessage struct {
+	Away     bool `json:"away"`
+	TimeLeft int  `json:"time_left,omitempty"`
+}
+
+// QueueMessage tells a waiting client how many are ahead of it, counting
+// itself, whenever that changes
+type QueueMessage struct {
+	Position int `json:"position"`
+}
+
+// MatchFoundMessage starts a game. Player always moves first, within
+// TimeLeft milliseconds if the server has a move clock. Resume is the
+// token for rejoining the game if the connection drops.
+type MatchFoundMessage struct {
+	Side     int    `json:"side"` // The client's side
+	Opponent string `json:"opponent"`
+	TimeLeft int    `json:"time_left,omitempty"`
+	Resume   string `json:"resume,omitempty"`
+}
+
+// MoveMessage is a move. Side, Hash and TimeLeft are only set by the
+// server, Hash being the HashBoard of its board after the move, with the
+// sides numbered as the server numbers them, for clients to check theirs
+// against. TimeLeft is the milliseconds the side to move next has to move
+// in, 0 if the server has no move clock.
+type MoveMessage struct {
+	Column   int    `json:"column"`
+	Side     int    `json:"side,omitempty"`
+	Hash     uint64 `json:"hash,omitempty"`
+	TimeLeft int    `json:"time_left,omitempty"`
+}
+
+// MoveAckMessage confirms the client's move was played, with the board's
+// hash after it and the opponent's time left as in MoveMessage
+type MoveAckMessage struct {
+	Column   int    `json:"column"`
+	Hash     uint64 `json:"hash,omitempty"`
+	TimeLeft int    `json:"time_left,omitempty"`
+}
+
+// MoveRejectMessage refuses the client's move. Column echoes the move
+// refused, which may be off the board.
+type MoveRejectMessage struct {
+	Column int    `json:"column"`
+	Code   string `json:"code"` // One of the reject reasons
+	Reason string `json:"reason"`
+}
+
+// GameOverMessage ends a game; Winner is Empty for a tie
+type GameOverMessage struct {
+	Winner int    `json:"winner"`
+	Reason string `json:"reason"` // One of the gameOver reasons
+}
+
+// ChatMessage is a line of chat. Side is only set on the lines the server
+// passes on to spectators, saying which side said it.
+type ChatMessage struct {
+	Text string `json:"text"`
+	Side int    `json:"side,omitempty"`
+}
+
+// RematchMessage offers to play again once a game is over
+type RematchMessage struct{}
+
+// ResignMessage gives up the game in progress
+type ResignMessage struct{}
+
+// DrawOfferMessage offers a draw. The game is drawn once both players have
+// offered; an offer lapses once either player moves.
+type DrawOfferMessage struct{}
+
+// SyncMessage carries the columns played so far, in order, with the first
+// move by Player, and the time left as in MoveMessage. Clients send it empty
+// to ask the server for the game.
+type SyncMessage struct {
+	Moves    []int `json:"moves,omitempty"`
+	TimeLeft int   `json:"time_left,omitempty"`
+}
+
+// PingMessage asks for a PongMessage with the same Nonce
+type PingMessage struct {
+	Nonce int `json:"nonce"`
+}
+
+// PongMessage answers a ping
+type PongMessage struct {
+	Nonce int `json:"nonce"`
+}
+
+func (HelloMessage) wireType() string       { return wireHello }
+func (SpectateMessage) wireType() string    { return wireSpectate }
+func (SpectatingMessage) wireType() string  { return wireSpectating }
+func (RatingMessage) wireType() string      { return wireRating }
+func (SignedInMessage) wireType() string    { return wireSignedIn }
+func (StandingsMessage) wireType() string   { return wireStandings }
+func (ResumeMessage) wireType() string      { return wireResume }
+func (ResumedMessage) wireType() string     { return wireResumed }
+func (AwayMessage) wireType() string        { return wireAway }
+func (LeaderboardMessage) wireType() string { return wireLeaderboard }
+func (QueueMessage) wireType() string       { return wireQueue }
+func (MatchFoundMessage) wireType() string  { return wireMatchFound }
+func (MoveMessage) wireType() string        { return wireMove }
+func (MoveAckMessage) wireType() string     { return wireMoveAck }
+func (MoveRejectMessage) wireType() string  { return wireMoveReject }
+func (GameOverMessage) wireType() string    { return wireGameOver }
+func (ChatMessage) wireType() string        { return wireChat }
+func (RematchMessage) wireType() string     { return wireRematch }
+func (ResignMessage) wireType() string      { return wireResign }
+func (DrawOfferMessage) wireType() string   { return wireDrawOffer }
+func (SyncMessage) wireType() string        { return wireSync }
+func (PingMessage) wireType() string        { return wirePing }
+func (PongMessage) wireType() string        { return wirePong }
+
+func (m HelloMessage) validate() error {
+	if len(m.Token) > maxTokenLength || ((m.Token != "" || m.Password != "") && m.Name == "") {
+		return errSessionToken
+	}
+	if err := checkText(m.Password, maxPasswordLength, true); err != nil {
+		return err
+	}
+	return checkText(m.Name, maxWireNameLength, true)
+}
+func (m SpectateMessage) validate() error { return checkText(m.Name, maxWireNameLength, true) }
+func (m SpectatingMessage) validate() error {
+	for _, name := range m.Players {
+		if err := checkText(name, maxWireNameLength, true); err != nil {
+			return err
+		}
+	}
+	return checkMoves(m.Moves)
+}
+func (m RatingMessage) validate() error {
+	if m.Rating < 0 {
+		return errRatingRange
+	}
+	return nil
+}
+func (m SignedInMessage) validate() error {
+	if m.Token == "" || len(m.Token) > maxTokenLength {
+		return errSessionToken
+	}
+	return nil
+}
+func (m StandingsMessage) validate() error { return checkText(m.Name, maxWireNameLength, true) }
+func (m ResumeMessage) validate() error    { return checkResume(m.Token, false) }
+func (m ResumedMessage) validate() error {
+	if err := checkTimeLeft(m.TimeLeft); err != nil {
+		return err
+	}
+	return checkMoves(m.Moves)
+}
+func (m AwayMessage) validate() error { return checkTimeLeft(m.TimeLeft) }
+func (m LeaderboardMessage) validate() error {
+	if len(m.Top) > maxLeaderboardSize {
+		return errTooManyRanks
+	}
+	for _, standing := range m.Top {
+		if err := standing.validate(); err != nil {
+			return err
+		}
+	}
+	if m.Player != nil {
+		return m.Player.validate()
+	}
+	return nil
+}
+func (m QueueMessage) validate() error {
+	if m.Position < 1 {
+		return errQueueRange
+	}
+	return nil
+}
+func (m MatchFoundMessage) validate() error {
+	if err := checkSide(m.Side, false); err != nil {
+		return err
+	}
+	if err := checkTimeLeft(m.TimeLeft); err != nil {
+		return err
+	}
+	if err := checkResume(m.Resume, true); err != nil {
+		return err
+	}
+	return checkText(m.Opponent, maxWireNameLength, true)
+}
+func (m MoveMessage) validate() error {
+	if err := checkColumn(m.Column); err != nil {
+		return err
+	}
+	if err := checkTimeLeft(m.TimeLeft); err != nil {
+		return err
+	}
+	return checkSide(m.Side, true)
+}
+func (m MoveAckMessage) validate() error {
+	if err := checkColumn(m.Column); err != nil {
+		return err
+	}
+	return checkTimeLeft(m.TimeLeft)
+}
+func (m MoveRejectMessage) validate() error { return checkRejectCode(m.Code) }
+func (m GameOverMessage) validate() error   { return checkSide(m.Winner, true) }
+func (m ChatMessage) validate() error {
+	if err := checkText(m.Text, maxChatWireLength, false); err != nil {
+		return err
+	}
+	return checkSide(m.Side, true)
+}
+func (RematchMessage) validate() error   { return nil }
+func (ResignMessage) validate() error    { return nil }
+func (DrawOfferMessage) validate() error { return nil }
+func (m SyncMessage) validate() error {
+	if err := checkTimeLeft(m.TimeLeft); err != nil {
+		return err
+	}
+	return checkMoves(m.Moves)
+}
+func (PingMessage) validate() error { return nil }
+func (PongMessage) validate() error { return nil }
+
+// wireTypes makes an empty message of each type, for decoding into
+var wireTypes = map[string]func() wireMessage{
+	wireHello:       func() wireMessage { return &HelloMessage{} },
+	wireSpectate:    func() wireMessage { return &SpectateMessage{} },
+	wireSpectating:  func() wireMessage { return &SpectatingMessage{} },
+	wireRating:      func() wireMessage { return &RatingMessage{} },
+	wireSignedIn:    func() wireMessage { return &SignedInMessage{} },
+	wireStandings:   func() wireMessage { return &StandingsMessage{} },
+	wireResume:      func() wireMessage { return &ResumeMessage{} },
+	wireResumed:     func() wireMessage { return &ResumedMessage{} },
+	wireAway:        func() wireMessage { return &AwayMessage{} },
+	wireLeaderboard: func() wireMessage { return &LeaderboardMessage{} },
+	wireQueue:       func() wireMessage { return &QueueMessage{} },
+	wireMatchFound:  func() wireMessage { return &MatchFoundMessage{} },
+	wireMove:        func() wireMessage { return &MoveMessage{} },
+	wireMoveAck:     func() wireMessage { return &MoveAckMessage{} },
+	wireMoveReject:  func() wireMessage { return &MoveRejectMessage{} },
+	wireGameOver:    func() wireMessage { return &GameOverMessage{} },
+	wireChat:        func() wireMessage { return &ChatMessage{} },
+	wireRematch:     func() wireMessage { return &RematchMessage{} },
+	wireResign:      func() wireMessage { return &ResignMessage{} },
+	wireDrawOffer:   func() wireMessage { return &DrawOfferMessage{} },
+	wireSync:        func() wireMessage { return &SyncMessage{} },
+	wirePing:        func() wireMessage { return &PingMessage{} },
+	wirePong:        func() wireMessage { return &PongMessage{} },
+}
+
+// checkColumn rejects columns off the board
+func checkColumn(col int) error {
+	if col < 0 || col >= Columns {
+		return errColumnRange
+	}
+	return nil
+}
+
+// checkTimeLeft rejects a negative time left
+func checkTimeLeft(ms int) error {
+	if ms < 0 {
+		return errTimeRange
+	}
+	return nil
+}
+
+// checkResume rejects a resume token that is too long, or empty unless
+// allowed
+func checkResume(token string, emptyOK bool) error {
+	if (token == "" && !emptyOK) || len(token) > maxResumeLength {
+		return errResumeToken
+	}
+	return nil
+}
+
+// checkMoves rejects a move list longer than the board holds or with a
+// column off the board
+func checkMoves(moves []int) error {
+	if len(moves) > Rows*Columns {
+		return errTooManyMoves
+	}
+	for _, col := range moves {
+		if err := checkColumn(col); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validate rejects a standing with an impossible rank or record
+func (s OnlineStanding) validate() error {
+	if s.Rank < 1 || s.Rating < 0 || s.Games < 0 || s.Wins < 0 || s.Wins > s.Games {
+		return errStanding
+	}
+	return checkText(s.Name, maxWireNameLength, false)
+}
+
+// checkRejectCode rejects codes that aren't a reject reason
+func checkRejectCode(code string) error {
+	switch code {
+	case rejectNotYourTurn, rejectOffBoard, rejectColumnFull, rejectGameOver:
+		return nil
+	}
+	return errRejectCode
+}
+
+// checkSide rejects anything but Player or Computer, and Empty if allowed
+func checkSide(side int, emptyOK bool) error {
+	if side == Player || side == Computer || (emptyOK && side == Empty) {
+		return nil
+	}
+	return errSideRange
+}
+
+// checkText rejects text over max characters, and empty text unless allowed
+func checkText(s string, max int, emptyOK bool) error {
+	if s == "" && !emptyOK {
+		return errEmptyText
+	}
+	if utf8.RuneCountInString(s) > max {
+		return errTextTooLong
+	}
+	return nil
+}
+
+// truncateText cuts s to at most max characters
+func truncateText(s string, max int) string {
+	if runes := []rune(s); len(runes) > max {
+		return string(runes[:max])
+	}
+	return s
+}
+
+// Message is the envelope every message travels in: its type, the protocol
+// version and the message's own fields as the payload
+type Message struct {
+	Type    string          `json:"type"`
+	Version int             `json:"version"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// newMessage validates msg and wraps it in its envelope
+func newMessage(msg wireMessage) (Message, error) {
+	if err := msg.validate(); err != nil {
+		return Message{}, err
+	}
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return Message{}, err
+	}
+	return Message{Type: msg.wireType(), Version: protocolVersion, Payload: payload}, nil
+}
+
+// open decodes and validates the message in the envelope. Fields it doesn't
+// know are ignored, so a newer peer can add optional ones, but unknown types
+// and other versions are refused. A message that decodes but fails
+// validation is returned along with the error, so it can be answered.
+func (m Message) open() (wireMessage, error) {
+	if m.Version != protocolVersion {
+		return nil, fmt.Errorf("%w %d", errWrongVersion, m.Version)
+	}
+	empty, ok := wireTypes[m.Type]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", errUnknownType, m.Type)
+	}
+	msg := empty()
+	if len(m.Payload) > 0 {
+		if err := json.Unmarshal(m.Payload, msg); err != nil {
+			return nil, fmt.Errorf("%w: %v", errBadMessage, err)
+		}
+	}
+	// Hand back the message itself rather than the pointer decoded into
+	msg = reflect.ValueOf(msg).Elem().Interface().(wireMessage)
+	if err := msg.validate(); err != nil {
+		return msg, err
+	}
+	return msg, nil
+}
+
+// marshalWire encodes a message as the JSON of its envelope
+func marshalWire(msg wireMessage) ([]byte, error) {
+	m, err := newMessage(msg)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(m)
+}
+
+// unmarshalWire decodes and validates the JSON of a message's envelope, as
+// Message.open does
+func unmarshalWire(data []byte) (wireMessage, error) {
+	var m Message
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("%w: %v", errBadMessage, err)
+	}
+	return m.open()
+}
+
+// frameHeaderSize is the length of a frame's header, its length in bytes
+// as a big-endian uint32
+const frameHeaderSize = 4
+
+// EncodeMessage writes m to w as one frame: the length of its JSON, then
+// the JSON. The frame goes in a single write, so writers taking turns on a
+// connection can't interleave.
+func EncodeMessage(w io.Writer, m Message) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if len(data) > maxMessageSize {
+		return errMessageTooLarge
+	}
+	frame := make([]byte, frameHeaderSize, frameHeaderSize+len(data))
+	binary.BigEndian.PutUint32(frame, uint32(len(data)))
+	_, err = w.Write(append(frame, data...))
+	return err
+}
+
+// DecodeMessage reads one frame from r, however many reads it arrives in.
+// A frame whose JSON isn't a Message is read whole and reported as a bad
+// message. One longer than maxMessageSize fails with errMessageTooLarge
+// without being read, and one cut short with io.ErrUnexpectedEOF.
+func DecodeMessage(r io.Reader) (Message, error) {
+	var header [frameHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return Message{}, err
+	}
+	size := binary.BigEndian.Uint32(header[:])
+	if size > maxMessageSize {
+		return Message{}, errMessageTooLarge
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return Message{}, err
+	}
+	var m Message
+	if err := json.Unmarshal(data, &m); err != nil {
+		return Message{}, fmt.Errorf("%w: %v", errBadMessage, err)
+	}
+	return m, nil
+}
+
+// wireCodec reads and writes messages on a connection, one frame each. It
+// is the only way client and server touch the wire.
+type wireCodec struct {
+	r io.Reader
+	w io.Writer
+}
+
+// newWireCodec wraps a connection
+func newWireCodec(rw io.ReadWriter) *wireCodec {
+	return &wireCodec{r: rw, w: rw}
+}
+
+// write sends one message
+func (c *wireCodec) write(msg wireMessage) error {
+	m, err := newMessage(msg)
+	if err != nil {
+		return err
+	}
+	return EncodeMessage(c.w, m)
+}
+
+// read waits for the next message. Errors wrapping errBadMessage leave the
+// codec ready for the next one; any other error means the connection failed.
+func (c *wireCodec) read() (wireMessage, error) {
+	m, err := DecodeMessage(c.r)
+	if err != nil {
+		return nil, err
+	}
+	return m.open()
+}
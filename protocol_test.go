@@ -0,0 +1,418 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// wireGolden is each message type as its frame carries it. Changing any of
+// these breaks peers on the same protocol version.
+var wireGolden = []struct {
+	msg  wireMessage
+	json string
+}{
+	{HelloMessage{Name: "alice", Rated: true, Token: "1711972800.5f0c"},
+		`{"type":"hello","version":1,"payload":{"name":"alice","rated":true,"token":"1711972800.5f0c"}}`},
+	{HelloMessage{Name: "alice", Rated: true, Password: "hunter22"},
+		`{"type":"hello","version":1,"payload":{"name":"alice","rated":true,"password":"hunter22"}}`},
+	{SignedInMessage{Token: "1711972800.5f0c"}, `{"type":"signed_in","version":1,"payload":{"token":"1711972800.5f0c"}}`},
+	{SpectateMessage{Name: "carol"}, `{"type":"spectate","version":1,"payload":{"name":"carol"}}`},
+	{SpectatingMessage{Players: [2]string{"alice", "bob"}, Moves: []int{3, 3, 4}},
+		`{"type":"spectating","version":1,"payload":{"players":["alice","bob"],"moves":[3,3,4]}}`},
+	{RatingMessage{Rating: 1216}, `{"type":"rating","version":1,"payload":{"rating":1216}}`},
+	{StandingsMessage{Name: "alice"}, `{"type":"standings","version":1,"payload":{"name":"alice"}}`},
+	{LeaderboardMessage{
+		Top:    []OnlineStanding{{Rank: 1, Name: "bob", Rating: 1300, Games: 4, Wins: 3}},
+		Player: &OnlineStanding{Rank: 2, Name: "alice", Rating: 1200, Games: 1, Wins: 0},
+	}, `{"type":"leaderboard","version":1,"payload":{"top":[{"rank":1,"name":"bob","rating":1300,"games":4,"wins":3}],` +
+		`"player":{"rank":2,"name":"alice","rating":1200,"games":1,"wins":0}}}`},
+	{ResumeMessage{Token: "5f0c"}, `{"type":"resume","version":1,"payload":{"token":"5f0c"}}`},
+	{ResumedMessage{Moves: []int{3, 4}, Hash: 0x1234, TimeLeft: 1500},
+		`{"type":"resumed","version":1,"payload":{"moves":[3,4],"hash":4660,"time_left":1500}}`},
+	{AwayMessage{Away: true, TimeLeft: 1500}, `{"type":"away","version":1,"payload":{"away":true,"time_left":1500}}`},
+	{AwayMessage{}, `{"type":"away","version":1,"payload":{"away":false}}`},
+	{QueueMessage{Position: 2}, `{"type":"queue","version":1,"payload":{"position":2}}`},
+	{MatchFoundMessage{Side: Computer, Opponent: "bob", TimeLeft: 30000, Resume: "5f0c"},
+		`{"type":"match_found","version":1,"payload":{"side":2,"opponent":"bob","time_left":30000,"resume":"5f0c"}}`},
+	{MoveMessage{Column: 3}, `{"type":"move","version":1,"payload":{"column":3}}`},
+	{MoveMessage{Column: 0, Side: Player, Hash: 0x1234, TimeLeft: 1500},
+		`{"type":"move","version":1,"payload":{"column":0,"side":1,"hash":4660,"time_left":1500}}`},
+	{MoveAckMessage{Column: 6, Hash: 0x1234}, `{"type":"move_ack","version":1,"payload":{"column":6,"hash":4660}}`},
+	{MoveRejectMessage{Column: 2, Code: rejectColumnFull, Reason: "column is full"},
+		`{"type":"move_reject","version":1,"payload":{"column":2,"code":"column_full","reason":"column is full"}}`},
+	{GameOverMessage{Winner: Empty, Reason: gameOverFull}, `{"type":"game_over","version":1,"payload":{"winner":0,"reason":"full"}}`},
+	{ChatMessage{Text: "good game"}, `{"type":"chat","version":1,"payload":{"text":"good game"}}`},
+	{RematchMessage{}, `{"type":"rematch","version":1,"payload":{}}`},
+	{ResignMessage{}, `{"type":"resign","version":1,"payload":{}}`},
+	{DrawOfferMessage{}, `{"type":"draw_offer","version":1,"payload":{}}`},
+	{SyncMessage{}, `{"type":"sync","version":1,"payload":{}}`},
+	{SyncMessage{Moves: []int{3, 4}, TimeLeft: 1500}, `{"type":"sync","version":1,"payload":{"moves":[3,4],"time_left":1500}}`},
+	{PingMessage{Nonce: 7}, `{"type":"ping","version":1,"payload":{"nonce":7}}`},
+	{PongMessage{Nonce: 7}, `{"type":"pong","version":1,"payload":{"nonce":7}}`},
+}
+
+func TestWireGolden(t *testing.T) {
+	covered := map[string]bool{}
+	for _, tc := range wireGolden {
+		covered[tc.msg.wireType()] = true
+
+		data, err := marshalWire(tc.msg)
+		if err != nil {
+			t.Errorf("marshalWire(%#v): %v", tc.msg, err)
+		} else if string(data) != tc.json {
+			t.Errorf("marshalWire(%#v)\n got %s\nwant %s", tc.msg, data, tc.json)
+		}
+
+		msg, err := unmarshalWire([]byte(tc.json))
+		if err != nil {
+			t.Errorf("unmarshalWire(%s): %v", tc.json, err)
+		} else if !reflect.DeepEqual(msg, tc.msg) {
+			t.Errorf("unmarshalWire(%s) = %#v, want %#v", tc.json, msg, tc.msg)
+		}
+	}
+	for wireType := range wireTypes {
+		if !covered[wireType] {
+			t.Errorf("no golden message of type %q", wireType)
+		}
+	}
+}
+
+func TestWireRejectsUnknownTypes(t *testing.T) {
+	for _, data := range []string{
+		`{"type":"teleport","version":1,"column":3}`,
+		`{"type":"","version":1}`,
+		`{"version":1}`,
+	} {
+		msg, err := unmarshalWire([]byte(data))
+		if !errors.Is(err, errUnknownType) || !errors.Is(err, errBadMessage) {
+			t.Errorf("unmarshalWire(%s) = %v, want an unknown type", data, err)
+		}
+		if msg != nil {
+			t.Errorf("unmarshalWire(%s) decoded %#v", data, msg)
+		}
+	}
+}
+
+func TestWireRejectsOtherVersions(t *testing.T) {
+	for _, data := range []string{
+		`{"type":"move","version":2,"column":3}`,
+		`{"type":"move","version":0,"column":3}`,
+		`{"type":"move","column":3}`,
+	} {
+		if _, err := unmarshalWire([]byte(data)); !errors.Is(err, errWrongVersion) {
+			t.Errorf("unmarshalWire(%s) = %v, want a wrong version", data, err)
+		}
+	}
+}
+
+func TestWireRejectsMalformedJSON(t *testing.T) {
+	for _, data := range []string{
+		`{"type":"move","version":1,"payload":{"column":"three"}}`,
+		`{"type":"move","version":1,"payload":{"column":3}`,
+		`{"type":"move","version":1,"payload":[3]}`,
+		`[1,2,3]`,
+	} {
+		if _, err := unmarshalWire([]byte(data)); !errors.Is(err, errBadMessage) {
+			t.Errorf("unmarshalWire(%s) = %v, want a bad message", data, err)
+		}
+	}
+}
+
+func TestWireChecksColumns(t *testing.T) {
+	for _, col := range []int{-1, Columns, 100} {
+		for _, msg := range []wireMessage{
+			MoveMessage{Column: col},
+			MoveAckMessage{Column: col},
+			SyncMessage{Moves: []int{3, col}},
+			SpectatingMessage{Players: [2]string{"a", "b"}, Moves: []int{col}},
+		} {
+			if _, err := marshalWire(msg); !errors.Is(err, errColumnRange) {
+				t.Errorf("marshalWire(%#v) = %v, want a column out of range", msg, err)
+			}
+		}
+	}
+
+	// A decoded move off the board comes back with the error, so the server
+	// can say which column it refused
+	msg, err := unmarshalWire([]byte(`{"type":"move","version":1,"payload":{"column":7}}`))
+	if !errors.Is(err, errColumnRange) {
+		t.Fatalf("unmarshalWire of column 7 = %v, want a column out of range", err)
+	}
+	if move, ok := msg.(MoveMessage); !ok || move.Column != 7 {
+		t.Errorf("unmarshalWire of column 7 returned %#v", msg)
+	}
+
+	// The edges of the board are fine
+	for _, col := range []int{0, Columns - 1} {
+		if _, err := marshalWire(MoveMessage{Column: col}); err != nil {
+			t.Errorf("marshalWire of column %d: %v", col, err)
+		}
+	}
+}
+
+func TestWireChecksFields(t *testing.T) {
+	long := string(make([]rune, maxWireNameLength+1))
+	for _, tc := range []struct {
+		msg  wireMessage
+		want error
+	}{
+		{HelloMessage{Name: long}, errTextTooLong},
+		{HelloMessage{Name: "alice", Token: strings.Repeat("f", maxTokenLength+1)}, errSessionToken},
+		{HelloMessage{Token: "1711972800.5f0c"}, errSessionToken},
+		{HelloMessage{Password: "hunter22"}, errSessionToken},
+		{HelloMessage{Name: "alice", Password: strings.Repeat("p", maxPasswordLength+1)}, errTextTooLong},
+		{SignedInMessage{}, errSessionToken},
+		{SignedInMessage{Token: strings.Repeat("f", maxTokenLength+1)}, errSessionToken},
+		{ChatMessage{}, errEmptyText},
+		{MatchFoundMessage{Side: Empty}, errSideRange},
+		{MatchFoundMessage{Side: Player, Resume: strings.Repeat("f", maxResumeLength+1)}, errResumeToken},
+		{ResumeMessage{}, errResumeToken},
+		{ResumedMessage{Moves: []int{Columns}}, errColumnRange},
+		{ResumedMessage{TimeLeft: -1}, errTimeRange},
+		{AwayMessage{TimeLeft: -1}, errTimeRange},
+		{SyncMessage{TimeLeft: -1}, errTimeRange},
+		{MoveMessage{Column: 0, Side: 3}, errSideRange},
+		{MoveMessage{Column: 0, TimeLeft: -1}, errTimeRange},
+		{QueueMessage{Position: 0}, errQueueRange},
+		{RatingMessage{Rating: -1}, errRatingRange},
+		{MoveRejectMessage{Code: "nope"}, errRejectCode},
+		{SyncMessage{Moves: make([]int, Rows*Columns+1)}, errTooManyMoves},
+		{LeaderboardMessage{Top: []OnlineStanding{{Rank: 1, Name: "a", Games: 1, Wins: 2}}}, errStanding},
+		{LeaderboardMessage{Top: make([]OnlineStanding, maxLeaderboardSize+1)}, errTooManyRanks},
+	} {
+		if err := tc.msg.validate(); !errors.Is(err, tc.want) {
+			t.Errorf("%#v.validate() = %v, want %v", tc.msg, err, tc.want)
+		}
+	}
+}
+
+func TestWireIgnoresUnknownFields(t *testing.T) {
+	// A newer peer on the same version may add optional fields, anywhere,
+	// and leave out a payload with nothing in it
+	for _, tc := range []struct {
+		json string
+		want wireMessage
+	}{
+		{`{"type":"move","version":1,"payload":{"column":4,"premove":true}}`, MoveMessage{Column: 4}},
+		{`{"emote":"wave","type":"chat","version":1,"payload":{"text":"hi"}}`, ChatMessage{Text: "hi"}},
+		{`{"type":"rematch","version":1,"payload":{"best_of":3}}`, RematchMessage{}},
+		{`{"type":"rematch","version":1}`, RematchMessage{}},
+		{`{"type":"match_found","version":1,"payload":{"side":1,"opponent":"bob","clock":{"increment":2}}}`,
+			MatchFoundMessage{Side: Player, Opponent: "bob"}},
+	} {
+		msg, err := unmarshalWire([]byte(tc.json))
+		if err != nil {
+			t.Errorf("unmarshalWire(%s): %v", tc.json, err)
+		} else if !reflect.DeepEqual(msg, tc.want) {
+			t.Errorf("unmarshalWire(%s) = %#v, want %#v", tc.json, msg, tc.want)
+		}
+	}
+}
+
+// readWriter reads from r and writes to w, to read a codec's stream back in
+// pieces
+type readWriter struct {
+	io.Reader
+	io.Writer
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	var stream bytes.Buffer
+	writer := newWireCodec(&stream)
+	for _, tc := range wireGolden {
+		if err := writer.write(tc.msg); err != nil {
+			t.Fatalf("writing %#v: %v", tc.msg, err)
+		}
+	}
+	sent := stream.String()
+
+	for _, split := range []struct {
+		name string
+		wrap func(io.Reader) io.Reader
+	}{
+		{"whole", func(r io.Reader) io.Reader { return r }},
+		{"one byte", iotest.OneByteReader},
+		{"half", iotest.HalfReader},
+		{"data with EOF", iotest.DataErrReader},
+	} {
+		t.Run(split.name, func(t *testing.T) {
+			reader := newWireCodec(readWriter{split.wrap(strings.NewReader(sent)), io.Discard})
+			for _, tc := range wireGolden {
+				msg, err := reader.read()
+				if err != nil {
+					t.Fatalf("reading %s: %v", tc.msg.wireType(), err)
+				}
+				if !reflect.DeepEqual(msg, tc.msg) {
+					t.Errorf("read %#v, want %#v", msg, tc.msg)
+				}
+			}
+			if _, err := reader.read(); err != io.EOF {
+				t.Errorf("read past the end = %v, want EOF", err)
+			}
+		})
+	}
+}
+
+func TestCodecAcrossWrites(t *testing.T) {
+	// Frames split over several writes on a connection, even through their
+	// headers, are read whole
+	var stream bytes.Buffer
+	codec := newWireCodec(&stream)
+	codec.write(MoveMessage{Column: 5})
+	codec.write(ResignMessage{})
+	sent := stream.Bytes()
+
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+	go func() {
+		for _, cut := range [][2]int{{0, 2}, {2, 9}, {9, len(sent) - 3}, {len(sent) - 3, len(sent)}} {
+			client.Write(sent[cut[0]:cut[1]])
+		}
+	}()
+
+	codec = newWireCodec(server)
+	for _, want := range []wireMessage{MoveMessage{Column: 5}, ResignMessage{}} {
+		msg, err := codec.read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(msg, want) {
+			t.Errorf("read %#v, want %#v", msg, want)
+		}
+	}
+}
+
+// frame makes a frame of data as it is, for messages the codec would
+// refuse to send
+func frame(data string) []byte {
+	return append(binary.BigEndian.AppendUint32(nil, uint32(len(data))), data...)
+}
+
+func TestCodecSurvivesBadMessages(t *testing.T) {
+	var stream []byte
+	for _, data := range []string{
+		`{"type":"teleport","version":1}`,
+		`{"type":"move","version":1,"payload":{"column":9}}`,
+		`not json at all`,
+		`{"type":"draw_offer","version":1}`,
+	} {
+		stream = append(stream, frame(data)...)
+	}
+	codec := newWireCodec(readWriter{iotest.OneByteReader(bytes.NewReader(stream)), io.Discard})
+
+	if _, err := codec.read(); !errors.Is(err, errBadMessage) {
+		t.Errorf("unknown type read as %v", err)
+	}
+	if _, err := codec.read(); !errors.Is(err, errColumnRange) {
+		t.Errorf("column 9 read as %v", err)
+	}
+	if _, err := codec.read(); !errors.Is(err, errBadMessage) {
+		t.Errorf("a frame that isn't JSON read as %v", err)
+	}
+	if msg, err := codec.read(); err != nil || msg != (DrawOfferMessage{}) {
+		t.Errorf("read %#v, %v after bad messages, want the draw offer", msg, err)
+	}
+}
+
+func TestCodecRefusesInvalidMessages(t *testing.T) {
+	var stream bytes.Buffer
+	if err := newWireCodec(&stream).write(MoveMessage{Column: Columns}); !errors.Is(err, errColumnRange) {
+		t.Errorf("writing column %d = %v", Columns, err)
+	}
+	if stream.Len() != 0 {
+		t.Errorf("an invalid message was sent: %q", stream.String())
+	}
+}
+
+func TestEncodeDecodeMessage(t *testing.T) {
+	// Every message type round-trips through its envelope and frame
+	var stream bytes.Buffer
+	for _, tc := range wireGolden {
+		m, err := newMessage(tc.msg)
+		if err != nil {
+			t.Fatalf("wrapping %#v: %v", tc.msg, err)
+		}
+		if err := EncodeMessage(&stream, m); err != nil {
+			t.Fatalf("encoding %#v: %v", tc.msg, err)
+		}
+	}
+	sent := stream.Bytes()
+
+	// However the stream is read
+	for _, split := range []struct {
+		name string
+		wrap func(io.Reader) io.Reader
+	}{
+		{"whole", func(r io.Reader) io.Reader { return r }},
+		{"one byte", iotest.OneByteReader},
+		{"half", iotest.HalfReader},
+		{"data with EOF", iotest.DataErrReader},
+	} {
+		r := split.wrap(bytes.NewReader(sent))
+		for _, tc := range wireGolden {
+			m, err := DecodeMessage(r)
+			if err != nil {
+				t.Fatalf("%s: decoding %s: %v", split.name, tc.msg.wireType(), err)
+			}
+			if m.Type != tc.msg.wireType() || m.Version != protocolVersion {
+				t.Errorf("%s: decoded a %q message of version %d, want %q", split.name, m.Type, m.Version, tc.msg.wireType())
+			}
+			if msg, err := m.open(); err != nil || !reflect.DeepEqual(msg, tc.msg) {
+				t.Errorf("%s: opened %#v, %v, want %#v", split.name, msg, err, tc.msg)
+			}
+		}
+		if _, err := DecodeMessage(r); err != io.EOF {
+			t.Errorf("%s: decoding past the end = %v, want EOF", split.name, err)
+		}
+	}
+
+	// A frame cut short, in its header or its JSON, has failed
+	one := frame(`{"type":"resign","version":1}`)
+	for _, cut := range []int{2, len(one) - 1} {
+		if _, err := DecodeMessage(bytes.NewReader(one[:cut])); err != io.ErrUnexpectedEOF {
+			t.Errorf("frame cut to %d bytes decoded as %v, want an unexpected EOF", cut, err)
+		}
+	}
+}
+
+// endless reads as bytes that never end
+type endless struct{}
+
+func (endless) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 'a'
+	}
+	return len(p), nil
+}
+
+func TestCodecRefusesOversizedMessages(t *testing.T) {
+	// A frame just over the limit fails the connection, before its JSON is
+	// read, however much follows
+	header := binary.BigEndian.AppendUint32(nil, maxMessageSize+1)
+	codec := newWireCodec(readWriter{io.MultiReader(bytes.NewReader(header), endless{}), io.Discard})
+	if _, err := codec.read(); !errors.Is(err, errMessageTooLarge) || errors.Is(err, errBadMessage) {
+		t.Errorf("oversized frame read as %v, want it too large", err)
+	}
+
+	// One right up to the limit is fine
+	padding := maxMessageSize - len(`{"type":"ping","version":1,"payload":{"nonce":1},"pad":""}`)
+	exact := frame(`{"type":"ping","version":1,"payload":{"nonce":1},"pad":"` + strings.Repeat("a", padding) + `"}`)
+	codec = newWireCodec(readWriter{bytes.NewReader(exact), io.Discard})
+	if msg, err := codec.read(); err != nil || msg != (PingMessage{Nonce: 1}) {
+		t.Errorf("message of %d bytes read as %#v, %v", len(exact)-frameHeaderSize, msg, err)
+	}
+
+	// And none bigger is sent
+	if err := EncodeMessage(io.Discard, Message{Type: wireChat, Version: protocolVersion,
+		Payload: []byte(`{"text":"` + strings.Repeat("a", maxMessageSize) + `"}`)}); !errors.Is(err, errMessageTooLarge) {
+		t.Errorf("encoding an oversized message = %v, want it too large", err)
+	}
+}
@@ -0,0 +1,490 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+//go:embed puzzles.txt
+var puzzleData string
+
+// puzzlePath is a JSON puzzle file to use instead of the bundled set; set by
+// the -puzzles flag
+var puzzlePath string
+
+// Longest forced win, in the player's moves, a puzzle prompt will announce
+const maxWinIn = (solverDepth + 1) / 2
+
+// Reasons a puzzle is refused
+var (
+	errPuzzleMover = errors.New("it isn't X's turn")
+	errNoForcedWin = errors.New("X has no forced win")
+)
+
+// Puzzle is a position where the player to move can force a win under the
+// standard rules. Its solutions are worked out as it is loaded, which takes
+// a while, so the set is loaded away from Update; see fetchPuzzles.
+type Puzzle struct {
+	board    GameBoard
+	solution []int // Winning columns stored with the puzzle, if any, checked against the solver
+	winIn    int   // Player moves needed to win
+	wins     []int // Columns that force a win, found by the solver
+}
+
+// puzzleLoad is the puzzle set as fetchPuzzles loaded it
+type puzzleLoad struct {
+	puzzles []*Puzzle
+	err     error
+}
+
+// puzzleFile is one entry of a JSON puzzle file
+type puzzleFile struct {
+	Position string `json:"position"`
+	Solution []int  `json:"solution"`
+	WinIn    int    `json:"win_in"`
+}
+
+// PuzzleStats tracks a user's puzzle progress
+type PuzzleStats struct {
+	Attempted  int `json:"attempted"`
+	Solved     int `json:"solved"`
+	Streak     int `json:"streak"`
+	BestStreak int `json:"best_streak"`
+	Next       int `json:"next"` // Index of the next puzzle to present
+
+	// Positions of the puzzles solved at least once
+	Completed []string `json:"completed,omitempty"`
+}
+
+// loadPuzzles parses the bundled puzzle set, skipping comments and blank lines
+func loadPuzzles(data string) ([]*Puzzle, error) {
+	puzzles := []*Puzzle{}
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		board, err := ParseBoard(line)
+		if err != nil {
+			return nil, fmt.Errorf("puzzle on line %d: %w", i+1, err)
+		}
+		puzzles = append(puzzles, &Puzzle{board: board})
+	}
+	if err := solvePuzzles(puzzles); err != nil {
+		return nil, err
+	}
+	return puzzles, nil
+}
+
+// loadPuzzlesJSON parses a puzzle file, a JSON list of positions with their
+// solutions, e.g. [{"position": "...", "solution": [3], "win_in": 2}]. Every
+// position has to have X to move and a win for X the solver can find.
+func loadPuzzlesJSON(data []byte) ([]*Puzzle, error) {
+	var entries []puzzleFile
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	puzzles := []*Puzzle{}
+	for i, entry := range entries {
+		board, err := ParseBoard(entry.Position)
+		if err != nil {
+			return nil, fmt.Errorf("puzzle %d: %w", i+1, err)
+		}
+		for _, col := range entry.Solution {
+			if col < 0 || col >= Columns {
+				return nil, fmt.Errorf("puzzle %d: solution column %d out of range", i+1, col)
+			}
+		}
+		puzzles = append(puzzles, &Puzzle{board: board, solution: entry.Solution, winIn: entry.WinIn})
+	}
+	if err := solvePuzzles(puzzles); err != nil {
+		return nil, err
+	}
+	return puzzles, nil
+}
+
+// solvePuzzles works out every puzzle's solutions, side by side as each
+// takes a while, reporting the first puzzle that can't be played
+func solvePuzzles(puzzles []*Puzzle) error {
+	errs := make([]error, len(puzzles))
+	var wg sync.WaitGroup
+	for i, p := range puzzles {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := p.solve(); err != nil {
+				errs[i] = fmt.Errorf("puzzle %d: %w", i+1, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// solve checks that the puzzle has X to move, as the game starts it with
+// the player to move, and works out the columns that force X's win and how
+// many moves it takes, unless stored with the puzzle. Only moves the
+// solver confirms count, so a stored solution it disagrees with can't mark
+// a losing move as right.
+func (p *Puzzle) solve() error {
+	if checkMover(p.board, Player) != nil || firstMover(p.board, Player) != Player {
+		return errPuzzleMover
+	}
+	p.wins = winningColumns(p.board, Player, solverDepth, standardRules)
+	if len(p.wins) == 0 {
+		return errNoForcedWin
+	}
+	for _, col := range p.solution {
+		if !containsColumn(p.wins, col) {
+			log.Printf("puzzle %s: ignoring stored solution %s, which the solver doesn't confirm",
+				FormatBoard(p.board), columnName(col))
+		}
+	}
+	for n := 1; p.winIn <= 0 && n <= maxWinIn; n++ {
+		if len(winningColumns(p.board, Player, 2*n-1, standardRules)) > 0 {
+			p.winIn = n
+		}
+	}
+	return nil
+}
+
+// readPuzzles loads the puzzle file given with -puzzles, falling back to the
+// bundled set if there is none or it can't be read
+func readPuzzles() ([]*Puzzle, error) {
+	if puzzlePath != "" {
+		data, err := os.ReadFile(puzzlePath)
+		if err == nil {
+			var puzzles []*Puzzle
+			puzzles, err = loadPuzzlesJSON(data)
+			if err == nil && len(puzzles) > 0 {
+				return puzzles, nil
+			}
+		}
+		if err != nil {
+			log.Printf("loading puzzles from %s: %v", puzzlePath, err)
+		}
+	}
+	return loadPuzzles(puzzleData)
+}
+
+// fetchPuzzles reads the puzzle set in the background, as solving it takes
+// too long to hold up Update
+func fetchPuzzles() <-chan puzzleLoad {
+	result := make(chan puzzleLoad, 1)
+	go func() {
+		puzzles, err := readPuzzles()
+		result <- puzzleLoad{puzzles: puzzles, err: err}
+	}()
+	return result
+}
+
+// solutions returns the columns that solve the puzzle
+func (p *Puzzle) solutions() []int {
+	return p.wins
+}
+
+// isSolution reports whether col is one of the puzzle's winning moves
+func (p *Puzzle) isSolution(col int) bool {
+	return containsColumn(p.solutions(), col)
+}
+
+// containsColumn reports whether cols includes col
+func containsColumn(cols []int, col int) bool {
+	for _, c := range cols {
+		if c == col {
+			return true
+		}
+	}
+	return false
+}
+
+// movesToWin returns how many moves the player needs to force the win
+func (p *Puzzle) movesToWin() int {
+	return p.winIn
+}
+
+// quickestWin returns the columns with which player wins soonest, and in how
+// many plies, searching no deeper than maxDepth; nil if there is no such win
+func quickestWin(board GameBoard, player, maxDepth int) ([]int, int) {
+	for depth := 1; depth <= maxDepth; depth += 2 {
+		if wins := winningColumns(board, player, depth, standardRules); len(wins) > 0 {
+			return wins, depth
+		}
+	}
+	return nil, 0
+}
+
+// refutation returns the computer's answer to a wrong move, and whether it
+// wins. A win comes as the whole line, the computer's quickest win against
+// the defence that holds out longest, ending with its four; otherwise it is
+// just the computer's best move.
+func refutation(board GameBoard) ([]int, bool) {
+	wins, depth := quickestWin(board, Computer, solverDepth-1)
+	if wins == nil {
+		return []int{getComputerMove(board, solverDepth-1, standardRules)}, false
+	}
+	line := []int{}
+	for {
+		board = dropPiece(board, wins[0], Computer)
+		line = append(line, wins[0])
+		if checkWin(board, Computer) {
+			return line, true
+		}
+		defence, longest := -1, 0
+		var next []int
+		for _, col := range getValidColumns(board) {
+			if w, d := quickestWin(dropPiece(board, col, Player), Computer, depth-2); w != nil && d > longest {
+				defence, longest, next = col, d, w
+			}
+		}
+		if defence < 0 {
+			return line, true
+		}
+		board = dropPiece(board, defence, Player)
+		line = append(line, defence)
+		wins, depth = next, longest
+	}
+}
+
+// record updates the stats with the outcome of an attempt
+func (s *PuzzleStats) record(solved bool) {
+	s.Attempted++
+	if solved {
+		s.Solved++
+		s.Streak++
+		if s.Streak > s.BestStreak {
+			s.BestStreak = s.Streak
+		}
+	} else {
+		s.Streak = 0
+	}
+	s.Next++
+}
+
+// complete marks the puzzle at position as solved, once
+func (s *PuzzleStats) complete(position string) {
+	for _, done := range s.Completed {
+		if done == position {
+			return
+		}
+	}
+	s.Completed = append(s.Completed, position)
+}
+
+// completedOf counts the puzzles in the set the user has solved
+func (s *PuzzleStats) completedOf(puzzles []*Puzzle) int {
+	done := map[string]bool{}
+	for _, position := range s.Completed {
+		done[position] = true
+	}
+	count := 0
+	for _, p := range puzzles {
+		if done[FormatBoard(p.board)] {
+			count++
+		}
+	}
+	return count
+}
+
+// puzzleStatsKey returns the storage key holding a user's puzzle stats
+func puzzleStatsKey(username string) string {
+	return "puzzles/" + userFileName(username) + ".json"
+}
+
+// userFileName turns a username into something safe to use in a storage key
+func userFileName(username string) string {
+	name := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, username)
+	if name == "" {
+		name = "default"
+	}
+	return name
+}
+
+// loadPuzzleStats reads a user's puzzle stats, starting fresh if none are saved
+func loadPuzzleStats(store Storage, username string) PuzzleStats {
+	stats := PuzzleStats{}
+	data, err := store.Load(puzzleStatsKey(username))
+	if err != nil {
+		return stats
+	}
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return PuzzleStats{}
+	}
+	return stats
+}
+
+// savePuzzleStats writes a user's puzzle stats to storage
+func savePuzzleStats(store Storage, username string, stats PuzzleStats) error {
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return err
+	}
+	return store.Save(puzzleStatsKey(username), data)
+}
+
+// openPuzzles shows the puzzle screen with the user's next puzzle. The
+// first time, the screen waits while the puzzles load in the background.
+func (g *ConnectFourGame) openPuzzles() {
+	g.puzzleStats = loadPuzzleStats(g.userStore, g.username)
+	if g.puzzles == nil {
+		if g.puzzleFetch == nil {
+			g.puzzleFetch = fetchPuzzles()
+		}
+		g.puzzle = nil
+		g.transitionTo(StatePuzzle)
+		return
+	}
+	if g.startPuzzle() {
+		g.transitionTo(StatePuzzle)
+	}
+}
+
+// updatePuzzleFetch takes in the puzzle set once it has loaded, presenting
+// the first puzzle if the puzzle screen is waiting for it. Without puzzles
+// the screen goes back to the menu.
+func (g *ConnectFourGame) updatePuzzleFetch() {
+	if g.puzzleFetch == nil {
+		return
+	}
+	var result puzzleLoad
+	select {
+	case result = <-g.puzzleFetch:
+	default:
+		return
+	}
+	g.puzzleFetch = nil
+
+	if result.err != nil {
+		log.Printf("loading puzzles: %v", result.err)
+	}
+	g.puzzles = result.puzzles
+	if g.state != StatePuzzle || g.puzzle != nil {
+		return
+	}
+	if g.startPuzzle() {
+		g.initUI()
+	} else {
+		g.showToast(tr("puzzle.unavailable"))
+		g.transitionTo(StateGameMode)
+	}
+}
+
+// startPuzzle presents the user's next puzzle, returning false if none are available
+func (g *ConnectFourGame) startPuzzle() bool {
+	if len(g.puzzles) == 0 {
+		return false
+	}
+
+	g.puzzle = g.puzzles[g.puzzleStats.Next%len(g.puzzles)]
+	g.setBoard(g.puzzle.board)
+	g.puzzleAnswered = false
+	g.puzzleMessage = ""
+	g.puzzleRefutation = ""
+	g.puzzleWinIn = g.puzzle.movesToWin()
+	g.dropAnim = nil
+	g.hoverColumn = -1
+	g.isHovering = false
+	return true
+}
+
+// answerPuzzle plays the chosen column and checks it against the solver
+func (g *ConnectFourGame) answerPuzzle(col int) {
+	solved := g.puzzle.isSolution(col)
+	g.setBoard(dropPiece(g.board, col, Player))
+	g.animateDrop(col)
+
+	if solved {
+		g.puzzleMessage = tr("puzzle.correct")
+	} else {
+		if wins := g.puzzle.solutions(); len(wins) == 1 {
+			g.puzzleMessage = tr("puzzle.wrong_one", columnNames(wins))
+		} else {
+			g.puzzleMessage = tr("puzzle.wrong_many", columnNames(wins))
+		}
+		if !checkWin(g.board, Player) && len(getValidColumns(g.board)) > 0 {
+			line, wins := refutation(g.board)
+			if wins {
+				g.puzzleRefutation = tr("puzzle.refuted_win", columnNames(line))
+			} else {
+				g.puzzleRefutation = tr("puzzle.refuted_hold", columnName(line[0]))
+			}
+		}
+	}
+
+	g.puzzleStats.record(solved)
+	if solved {
+		g.puzzleStats.complete(FormatBoard(g.puzzle.board))
+	}
+	if err := savePuzzleStats(g.userStore, g.username, g.puzzleStats); err != nil {
+		log.Printf("saving puzzle stats: %v", err)
+	}
+
+	g.puzzleAnswered = true
+	g.initUI()
+}
+
+// drawPuzzleScreen renders the current puzzle and the user's progress
+func (g *ConnectFourGame) drawPuzzleScreen(screen *ebiten.Image) {
+	// Nothing to show but the back button while the puzzles load
+	if g.puzzle == nil {
+		loading := tr("puzzle.loading")
+		loadingBounds := boundString(fontFace, loading)
+		text.Draw(screen, loading, fontFace,
+			g.screenWidth/2-loadingBounds.Dx()/2, int(90*g.scaleY), colorText)
+		for _, btn := range g.buttons {
+			g.drawButton(screen, btn)
+		}
+		return
+	}
+
+	// Progress summary
+	progress := tr("puzzle.progress",
+		g.puzzleStats.Solved, g.puzzleStats.Attempted, g.puzzleStats.Streak, g.puzzleStats.BestStreak)
+	progressBounds := boundString(fontFace, progress)
+	text.Draw(screen, progress, fontFace,
+		g.screenWidth/2-progressBounds.Dx()/2, int(40*g.scaleY), colorText)
+
+	completed := tr("puzzle.completed", g.puzzleStats.completedOf(g.puzzles), len(g.puzzles))
+	completedBounds := boundString(fontFace, completed)
+	text.Draw(screen, completed, fontFace,
+		g.screenWidth/2-completedBounds.Dx()/2, int(65*g.scaleY), colorText)
+
+	// Puzzle prompt or feedback
+	number := g.puzzleStats.Next%len(g.puzzles) + 1
+	statusText := tr("puzzle.prompt", number, len(g.puzzles))
+	if n := g.puzzleWinIn; n > 0 {
+		statusText = tr("puzzle.prompt_win_in", number, len(g.puzzles), tr(discColorKeys[g.profile.DiscColor]), n)
+	}
+	if g.puzzleAnswered {
+		statusText = g.puzzleMessage
+	}
+	statusBounds := boundString(fontFace, statusText)
+	text.Draw(screen, statusText, fontFace,
+		g.screenWidth/2-statusBounds.Dx()/2, int(90*g.scaleY), colorText)
+	if g.puzzleAnswered && g.puzzleRefutation != "" {
+		refutationBounds := boundString(fontFace, g.puzzleRefutation)
+		text.Draw(screen, g.puzzleRefutation, fontFace,
+			g.screenWidth/2-refutationBounds.Dx()/2, int(112*g.scaleY), colorText)
+	}
+
+	g.drawBoard(screen)
+
+	// Draw buttons
+	for _, btn := range g.buttons {
+		g.drawButton(screen, btn)
+	}
+}
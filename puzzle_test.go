@@ -0,0 +1,195 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// bundledPuzzles loads the first n bundled puzzles, as solving the whole
+// set takes a while
+func bundledPuzzles(t *testing.T, n int) []*Puzzle {
+	t.Helper()
+	var lines []string
+	for _, line := range strings.Split(puzzleData, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") && len(lines) < n {
+			lines = append(lines, line)
+		}
+	}
+	puzzles, err := loadPuzzles(strings.Join(lines, "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return puzzles
+}
+
+func TestPuzzleSolutionsAreSolverConfirmed(t *testing.T) {
+	data := []byte(`[
+		{"position": "......./......./......./......./OOO..../XXX....", "solution": [3, 6]},
+		{"position": "......./......./......./......./OOO..../XXX....", "solution": [6]},
+		{"position": "......./......./......./......./OOO..../XXX...."}
+	]`)
+	puzzles, err := loadPuzzlesJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := winningColumns(puzzles[0].board, Player, solverDepth, standardRules)
+	if !slices.Contains(want, 3) || slices.Contains(want, 6) {
+		t.Fatalf("solver found %v, want 3 and not 6 among them", want)
+	}
+	for i, p := range puzzles {
+		if got := p.solutions(); !slices.Equal(got, want) {
+			t.Errorf("puzzle %d: solutions = %v, want the solver's %v", i+1, got, want)
+		}
+		if p.isSolution(6) {
+			t.Errorf("puzzle %d: a stored column the solver rejects counts as solving it", i+1)
+		}
+		if !p.isSolution(3) {
+			t.Errorf("puzzle %d: the winning column doesn't count", i+1)
+		}
+	}
+}
+
+func TestLoadPuzzlesJSONRejects(t *testing.T) {
+	for name, data := range map[string]string{
+		"not JSON":          `{`,
+		"bad position":      `[{"position": "......."}]`,
+		"already won":       `[{"position": "......./......./......./......./OOO..../XXXX..."}]`,
+		"column off board":  `[{"position": "......./......./......./......./......./.......", "solution": [7]}]`,
+		"negative solution": `[{"position": "......./......./......./......./......./.......", "solution": [-1]}]`,
+	} {
+		if _, err := loadPuzzlesJSON([]byte(data)); err == nil {
+			t.Errorf("%s: loaded without an error", name)
+		}
+	}
+
+	// Positions have to be X's to win, with the win there to be found
+	for name, tt := range map[string]struct {
+		data string
+		want error
+	}{
+		"O to move":      {`[{"position": "......./......./......./......./OOO..../XXX...X"}]`, errPuzzleMover},
+		"O a piece up":   {`[{"position": "......./......./......./......./OO...../XX....O"}]`, errPuzzleMover},
+		"no forced win":  {`[{"position": "......./......./......./......./......./......."}]`, errNoForcedWin},
+		"one of several": {`[{"position": "......./......./......./......./OOO..../XXX...."}, {"position": "......./......./......./......./......./......."}]`, errNoForcedWin},
+	} {
+		if _, err := loadPuzzlesJSON([]byte(tt.data)); !errors.Is(err, tt.want) {
+			t.Errorf("%s: loading gave %v, want %v", name, err, tt.want)
+		}
+	}
+}
+
+func TestBundledPuzzlesAreSolvable(t *testing.T) {
+	puzzles, err := loadPuzzles(puzzleData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range puzzles {
+		if len(p.solutions()) == 0 || p.movesToWin() <= 0 || p.movesToWin() > maxWinIn {
+			t.Errorf("puzzle %d: solutions %v, win in %d", i+1, p.solutions(), p.movesToWin())
+		}
+	}
+}
+
+func TestRefutationPlaysOutTheWin(t *testing.T) {
+	puzzle, err := ParseBoard("....O../....X../....OO./O..OXX./XXXOXOO/OXXXOXO")
+	if err != nil {
+		t.Fatal(err)
+	}
+	board := dropPiece(puzzle, 6, Player)
+	line, wins := refutation(board)
+	if !wins || len(line) < 3 || len(line)%2 == 0 {
+		t.Fatalf("refutation = %v, %v; want a line of several moves ending in the computer's win", line, wins)
+	}
+	for i, col := range line {
+		player := Computer
+		if i%2 == 1 {
+			player = Player
+		}
+		if !slices.Contains(getValidColumns(board), col) {
+			t.Fatalf("move %d of %v is into a full column", i+1, line)
+		}
+		board = dropPiece(board, col, player)
+		if won := checkWin(board, player); won != (i == len(line)-1) {
+			t.Fatalf("after move %d of %v a four is %v", i+1, line, won)
+		}
+	}
+	if !checkWin(board, Computer) {
+		t.Errorf("%v doesn't end with the computer winning", line)
+	}
+}
+
+func TestStartPuzzleShowsTheWinIn(t *testing.T) {
+	puzzles := bundledPuzzles(t, 1)
+	if puzzles[0].winIn <= 0 {
+		t.Fatalf("loaded with win in %d, want it worked out", puzzles[0].winIn)
+	}
+	g := &ConnectFourGame{puzzles: puzzles}
+	if !g.startPuzzle() {
+		t.Fatal("no puzzle started")
+	}
+	if g.puzzleWinIn != g.puzzle.winIn {
+		t.Errorf("after starting, win in %d, want the puzzle's %d", g.puzzleWinIn, g.puzzle.winIn)
+	}
+}
+
+func TestPuzzlesLoadInTheBackground(t *testing.T) {
+	// Standing in for a load already under way, so the whole set isn't solved
+	fetched := make(chan puzzleLoad, 1)
+	g := newConnectFourGame(newMemoryStorage())
+	g.frame = 1
+	g.puzzleFetch = fetched
+	g.openPuzzles()
+	g.updatePuzzleFetch()
+	if g.state != StatePuzzle || g.puzzle != nil || g.boardInputActive() {
+		t.Fatalf("opening puzzles: state %d, puzzle %v", g.state, g.puzzle)
+	}
+
+	// The first puzzle is presented once the set has loaded
+	puzzles := bundledPuzzles(t, 1)
+	fetched <- puzzleLoad{puzzles: puzzles}
+	g.updatePuzzleFetch()
+	if g.puzzleFetch != nil || g.puzzle != puzzles[0] || !g.boardInputActive() {
+		t.Errorf("after loading: puzzle %v, fetching %v", g.puzzle, g.puzzleFetch != nil)
+	}
+
+	// A set that can't be loaded sends the player back to the menu
+	failed := make(chan puzzleLoad, 1)
+	g.puzzles, g.puzzleFetch = nil, failed
+	g.openPuzzles()
+	failed <- puzzleLoad{err: errNoForcedWin}
+	g.updatePuzzleFetch()
+	if g.state != StateGameMode || g.toastMessage != tr("puzzle.unavailable") {
+		t.Errorf("failed load left state %d with toast %q", g.state, g.toastMessage)
+	}
+}
+
+func TestPuzzleProgressPersists(t *testing.T) {
+	puzzles := bundledPuzzles(t, 2)
+	store := newMemoryStorage()
+	g := &ConnectFourGame{userStore: store, username: "ada", puzzles: puzzles}
+	g.puzzleStats = loadPuzzleStats(store, g.username)
+	g.startPuzzle()
+	first := g.puzzle
+	g.answerPuzzle(first.solutions()[0])
+
+	stats := loadPuzzleStats(store, "ada")
+	if stats.Attempted != 1 || stats.Solved != 1 || stats.Streak != 1 || stats.Next != 1 {
+		t.Errorf("saved stats = %+v, want one puzzle attempted and solved", stats)
+	}
+	if stats.completedOf(puzzles) != 1 || !slices.Contains(stats.Completed, FormatBoard(first.board)) {
+		t.Errorf("saved completions = %v, want the solved puzzle", stats.Completed)
+	}
+
+	g = &ConnectFourGame{userStore: store, username: "ada", puzzles: puzzles}
+	g.puzzleStats = loadPuzzleStats(store, g.username)
+	g.startPuzzle()
+	if g.puzzle == first {
+		t.Error("coming back presents the solved puzzle again instead of the next")
+	}
+	if other := loadPuzzleStats(store, "grace"); other.Attempted != 0 {
+		t.Errorf("another user starts with %+v", other)
+	}
+}
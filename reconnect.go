@@ -0,0 +1,265 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"image/color"
+	"log"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// Reconnection: both ends of a connection to the game server hear from the
+// other at least every heartbeatInterval, as clients ping and the server
+// answers, so one silent for heartbeatTimeout has dropped. A client that
+// drops mid-game keeps trying to rejoin it for reconnectTimeout, and the
+// server holds its seat for reconnectGrace, which is longer to allow for
+// the server noticing first. These are the usual timings, which
+// serverConfig and clientConfig start from.
+const (
+	heartbeatInterval = 5 * time.Second
+	heartbeatTimeout  = 15 * time.Second
+	reconnectRetry    = 2 * time.Second // Pause between attempts to reconnect
+	reconnectTimeout  = 30 * time.Second
+	reconnectGrace    = 60 * time.Second
+)
+
+// resumeDesk keeps the tokens of every match being played, so that a client
+// that loses its connection can find its way back. Matches and arriving
+// clients use it from their own goroutines, so it is guarded by mu.
+type resumeDesk struct {
+	mu     sync.Mutex
+	tokens map[string]*resumeSeats
+}
+
+// resumeSeats are a match's resume tokens, in the order of its clients for
+// the game being played, and the clients rejoining it
+type resumeSeats struct {
+	tokens  [2]string
+	rejoins chan *netPeer
+	done    chan struct{} // Closed once the match is over
+}
+
+// open hands out the tokens for a new match
+func (d *resumeDesk) open() *resumeSeats {
+	seats := &resumeSeats{rejoins: make(chan *netPeer), done: make(chan struct{})}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.tokens == nil {
+		d.tokens = make(map[string]*resumeSeats)
+	}
+	for i := range seats.tokens {
+		seats.tokens[i] = newResumeToken()
+		d.tokens[seats.tokens[i]] = seats
+	}
+	return seats
+}
+
+// close forgets a match's tokens once it is over
+func (d *resumeDesk) close(seats *resumeSeats) {
+	d.mu.Lock()
+	for _, token := range seats.tokens {
+		delete(d.tokens, token)
+	}
+	d.mu.Unlock()
+	close(seats.done)
+}
+
+// rejoin hands a client to the match its token belongs to, reporting false
+// if there is no such match any more
+func (d *resumeDesk) rejoin(p *netPeer) bool {
+	d.mu.Lock()
+	seats := d.tokens[p.resume]
+	d.mu.Unlock()
+	if seats == nil {
+		return false
+	}
+	select {
+	case seats.rejoins <- p:
+		return true
+	case <-seats.done:
+		return false
+	}
+}
+
+// newResumeToken makes a token nobody could guess, for a client that loses
+// its connection mid-game to rejoin the game with
+func newResumeToken() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// historiesAgree reports whether a client's moves, kept while it was away,
+// fit the server's columns for the same game. The client may be behind the
+// server, having missed moves while away, or a move ahead, having played
+// one the server never heard of; the server's game stands either way.
+// Histories that differ anywhere else can't be reconciled.
+func historiesAgree(local []Move, server []int) bool {
+	shared := min(len(local), len(server))
+	agree := len(local) <= len(server) ||
+		(len(local) == len(server)+1 && local[shared].Player == Player)
+	for i := range shared {
+		if local[i].Column != server[i] {
+			agree = false
+		}
+	}
+	return agree
+}
+
+// leave holds the seat of the client in peers[seat], which has lost the
+// connection mid-game, for reconnectGrace, and tells its opponent. The
+// clock stands still until it is back.
+func (m *netMatch) leave(peers *[2]*netPeer, seat int) {
+	peers[seat].close()
+	peers[seat] = nil
+	m.away[seat] = time.NewTimer(m.reconnectGrace)
+	m.stopClock()
+	peers[1-seat].send(AwayMessage{Away: true, TimeLeft: m.timeLeft()})
+}
+
+// rejoin seats a reconnected client in place of its old connection, which
+// the server may not have noticed has gone, and hands it the game so far.
+// Its opponent is told it is back, and the clock carries on from where it
+// stopped.
+func (m *netMatch) rejoin(peers *[2]*netPeer, seats *resumeSeats, p *netPeer) {
+	seat := slices.Index(seats.tokens[:], p.resume)
+	peers[seat].close()
+	p.hello = HelloMessage{Name: m.players[seat]}
+	p.account = m.accounts[seat]
+	peers[seat] = p
+	away := m.away[seat]
+	if away != nil {
+		away.Stop()
+		m.away[seat] = nil
+		m.resumeClock()
+	}
+	p.send(ResumedMessage{Moves: m.moves, Hash: m.hash, TimeLeft: m.timeLeft()})
+	if m.over {
+		p.send(m.ending)
+	}
+	if away != nil {
+		peers[1-seat].send(AwayMessage{Away: false, TimeLeft: m.timeLeft()})
+	}
+}
+
+// graceUp returns a channel that fires when the client on seat has been
+// away too long to come back, or nil if it is there
+func (m *netMatch) graceUp(seat int) <-chan time.Time {
+	if m.away[seat] == nil {
+		return nil
+	}
+	return m.away[seat].C
+}
+
+// abandon gives up on the client on seat coming back. It loses the game,
+// unless the game ended while it was away.
+func (m *netMatch) abandon(peers [2]*netPeer, seat int) {
+	m.end(peers, GameOverMessage{Winner: opponentOf(Player + seat), Reason: gameOverLeft})
+}
+
+// heartbeat pings the server through codec every heartbeatInterval, so both
+// ends can tell the connection is alive, until the connection is closed
+func (o *OnlineGame) heartbeat(codec *wireCodec) {
+	ticker := time.NewTicker(o.config.heartbeatInterval)
+	defer ticker.Stop()
+	for nonce := 1; ; nonce++ {
+		select {
+		case <-ticker.C:
+		case <-o.done:
+			return
+		}
+		o.writeMu.Lock()
+		err := codec.write(PingMessage{Nonce: nonce})
+		o.writeMu.Unlock()
+		if err != nil {
+			return
+		}
+	}
+}
+
+// reconnecting reports whether the game is waiting to get its connection
+// to the server back
+func (g *ConnectFourGame) reconnecting() bool {
+	return g.online != nil && !g.online.giveUp.IsZero()
+}
+
+// startReconnecting keeps the game going after the connection drops
+// mid-game, dialling the server again until it is back or its
+// reconnectTimeout has passed
+func (g *ConnectFourGame) startReconnecting(err error) {
+	o := g.online
+	log.Printf("lost the game server: %v; reconnecting", err)
+	o.hangUp()
+	o.giveUp = time.Now().Add(o.config.reconnectTimeout)
+	o.startClock(0)
+	g.dropAnim = nil
+	go o.connect(o.giveUp)
+}
+
+// updateReconnecting gives up on the game once reconnecting has taken too
+// long, however far it got
+func (g *ConnectFourGame) updateReconnecting() {
+	if g.reconnecting() && time.Now().After(g.online.giveUp) {
+		g.leaveOnlineGame(tr("online.reconnect_failed"))
+	}
+}
+
+// resumeOnlineGame carries on with the game once the server has taken the
+// client back, as long as both agree on the position and the server's game
+// passes the same checks as a sync
+func (g *ConnectFourGame) resumeOnlineGame(msg ResumedMessage) {
+	o := g.online
+	if !g.reconnecting() {
+		return
+	}
+	if !historiesAgree(g.moves, msg.Moves) {
+		g.leaveOnlineGame(tr("online.resume_disagree"))
+		return
+	}
+
+	o.giveUp = time.Time{}
+	g.syncOnlineGame(msg.Moves)
+	if g.online == nil {
+		return
+	}
+	if msg.Hash != 0 && HashBoard(o.serverBoard(g.board)) != msg.Hash {
+		g.leaveOnlineGame(tr("online.resume_disagree"))
+		return
+	}
+	o.startClock(msg.TimeLeft)
+	g.showToast(tr("online.reconnected"))
+}
+
+// drawReconnecting dims the game while the connection is being got back,
+// with how long is left before it is given up
+func (g *ConnectFourGame) drawReconnecting(screen *ebiten.Image) {
+	if !g.reconnecting() || g.state != StateGame {
+		return
+	}
+	drawRect(screen, 0, 0, float64(g.screenWidth), float64(g.screenHeight),
+		color.RGBA{0, 0, 0, 120})
+
+	boxWidth := 320 * g.scaleX
+	boxHeight := 80 * g.scaleY
+	boxX := float64(g.screenWidth)/2 - boxWidth/2
+	boxY := float64(g.screenHeight)/2 - boxHeight/2
+	drawRect(screen, boxX-2, boxY-2, boxWidth+4, boxHeight+4, colorButton)
+	drawRect(screen, boxX, boxY, boxWidth, boxHeight, colorBackground)
+
+	message := tr("online.reconnecting")
+	bounds := boundString(fontFace, message)
+	text.Draw(screen, message, fontFace,
+		g.screenWidth/2-bounds.Dx()/2, int(boxY+32*g.scaleY), colorText)
+
+	left := max(0, int(math.Ceil(time.Until(g.online.giveUp).Seconds())))
+	countdown := tr("online.reconnect_left", left)
+	bounds = boundString(fontFace, countdown)
+	text.Draw(screen, countdown, fontFace,
+		g.screenWidth/2-bounds.Dx()/2, int(boxY+58*g.scaleY), colorText)
+}
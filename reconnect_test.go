@@ -0,0 +1,335 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testClientConfig has a client connect to the server listening on ln
+func testClientConfig(ln *pipeListener) clientConfig {
+	config := newClientConfig()
+	config.dial = func(string) (net.Conn, error) { return ln.dial() }
+	return config
+}
+
+// pump runs the online side of Update until done reports true
+func pump(t *testing.T, g *ConnectFourGame, done func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(testTimeout)
+	for !done() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the game server")
+		}
+		g.updateOnline()
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRejoinAfterDroppingOut(t *testing.T) {
+	ln, _ := startTestServer(t, 0)
+	clients := startMatch(t, ln)
+	var board GameBoard
+	playMove(t, clients, &board, Player, 3)
+	playMove(t, clients, &board, Computer, 4)
+
+	clients[0].conn.Close()
+	if away := expect[AwayMessage](clients[1]); !away.Away {
+		t.Errorf("opponent was told %+v, want away", away)
+	}
+
+	back := connect(t, ln, ResumeMessage{Token: clients[0].found.Resume})
+	resumed := expect[ResumedMessage](back)
+	if !reflect.DeepEqual(resumed.Moves, []int{3, 4}) || resumed.Hash != HashBoard(board) {
+		t.Errorf("rejoined with %+v, want moves [3 4] and hash %x", resumed, HashBoard(board))
+	}
+	if away := expect[AwayMessage](clients[1]); away.Away {
+		t.Errorf("opponent was told %+v, want back", away)
+	}
+
+	// The game carries on where it was left
+	clients[0] = back
+	playMove(t, clients, &board, Player, 3)
+	clients[1].send(ResignMessage{})
+	for _, c := range clients {
+		if end := expect[GameOverMessage](c); end.Winner != Player || end.Reason != gameOverResign {
+			t.Errorf("game ended %+v, want the second player resigning", end)
+		}
+	}
+}
+
+func TestRejoinReplacesAConnectionNotYetDropped(t *testing.T) {
+	ln, _ := startTestServer(t, 0)
+	clients := startMatch(t, ln)
+
+	// The client knows its connection is gone before the server does
+	back := connect(t, ln, ResumeMessage{Token: clients[1].found.Resume})
+	if resumed := expect[ResumedMessage](back); len(resumed.Moves) != 0 {
+		t.Errorf("rejoined with moves %v", resumed.Moves)
+	}
+	if msg := clients[1].next(); msg != nil {
+		t.Errorf("old connection was sent %#v, want it hung up", msg)
+	}
+
+	// Nobody was away, and the opponent talks to the new connection
+	clients[0].send(ChatMessage{Text: "still there?"})
+	if chat := expect[ChatMessage](back); chat.Text != "still there?" {
+		t.Errorf("rejoined client heard %q", chat.Text)
+	}
+}
+
+func TestDroppingOutForTooLongLoses(t *testing.T) {
+	config := newServerConfig(0)
+	config.reconnectGrace = 50 * time.Millisecond
+	ln, _ := startConfiguredServer(t, config)
+	clients := startMatch(t, ln)
+
+	clients[0].conn.Close()
+	expect[AwayMessage](clients[1])
+	if end := expect[GameOverMessage](clients[1]); end.Winner != Computer || end.Reason != gameOverLeft {
+		t.Errorf("game ended %+v, want a win for the player still there", end)
+	}
+
+	// Too late to come back
+	late := connect(t, ln, ResumeMessage{Token: clients[0].found.Resume})
+	if end := expect[GameOverMessage](late); end.Reason != gameOverGone {
+		t.Errorf("rejoining too late was answered %+v", end)
+	}
+	if msg := late.next(); msg != nil {
+		t.Errorf("server sent %#v, want it to hang up", msg)
+	}
+}
+
+func TestSilentClientsAreDropped(t *testing.T) {
+	config := newServerConfig(0)
+	config.heartbeatTimeout = 200 * time.Millisecond
+	ln, _ := startConfiguredServer(t, config)
+	silent := connect(t, ln, SpectateMessage{Name: "alice"})
+	pinging := connect(t, ln, SpectateMessage{Name: "bob"})
+
+	for nonce := range 4 {
+		time.Sleep(config.heartbeatTimeout / 2)
+		pinging.send(PingMessage{Nonce: nonce})
+		if pong := expect[PongMessage](pinging); pong.Nonce != nonce {
+			t.Errorf("pong %d for ping %d", pong.Nonce, nonce)
+		}
+	}
+	if msg := silent.next(); msg != nil {
+		t.Errorf("silent client was sent %#v, want it hung up", msg)
+	}
+}
+
+func TestClockStandsStillWhileAway(t *testing.T) {
+	const moveTime = 600 * time.Millisecond
+	config := newServerConfig(moveTime)
+	config.reconnectGrace = 5 * time.Second
+	ln, _ := startConfiguredServer(t, config)
+	clients := startMatch(t, ln)
+
+	// The player to move drops out for longer than the move has
+	clients[0].conn.Close()
+	away := expect[AwayMessage](clients[1])
+	if !away.Away || away.TimeLeft <= 0 || away.TimeLeft > 600 {
+		t.Errorf("opponent was told %+v, want away with the clock stopped", away)
+	}
+	time.Sleep(2 * moveTime)
+
+	back := connect(t, ln, ResumeMessage{Token: clients[0].found.Resume})
+	resumed := expect[ResumedMessage](back)
+	if resumed.TimeLeft <= 0 || resumed.TimeLeft > away.TimeLeft {
+		t.Errorf("rejoined with %d ms left, want what was left on going, %d", resumed.TimeLeft, away.TimeLeft)
+	}
+	if again := expect[AwayMessage](clients[1]); again.Away || again.TimeLeft <= 0 || again.TimeLeft > away.TimeLeft {
+		t.Errorf("opponent was told %+v, want back with %d ms left", again, away.TimeLeft)
+	}
+
+	// Once back, the clock runs out as before
+	clients[0] = back
+	for i, c := range clients {
+		if end := expect[GameOverMessage](c); end.Winner != Computer || end.Reason != gameOverTime {
+			t.Errorf("client %d: game ended %+v, want the first player out of time", i, end)
+		}
+	}
+}
+
+func TestClientRejoinsItsGame(t *testing.T) {
+	ln, _ := startTestServer(t, 0)
+	t.Cleanup(func() { firstPlayer = Player })
+
+	g := newConnectFourGame(newMemoryStorage())
+	g.frame = 1
+	g.username = "alice"
+	g.settings.RatedOnline = false // Casual, like the opponent
+	g.online = connectOnline("pipe", testClientConfig(ln))
+	t.Cleanup(g.closeOnline)
+	opponent := connect(t, ln, HelloMessage{Name: "bob"})
+	pump(t, g, func() bool { return g.state == StateGame })
+	found := expect[MatchFoundMessage](opponent)
+
+	// play has whoever's turn it is play col
+	play := func(col int) {
+		t.Helper()
+		moves := len(g.moves)
+		if (moves%2 == 0) == (found.Side == Player) {
+			opponent.send(MoveMessage{Column: col})
+			expect[MoveAckMessage](opponent)
+			pump(t, g, func() bool { return len(g.moves) > moves })
+		} else {
+			g.dropInColumn(col)
+			if move := expect[MoveMessage](opponent); move.Column != col {
+				t.Fatalf("opponent heard column %d, want %d", move.Column, col)
+			}
+		}
+		g.dropAnim = nil
+	}
+	play(3)
+
+	// The connection drops mid-game
+	dropped := g.online.conn
+	dropped.Close()
+	pump(t, g, func() bool { return g.online.conn != nil && g.online.conn != dropped && !g.reconnecting() })
+	if g.state != StateGame || g.toastMessage != tr("online.reconnected") {
+		t.Fatalf("after reconnecting: state %d, toast %q", g.state, g.toastMessage)
+	}
+	if want := []Move{{Column: 3, Player: g.moves[0].Player}}; !reflect.DeepEqual(g.moves, want) {
+		t.Errorf("resumed with moves %v, want %v", g.moves, want)
+	}
+	if away := expect[AwayMessage](opponent); !away.Away {
+		t.Errorf("opponent was told %+v, want away", away)
+	}
+	if away := expect[AwayMessage](opponent); away.Away {
+		t.Errorf("opponent was told %+v, want back", away)
+	}
+
+	// Play carries on over the new connection
+	play(4)
+	if len(g.moves) != 2 || g.moves[1].Column != 4 {
+		t.Errorf("after rejoining, moves %v", g.moves)
+	}
+}
+
+func TestClientGivesUpReconnecting(t *testing.T) {
+	g, _ := onlineTestGame(t, Player)
+	g.online.config = clientConfig{
+		dial:             func(string) (net.Conn, error) { return nil, errors.New("unreachable") },
+		reconnectRetry:   5 * time.Millisecond,
+		reconnectTimeout: 50 * time.Millisecond,
+	}
+	g.online.conn, _ = net.Pipe()
+	g.online.resume = "5f0c"
+	g.handleNetEvent(netEvent{err: io.ErrUnexpectedEOF})
+	if !g.reconnecting() {
+		t.Fatal("losing the connection mid-game didn't reconnect")
+	}
+	pump(t, g, func() bool { return !g.reconnecting() })
+	if g.online != nil || g.state != StateGameMode || g.toastMessage != tr("online.reconnect_failed") {
+		t.Errorf("gave up to state %d with toast %q", g.state, g.toastMessage)
+	}
+}
+
+func TestResumeChecksThePositionsAgree(t *testing.T) {
+	// Moving first, so the client's own moves are the even ones
+	for _, tc := range []struct {
+		name           string
+		client, server []int
+		hash           uint64 // The server's, if not the hash of its moves
+		resumes        bool
+	}{
+		{"in step", []int{3, 4}, []int{3, 4}, 0, true},
+		{"missed the opponent's move", []int{3}, []int{3, 4}, 0, true},
+		{"own move never arrived", []int{3, 4, 5}, []int{3, 4}, 0, true},
+		{"opponent's move the server lacks", []int{3, 4}, []int{3}, 0, false},
+		{"different moves", []int{3, 4}, []int{3, 2}, 0, false},
+		{"different boards", []int{3}, []int{3}, 1, false},
+		{"moves after a four", []int{0, 1, 0, 1, 0, 1}, []int{0, 1, 0, 1, 0, 1, 0, 1}, 0, false},
+		{"a column overfilled", []int{0, 0, 0, 0, 0, 0}, []int{0, 0, 0, 0, 0, 0, 0}, 0, false},
+	} {
+		g, _ := onlineTestGame(t, Player)
+		g.syncOnlineGame(tc.client)
+		g.online.giveUp = time.Now().Add(time.Minute)
+		hash := tc.hash
+		if hash == 0 {
+			hash = serverHash(tc.server...)
+		}
+		g.resumeOnlineGame(ResumedMessage{Moves: tc.server, Hash: hash})
+
+		if !tc.resumes {
+			if g.online != nil || g.state != StateGameMode {
+				t.Errorf("%s: resumed a game the server disagrees with", tc.name)
+			}
+			continue
+		}
+		if g.online == nil || g.reconnecting() {
+			t.Errorf("%s: didn't resume", tc.name)
+			continue
+		}
+		var cols []int
+		for _, move := range g.moves {
+			cols = append(cols, move.Column)
+		}
+		if !reflect.DeepEqual(cols, tc.server) {
+			t.Errorf("%s: resumed with moves %v, want the server's %v", tc.name, cols, tc.server)
+		}
+	}
+}
+
+func TestClientClockFollowsTheServer(t *testing.T) {
+	g, _ := onlineTestGame(t, Player)
+	if _, ok := g.online.clockLeft(); ok {
+		t.Error("a server without a clock shows one")
+	}
+
+	g.handleNetEvent(netEvent{msg: SyncMessage{TimeLeft: 5000}})
+	if left, ok := g.online.clockLeft(); !ok || left > 5*time.Second || left < 4*time.Second {
+		t.Errorf("synced clock shows %v, want 5s", left)
+	}
+
+	// It stands still while the opponent is away
+	g.handleNetEvent(netEvent{msg: AwayMessage{Away: true, TimeLeft: 4000}})
+	time.Sleep(20 * time.Millisecond)
+	if left, ok := g.online.clockLeft(); !ok || left != 4*time.Second {
+		t.Errorf("clock shows %v while the opponent is away, want 4s", left)
+	}
+	g.handleNetEvent(netEvent{msg: AwayMessage{Away: false, TimeLeft: 4000}})
+	time.Sleep(20 * time.Millisecond)
+	if left, ok := g.online.clockLeft(); !ok || left >= 4*time.Second {
+		t.Errorf("clock shows %v once the opponent is back, want it running", left)
+	}
+}
+
+func TestHistoriesAgree(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		local  []Move
+		server []int
+		want   bool
+	}{
+		{"same moves", movesFrom("4453"), []int{3, 3, 4, 2}, true},
+		{"nothing played", nil, nil, true},
+		{"missed the opponent's reply", movesFrom("445"), []int{3, 3, 4, 2}, true},
+		{"missed several moves", movesFrom("4"), []int{3, 3, 4, 2}, true},
+		{"own move never arrived", movesFrom("44535"), []int{3, 3, 4, 2}, true},
+		{"opponent's move the server never sent", movesFrom("4453"), []int{3, 3, 4}, false},
+		{"two moves ahead", movesFrom("44535"), []int{3, 3, 4}, false},
+		{"different move", movesFrom("4454"), []int{3, 3, 4, 2}, false},
+		{"different earlier move", movesFrom("145"), []int{3, 3, 4, 2}, false},
+	} {
+		if got := historiesAgree(tt.local, tt.server); got != tt.want {
+			t.Errorf("%s: historiesAgree = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestResumeTokensDiffer(t *testing.T) {
+	seen := map[string]bool{}
+	for range 100 {
+		token := newResumeToken()
+		if len(token) != 32 || seen[token] {
+			t.Fatalf("token %q repeated or not 16 bytes of hex", token)
+		}
+		seen[token] = true
+	}
+}
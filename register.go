@@ -0,0 +1,126 @@
+package main
+
+import (
+	"errors"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// openRegister shows the registration screen
+func (g *ConnectFourGame) openRegister() {
+	g.registerValues = [3]string{}
+	g.transitionTo(StateRegister)
+}
+
+// initRegisterUI creates the registration fields and buttons
+func (g *ConnectFourGame) initRegisterUI() {
+	fields := []struct {
+		label, placeholder string
+		isPassword         bool
+	}{
+		{tr("login.username"), tr("login.username_placeholder"), false},
+		{tr("login.password"), tr("register.password_placeholder"), true},
+		{tr("register.confirm"), tr("register.confirm_placeholder"), true},
+	}
+	for i, f := range fields {
+		g.textInputs = append(g.textInputs, &TextInput{
+			x:           float64(g.screenWidth)/2 - 100*g.scaleX,
+			y:           (170 + float64(i)*70) * g.scaleY,
+			w:           200 * g.scaleX,
+			h:           30 * g.scaleY,
+			label:       f.label,
+			placeholder: f.placeholder,
+			isPassword:  f.isPassword,
+		})
+	}
+
+	// Create and cancel buttons
+	g.buttons = append(g.buttons, &Button{
+		x:    float64(g.screenWidth)/2 - 105*g.scaleX,
+		y:    390 * g.scaleY,
+		w:    100 * g.scaleX,
+		h:    40 * g.scaleY,
+		text: tr("register.create"),
+		action: func() {
+			g.createAccount()
+		},
+	})
+	g.buttons = append(g.buttons, &Button{
+		x:    float64(g.screenWidth)/2 + 5*g.scaleX,
+		y:    390 * g.scaleY,
+		w:    100 * g.scaleX,
+		h:    40 * g.scaleY,
+		text: tr("register.cancel"),
+		action: func() {
+			g.transitionTo(StateLogin)
+		},
+	})
+}
+
+// registerFields returns the username, password and confirmation inputs
+func (g *ConnectFourGame) registerFields() map[string]*TextInput {
+	return map[string]*TextInput{
+		fieldUsername: g.textInputs[0],
+		fieldPassword: g.textInputs[1],
+		fieldConfirm:  g.textInputs[2],
+	}
+}
+
+// updateRegister validates the form as it is filled in. Fields that are
+// still empty aren't flagged until Create is pressed.
+func (g *ConnectFourGame) updateRegister() {
+	values := [3]string{g.textInputs[0].value, g.textInputs[1].value, g.textInputs[2].value}
+	if values == g.registerValues {
+		return
+	}
+	g.registerValues = values
+
+	errs := validateRegistration(g.storage, values[0], values[1], values[2])
+	for field, input := range g.registerFields() {
+		input.err = ""
+		if key, ok := errs[field]; ok && input.value != "" {
+			input.err = loginErrorText(key)
+		}
+	}
+}
+
+// createAccount registers the entered details and logs in, or shows what
+// is wrong with them
+func (g *ConnectFourGame) createAccount() {
+	fields := g.registerFields()
+	username, password := fields[fieldUsername].value, fields[fieldPassword].value
+	errs := validateRegistration(g.storage, username, password, fields[fieldConfirm].value)
+	if len(errs) == 0 {
+		err := registerAccount(g.storage, username, password)
+		if err == nil {
+			g.finishLogin(username, password)
+			return
+		}
+		if errors.Is(err, errUsernameTaken) {
+			errs[fieldUsername] = "register.error.username_taken"
+		} else {
+			log.Printf("creating account: %v", err)
+			errs[fieldUsername] = "register.error.failed"
+		}
+	}
+	for field, key := range errs {
+		g.showInputError(fields[field], loginErrorText(key))
+	}
+}
+
+// drawRegisterScreen renders the registration form
+func (g *ConnectFourGame) drawRegisterScreen(screen *ebiten.Image) {
+	title := tr("register.title")
+	titleBounds := boundString(fontFace, title)
+	text.Draw(screen, title, fontFace,
+		g.screenWidth/2-titleBounds.Dx()/2, int(110*g.scaleY), colorText)
+
+	for _, input := range g.textInputs {
+		g.drawTextInput(screen, input)
+	}
+	for _, btn := range g.buttons {
+		g.drawButton(screen, btn)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"maps"
+	"testing"
+)
+
+func TestValidateRegistration(t *testing.T) {
+	store := newMemoryStorage()
+	for _, name := range []string{"alice", "ann!"} {
+		if err := registerAccount(store, name, "secret1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name                        string
+		username, password, confirm string
+		want                        map[string]string
+	}{
+		{"valid", "bob", "hunter22", "hunter22", map[string]string{}},
+		{"taken", "alice", "hunter22", "hunter22", map[string]string{
+			fieldUsername: "register.error.username_taken",
+		}},
+		{"taken under another spelling", "ann?", "hunter22", "hunter22", map[string]string{
+			fieldUsername: "register.error.username_taken",
+		}},
+		{"mismatched confirmation", "bob", "hunter22", "hunter23", map[string]string{
+			fieldConfirm: "register.error.confirm_mismatch",
+		}},
+		{"empty confirmation", "bob", "hunter22", "", map[string]string{
+			fieldConfirm: "register.error.confirm_mismatch",
+		}},
+		{"short password", "bob", "abc", "abc", map[string]string{
+			fieldPassword: "register.error.password_too_short",
+		}},
+		{"everything wrong", "", "abc", "abd", map[string]string{
+			fieldUsername: "login.error.username_required",
+			fieldPassword: "register.error.password_too_short",
+			fieldConfirm:  "register.error.confirm_mismatch",
+		}},
+	}
+	for _, tt := range tests {
+		got := validateRegistration(store, tt.username, tt.password, tt.confirm)
+		if !maps.Equal(got, tt.want) {
+			t.Errorf("%s: validateRegistration = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRegisterAccount(t *testing.T) {
+	store := newMemoryStorage()
+	if err := registerAccount(store, "alice", "secret1"); err != nil {
+		t.Fatal(err)
+	}
+	account, ok := loadAccount(store, "alice")
+	if !ok || !account.checkPassword("secret1") || account.checkPassword("secret2") {
+		t.Errorf("account %+v doesn't check its password", account)
+	}
+
+	if err := registerAccount(store, "alice", "other1"); !errors.Is(err, errUsernameTaken) {
+		t.Errorf("registering a taken username: got %v, want errUsernameTaken", err)
+	}
+	if account, _ := loadAccount(store, "alice"); !account.checkPassword("secret1") {
+		t.Error("a second registration replaced the first account's password")
+	}
+}
+
+func TestCreateAccountForm(t *testing.T) {
+	store := newMemoryStorage()
+	if err := registerAccount(store, "alice", "secret1"); err != nil {
+		t.Fatal(err)
+	}
+	g := newConnectFourGame(store)
+
+	fill := func(username, password, confirm string) {
+		g.openRegister()
+		g.textInputs[0].value = username
+		g.textInputs[1].value = password
+		g.textInputs[2].value = confirm
+		g.createAccount()
+	}
+
+	fill("alice", "secret2", "secret2")
+	if g.state != StateRegister || g.textInputs[0].err == "" {
+		t.Errorf("duplicate username: state %s, username error %q", stateName(g.state), g.textInputs[0].err)
+	}
+
+	fill("bob", "hunter22", "hunter23")
+	if g.state != StateRegister || g.textInputs[2].err == "" {
+		t.Errorf("mismatched confirmation: state %s, confirm error %q", stateName(g.state), g.textInputs[2].err)
+	}
+	if accountExists(store, "bob") {
+		t.Error("an account was created from a mismatched confirmation")
+	}
+
+	fill("bob", "hunter22", "hunter22")
+	if g.state == StateRegister || g.username != "bob" || !accountExists(store, "bob") {
+		t.Errorf("valid form: state %s, logged in as %q", stateName(g.state), g.username)
+	}
+}
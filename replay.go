@@ -0,0 +1,315 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// Number of saved games listed per page on the replay list screen
+const replaysPerPage = 8
+
+// Autoplay speeds (moves per second) the replay viewer cycles through
+var replaySpeeds = []float64{0.5, 1, 2, 4}
+
+// Replay steps through the moves of a recorded game
+type Replay struct {
+	moves []Move
+	ply   int // Number of moves currently applied
+}
+
+// NewReplay creates a replay of a game played under variant's rules,
+// positioned before the first move. The move list is cut short at the first
+// move that isn't legal on the board, including any move made after the game
+// was already won.
+func NewReplay(moves []Move, variant int) *Replay {
+	var board GameBoard
+	for i, move := range moves {
+		if move.Column < 0 || move.Column >= Columns || board[0][move.Column] != Empty ||
+			(move.Player != Player && move.Player != Computer) ||
+			(variant == VariantStandard && validatePosition(board) != nil) {
+			moves = moves[:i]
+			break
+		}
+		next := dropPiece(board, move.Column, move.Player)
+		if err := ValidateBoard(next); err != nil && (variant == VariantStandard || !errors.Is(err, errBothWon)) {
+			// e.g. one side moving twice in a row
+			moves = moves[:i]
+			break
+		}
+		board = next
+	}
+	return &Replay{moves: moves}
+}
+
+// Len returns the number of moves in the replay
+func (r *Replay) Len() int {
+	return len(r.moves)
+}
+
+// Ply returns the number of moves currently applied
+func (r *Replay) Ply() int {
+	return r.ply
+}
+
+// Seek jumps to the position after ply moves, clamped to the game's length
+func (r *Replay) Seek(ply int) {
+	r.ply = max(0, min(ply, len(r.moves)))
+}
+
+// Step moves forwards or backwards by delta moves, returning false if already at the end
+func (r *Replay) Step(delta int) bool {
+	before := r.ply
+	r.Seek(r.ply + delta)
+	return r.ply != before
+}
+
+// Board returns the position after the currently applied moves
+func (r *Replay) Board() GameBoard {
+	var board GameBoard
+	for _, move := range r.moves[:r.ply] {
+		board = dropPiece(board, move.Column, move.Player)
+	}
+	return board
+}
+
+// openReplayList loads the saved games and shows the replay list
+func (g *ConnectFourGame) openReplayList() {
+	games, err := loadSavedGames(g.gamesStore())
+	if err != nil {
+		log.Printf("loading saved games: %v", err)
+	}
+	g.savedGames = games
+	g.replayPage = 0
+	g.transitionTo(StateReplayList)
+}
+
+// openReplay starts viewing a saved game from its first move
+func (g *ConnectFourGame) openReplay(game *SavedGame) {
+	g.openReplayAt(game, 0, StateReplayList)
+}
+
+// openReplayAt starts viewing a saved game after ply moves, with Back
+// returning to returnState
+func (g *ConnectFourGame) openReplayAt(game *SavedGame, ply int, returnState int) {
+	g.replayGame = game
+	g.replay = NewReplay(game.Moves, game.Variant)
+	g.replay.Seek(ply)
+	g.replayReturnState = returnState
+	g.replayPlaying = false
+	g.replayTimer = 0
+	g.transitionTo(StateReplay)
+}
+
+// initReplayListUI creates a button per saved game on the current page, plus paging buttons
+func (g *ConnectFourGame) initReplayListUI() {
+	// Back button
+	g.buttons = append(g.buttons, &Button{
+		x:    float64(g.screenWidth) - 120*g.scaleX,
+		y:    20 * g.scaleY,
+		w:    100 * g.scaleX,
+		h:    30 * g.scaleY,
+		text: tr("common.back"),
+		action: func() {
+			g.transitionTo(StateGameMode)
+		},
+	})
+
+	start := g.replayPage * replaysPerPage
+	for i := start; i < len(g.savedGames) && i < start+replaysPerPage; i++ {
+		game := &g.savedGames[i]
+		g.buttons = append(g.buttons, &Button{
+			x: float64(g.screenWidth)/2 - 200*g.scaleX,
+			y: float64(110+(i-start)*40) * g.scaleY,
+			w: 400 * g.scaleX,
+			h: 32 * g.scaleY,
+			text: tr("replay.entry", game.Played.Local().Format("2006-01-02 15:04"),
+				game.Username, game.resultText(), len(game.Moves)),
+			action: func() {
+				g.openReplay(game)
+			},
+		})
+	}
+
+	// Paging buttons
+	if g.replayPage > 0 {
+		g.buttons = append(g.buttons, &Button{
+			x:    float64(g.screenWidth)/2 - 200*g.scaleX,
+			y:    450 * g.scaleY,
+			w:    100 * g.scaleX,
+			h:    30 * g.scaleY,
+			text: tr("replay.newer"),
+			action: func() {
+				g.replayPage--
+				g.initUI()
+			},
+		})
+	}
+	if start+replaysPerPage < len(g.savedGames) {
+		g.buttons = append(g.buttons, &Button{
+			x:    float64(g.screenWidth)/2 + 100*g.scaleX,
+			y:    450 * g.scaleY,
+			w:    100 * g.scaleX,
+			h:    30 * g.scaleY,
+			text: tr("replay.older"),
+			action: func() {
+				g.replayPage++
+				g.initUI()
+			},
+		})
+	}
+}
+
+// initReplayUI creates the replay navigation controls above the board
+func (g *ConnectFourGame) initReplayUI() {
+	// Back button
+	g.buttons = append(g.buttons, &Button{
+		x:    float64(g.screenWidth) - 120*g.scaleX,
+		y:    20 * g.scaleY,
+		w:    100 * g.scaleX,
+		h:    30 * g.scaleY,
+		text: tr("common.back"),
+		action: func() {
+			g.replayPlaying = false
+			g.transitionTo(g.replayReturnState)
+		},
+	})
+
+	// Practice button, playing the game again against the recorded replies
+	g.buttons = append(g.buttons, &Button{
+		x:    float64(g.screenWidth) - 120*g.scaleX,
+		y:    60 * g.scaleY,
+		w:    100 * g.scaleX,
+		h:    30 * g.scaleY,
+		text: tr("replay.practice"),
+		action: func() {
+			g.startPractice(g.replayGame)
+		},
+	})
+
+	playText := tr("replay.play")
+	if g.replayPlaying {
+		playText = tr("replay.pause")
+	}
+	controls := []struct {
+		text   string
+		action func()
+	}{
+		{tr("replay.first"), func() { g.seekReplay(0) }},
+		{tr("replay.prev"), func() { g.seekReplay(g.replay.Ply() - 1) }},
+		{playText, func() { g.toggleReplayPlaying() }},
+		{tr("replay.next"), func() { g.seekReplay(g.replay.Ply() + 1) }},
+		{tr("replay.last"), func() { g.seekReplay(g.replay.Len()) }},
+		{tr("replay.slower"), func() { g.replaySpeed = max(0, g.replaySpeed-1) }},
+		{tr("replay.faster"), func() { g.replaySpeed = min(len(replaySpeeds)-1, g.replaySpeed+1) }},
+	}
+
+	buttonWidth := 70 * g.scaleX
+	gap := 10 * g.scaleX
+	totalWidth := float64(len(controls))*buttonWidth + float64(len(controls)-1)*gap
+	for i, control := range controls {
+		action := control.action
+		g.buttons = append(g.buttons, &Button{
+			x:    float64(g.screenWidth)/2 - totalWidth/2 + float64(i)*(buttonWidth+gap),
+			y:    g.boardOffsetY - 50*g.scaleY,
+			w:    buttonWidth,
+			h:    30 * g.scaleY,
+			text: control.text,
+			action: func() {
+				action()
+				g.initUI() // Refresh the Play/Pause label
+			},
+		})
+	}
+}
+
+// seekReplay stops autoplay and jumps to the position after ply moves
+func (g *ConnectFourGame) seekReplay(ply int) {
+	g.replayPlaying = false
+	g.replay.Seek(ply)
+}
+
+// toggleReplayPlaying starts or stops autoplay, restarting from the beginning at the end
+func (g *ConnectFourGame) toggleReplayPlaying() {
+	g.replayPlaying = !g.replayPlaying
+	g.replayTimer = 0
+	if g.replayPlaying && g.replay.Ply() == g.replay.Len() {
+		g.replay.Seek(0)
+	}
+}
+
+// updateReplay handles keyboard stepping and autoplay in the replay viewer
+func (g *ConnectFourGame) updateReplay() {
+	// Space plays and pauses, unless it is pressing a focused button
+	if action(ActionPause) && focusedIndex(g.focusOrder()) < 0 {
+		g.toggleReplayPlaying()
+		g.initUI()
+	}
+	if action(ActionLeft) {
+		g.seekReplay(g.replay.Ply() - 1)
+		g.initUI()
+	}
+	if action(ActionRight) {
+		g.seekReplay(g.replay.Ply() + 1)
+		g.initUI()
+	}
+
+	if g.replayPlaying {
+		g.replayTimer += tickDuration()
+		if g.replayTimer >= time.Duration(float64(time.Second)/replaySpeeds[g.replaySpeed]) {
+			g.replayTimer = 0
+			if !g.replay.Step(1) || g.replay.Ply() == g.replay.Len() {
+				g.replayPlaying = false
+				g.initUI()
+			}
+		}
+	}
+}
+
+// drawReplayListScreen renders the list of saved games
+func (g *ConnectFourGame) drawReplayListScreen(screen *ebiten.Image) {
+	title := tr("replay.title")
+	if len(g.savedGames) == 0 {
+		title = tr("replay.empty")
+	}
+	titleBounds := boundString(fontFace, title)
+	text.Draw(screen, title, fontFace,
+		g.screenWidth/2-titleBounds.Dx()/2, int(80*g.scaleY), colorText)
+
+	// Draw buttons
+	for _, btn := range g.buttons {
+		g.drawButton(screen, btn)
+	}
+}
+
+// drawReplayScreen renders the replayed position and the move counter, and
+// describes any disc the cursor is over
+func (g *ConnectFourGame) drawReplayScreen(screen *ebiten.Image) {
+	header := fmt.Sprintf("%s - %s", g.replayGame.Played.Local().Format("2006-01-02 15:04"), g.replayGame.resultText())
+	headerBounds := boundString(fontFace, header)
+	text.Draw(screen, header, fontFace,
+		g.screenWidth/2-headerBounds.Dx()/2, int(40*g.scaleY), colorText)
+
+	status := tr("replay.status", g.replay.Ply(), g.replay.Len(), replaySpeeds[g.replaySpeed])
+	statusBounds := boundString(fontFace, status)
+	text.Draw(screen, status, fontFace,
+		g.screenWidth/2-statusBounds.Dx()/2, int(70*g.scaleY), colorText)
+
+	originX, originY := g.boardOrigin()
+	g.drawBoardAt(screen, g.replay.Board(), originX, originY, g.cellSize)
+	if g.showCoordinates {
+		g.drawCoordinates(screen, originX, originY, g.cellSize)
+	}
+
+	// Draw buttons
+	for _, btn := range g.buttons {
+		g.drawButton(screen, btn)
+	}
+
+	// Over everything, the move under the cursor
+	g.drawReplayTooltip(screen, originX, originY)
+}
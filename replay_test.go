@@ -0,0 +1,106 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// movesFrom turns 1-based column digits into alternating moves, the player
+// first, as boardFromMoves plays them
+func movesFrom(columns string) []Move {
+	moves := []Move{}
+	side := Player
+	for _, ch := range columns {
+		moves = append(moves, Move{Column: int(ch - '1'), Player: side})
+		side = opponentOf(side)
+	}
+	return moves
+}
+
+func TestLoadSavedGamesSkipsCorrupt(t *testing.T) {
+	store := newMemoryStorage()
+	older := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	for _, game := range []SavedGame{
+		{Username: "alice", Played: older, Winner: Player, Moves: movesFrom("1213141")},
+		{Username: "alice", Played: newer, Winner: Empty, Moves: movesFrom("44")},
+	} {
+		if err := saveGame(store, game); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store.Save(savedGamesDir+"/truncated.json", []byte(`{"username": "alice", "moves": [`))
+	store.Save(savedGamesDir+"/garbage.json", []byte("not json at all"))
+
+	games, err := loadSavedGames(store)
+	if err != nil {
+		t.Fatalf("loadSavedGames: %v", err)
+	}
+	if len(games) != 2 {
+		t.Fatalf("loaded %d games, want the 2 readable ones", len(games))
+	}
+	if !games[0].Played.Equal(newer) || !games[1].Played.Equal(older) {
+		t.Errorf("games played at %v and %v, want newest first", games[0].Played, games[1].Played)
+	}
+	if len(games[1].Moves) != 7 || games[1].Winner != Player {
+		t.Errorf("older game loaded as %d moves won by %d", len(games[1].Moves), games[1].Winner)
+	}
+
+	// An empty store has no games and no error
+	if games, err := loadSavedGames(newMemoryStorage()); err != nil || len(games) != 0 {
+		t.Errorf("empty store gave %d games, error %v", len(games), err)
+	}
+}
+
+func TestReplayStepAndSeekBounds(t *testing.T) {
+	r := NewReplay(movesFrom("4455667"), VariantStandard)
+	if r.Len() != 7 || r.Ply() != 0 {
+		t.Fatalf("replay of 7 moves has length %d at ply %d", r.Len(), r.Ply())
+	}
+
+	if r.Step(-1) {
+		t.Error("stepped back from the start")
+	}
+	for ply := 1; ply <= 7; ply++ {
+		if !r.Step(1) || r.Ply() != ply {
+			t.Fatalf("step %d reached ply %d", ply, r.Ply())
+		}
+	}
+	if r.Step(1) || r.Ply() != 7 {
+		t.Errorf("stepped past the end to ply %d", r.Ply())
+	}
+
+	for _, tt := range []struct {
+		seek, want int
+	}{
+		{3, 3}, {0, 0}, {-5, 0}, {7, 7}, {100, 7},
+	} {
+		r.Seek(tt.seek)
+		if r.Ply() != tt.want {
+			t.Errorf("Seek(%d) went to ply %d, want %d", tt.seek, r.Ply(), tt.want)
+		}
+		if want := boardFromMoves(t, "4455667"[:tt.want]); r.Board() != want {
+			t.Errorf("Seek(%d) shows the wrong position", tt.seek)
+		}
+	}
+	if !r.Step(-3) || r.Ply() != 4 {
+		t.Errorf("stepping back 3 from the end reached ply %d", r.Ply())
+	}
+}
+
+func TestReplayStopsAtBadMoves(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		moves []Move
+		want  int
+	}{
+		{"moves after the win", movesFrom("121314155"), 7},
+		{"column off the board", append(movesFrom("44"), Move{Column: Columns, Player: Player}), 2},
+		{"same side twice", append(movesFrom("4"), Move{Column: 3, Player: Player}), 1},
+		{"full column", movesFrom("1111111"), 6},
+	} {
+		if got := NewReplay(tt.moves, VariantStandard).Len(); got != tt.want {
+			t.Errorf("%s: replay has %d moves, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// Replay tooltip layout, in logical pixels
+const (
+	replayTipLineHeight = 18
+	replayTipPadding    = 8
+	replayTipOffset     = 16 // From the cursor to the tooltip's corner
+)
+
+// moveAt returns the index of the move that put the disc at row, col,
+// among the moves the replay has applied, and false if no such move did
+func (r *Replay) moveAt(row, col int) (int, bool) {
+	var board GameBoard
+	for i, move := range r.moves[:r.ply] {
+		if move.Column == col && landingRow(board, col) == row {
+			return i, true
+		}
+		board = dropPiece(board, move.Column, move.Player)
+	}
+	return 0, false
+}
+
+// cachedOutcome returns the outcome for toMove on board if the solver has
+// already worked it out, for the game analysis or assist mode's checks. It
+// never searches, so it is cheap enough to call while drawing.
+func (g *ConnectFourGame) cachedOutcome(board GameBoard, toMove int) (int, bool) {
+	if g.analysis != nil {
+		if outcome, ok := g.analysis.cache[analysisKey(board, toMove)]; ok {
+			return outcome, true
+		}
+	}
+	if gameVariant == VariantStandard {
+		// Assist mode searches the position after each move one ply short
+		if outcome, ok := outcomeCache[outcomeKey{HashBoard(board), toMove, assistDepth - 1, VariantStandard}]; ok {
+			return outcome, true
+		}
+	}
+	return SolveUnknown, false
+}
+
+// replayTooltipLines describes the move with the given index: its number and
+// cell, who played it and, if known, the outcome it left the mover
+func (g *ConnectFourGame) replayTooltipLines(ply int) []string {
+	move := g.replay.moves[ply]
+	var before GameBoard
+	for _, m := range g.replay.moves[:ply] {
+		before = dropPiece(before, m.Column, m.Player)
+	}
+	after := dropPiece(before, move.Column, move.Player)
+
+	mover := g.replayGame.Username
+	if move.Player == Computer {
+		mover = tr("game.computer")
+	}
+	lines := []string{
+		tr("replay.tip_move", ply+1, cellName(landingRow(before, move.Column), move.Column)),
+		tr("replay.tip_player", mover),
+	}
+
+	evaluation := tr("replay.tip_unevaluated")
+	if g.replayGame.Variant == VariantStandard {
+		switch {
+		case checkWin(after, move.Player):
+			evaluation = tr("replay.tip_outcome", mover, outcomeText(SolveWin))
+		case isBoardFull(after):
+			evaluation = tr("replay.tip_outcome", mover, outcomeText(SolveUnknown))
+		default:
+			// The opponent's outcome is the reverse of the mover's
+			if outcome, ok := g.cachedOutcome(after, opponentOf(move.Player)); ok {
+				evaluation = tr("replay.tip_outcome", mover, outcomeText(-outcome))
+			}
+		}
+	}
+	return append(lines, evaluation)
+}
+
+// drawReplayTooltip describes the move under the cursor when it is over one
+// of the discs on the replayed board
+func (g *ConnectFourGame) drawReplayTooltip(screen *ebiten.Image, originX, originY float64) {
+	cx, cy := ebiten.CursorPosition()
+	row, col, ok := g.cellAt(float64(cx), float64(cy), originX, originY)
+	if !ok {
+		return
+	}
+	board := g.replay.Board()
+	if board.At(row, col) == Empty {
+		return
+	}
+	ply, ok := g.replay.moveAt(row, col)
+	if !ok {
+		// Every disc comes from a move, but a tooltip is no place to fail
+		return
+	}
+
+	lines := g.replayTooltipLines(ply)
+	lineHeight, padding := g.px(replayTipLineHeight), g.px(replayTipPadding)
+	width := 0
+	for _, line := range lines {
+		width = max(width, boundString(fontFace, line).Dx())
+	}
+	w, h := width+2*padding, len(lines)*lineHeight+padding
+
+	// Below right of the cursor, flipped to stay on screen
+	x, y := cx+g.px(replayTipOffset), cy+g.px(replayTipOffset)
+	if x+w > g.screenWidth {
+		x = cx - g.px(replayTipOffset) - w
+	}
+	if y+h > g.screenHeight {
+		y = cy - g.px(replayTipOffset) - h
+	}
+
+	drawRect(screen, float64(x), float64(y), float64(w), float64(h), color.RGBA{40, 40, 40, 220})
+	for i, line := range lines {
+		text.Draw(screen, line, fontFace, x+padding, y+(i+1)*lineHeight-g.px(2), colorButtonText)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import "time"
+
+// Time the window size must hold still before the layout is rebuilt
+const resizeSettleTime = 100 * time.Millisecond
+
+// ResizeDebouncer holds back layout rebuilds while the window is being
+// resized, so dragging an edge rebuilds once at the end instead of on every
+// frame of the drag
+type ResizeDebouncer struct {
+	width, height int       // Latest size seen
+	changed       time.Time // When the latest size was first seen
+}
+
+// settled records the window size seen at now and reports whether it has
+// held for long enough to rebuild the layout. The very first size settles
+// straight away so the game doesn't start with the wrong layout.
+func (d *ResizeDebouncer) settled(width, height int, now time.Time) bool {
+	first := d.changed.IsZero()
+	if first || width != d.width || height != d.height {
+		d.width, d.height, d.changed = width, height, now
+		return first
+	}
+	return now.Sub(d.changed) >= resizeSettleTime
+}
+
+// resizeClock tells the time for resize debouncing; tests replace it to step
+// through a drag without waiting
+var resizeClock = time.Now
+
+// applyWindowSize rebuilds the layout for the window's size once it has
+// stopped changing. The size comes from Layout rather than
+// ebiten.WindowSize, which reports 0x0 in browsers.
+func (g *ConnectFourGame) applyWindowSize() {
+	w, h := g.layoutWidth, g.layoutHeight
+	if w <= 0 || h <= 0 || (w == g.screenWidth && h == g.screenHeight) ||
+		!g.resize.settled(w, h, resizeClock()) {
+		return
+	}
+	g.screenWidth = w
+	g.screenHeight = h
+	g.updateLayout()
+	g.relayout()
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// fakeResizeClock makes resizeClock return *now until the test ends
+func fakeResizeClock(t *testing.T, now *time.Time) {
+	clock := resizeClock
+	resizeClock = func() time.Time { return *now }
+	t.Cleanup(func() { resizeClock = clock })
+}
+
+func TestResizesAreCoalesced(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	fakeResizeClock(t, &now)
+
+	g := newConnectFourGame(newMemoryStorage())
+	g.layoutWidth, g.layoutHeight = g.screenWidth, g.screenHeight
+	g.applyWindowSize()
+
+	// The first size seen settles straight away
+	g.layoutWidth, g.layoutHeight = 900, 700
+	g.applyWindowSize()
+	if g.screenWidth != 900 || g.screenHeight != 700 {
+		t.Fatalf("first size gave a %dx%d layout, want 900x700", g.screenWidth, g.screenHeight)
+	}
+
+	// Dragging an edge for half a second, a new size every frame
+	frame := time.Second / 60
+	rebuilds := 0
+	for w := 910; w <= 1200; w += 10 {
+		now = now.Add(frame)
+		g.layoutWidth = w
+		g.applyWindowSize()
+		if g.screenWidth != 900 {
+			rebuilds++
+		}
+	}
+	if rebuilds != 0 {
+		t.Errorf("layout rebuilt %d times during the drag, want none", rebuilds)
+	}
+
+	// Once the size holds still the layout is rebuilt once, for the final size
+	now = now.Add(resizeSettleTime - time.Millisecond)
+	g.applyWindowSize()
+	if g.screenWidth != 900 {
+		t.Fatalf("layout rebuilt at width %d before the size settled", g.screenWidth)
+	}
+	now = now.Add(time.Millisecond)
+	g.applyWindowSize()
+	if g.screenWidth != 1200 || g.screenHeight != 700 {
+		t.Errorf("settled layout is %dx%d, want 1200x700", g.screenWidth, g.screenHeight)
+	}
+	if want := 60 * math.Min(1200/float64(g.baseWidth), 700/float64(g.baseHeight)); g.cellSize != want {
+		t.Errorf("cell size %g not rebuilt for 1200x700", g.cellSize)
+	}
+}
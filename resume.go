@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log"
+	"time"
+)
+
+// ResumeGame is an unfinished game saved so it can be continued later
+type ResumeGame struct {
+	Saved      time.Time `json:"saved"`
+	Difficulty int       `json:"difficulty"`
+	Moves      []Move    `json:"moves"`
+	Variant    int       `json:"variant,omitempty"`
+	Takebacks  int       `json:"takebacks,omitempty"` // Used so far, still counted once resumed
+}
+
+// resumeKey returns the storage key holding a user's unfinished game
+func resumeKey(username string) string {
+	return "resume/" + userFileName(username) + ".json"
+}
+
+// saveResume writes a user's unfinished game to storage
+func saveResume(store Storage, username string, game ResumeGame) error {
+	data, err := json.MarshalIndent(game, "", "  ")
+	if err != nil {
+		return err
+	}
+	return store.Save(resumeKey(username), data)
+}
+
+// loadResume reads a user's unfinished game, returning nil if there isn't a usable one
+func loadResume(store Storage, username string) *ResumeGame {
+	data, err := store.Load(resumeKey(username))
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("loading unfinished game: %v", err)
+		}
+		return nil
+	}
+	var game ResumeGame
+	if err := json.Unmarshal(data, &game); err != nil {
+		log.Printf("loading unfinished game: %v", err)
+		return nil
+	}
+	if game.Variant < 0 || game.Variant >= numVariants {
+		return nil
+	}
+	return &game
+}
+
+// deleteResume removes a user's unfinished game
+func deleteResume(store Storage, username string) {
+	if err := store.Delete(resumeKey(username)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Printf("deleting unfinished game: %v", err)
+	}
+}
+
+// suspendGame saves the game in progress so it can be resumed later
+func (g *ConnectFourGame) suspendGame() {
+	if g.state != StateGame || !g.gameInProgress || g.hotseat || g.seatSwapped || g.online != nil || g.fromPosition ||
+		len(g.moves) == 0 {
+		return
+	}
+
+	game := ResumeGame{
+		Saved:      time.Now(),
+		Difficulty: g.difficulty,
+		Moves:      g.moves,
+		Variant:    gameVariant,
+		Takebacks:  g.takebacks,
+	}
+	if err := saveResume(g.userStore, g.username, game); err != nil {
+		log.Printf("saving unfinished game: %v", err)
+		return
+	}
+	g.pendingResume = &game
+}
+
+// resumeGame rebuilds a saved game and continues it, with the computer
+// moving straight away if it was its turn
+func (g *ConnectFourGame) resumeGame(game *ResumeGame) {
+	g.initializeGame()
+	g.setDifficulty(game.Difficulty)
+	setVariant(game.Variant)
+
+	// Stop at the first move that doesn't fit, in case the file was edited
+	replay := NewReplay(game.Moves, game.Variant)
+	replay.Seek(replay.Len())
+	board := replay.Board()
+	if _, over := gameOver(board); over {
+		// Nothing left to play, e.g. the file was edited by hand
+		log.Printf("discarding unfinished game: the game is already over")
+		g.discardResume()
+		g.transitionTo(StateGameMode)
+		return
+	}
+	g.setBoard(board)
+	g.moves = append([]Move(nil), replay.moves...)
+	// Whose turn it is follows from the moves that were kept, not the file
+	if len(g.moves) > 0 {
+		g.setStarter(g.moves[0].Player)
+		g.turn = opponentOf(g.moves[len(g.moves)-1].Player)
+	}
+	g.takebacks = game.Takebacks
+	if g.undoBudget != undoUnlimited {
+		g.undoBudget = max(0, g.undoBudget-game.Takebacks)
+	}
+
+	deleteResume(g.userStore, g.username)
+	g.pendingResume = nil
+	g.transitionTo(StateGame)
+}
+
+// discardResume throws away the user's unfinished game
+func (g *ConnectFourGame) discardResume() {
+	deleteResume(g.userStore, g.username)
+	g.pendingResume = nil
+}
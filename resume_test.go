@@ -0,0 +1,136 @@
+package main
+
+import "testing"
+
+func TestResumeTurnFollowsMoves(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  string
+		moves int
+		turn  int
+	}{
+		{
+			name:  "computer to move",
+			file:  `{"turn": 1, "moves": [{"column": 3, "player": 1}, {"column": 3, "player": 2}, {"column": 2, "player": 1}]}`,
+			moves: 3,
+			turn:  Computer,
+		},
+		{
+			name:  "player to move",
+			file:  `{"turn": 2, "moves": [{"column": 3, "player": 1}, {"column": 4, "player": 2}]}`,
+			moves: 2,
+			turn:  Player,
+		},
+		{
+			name:  "computer started",
+			file:  `{"moves": [{"column": 3, "player": 2}]}`,
+			moves: 1,
+			turn:  Player,
+		},
+		{
+			name:  "moves after a bad one are dropped",
+			file:  `{"turn": 1, "moves": [{"column": 3, "player": 1}, {"column": 3, "player": 2}, {"column": 3, "player": 1}, {"column": 9, "player": 2}]}`,
+			moves: 3,
+			turn:  Computer,
+		},
+		{
+			name:  "side moving twice",
+			file:  `{"turn": 2, "moves": [{"column": 3, "player": 1}, {"column": 2, "player": 1}]}`,
+			moves: 1,
+			turn:  Computer,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemoryStorage()
+			g := newConnectFourGame(store)
+			g.username = "alice"
+			g.userStore = store
+			if err := store.Save(resumeKey("alice"), []byte(tt.file)); err != nil {
+				t.Fatal(err)
+			}
+			game := loadResume(store, "alice")
+			if game == nil {
+				t.Fatal("loadResume returned nil")
+			}
+
+			g.resumeGame(game)
+			g.cancelComputerMove()
+			if len(g.moves) != tt.moves {
+				t.Errorf("resumed with %d moves, want %d", len(g.moves), tt.moves)
+			}
+			if g.turn != tt.turn {
+				t.Errorf("turn = %d, want %d", g.turn, tt.turn)
+			}
+		})
+	}
+}
+
+func TestResumeRoundTrip(t *testing.T) {
+	store := newMemoryStorage()
+	g := newConnectFourGame(store)
+	g.username, g.userStore = "alice", store
+	g.frame = 1
+	g.startNewGame()
+	g.setDifficulty(2)
+	computerToMove(t, g, "4453")
+	g.turn = Player
+	g.takebacks = 1
+
+	g.suspendGame()
+	saved := loadResume(store, "alice")
+	if saved == nil {
+		t.Fatal("suspending left no game to resume")
+	}
+	if len(saved.Moves) != 4 || saved.Difficulty != 2 || saved.Takebacks != 1 || saved.Variant != VariantStandard {
+		t.Errorf("saved %d moves at difficulty %d with %d takebacks, variant %d",
+			len(saved.Moves), saved.Difficulty, saved.Takebacks, saved.Variant)
+	}
+
+	resumed := newConnectFourGame(store)
+	resumed.username, resumed.userStore = "alice", store
+	resumed.resumeGame(saved)
+	resumed.cancelComputerMove()
+	if resumed.board != boardFromMoves(t, "4453") || resumed.turn != Player {
+		t.Errorf("resumed with the wrong position or turn %d", resumed.turn)
+	}
+	if resumed.difficulty != 2 || resumed.takebacks != 1 || resumed.state != StateGame {
+		t.Errorf("resumed at difficulty %d with %d takebacks in state %d", resumed.difficulty, resumed.takebacks, resumed.state)
+	}
+	if loadResume(store, "alice") != nil {
+		t.Error("resumed game still saved")
+	}
+
+	// Unusable files resume nothing
+	for _, file := range []string{`{"moves": [`, `{"variant": 99, "moves": []}`} {
+		store.Save(resumeKey("alice"), []byte(file))
+		if game := loadResume(store, "alice"); game != nil {
+			t.Errorf("loadResume(%s) = %+v, want nil", file, game)
+		}
+	}
+}
+
+func TestResumeStartsComputerSearch(t *testing.T) {
+	calls := heldSearch(t)
+	store := newMemoryStorage()
+	g := newConnectFourGame(store)
+	g.username, g.userStore = "alice", store
+	g.settings.ReducedMotion = true
+
+	g.resumeGame(&ResumeGame{Moves: movesFrom("445")})
+	g.blunderRate = 0
+	if g.turn != Computer || g.state != StateGame {
+		t.Fatalf("resumed in state %d with %d to move, want the computer", g.state, g.turn)
+	}
+
+	g.updateComputerMove()
+	call := <-calls
+	if call.board != boardFromMoves(t, "445") {
+		t.Error("computer searched a position other than the resumed one")
+	}
+	answered(t, g, call, 4)
+	ticksUntil(t, g, func() bool { return g.turn == Player })
+	if want := boardFromMoves(t, "4455"); g.board != want {
+		t.Error("computer's reply not played on the resumed board")
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"sort"
+	"time"
+)
+
+// Storage directory holding finished games
+const savedGamesDir = "games"
+
+// Move is a single piece dropped by one side
+type Move struct {
+	Column int `json:"column"`
+	Player int `json:"player"`
+}
+
+// SavedGame is the record of a finished game
+type SavedGame struct {
+	Username   string    `json:"username"`
+	Played     time.Time `json:"played"`
+	Difficulty int       `json:"difficulty"`
+	Winner     int       `json:"winner"` // Player, Computer or Empty for a tie
+	Moves      []Move    `json:"moves"`
+	Guest      bool      `json:"guest,omitempty"`   // Played in a guest session
+	Variant    int       `json:"variant,omitempty"` // Rules played, VariantStandard if absent
+}
+
+// resultText describes the outcome of the game from the player's point of view
+func (sg *SavedGame) resultText() string {
+	switch sg.Winner {
+	case Player:
+		return tr("result.win")
+	case Computer:
+		return tr("result.loss")
+	default:
+		return tr("result.tie")
+	}
+}
+
+// savedGameKey returns the storage key for a game finished at t
+func savedGameKey(t time.Time) string {
+	return savedGamesDir + "/" + t.UTC().Format("20060102-150405.000") + ".json"
+}
+
+// saveGame writes a finished game to storage
+func saveGame(store Storage, game SavedGame) error {
+	data, err := json.MarshalIndent(game, "", "  ")
+	if err != nil {
+		return err
+	}
+	return store.Save(savedGameKey(game.Played), data)
+}
+
+// gamesStore returns where finished games are saved: the shared storage, or
+// for a guest the session's memory, so guests leave no games behind
+func (g *ConnectFourGame) gamesStore() Storage {
+	if g.guest {
+		return g.userStore
+	}
+	return g.storage
+}
+
+// loadSavedGames reads every saved game, newest first. Games that can't be
+// read or decoded are skipped so one bad file doesn't hide the rest.
+func loadSavedGames(store Storage) ([]SavedGame, error) {
+	keys, err := store.List(savedGamesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	games := []SavedGame{}
+	for _, key := range keys {
+		data, err := store.Load(key)
+		if err != nil {
+			log.Printf("skipping saved game %s: %v", key, err)
+			continue
+		}
+		var game SavedGame
+		if err := json.Unmarshal(data, &game); err != nil {
+			log.Printf("skipping saved game %s: %v", key, err)
+			continue
+		}
+		games = append(games, game)
+	}
+
+	sort.SliceStable(games, func(i, j int) bool {
+		return games[i].Played.After(games[j].Played)
+	})
+	return games, nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"log"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Size of the board cells and margin in exported screenshots
+const (
+	screenshotCellSize = 60
+	screenshotMargin   = 20
+)
+
+// screenshotFileName builds a file name from the capture time and game result
+func screenshotFileName(t time.Time, result string) string {
+	return fmt.Sprintf("connectfour-%s-%s.png", t.Format("2006-01-02-150405"), result)
+}
+
+// encodeScreenshot converts a rendered image into PNG data
+func encodeScreenshot(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// resultSlug describes the state of the game for use in file names
+func (g *ConnectFourGame) resultSlug() string {
+	if g.gameInProgress {
+		return "in-progress"
+	}
+	switch winner, _ := gameOver(g.board); winner {
+	case Player:
+		return "you-won"
+	case Computer:
+		return "computer-won"
+	default:
+		return "tie"
+	}
+}
+
+// renderBoardImage draws the current board into an offscreen image
+func (g *ConnectFourGame) renderBoardImage() *image.RGBA {
+	width := Columns*screenshotCellSize + 2*screenshotMargin
+	height := Rows*screenshotCellSize + 2*screenshotMargin
+
+	offscreen := ebiten.NewImage(width, height)
+	defer offscreen.Deallocate()
+	offscreen.Fill(colorBackground)
+	g.drawBoardAt(offscreen, g.board, screenshotMargin, screenshotMargin, screenshotCellSize)
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	offscreen.ReadPixels(img.Pix)
+	return img
+}
+
+// saveScreenshot exports the current board as a PNG and reports where it went
+func (g *ConnectFourGame) saveScreenshot() {
+	data, err := encodeScreenshot(g.renderBoardImage())
+	if err == nil {
+		var path string
+		path, err = writeScreenshot(screenshotFileName(time.Now(), g.resultSlug()), data)
+		if err == nil {
+			g.showToast(tr("game.screenshot_saved", path))
+			return
+		}
+	}
+	log.Printf("saving screenshot: %v", err)
+	g.showToast(tr("game.screenshot_failed"))
+}
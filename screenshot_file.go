@@ -0,0 +1,74 @@
+//go:build !js
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// screenshotDir returns the user's pictures folder, or a folder in the config
+// directory when there isn't one
+func screenshotDir() (string, error) {
+	if home, err := os.UserHomeDir(); err == nil {
+		pictures := filepath.Join(home, "Pictures")
+		if info, err := os.Stat(pictures); err == nil && info.IsDir() {
+			return pictures, nil
+		}
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, "ConnectFour", "screenshots")
+	return dir, os.MkdirAll(dir, 0o755)
+}
+
+// writeScreenshot saves PNG data to the screenshot folder, returning the file path
+func writeScreenshot(name string, data []byte) (string, error) {
+	dir, err := screenshotDir()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, name)
+	return path, os.WriteFile(path, data, 0o644)
+}
+
+// writeReplayFile saves an exported game to the replays folder in the config
+// directory, returning the file path
+func writeReplayFile(name string, data []byte) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, "ConnectFour", "replays")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, name)
+	return path, os.WriteFile(path, data, 0o644)
+}
+
+// writeClipboard puts text on the clipboard through the platform's
+// clipboard command
+func writeClipboard(s string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		cmd = exec.Command("clip")
+	case "darwin":
+		cmd = exec.Command("pbcopy")
+	default:
+		if _, err := exec.LookPath("wl-copy"); err == nil {
+			cmd = exec.Command("wl-copy")
+		} else {
+			cmd = exec.Command("xclip", "-selection", "clipboard")
+		}
+	}
+	cmd.Stdin = strings.NewReader(s)
+	return cmd.Run()
+}
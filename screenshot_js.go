@@ -0,0 +1,55 @@
+//go:build js
+
+package main
+
+import (
+	"errors"
+	"syscall/js"
+)
+
+// writeScreenshot offers PNG data to the browser as a download, returning the file name
+func writeScreenshot(name string, data []byte) (string, error) {
+	return download(name, "image/png", data)
+}
+
+// writeReplayFile offers an exported game to the browser as a download,
+// returning the file name
+func writeReplayFile(name string, data []byte) (string, error) {
+	return download(name, "application/json", data)
+}
+
+// download hands data to the browser as a file download
+func download(name, mimeType string, data []byte) (string, error) {
+	array := js.Global().Get("Uint8Array").New(len(data))
+	js.CopyBytesToJS(array, data)
+
+	blob := js.Global().Get("Blob").New([]any{array}, map[string]any{"type": mimeType})
+	url := js.Global().Get("URL").Call("createObjectURL", blob)
+
+	link := js.Global().Get("document").Call("createElement", "a")
+	link.Set("href", url)
+	link.Set("download", name)
+	link.Call("click")
+
+	// The download has taken its copy once the click is handled, so the URL
+	// can be let go on the next turn of the event loop
+	var revoke js.Func
+	revoke = js.FuncOf(func(js.Value, []js.Value) any {
+		js.Global().Get("URL").Call("revokeObjectURL", url)
+		revoke.Release()
+		return nil
+	})
+	js.Global().Call("setTimeout", revoke, 0)
+	return name, nil
+}
+
+// writeClipboard puts text on the clipboard through the browser. Browsers
+// without clipboard access report an error.
+func writeClipboard(s string) error {
+	clipboard := js.Global().Get("navigator").Get("clipboard")
+	if clipboard.IsUndefined() {
+		return errors.New("clipboard not available")
+	}
+	clipboard.Call("writeText", s)
+	return nil
+}
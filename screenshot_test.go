@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+	"time"
+)
+
+func TestScreenshotFileName(t *testing.T) {
+	for _, tt := range []struct {
+		at     time.Time
+		result string
+		want   string
+	}{
+		{time.Date(2024, 3, 7, 9, 5, 2, 0, time.UTC), "you-won", "connectfour-2024-03-07-090502-you-won.png"},
+		{time.Date(2024, 12, 31, 23, 59, 59, 999, time.UTC), "tie", "connectfour-2024-12-31-235959-tie.png"},
+		{time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), "in-progress", "connectfour-2025-01-01-000000-in-progress.png"},
+	} {
+		if got := screenshotFileName(tt.at, tt.result); got != tt.want {
+			t.Errorf("screenshotFileName(%v, %q) = %q, want %q", tt.at, tt.result, got, tt.want)
+		}
+	}
+}
+
+func TestResultSlug(t *testing.T) {
+	g := newConnectFourGame(newMemoryStorage())
+	for _, tt := range []struct {
+		name       string
+		moves      string
+		inProgress bool
+		want       string
+	}{
+		{"game going on", "4455", true, "in-progress"},
+		{"player won", "1213141", false, "you-won"},
+		{"computer won", "21213131", false, "computer-won"},
+		{"no winner", "", false, "tie"},
+	} {
+		g.board = boardFromMoves(t, tt.moves)
+		g.gameInProgress = tt.inProgress
+		if got := g.resultSlug(); got != tt.want {
+			t.Errorf("%s: resultSlug = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeScreenshot(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	img.Set(0, 0, color.RGBA{255, 0, 0, 255})
+	img.Set(2, 1, color.RGBA{0, 0, 255, 255})
+	img.Set(1, 0, colorBackground)
+
+	data, err := encodeScreenshot(img)
+	if err != nil {
+		t.Fatalf("encodeScreenshot: %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")) {
+		t.Fatalf("screenshot doesn't start with the PNG signature: % x", data[:8])
+	}
+	decoded, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decoding the screenshot: %v", err)
+	}
+	if decoded.Bounds() != img.Bounds() {
+		t.Fatalf("screenshot is %v, want %v", decoded.Bounds(), img.Bounds())
+	}
+	for y := range 2 {
+		for x := range 3 {
+			if got, want := color.RGBAModel.Convert(decoded.At(x, y)), img.At(x, y); got != want {
+				t.Errorf("pixel (%d, %d) = %v, want %v", x, y, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+// seatIsComputer reports whether the engine plays side in the current game.
+// The player's side is always a person; the computer's can be handed to a
+// friend at the same screen and back again mid-game.
+func (g *ConnectFourGame) seatIsComputer(side int) bool {
+	return side == Computer && !g.hotseat && g.online == nil
+}
+
+// canSwapSeat reports whether the computer's side may change hands now.
+// Tournament matches and practice games keep their seats.
+func (g *ConnectFourGame) canSwapSeat() bool {
+	return g.state == StateGame && g.gameInProgress && g.tournamentMatch == nil && g.practiceGame == nil &&
+		g.online == nil
+}
+
+// swapSeat hands the computer's side to a friend, or back to the computer,
+// carrying on from the same position and moves. A move the computer is
+// thinking about is dropped. Once swapped the game is a friendly one and
+// doesn't count towards the player's stats.
+func (g *ConnectFourGame) swapSeat() {
+	g.cancelComputerMove()
+	g.pendingColumn = -1
+	g.hotseat = !g.hotseat
+	g.seatSwapped = true
+
+	g.sideNames = [2]string{g.username, tr("game.computer")}
+	if g.hotseat {
+		g.sideNames[1] = tr("game.friend")
+	}
+	g.initUI()
+}
+
+// swapSeatText returns the label of the button handing over the computer's side
+func (g *ConnectFourGame) swapSeatText() string {
+	if g.hotseat {
+		return tr("game.seat_to_computer")
+	}
+	return tr("game.seat_to_friend")
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Storage keys for the remembered login and the key that signs it, and for
+// the key a game server issues session tokens with
+const (
+	sessionKey       = "session.json"
+	sessionSecretKey = "session_secret"
+	serverSecretKey  = "server_secret"
+)
+
+// How long "Remember me" keeps a user logged in
+const sessionLifetime = 30 * 24 * time.Hour
+
+// Session is a remembered login. The signature stops the file being edited
+// to log in as someone else without knowing the local secret.
+type Session struct {
+	Username  string    `json:"username"`
+	Expires   time.Time `json:"expires"`
+	Signature string    `json:"signature"`
+}
+
+// sessionSecret returns this machine's signing key, creating it the first time
+func sessionSecret(store Storage) ([]byte, error) {
+	return loadSecret(store, sessionSecretKey)
+}
+
+// loadSecret returns the signing key saved under key, creating it the first
+// time
+func loadSecret(store Storage, key string) ([]byte, error) {
+	data, err := store.Load(key)
+	if err == nil {
+		return hex.DecodeString(string(data))
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	if err := store.Save(key, []byte(hex.EncodeToString(secret))); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// sign returns the signature of the session's username and expiry
+func (s Session) sign(secret []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	fmt.Fprintf(mac, "%s\n%d", s.Username, s.Expires.Unix())
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// createSession remembers a login until sessionLifetime after now
+func createSession(store Storage, username string, now time.Time) error {
+	secret, err := sessionSecret(store)
+	if err != nil {
+		return err
+	}
+	session := Session{Username: username, Expires: now.Add(sessionLifetime).Truncate(time.Second)}
+	session.Signature = session.sign(secret)
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return err
+	}
+	return store.Save(sessionKey, data)
+}
+
+// loadSession returns the remembered username if there is a session that
+// is correctly signed and hasn't expired
+func loadSession(store Storage, now time.Time) (string, bool) {
+	data, err := store.Load(sessionKey)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("loading session: %v", err)
+		}
+		return "", false
+	}
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		log.Printf("loading session: %v", err)
+		return "", false
+	}
+	secret, err := sessionSecret(store)
+	if err != nil {
+		log.Printf("loading session: %v", err)
+		return "", false
+	}
+	if !hmac.Equal([]byte(session.sign(secret)), []byte(session.Signature)) {
+		log.Printf("ignoring session: bad signature")
+		return "", false
+	}
+	if !now.Before(session.Expires) {
+		return "", false
+	}
+	return session.Username, true
+}
+
+// issueSessionToken signs in username until sessionLifetime after now, for
+// a game server to hand a client once it has checked who the client is. It
+// is the expiry and signature of a Session, as the server already knows the
+// name.
+func issueSessionToken(secret []byte, username string, now time.Time) string {
+	session := Session{Username: username, Expires: now.Add(sessionLifetime).Truncate(time.Second)}
+	return strconv.FormatInt(session.Expires.Unix(), 10) + "." + session.sign(secret)
+}
+
+// checkSessionToken reports whether token was issued with secret for
+// username and hasn't expired by now
+func checkSessionToken(secret []byte, username, token string, now time.Time) bool {
+	expires, signature, ok := strings.Cut(token, ".")
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if !ok || err != nil {
+		return false
+	}
+	session := Session{Username: username, Expires: time.Unix(unix, 0)}
+	return hmac.Equal([]byte(session.sign(secret)), []byte(signature)) && now.Before(session.Expires)
+}
+
+// serverTokensKey returns the storage key holding the session tokens game
+// servers issued a user, by server address
+func serverTokensKey(username string) string {
+	return "server_tokens/" + userFileName(username) + ".json"
+}
+
+// loadServerTokens reads the session tokens game servers issued a user
+func loadServerTokens(store Storage, username string) map[string]string {
+	tokens := map[string]string{}
+	data, err := store.Load(serverTokensKey(username))
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("loading server tokens: %v", err)
+		}
+		return tokens
+	}
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		log.Printf("loading server tokens: %v", err)
+		return map[string]string{}
+	}
+	return tokens
+}
+
+// saveServerToken remembers the session token the game server at addr
+// issued a user, or forgets it if token is empty
+func saveServerToken(store Storage, username, addr, token string) {
+	tokens := loadServerTokens(store, username)
+	if token == "" {
+		delete(tokens, addr)
+	} else {
+		tokens[addr] = token
+	}
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err == nil {
+		err = store.Save(serverTokensKey(username), data)
+	}
+	if err != nil {
+		log.Printf("saving server token: %v", err)
+	}
+}
+
+// deleteServerTokens forgets every session token game servers issued a user
+func deleteServerTokens(store Storage, username string) {
+	if err := store.Delete(serverTokensKey(username)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Printf("deleting server tokens: %v", err)
+	}
+}
+
+// serverAccountSecretKey returns the storage key of the secret a user signs
+// in to their account on the game server at addr with
+func serverAccountSecretKey(username, addr string) string {
+	return "server_accounts/" + userFileName(username) + "/" + userFileName(addr)
+}
+
+// serverAccountSecret returns the secret a user signs in to their account
+// on the game server at addr with, creating it the first time. It is random
+// and only ever sent to that server, so the password the user logs in to
+// this machine with never leaves it.
+func serverAccountSecret(store Storage, username, addr string) (string, error) {
+	secret, err := loadSecret(store, serverAccountSecretKey(username, addr))
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// serverLogins signs players in to a game server against the accounts it
+// holds itself, which have nothing to do with the accounts on the players'
+// machines. Players are greeted on their own goroutines, so signing in is
+// guarded by mu.
+type serverLogins struct {
+	mu     sync.Mutex
+	store  Storage
+	secret []byte
+}
+
+// loadServerLogins reads the accounts and signing key saved in store,
+// creating the key the first time
+func loadServerLogins(store Storage) (*serverLogins, error) {
+	secret, err := loadSecret(store, serverSecretKey)
+	if err != nil {
+		return nil, err
+	}
+	return &serverLogins{store: store, secret: secret}, nil
+}
+
+// signIn checks password against username's account on the server,
+// registering the account with that password the first time the name signs
+// in, and returns a session token for it issued at now. It reports false if
+// the password is wrong.
+func (l *serverLogins) signIn(username, password string, now time.Time) (string, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	account, found := loadAccount(l.store, username)
+	switch {
+	case found && !account.checkPassword(password):
+		return "", false
+	case !found:
+		if err := registerAccount(l.store, username, password); err != nil {
+			log.Printf("registering %s: %v", username, err)
+			return "", false
+		}
+	}
+	return issueSessionToken(l.secret, username, now), true
+}
+
+// check reports whether token is one this server issued for username that
+// hasn't expired by now
+func (l *serverLogins) check(username, token string, now time.Time) bool {
+	return checkSessionToken(l.secret, username, token, now)
+}
+
+// deleteSession forgets the remembered login
+func deleteSession(store Storage) {
+	if err := store.Delete(sessionKey); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Printf("deleting session: %v", err)
+	}
+}
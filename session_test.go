@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sessionNow is the fixed time the session tests are run at
+var sessionNow = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func TestSessionRoundTrip(t *testing.T) {
+	store := newMemoryStorage()
+	if _, ok := loadSession(store, sessionNow); ok {
+		t.Fatal("loaded a session before one was created")
+	}
+	if err := createSession(store, "alice", sessionNow); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		at   time.Time
+		ok   bool
+	}{
+		{"straight away", sessionNow, true},
+		{"a day before expiry", sessionNow.Add(sessionLifetime - 24*time.Hour), true},
+		{"at expiry", sessionNow.Add(sessionLifetime), false},
+		{"after expiry", sessionNow.Add(sessionLifetime + time.Hour), false},
+	} {
+		username, ok := loadSession(store, tt.at)
+		if ok != tt.ok || (ok && username != "alice") {
+			t.Errorf("%s: loadSession = %q, %v, want ok %v", tt.name, username, ok, tt.ok)
+		}
+	}
+
+	deleteSession(store)
+	if _, ok := loadSession(store, sessionNow); ok {
+		t.Error("loaded a deleted session")
+	}
+}
+
+func TestSessionTampering(t *testing.T) {
+	tamper := map[string]func(*Session){
+		"username":  func(s *Session) { s.Username = "mallory" },
+		"expiry":    func(s *Session) { s.Expires = s.Expires.Add(365 * 24 * time.Hour) },
+		"signature": func(s *Session) { s.Signature = "00" + s.Signature[2:] },
+	}
+	for name, change := range tamper {
+		store := newMemoryStorage()
+		if err := createSession(store, "alice", sessionNow); err != nil {
+			t.Fatal(err)
+		}
+		data, _ := store.Load(sessionKey)
+		var session Session
+		if err := json.Unmarshal(data, &session); err != nil {
+			t.Fatal(err)
+		}
+		change(&session)
+		data, _ = json.Marshal(session)
+		store.Save(sessionKey, data)
+
+		if username, ok := loadSession(store, sessionNow); ok {
+			t.Errorf("session with its %s changed logged in %q", name, username)
+		}
+	}
+
+	// Signed with another machine's secret
+	store := newMemoryStorage()
+	createSession(store, "alice", sessionNow)
+	store.Save(sessionSecretKey, []byte("00112233445566778899aabbccddeeff"))
+	if _, ok := loadSession(store, sessionNow); ok {
+		t.Error("session accepted under a different secret")
+	}
+
+	// Not a session at all
+	store.Save(sessionKey, []byte("{"))
+	if _, ok := loadSession(store, sessionNow); ok {
+		t.Error("corrupt session accepted")
+	}
+}
+
+func TestSessionSkipsLogin(t *testing.T) {
+	store := newMemoryStorage()
+	if err := createSession(store, "alice", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	g := newConnectFourGame(store)
+	if g.state == StateLogin || g.username != "alice" {
+		t.Errorf("remembered login: state %s, user %q", stateName(g.state), g.username)
+	}
+
+	expired := newMemoryStorage()
+	if err := createSession(expired, "alice", time.Now().Add(-sessionLifetime-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	g = newConnectFourGame(expired)
+	if g.state != StateLogin || g.username != "" {
+		t.Errorf("expired login: state %s, user %q", stateName(g.state), g.username)
+	}
+}
+
+func TestSessionToken(t *testing.T) {
+	secret, err := sessionSecret(newMemoryStorage())
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := issueSessionToken(secret, "alice", sessionNow)
+
+	for _, tt := range []struct {
+		name     string
+		username string
+		token    string
+		at       time.Time
+		ok       bool
+	}{
+		{"straight away", "alice", token, sessionNow, true},
+		{"a day before expiry", "alice", token, sessionNow.Add(sessionLifetime - 24*time.Hour), true},
+		{"at expiry", "alice", token, sessionNow.Add(sessionLifetime), false},
+		{"for someone else", "mallory", token, sessionNow, false},
+		{"a later expiry", "alice", "9" + token, sessionNow, false},
+		{"no expiry", "alice", token[strings.Index(token, "."):], sessionNow, false},
+		{"no signature", "alice", token[:strings.Index(token, ".")+1], sessionNow, false},
+		{"empty", "alice", "", sessionNow, false},
+	} {
+		if ok := checkSessionToken(secret, tt.username, tt.token, tt.at); ok != tt.ok {
+			t.Errorf("%s: checkSessionToken = %v, want %v", tt.name, ok, tt.ok)
+		}
+	}
+	if checkSessionToken([]byte("another machine"), "alice", token, sessionNow) {
+		t.Error("a token checked out with another machine's key")
+	}
+}
+
+func TestServerLoginsSignIn(t *testing.T) {
+	logins, err := loadServerLogins(newMemoryStorage())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first sign-in registers the name with its password
+	token, ok := logins.signIn("alice", "hunter22", sessionNow)
+	if !ok || !logins.check("alice", token, sessionNow) {
+		t.Fatalf("first sign-in gave %q, %v", token, ok)
+	}
+	if _, ok := logins.signIn("alice", "wrong", sessionNow); ok {
+		t.Error("signed in with the wrong password")
+	}
+	if again, ok := logins.signIn("alice", "hunter22", sessionNow); !ok || !logins.check("alice", again, sessionNow) {
+		t.Error("signing in again with the right password failed")
+	}
+	if logins.check("bob", token, sessionNow) {
+		t.Error("alice's token signed in bob")
+	}
+
+	// Only tokens this server issued are taken, not ones a client signs
+	client := newMemoryStorage()
+	secret, err := sessionSecret(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if logins.check("alice", issueSessionToken(secret, "alice", sessionNow), sessionNow) {
+		t.Error("a token signed with a client's key was taken")
+	}
+}
@@ -0,0 +1,528 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// Window size limits and defaults
+const (
+	defaultWindowWidth  = 800
+	defaultWindowHeight = 600
+	minWindowWidth      = 480
+	minWindowHeight     = 360
+	settingsSaveDelay   = time.Second // Wait after the last change before saving
+	defaultVolume       = 80
+)
+
+// settingsKey is the storage key holding the app-wide settings
+const settingsKey = "settings.json"
+
+// Settings holds preferences that apply to every user on this machine
+type Settings struct {
+	WindowWidth  int  `json:"window_width"`
+	WindowHeight int  `json:"window_height"`
+	Fullscreen   bool `json:"fullscreen"`
+
+	// Game ticks per second, one of tickRates, and vertical sync
+	TPS   int  `json:"tps"`
+	VSync bool `json:"vsync"`
+
+	// Drop to a lower tick rate on quiet screens such as the menus
+	ThrottleMenus bool `json:"throttle_menus"`
+
+	// Piece drop animation
+	DropGravity     float64 `json:"drop_gravity"`     // Cells per 60 Hz step, per step
+	DropRestitution float64 `json:"drop_restitution"` // Fraction of the speed kept when bouncing
+	DropBounce      bool    `json:"drop_bounce"`      // False for a flat drop
+
+	// Volume levels from 0 to 100
+	MusicVolume   int  `json:"music_volume"`
+	EffectsVolume int  `json:"effects_volume"`
+	Muted         bool `json:"muted"`
+
+	// Accessibility
+	ReducedMotion bool `json:"reduced_motion"` // Skip non-essential animation and the computer's thinking pause
+	ConfirmMoves  bool `json:"confirm_moves"`  // Moves take a second click to confirm
+
+	// Ring the most recently placed piece
+	HighlightLastMove bool `json:"highlight_last_move"`
+
+	// Shade the whole hovered column rather than just its top slot
+	ColumnHighlight bool `json:"column_highlight"`
+
+	// Show how much room is left in each column above the board
+	CapacityBars bool `json:"capacity_bars"`
+
+	// Draw a soft shadow under each disc
+	DiscShadows bool `json:"disc_shadows"`
+
+	// A character written on each seat's discs, the player's first and then
+	// the opponent's, or empty for plain discs
+	DiscLabels [2]string `json:"disc_labels"`
+
+	// Show the engine's assessment beside the board, effectively a hint
+	EvalBar bool `json:"eval_bar"`
+
+	// Warn before a move that loses by force and mark the cells the computer
+	// would win on, for learners
+	AssistMode bool `json:"assist_mode"`
+
+	// Takebacks allowed per game, one of undoLimits, undoUnlimited for any number
+	UndoLimit int `json:"undo_limit"`
+
+	// Let the computer resign once it has a proven loss
+	EngineResign bool `json:"engine_resign"`
+
+	// Hide swearing in online chat
+	MaskProfanity bool `json:"mask_profanity"`
+
+	// Ask the game server for rated games rather than casual ones
+	RatedOnline bool `json:"rated_online"`
+
+	// Ranked play, alternating who moves first against the computer
+	AlternateStart bool `json:"alternate_start"`
+
+	// Interface language, one of languages
+	Language string `json:"language"`
+
+	// Appearance and the difficulty new games start at
+	Theme      string `json:"theme"` // One of the theme ids
+	Difficulty int    `json:"difficulty"`
+
+	// Rules new games are played by, VariantStandard by default
+	Variant int `json:"variant"`
+
+	// Keys for the named actions, by key name, replacing the defaults of
+	// the actions listed
+	KeyBindings map[string][]string `json:"key_bindings,omitempty"`
+}
+
+// defaultSettings returns the settings used before anything has been saved
+func defaultSettings() Settings {
+	return Settings{
+		WindowWidth:       defaultWindowWidth,
+		WindowHeight:      defaultWindowHeight,
+		TPS:               baseTPS,
+		VSync:             true,
+		ThrottleMenus:     true,
+		DropGravity:       defaultDropGravity,
+		DropRestitution:   defaultDropRestitution,
+		DropBounce:        true,
+		HighlightLastMove: true,
+		ColumnHighlight:   true,
+		DiscShadows:       true,
+		CapacityBars:      true,
+		MaskProfanity:     true,
+		RatedOnline:       true,
+		MusicVolume:       defaultVolume,
+		EffectsVolume:     defaultVolume,
+		Language:          fallbackLanguage,
+		Theme:             themes[0].ID,
+		Difficulty:        DifficultyMedium,
+		UndoLimit:         undoUnlimited,
+	}
+}
+
+// loadSettings reads the saved settings, falling back to the defaults
+func loadSettings(store Storage) Settings {
+	settings := defaultSettings()
+	data, err := store.Load(settingsKey)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("loading settings: %v", err)
+		}
+		return settings
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		log.Printf("loading settings: %v", err)
+		return defaultSettings()
+	}
+
+	// Keep hand-edited physics values sensible
+	if settings.DropGravity <= 0 || settings.DropGravity > 1 {
+		settings.DropGravity = defaultDropGravity
+	}
+	settings.DropRestitution = math.Max(0, math.Min(0.9, settings.DropRestitution))
+	settings.MusicVolume = max(0, min(100, settings.MusicVolume))
+	settings.EffectsVolume = max(0, min(100, settings.EffectsVolume))
+	if settings.Difficulty < 0 || settings.Difficulty >= numDifficulties {
+		settings.Difficulty = DifficultyMedium
+	}
+	for i, label := range settings.DiscLabels {
+		settings.DiscLabels[i] = discLabel(label)
+	}
+	if settings.Variant < 0 || settings.Variant >= numVariants {
+		settings.Variant = VariantStandard
+	}
+	if !slices.Contains(tickRates, settings.TPS) {
+		settings.TPS = baseTPS
+	}
+	if !slices.Contains(undoLimits, settings.UndoLimit) {
+		settings.UndoLimit = undoUnlimited
+	}
+	return settings
+}
+
+// saveSettings writes the settings to storage
+func saveSettings(store Storage, settings Settings) error {
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return err
+	}
+	return store.Save(settingsKey, data)
+}
+
+// clampWindowSize keeps a restored window size between the minimum size and
+// the monitor size. A monitor size of 0 means it is unknown and only the
+// minimum applies; a missing saved size falls back to the default.
+func clampWindowSize(width, height, monitorWidth, monitorHeight int) (int, int) {
+	if width <= 0 || height <= 0 {
+		width, height = defaultWindowWidth, defaultWindowHeight
+	}
+	if monitorWidth > 0 {
+		width = min(width, monitorWidth)
+	}
+	if monitorHeight > 0 {
+		height = min(height, monitorHeight)
+	}
+	return max(width, minWindowWidth), max(height, minWindowHeight)
+}
+
+// applyWindowSettings restores the saved window size and fullscreen mode
+func applyWindowSettings(settings Settings) {
+	monitorWidth, monitorHeight := 0, 0
+	if monitor := ebiten.Monitor(); monitor != nil {
+		monitorWidth, monitorHeight = monitor.Size()
+	}
+	width, height := clampWindowSize(settings.WindowWidth, settings.WindowHeight, monitorWidth, monitorHeight)
+
+	ebiten.SetWindowSizeLimits(minWindowWidth, minWindowHeight, -1, -1)
+	ebiten.SetWindowSize(width, height)
+	ebiten.SetFullscreen(settings.Fullscreen)
+}
+
+// toggleFullscreen switches between fullscreen and a window. Leaving
+// fullscreen restores the window size remembered from before, which
+// trackWindowSettings stops updating while fullscreen.
+func (g *ConnectFourGame) toggleFullscreen() {
+	if !ebiten.IsFullscreen() {
+		ebiten.SetFullscreen(true)
+		return
+	}
+	ebiten.SetFullscreen(false)
+	if g.settings.WindowWidth > 0 && g.settings.WindowHeight > 0 {
+		ebiten.SetWindowSize(g.settings.WindowWidth, g.settings.WindowHeight)
+	}
+}
+
+// trackWindowSettings notices window size and fullscreen changes and saves
+// them once they have settled
+func (g *ConnectFourGame) trackWindowSettings() {
+	width, height := ebiten.WindowSize()
+	fullscreen := ebiten.IsFullscreen()
+
+	// Browsers report no window size; there is nothing to remember there
+	if width > 0 && height > 0 && !fullscreen &&
+		(width != g.settings.WindowWidth || height != g.settings.WindowHeight) {
+		g.settings.WindowWidth = width
+		g.settings.WindowHeight = height
+		g.settingsSaveTimer = settingsSaveDelay
+	}
+	if fullscreen != g.settings.Fullscreen {
+		g.settings.Fullscreen = fullscreen
+		g.settingsSaveTimer = settingsSaveDelay
+	}
+
+	if g.settingsSaveTimer > 0 {
+		countDown(&g.settingsSaveTimer)
+		if g.settingsSaveTimer == 0 {
+			g.flushSettings()
+		}
+	}
+}
+
+// flushSettings saves the settings right away, e.g. when the window closes
+func (g *ConnectFourGame) flushSettings() {
+	g.settingsSaveTimer = 0
+	if err := saveSettings(g.storage, g.settings); err != nil {
+		log.Printf("saving settings: %v", err)
+	}
+}
+
+// animationsEnabled reports whether decorative animation should run. Every
+// animation updater checks this rather than the setting directly.
+func (g *ConnectFourGame) animationsEnabled() bool {
+	return !g.settings.ReducedMotion
+}
+
+// How long the computer appears to think before moving
+const thinkingPauseTime = 300 * time.Millisecond
+
+// thinkingPause returns how long the computer appears to think before
+// moving, no time at all with reduced motion so its moves appear straight
+// away
+func (g *ConnectFourGame) thinkingPause() time.Duration {
+	if !g.animationsEnabled() {
+		return 0
+	}
+	return thinkingPauseTime
+}
+
+// decorationsActive reports whether purely decorative animation should
+// advance, given whether the window has focus. Unlike animations that show
+// what happened in the game, it stops while the window is unfocused so a
+// backgrounded game doesn't spend time on scenery nobody is looking at.
+func (g *ConnectFourGame) decorationsActive(focused bool) bool {
+	return g.animationsEnabled() && focused
+}
+
+// openSettings shows the settings screen, returning to the current screen afterwards
+func (g *ConnectFourGame) openSettings() {
+	g.settingsReturnState = g.state
+	g.transitionTo(StateSettings)
+}
+
+// initSettingsUI creates the settings dropdowns, toggles, sliders and the back button
+func (g *ConnectFourGame) initSettingsUI() {
+	// Back button
+	g.buttons = append(g.buttons, &Button{
+		x:    float64(g.screenWidth) - 120*g.scaleX,
+		y:    20 * g.scaleY,
+		w:    100 * g.scaleX,
+		h:    30 * g.scaleY,
+		text: tr("common.back"),
+		action: func() {
+			g.transitionTo(g.settingsReturnState)
+		},
+	})
+
+	// Key bindings button
+	g.buttons = append(g.buttons, &Button{
+		x:      20 * g.scaleX,
+		y:      20 * g.scaleY,
+		w:      100 * g.scaleX,
+		h:      30 * g.scaleY,
+		text:   tr("settings.keys"),
+		action: g.openKeyBindings,
+	})
+
+	// Tutorial button, replaying it over a new game once someone is logged in
+	if g.username != "" {
+		g.buttons = append(g.buttons, &Button{
+			x:    float64(g.screenWidth) - 120*g.scaleX,
+			y:    60 * g.scaleY,
+			w:    100 * g.scaleX,
+			h:    30 * g.scaleY,
+			text: tr("settings.tutorial"),
+			action: func() {
+				g.flushSettings()
+				g.startComputerGame()
+				g.startTutorial()
+			},
+		})
+	}
+
+	// Enumerated settings
+	languageNames := []string{}
+	for _, language := range languages {
+		languageNames = append(languageNames, languageName(language))
+	}
+	difficultyNames := []string{}
+	for difficulty := range numDifficulties {
+		difficultyNames = append(difficultyNames, difficultyName(difficulty))
+	}
+	themeNames := []string{}
+	for _, theme := range themes {
+		themeNames = append(themeNames, tr(theme.Name))
+	}
+	variantNames := []string{}
+	for variant := range numVariants {
+		variantNames = append(variantNames, variantName(variant))
+	}
+	tickRateNames := []string{}
+	for _, tps := range tickRates {
+		tickRateNames = append(tickRateNames, tr("settings.tick_rate_option", tps))
+	}
+	dropdowns := []struct {
+		label    string
+		options  []string
+		selected int
+		onChange func(int)
+	}{
+		{tr("settings.language"), languageNames, max(0, slices.Index(languages, currentLanguage)), func(i int) {
+			g.settings.Language = languages[i]
+			setLanguage(g.settings.Language)
+			g.initUI() // Relabel everything in the new language
+		}},
+		{tr("settings.difficulty"), difficultyNames, g.difficulty, func(i int) {
+			g.settings.Difficulty = i
+			g.setDifficulty(i)
+		}},
+		{tr("settings.variant"), variantNames, g.settings.Variant, func(i int) {
+			g.settings.Variant = i // Takes effect from the next game
+		}},
+		{tr("settings.theme"), themeNames, themeIndex(g.settings.Theme), func(i int) {
+			g.settings.Theme = themes[i].ID
+			applyTheme(g.settings.Theme)
+		}},
+		{tr("settings.tick_rate"), tickRateNames, max(0, slices.Index(tickRates, g.settings.TPS)), func(i int) {
+			g.settings.TPS = tickRates[i]
+			applyTimingSettings(g.settings)
+		}},
+	}
+	for i, d := range dropdowns {
+		onChange := d.onChange
+		g.dropdowns = append(g.dropdowns, &Dropdown{
+			x:        float64(g.screenWidth)/2 - 120*g.scaleX,
+			y:        (110 + float64(i)*30) * g.scaleY,
+			w:        240 * g.scaleX,
+			h:        26 * g.scaleY,
+			label:    d.label,
+			options:  d.options,
+			selected: d.selected,
+			onChange: func(i int) {
+				onChange(i)
+				g.flushSettings()
+			},
+		})
+	}
+
+	// Takebacks per game, left of the dropdowns as they fill the middle
+	undoLimitNames := []string{}
+	for _, limit := range undoLimits {
+		undoLimitNames = append(undoLimitNames, undoLimitName(limit))
+	}
+	g.dropdowns = append(g.dropdowns, &Dropdown{
+		x:        float64(g.screenWidth)/2 - 390*g.scaleX,
+		y:        110 * g.scaleY,
+		w:        250 * g.scaleX,
+		h:        26 * g.scaleY,
+		label:    tr("settings.undo_limit"),
+		options:  undoLimitNames,
+		selected: max(0, slices.Index(undoLimits, g.settings.UndoLimit)),
+		onChange: func(i int) {
+			g.settings.UndoLimit = undoLimits[i] // Takes effect from the next game
+			g.flushSettings()
+		},
+	})
+
+	// Disc labels, typed in beside the dropdowns
+	g.initDiscLabelsUI()
+
+	// On/off settings
+	toggles := []struct {
+		label   string
+		setting *bool
+	}{
+		{tr("settings.mute"), &g.settings.Muted},
+		{tr("settings.reduced_motion"), &g.settings.ReducedMotion},
+		{tr("settings.bounce"), &g.settings.DropBounce},
+		{tr("settings.confirm_moves"), &g.settings.ConfirmMoves},
+		{tr("settings.highlight_last_move"), &g.settings.HighlightLastMove},
+		{tr("settings.column_highlight"), &g.settings.ColumnHighlight},
+		{tr("settings.disc_shadows"), &g.settings.DiscShadows},
+		{tr("settings.capacity_bars"), &g.settings.CapacityBars},
+		{tr("settings.eval_bar"), &g.settings.EvalBar},
+		{tr("settings.assist_mode"), &g.settings.AssistMode},
+		{tr("settings.engine_resign"), &g.settings.EngineResign},
+		{tr("settings.alternate_start"), &g.settings.AlternateStart},
+		{tr("settings.vsync"), &g.settings.VSync},
+		{tr("settings.throttle_menus"), &g.settings.ThrottleMenus},
+		{tr("settings.mask_profanity"), &g.settings.MaskProfanity},
+	}
+	// Laid out in two columns, filling across
+	for i, t := range toggles {
+		setting := t.setting
+		g.toggles = append(g.toggles, &Toggle{
+			x:     float64(g.screenWidth)/2 + (float64(i%2)*260-250)*g.scaleX,
+			y:     (266 + float64(i/2)*24) * g.scaleY,
+			w:     240 * g.scaleX,
+			h:     20 * g.scaleY,
+			label: t.label,
+			on:    *setting,
+			onChange: func(on bool) {
+				*setting = on
+				applyTimingSettings(g.settings)
+				g.flushSettings()
+			},
+		})
+	}
+
+	// Continuous settings, saved once the slider stops moving
+	percent := func(v float64) string { return fmt.Sprintf("%.0f%%", v) }
+	sliders := []struct {
+		label    string
+		min, max float64
+		value    float64
+		onChange func(float64)
+	}{
+		{tr("settings.drop_speed"), 50, 250, g.settings.DropGravity / defaultDropGravity * 100, func(v float64) {
+			g.settings.DropGravity = defaultDropGravity * v / 100
+		}},
+		{tr("settings.music_volume"), 0, 100, float64(g.settings.MusicVolume), func(v float64) {
+			g.settings.MusicVolume = int(v)
+		}},
+		{tr("settings.effects_volume"), 0, 100, float64(g.settings.EffectsVolume), func(v float64) {
+			g.settings.EffectsVolume = int(v)
+		}},
+	}
+	for i, s := range sliders {
+		onChange := s.onChange
+		slider := &Slider{
+			x:      float64(g.screenWidth)/2 - 120*g.scaleX,
+			y:      (460 + float64(i)*45) * g.scaleY,
+			w:      240 * g.scaleX,
+			h:      20 * g.scaleY,
+			label:  s.label,
+			min:    s.min,
+			max:    s.max,
+			step:   10,
+			format: percent,
+			onChange: func(v float64) {
+				onChange(v)
+				g.settingsSaveTimer = settingsSaveDelay
+			},
+		}
+		slider.value = slider.clamp(s.value)
+		g.sliders = append(g.sliders, slider)
+	}
+}
+
+// drawSettingsScreen renders the settings screen
+func (g *ConnectFourGame) drawSettingsScreen(screen *ebiten.Image) {
+	g.drawFallingDiscs(screen)
+
+	title := tr("settings.title")
+	titleBounds := boundString(fontFace, title)
+	text.Draw(screen, title, fontFace,
+		g.screenWidth/2-titleBounds.Dx()/2, int(90*g.scaleY), colorText)
+
+	// Draw buttons, toggles, sliders, inputs and dropdowns, with an open list
+	// on top
+	for _, btn := range g.buttons {
+		g.drawButton(screen, btn)
+	}
+	for _, toggle := range g.toggles {
+		g.drawToggle(screen, toggle)
+	}
+	for _, slider := range g.sliders {
+		g.drawSlider(screen, slider)
+	}
+	for _, input := range g.textInputs {
+		g.drawTextInput(screen, input)
+	}
+	for _, d := range g.dropdowns {
+		g.drawDropdown(screen, d)
+	}
+	g.drawDropdownList(screen)
+}
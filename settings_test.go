@@ -0,0 +1,90 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestClampWindowSize(t *testing.T) {
+	for _, tt := range []struct {
+		name                        string
+		width, height               int
+		monitorWidth, monitorHeight int
+		wantWidth, wantHeight       int
+	}{
+		{"fits", 1024, 768, 1920, 1080, 1024, 768},
+		{"zero size", 0, 0, 1920, 1080, defaultWindowWidth, defaultWindowHeight},
+		{"zero height", 1024, 0, 1920, 1080, defaultWindowWidth, defaultWindowHeight},
+		{"oversized", 2560, 1440, 1920, 1080, 1920, 1080},
+		{"undersized", 200, 100, 1920, 1080, minWindowWidth, minWindowHeight},
+		{"unknown monitor", 2560, 1440, 0, 0, 2560, 1440},
+		{"unknown monitor, undersized", 200, 100, 0, 0, minWindowWidth, minWindowHeight},
+		{"monitor smaller than the minimum", 800, 600, 320, 240, minWindowWidth, minWindowHeight},
+	} {
+		w, h := clampWindowSize(tt.width, tt.height, tt.monitorWidth, tt.monitorHeight)
+		if w != tt.wantWidth || h != tt.wantHeight {
+			t.Errorf("%s: clampWindowSize = %dx%d, want %dx%d", tt.name, w, h, tt.wantWidth, tt.wantHeight)
+		}
+	}
+}
+
+func TestDecorationsFollowFocus(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		focused       bool
+		reducedMotion bool
+		want          bool
+	}{
+		{"focused", true, false, true},
+		{"unfocused", false, false, false},
+		{"focused with reduced motion", true, true, false},
+		{"unfocused with reduced motion", false, true, false},
+	} {
+		g := newConnectFourGame(newMemoryStorage())
+		g.settings.ReducedMotion = tt.reducedMotion
+		if got := g.decorationsActive(tt.focused); got != tt.want {
+			t.Errorf("%s: decorationsActive = %v, want %v", tt.name, got, tt.want)
+		}
+
+		// The background discs move only while decorations are active
+		before := slices.Clone(g.fallingDiscs)
+		g.updateFallingDiscs(tt.focused)
+		if moved := !slices.Equal(before, g.fallingDiscs); moved != tt.want {
+			t.Errorf("%s: background discs moved %v, want %v", tt.name, moved, tt.want)
+		}
+	}
+}
+
+func TestReducedMotionSkipsAnimation(t *testing.T) {
+	const moves = "4453322"
+	boards := map[bool]GameBoard{}
+	for _, reduced := range []bool{false, true} {
+		g := keysTestGame(t)
+		g.settings.ReducedMotion = reduced
+		for _, ch := range moves {
+			g.dropInColumn(int(ch - '1'))
+			frames := 0
+			for ; g.dropAnim != nil && frames < 1000; frames++ {
+				g.updateDropAnimation()
+			}
+			if reduced && frames != 0 {
+				t.Errorf("reduced motion: drop in column %c animated for %d frames", ch, frames)
+			}
+			if !reduced && (frames == 0 || frames == 1000) {
+				t.Errorf("drop in column %c animated for %d frames", ch, frames)
+			}
+		}
+		boards[reduced] = g.board
+
+		if pause := g.thinkingPause(); reduced && pause != 0 || !reduced && pause != thinkingPauseTime {
+			t.Errorf("reduced motion %v: computer thinks for %v", reduced, pause)
+		}
+	}
+
+	if boards[true] != boards[false] {
+		t.Error("reduced motion ended on a different board")
+	}
+	if want := boardFromMoves(t, moves); boards[true] != want {
+		t.Error("reduced motion board doesn't match the moves played")
+	}
+}
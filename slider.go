@@ -0,0 +1,126 @@
+package main
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// Width of the slider's draggable handle, before scaling
+const sliderHandleWidth = 12
+
+// Slider is a horizontal control for picking a value between min and max
+type Slider struct {
+	x, y, w, h     float64 // Track area; the label is drawn above it
+	label          string
+	min, max, step float64
+	value          float64
+	format         func(float64) string // Formats the value shown after the label
+	onChange       func(float64)
+	focused        bool
+	dragging       bool
+}
+
+// contains reports whether a point is on the track, allowing for the handle
+// sticking out past either end
+func (s *Slider) contains(x, y float64) bool {
+	return pointInRect(x, y, s.x-sliderHandleWidth/2, s.y, s.w+sliderHandleWidth, s.h)
+}
+
+// clamp limits a value to the slider's range and snaps it to the nearest step
+func (s *Slider) clamp(v float64) float64 {
+	if s.step > 0 {
+		v = s.min + math.Round((v-s.min)/s.step)*s.step
+	}
+	return math.Max(s.min, math.Min(s.max, v))
+}
+
+// valueAt returns the value under an x position on the track
+func (s *Slider) valueAt(x float64) float64 {
+	if s.w <= 0 {
+		return s.min
+	}
+	return s.clamp(s.min + (x-s.x)/s.w*(s.max-s.min))
+}
+
+// handleX returns the x position of the centre of the handle
+func (s *Slider) handleX() float64 {
+	if s.max == s.min {
+		return s.x
+	}
+	return s.x + (s.value-s.min)/(s.max-s.min)*s.w
+}
+
+// setValue changes the value, calling onChange if it actually changed
+func (s *Slider) setValue(v float64) {
+	v = s.clamp(v)
+	if v == s.value {
+		return
+	}
+	s.value = v
+	if s.onChange != nil {
+		s.onChange(v)
+	}
+}
+
+// updateSliders handles dragging sliders with the mouse and nudging the
+// focused one with the arrow keys
+func (g *ConnectFourGame) updateSliders() {
+	if len(g.sliders) == 0 {
+		return
+	}
+
+	x, y := ebiten.CursorPosition()
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		// A click focuses the slider under the cursor and starts dragging it
+		for _, s := range g.sliders {
+			s.dragging = s.contains(float64(x), float64(y))
+			if s.dragging {
+				g.setFocus(s)
+				s.setValue(s.valueAt(float64(x)))
+			}
+		}
+	}
+
+	for _, s := range g.sliders {
+		if s.dragging {
+			if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+				s.setValue(s.valueAt(float64(x)))
+			} else {
+				s.dragging = false
+			}
+		}
+		if s.focused {
+			if action(ActionLeft) {
+				s.setValue(s.value - s.step)
+			}
+			if action(ActionRight) {
+				s.setValue(s.value + s.step)
+			}
+		}
+	}
+}
+
+// drawSlider renders a slider's label, track and handle in the button style
+func (g *ConnectFourGame) drawSlider(screen *ebiten.Image, s *Slider) {
+	label := s.label
+	if s.format != nil {
+		label += ": " + s.format(s.value)
+	}
+	text.Draw(screen, label, fontFace, int(s.x), int(s.y-6), colorText)
+
+	// Track, filled up to the handle
+	trackY := s.y + s.h/2 - 3
+	drawRect(screen, s.x, trackY, s.w, 6, colorSlotBg)
+	drawRect(screen, s.x, trackY, s.handleX()-s.x, 6, colorButton)
+
+	// Handle, outlined while focused
+	handleW := sliderHandleWidth * g.scaleX
+	handleX := s.handleX() - handleW/2
+	if s.focused {
+		drawRect(screen, handleX-2, s.y-2, handleW+4, s.h+4, colorText)
+	}
+	drawRect(screen, handleX, s.y, handleW, s.h, colorButton)
+}
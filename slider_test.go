@@ -0,0 +1,84 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestSliderClamp(t *testing.T) {
+	s := &Slider{x: 100, w: 200, min: 0, max: 1, step: 0.1}
+	for _, tt := range []struct {
+		name string
+		v    float64
+		want float64
+	}{
+		{"on a step", 0.3, 0.3},
+		{"rounds down to a step", 0.34, 0.3},
+		{"rounds up to a step", 0.36, 0.4},
+		{"below the minimum", -0.5, 0},
+		{"above the maximum", 1.7, 1},
+		{"at the ends", 1, 1},
+	} {
+		if got := s.clamp(tt.v); !approxEqual(got, tt.want) {
+			t.Errorf("%s: clamp(%g) = %g, want %g", tt.name, tt.v, got, tt.want)
+		}
+	}
+
+	// Steps count from the minimum, not from zero
+	offset := &Slider{min: 5, max: 50, step: 10}
+	if got := offset.clamp(22); got != 25 {
+		t.Errorf("clamp(22) from 5 in steps of 10 = %g, want 25", got)
+	}
+
+	// Positions on the track map onto the range, and off it to the ends
+	for x, want := range map[float64]float64{100: 0, 200: 0.5, 300: 1, 40: 0, 420: 1} {
+		if got := s.valueAt(x); !approxEqual(got, want) {
+			t.Errorf("valueAt(%g) = %g, want %g", x, got, want)
+		}
+	}
+}
+
+func TestSliderStepsWithArrowKeys(t *testing.T) {
+	g := newConnectFourGame(newMemoryStorage())
+	var changes []float64
+	s := &Slider{w: 200, min: 0, max: 1, step: 0.25, value: 0.5,
+		onChange: func(v float64) { changes = append(changes, v) }}
+	g.sliders = []*Slider{s}
+
+	// Keys only move the focused slider
+	pressKeys(t, ebiten.KeyArrowRight)
+	g.updateSliders()
+	if s.value != 0.5 {
+		t.Fatalf("unfocused slider moved to %g", s.value)
+	}
+
+	g.setFocus(s)
+	for _, tt := range []struct {
+		key  ebiten.Key
+		want float64
+	}{
+		{ebiten.KeyArrowRight, 0.75},
+		{ebiten.KeyArrowRight, 1},
+		{ebiten.KeyArrowRight, 1}, // Held at the maximum
+		{ebiten.KeyArrowLeft, 0.75},
+	} {
+		pressKeys(t, tt.key)
+		g.updateSliders()
+		if s.value != tt.want {
+			t.Errorf("after %v value is %g, want %g", tt.key, s.value, tt.want)
+		}
+	}
+
+	// Pushing against the end doesn't report a change
+	if want := []float64{0.75, 1, 0.75}; !slices.Equal(changes, want) {
+		t.Errorf("onChange called with %v, want %v", changes, want)
+	}
+}
+
+// approxEqual compares slider values, which pick up rounding error in steps
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
@@ -0,0 +1,24 @@
+package main
+
+// The board has a single owner: the game loop. Only Update and the functions
+// it calls may change the board, and they do so through setBoard. Code on
+// other goroutines, such as spectators or an evaluation bar, must never read
+// g.board directly and uses BoardSnapshot instead, as do the computer's
+// search and the evaluation bar when they hand a position to their
+// goroutines. The owner itself can read g.board without locking, since
+// nothing else writes it.
+
+// setBoard replaces the board, excluding snapshot readers while it changes
+func (g *ConnectFourGame) setBoard(board GameBoard) {
+	g.boardMu.Lock()
+	g.board = board
+	g.boardMu.Unlock()
+}
+
+// BoardSnapshot returns a copy of the current board that is safe to take
+// from any goroutine
+func (g *ConnectFourGame) BoardSnapshot() GameBoard {
+	g.boardMu.RLock()
+	defer g.boardMu.RUnlock()
+	return g.board
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestBoardSnapshotWhileMoving reads the board from another goroutine while
+// the game loop keeps replacing it. Run with -race to check the locking; a
+// torn copy is caught without it, as every board written has one piece
+// throughout.
+func TestBoardSnapshotWhileMoving(t *testing.T) {
+	g := &ConnectFourGame{}
+	const writes = 2000
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			board := g.BoardSnapshot()
+			first := board.At(0, 0)
+			for row := range Rows {
+				for col := range Columns {
+					if board.At(row, col) != first {
+						t.Errorf("torn snapshot: %v", board)
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	for i := range writes {
+		var board GameBoard
+		for row := range Rows {
+			for col := range Columns {
+				board.Set(row, col, i%3)
+			}
+		}
+		g.setBoard(board)
+	}
+	close(done)
+	wg.Wait()
+}
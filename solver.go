@@ -0,0 +1,71 @@
+package main
+
+import (
+	"math"
+)
+
+// Solver results, from the point of view of the side to move
+const (
+	SolveLoss    = -1
+	SolveUnknown = 0 // No forced result within the search depth (or a draw)
+	SolveWin     = 1
+)
+
+// Default depth (in plies) used when solving positions
+const solverDepth = 7
+
+// opponentOf returns the other side
+func opponentOf(player int) int {
+	if player == Player {
+		return Computer
+	}
+	return Player
+}
+
+// Solve determines whether the side to move can force a win (or is forced
+// to lose) within depth plies under rules. Only proven results are
+// reported; heuristic scores from the search horizon count as unknown.
+func Solve(board GameBoard, toMove int, depth int, rules Rules) int {
+	return solveWithBudget(board, toMove, depth, 0, rules)
+}
+
+// solveWithBudget is Solve with a cap on the positions searched (0 for no
+// cap). Searches that run out of budget report SolveUnknown.
+func solveWithBudget(board GameBoard, toMove int, depth int, maxNodes int, rules Rules) int {
+	s := &search{rules: rules, maxNodes: maxNodes}
+	_, score := s.minimax(board, depth, math.Inf(-1), math.Inf(1), toMove == Computer)
+	if s.aborted {
+		return SolveUnknown
+	}
+
+	// minimax scores are from the computer's point of view
+	if toMove == Player {
+		score = -score
+	}
+
+	switch {
+	case math.IsInf(score, 1):
+		return SolveWin
+	case math.IsInf(score, -1):
+		return SolveLoss
+	default:
+		return SolveUnknown
+	}
+}
+
+// winningColumns returns every column where player can force a win within
+// depth plies under rules, including immediate wins.
+func winningColumns(board GameBoard, player int, depth int, rules Rules) []int {
+	wins := []int{}
+	for _, col := range getValidColumns(board) {
+		newBoard := dropPiece(board, col, player)
+		if checkWin(newBoard, player) {
+			wins = append(wins, col)
+			continue
+		}
+		if depth > 1 && Solve(newBoard, opponentOf(player), depth-1, rules) == SolveLoss {
+			wins = append(wins, col)
+		}
+	}
+	return wins
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"log"
+	"slices"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// spectatorHub keeps the games being played on the server and the
+// spectators watching them. Matches report to it from their own goroutines,
+// so everything in it is guarded by mu.
+type spectatorHub struct {
+	mu      sync.Mutex
+	games   []*liveGame // Oldest first
+	waiting []*netPeer  // Spectators with no game to watch yet
+}
+
+// liveGame is a game in progress, as its spectators see it
+type liveGame struct {
+	players  [2]string
+	moves    []int
+	watchers []*netPeer
+}
+
+// watch takes on a new spectator and reads its messages until it leaves.
+// Spectators can't move, offer rematches or chat, so all it sends is dropped.
+func (h *spectatorHub) watch(p *netPeer) {
+	h.mu.Lock()
+	if len(h.games) > 0 {
+		h.games[0].attach(p)
+	} else {
+		h.waiting = append(h.waiting, p)
+	}
+	h.mu.Unlock()
+
+	for range p.messages {
+	}
+	h.leave(p)
+	p.close()
+}
+
+// attach adds a spectator to the game, sending it the game so far
+func (game *liveGame) attach(p *netPeer) {
+	game.watchers = append(game.watchers, p)
+	p.send(SpectatingMessage{Players: game.players, Moves: game.moves})
+}
+
+// begin records a game starting between players, given in side order, and
+// hands it the spectators waiting for one
+func (h *spectatorHub) begin(players [2]string) *liveGame {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	game := &liveGame{players: players}
+	h.games = append(h.games, game)
+	for _, p := range h.waiting {
+		game.attach(p)
+	}
+	h.waiting = nil
+	return game
+}
+
+// played passes a move on to the game's spectators
+func (h *spectatorHub) played(game *liveGame, col, side int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	game.moves = append(game.moves, col)
+	for _, p := range game.watchers {
+		p.send(MoveMessage{Column: col, Side: side})
+	}
+}
+
+// chatted passes a line of chat from side on to the game's spectators
+func (h *spectatorHub) chatted(game *liveGame, line string, side int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, p := range game.watchers {
+		p.send(ChatMessage{Text: line, Side: side})
+	}
+}
+
+// finish tells the game's spectators how it ended and forgets the game. The
+// spectators wait for the next game to begin, which is often the rematch.
+// Finishing a game twice does nothing.
+func (h *spectatorHub) finish(game *liveGame, end GameOverMessage) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	i := slices.Index(h.games, game)
+	if i < 0 {
+		return
+	}
+	h.games = slices.Delete(h.games, i, i+1)
+	for _, p := range game.watchers {
+		p.send(end)
+	}
+	h.waiting = append(h.waiting, game.watchers...)
+	game.watchers = nil
+}
+
+// leave forgets a spectator that has disconnected
+func (h *spectatorHub) leave(p *netPeer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.waiting = slices.DeleteFunc(h.waiting, func(w *netPeer) bool { return w == p })
+	for _, game := range h.games {
+		game.watchers = slices.DeleteFunc(game.watchers, func(w *netPeer) bool { return w == p })
+	}
+}
+
+// SpectatedGame is an online game being watched, as far as it has got
+type SpectatedGame struct {
+	players [2]string // In side order
+	board   GameBoard
+	moves   int
+	result  string // How the game ended, once it has
+}
+
+// play adds a move to the watched game, ignoring one that doesn't fit
+func (s *SpectatedGame) play(col, side int) {
+	if s.result != "" || s.board.columnFull(col) {
+		return
+	}
+	s.board = dropPiece(s.board, col, side)
+	s.moves++
+}
+
+// spectateServer connects to the address entered on the online screen to
+// watch games rather than play
+func (g *ConnectFourGame) spectateServer() {
+	g.connectToServer()
+	g.online.spectator = true
+}
+
+// handleSpectatorMessage acts on a message from the game server while
+// spectating. Moves arrive with the server's sides, which are shown as is.
+func (g *ConnectFourGame) handleSpectatorMessage(msg wireMessage) {
+	switch msg := msg.(type) {
+	case SpectatingMessage:
+		game := &SpectatedGame{players: msg.Players}
+		side := Player
+		for _, col := range msg.Moves {
+			if game.board.columnFull(col) || validatePosition(game.board) != nil {
+				log.Printf("spectated game has an illegal move in column %d", col)
+				break
+			}
+			game.play(col, side)
+			side = opponentOf(side)
+		}
+		g.spectated = game
+		if g.state != StateSpectate {
+			g.transitionTo(StateSpectate)
+		}
+
+	case MoveMessage:
+		if g.spectated != nil {
+			g.spectated.play(msg.Column, msg.Side)
+		}
+
+	case ChatMessage:
+		if g.spectated != nil && msg.Side != Empty {
+			line := msg.Text
+			if g.settings.MaskProfanity {
+				line = maskProfanity(line)
+			}
+			g.showToast(tr("online.chat", g.spectated.players[msg.Side-Player], line))
+		}
+
+	case GameOverMessage:
+		if g.spectated != nil {
+			g.spectated.result = g.spectated.resultText(msg)
+		}
+
+	case PingMessage:
+		g.online.send(PongMessage{Nonce: msg.Nonce})
+	}
+}
+
+// resultText describes how a watched game ended
+func (s *SpectatedGame) resultText(end GameOverMessage) string {
+	if end.Winner == Empty {
+		return tr("spectate.draw")
+	}
+	winner, loser := s.players[end.Winner-Player], s.players[opponentOf(end.Winner)-Player]
+	switch end.Reason {
+	case gameOverLeft:
+		return tr("spectate.left", loser)
+	case gameOverResign:
+		return tr("spectate.resigned", loser)
+	}
+	return tr("spectate.won", winner)
+}
+
+// initSpectateUI adds the spectator view's back button, which stops
+// watching. The board takes no input.
+func (g *ConnectFourGame) initSpectateUI() {
+	g.buttons = append(g.buttons, &Button{
+		x:    float64(g.screenWidth) - 120*g.scaleX,
+		y:    20 * g.scaleY,
+		w:    100 * g.scaleX,
+		h:    30 * g.scaleY,
+		text: tr("common.back"),
+		action: func() {
+			g.spectated = nil
+			g.openOnline()
+		},
+	})
+}
+
+// drawSpectateScreen renders the watched game like a replay, with a LIVE
+// marker until it ends and its result after
+func (g *ConnectFourGame) drawSpectateScreen(screen *ebiten.Image) {
+	s := g.spectated
+	header := tr("spectate.header", s.players[0], s.players[1])
+	headerBounds := boundString(fontFace, header)
+	text.Draw(screen, header, fontFace,
+		g.screenWidth/2-headerBounds.Dx()/2, int(40*g.scaleY), colorText)
+
+	status := tr("spectate.moves", s.moves)
+	if s.result != "" {
+		status = s.result
+	}
+	statusBounds := boundString(fontFace, status)
+	text.Draw(screen, status, fontFace,
+		g.screenWidth/2-statusBounds.Dx()/2, int(70*g.scaleY), colorText)
+
+	if s.result == "" {
+		x, y := int(30*g.scaleX), int(35*g.scaleY)
+		g.drawSmoothCircle(screen, x, y, float64(g.px(6)), colorError)
+		text.Draw(screen, tr("spectate.live"), fontFace, x+g.px(12), y+g.px(5), colorError)
+	}
+
+	originX, originY := g.boardOrigin()
+	g.drawBoardAt(screen, s.board, originX, originY, g.cellSize)
+	if g.showCoordinates {
+		g.drawCoordinates(screen, originX, originY, g.cellSize)
+	}
+
+	for _, btn := range g.buttons {
+		g.drawButton(screen, btn)
+	}
+}
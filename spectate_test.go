@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// syncWith has the client ask for the game so far. The server answers
+// once it has dealt with everything sent before, spectators included.
+func syncWith(c *testClient) []int {
+	c.t.Helper()
+	c.send(SyncMessage{})
+	return expect[SyncMessage](c).Moves
+}
+
+func TestSpectatorJoinsLate(t *testing.T) {
+	ln, _ := startTestServer(t, 0)
+	clients := startMatch(t, ln)
+	var board GameBoard
+	playMove(t, clients, &board, Player, 3)
+	playMove(t, clients, &board, Computer, 4)
+	syncWith(clients[0])
+
+	watcher := connect(t, ln, SpectateMessage{Name: "carol"})
+	watching := expect[SpectatingMessage](watcher)
+	if watching.Players != [2]string{"alice", "bob"} && watching.Players != [2]string{"bob", "alice"} {
+		t.Errorf("watching %v", watching.Players)
+	}
+	if !reflect.DeepEqual(watching.Moves, []int{3, 4}) {
+		t.Errorf("late spectator was sent moves %v, want [3 4]", watching.Moves)
+	}
+
+	playMove(t, clients, &board, Player, 3)
+	if move := expect[MoveMessage](watcher); move.Column != 3 || move.Side != Player {
+		t.Errorf("spectator saw %+v, want column 3 by the first player", move)
+	}
+}
+
+func TestSpectatorCannotPlay(t *testing.T) {
+	ln, _ := startTestServer(t, 0)
+	clients := startMatch(t, ln)
+	watcher := connect(t, ln, SpectateMessage{Name: "carol"})
+	expect[SpectatingMessage](watcher)
+
+	// A pong comes back once the server has read everything sent before
+	// it, so the spectator's messages have all been dealt with
+	watcher.send(MoveMessage{Column: 0})
+	watcher.send(ChatMessage{Text: "play 0"})
+	watcher.send(DrawOfferMessage{})
+	watcher.send(ResignMessage{})
+	watcher.send(PingMessage{Nonce: 1})
+	expect[PongMessage](watcher)
+
+	if moves := syncWith(clients[0]); len(moves) != 0 {
+		t.Errorf("spectator got moves %v played", moves)
+	}
+	clients[0].send(ChatMessage{Text: "hello"})
+	if chat := expect[ChatMessage](clients[1]); chat.Text != "hello" {
+		t.Errorf("player heard %q, want only the opponent's chat", chat.Text)
+	}
+
+	// The spectator hears the game's chat, saying who said it, and its moves
+	if chat := expect[ChatMessage](watcher); chat.Text != "hello" || chat.Side != Player {
+		t.Errorf("spectator heard %+v, want the first player's hello", chat)
+	}
+	var board GameBoard
+	playMove(t, clients, &board, Player, 5)
+	if move := expect[MoveMessage](watcher); move.Column != 5 {
+		t.Errorf("spectator saw %+v, want column 5", move)
+	}
+}
+
+func TestSpectatorsFollowTheRematch(t *testing.T) {
+	ln, _ := startTestServer(t, 0)
+	clients := startMatch(t, ln)
+	watcher := connect(t, ln, SpectateMessage{Name: "carol"})
+	expect[SpectatingMessage](watcher)
+
+	clients[0].send(ResignMessage{})
+	for _, c := range clients {
+		expect[GameOverMessage](c)
+	}
+	if end := expect[GameOverMessage](watcher); end.Reason != gameOverResign || end.Winner != Computer {
+		t.Errorf("spectator saw the game end %+v", end)
+	}
+
+	// With the game over, spectators wait for the next, and any more moves
+	// are refused rather than passed on
+	clients[1].send(MoveMessage{Column: 0})
+	expect[MoveRejectMessage](clients[1])
+	late := connect(t, ln, SpectateMessage{Name: "dave"})
+
+	for _, c := range clients {
+		c.send(RematchMessage{})
+	}
+	for _, w := range []*testClient{watcher, late} {
+		if watching := expect[SpectatingMessage](w); len(watching.Moves) != 0 {
+			t.Errorf("rematch started with moves %v", watching.Moves)
+		}
+	}
+}
+
+// hubPeer is a spectator whose messages are kept in out
+func hubPeer(out *bytes.Buffer) *netPeer {
+	return &netPeer{codec: newWireCodec(out), spectator: true}
+}
+
+func TestSpectatorHubCleansUp(t *testing.T) {
+	var out [2]bytes.Buffer
+	hub := &spectatorHub{}
+	early, late := hubPeer(&out[0]), hubPeer(&out[1])
+
+	// One spectator is waiting when the game begins, the other joins it
+	hub.waiting = append(hub.waiting, early)
+	game := hub.begin([2]string{"alice", "bob"})
+	hub.played(game, 3, Player)
+	hub.mu.Lock()
+	game.attach(late)
+	hub.mu.Unlock()
+
+	end := GameOverMessage{Winner: Player, Reason: gameOverFour}
+	hub.finish(game, end)
+	hub.finish(game, end)
+	if len(hub.games) != 0 {
+		t.Errorf("finished game still listed: %d games", len(hub.games))
+	}
+	if len(hub.waiting) != 2 || len(game.watchers) != 0 {
+		t.Errorf("after the game %d spectators wait and %d still watch, want 2 and 0", len(hub.waiting), len(game.watchers))
+	}
+
+	hub.leave(late)
+	if len(hub.waiting) != 1 || hub.waiting[0] != early {
+		t.Errorf("leaving left %d waiting", len(hub.waiting))
+	}
+
+	// The game's end was sent once, however many times it was finished
+	for i := range out {
+		var ends int
+		codec := newWireCodec(&out[i])
+		for {
+			msg, err := codec.read()
+			if err != nil {
+				break
+			}
+			if _, ok := msg.(GameOverMessage); ok {
+				ends++
+			}
+		}
+		if ends != 1 {
+			t.Errorf("spectator %d was told the game ended %d times", i, ends)
+		}
+	}
+}
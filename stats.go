@@ -0,0 +1,209 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// GameResult is one finished game in a user's history
+type GameResult struct {
+	Played     time.Time `json:"played"`
+	Difficulty int       `json:"difficulty"`
+	Winner     int       `json:"winner"` // Player, Computer or Empty for a tie
+	Moves      int       `json:"moves"`
+	Starter    int       `json:"starter,omitempty"` // Side that moved first, zero in records from before it varied
+}
+
+// UserStats is everything recorded about a user's games
+type UserStats struct {
+	Username string       `json:"username"`
+	Results  []GameResult `json:"results"`
+}
+
+// WinLossRecord counts results of one kind of game
+type WinLossRecord struct {
+	Wins, Losses, Draws int
+}
+
+// StatsSummary aggregates a set of game results
+type StatsSummary struct {
+	Played        int
+	Record        WinLossRecord
+	ByDifficulty  [numDifficulties]WinLossRecord
+	LongestStreak int     // Most wins in a row
+	AverageMoves  float64 // Average game length in moves, counting both sides
+	FastestWin    int     // Fewest moves in a win, 0 if there are no wins
+}
+
+// statsKey returns the storage key holding a user's stats
+func statsKey(username string) string {
+	return "stats/" + userFileName(username) + ".json"
+}
+
+// loadStats reads a user's stats. A missing file means no games yet, and a
+// corrupt one is logged and treated the same way rather than failing.
+func loadStats(store Storage, username string) UserStats {
+	stats := UserStats{}
+	data, err := store.Load(statsKey(username))
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("loading stats: %v", err)
+		}
+		return stats
+	}
+	if err := json.Unmarshal(data, &stats); err != nil {
+		log.Printf("loading stats: %v", err)
+		return UserStats{}
+	}
+	return stats
+}
+
+// recordResult adds a finished game to a user's stats
+func recordResult(store Storage, username string, result GameResult) error {
+	stats := loadStats(store, username)
+	stats.Username = username
+	stats.Results = append(stats.Results, result)
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return err
+	}
+	return store.Save(statsKey(username), data)
+}
+
+// summarize aggregates the results played at or after since
+func summarize(results []GameResult, since time.Time) StatsSummary {
+	summary := StatsSummary{}
+	streak := 0
+	totalMoves := 0
+
+	for _, result := range results {
+		if result.Played.Before(since) {
+			continue
+		}
+
+		summary.Played++
+		totalMoves += result.Moves
+
+		var record *WinLossRecord
+		if result.Difficulty >= 0 && result.Difficulty < numDifficulties {
+			record = &summary.ByDifficulty[result.Difficulty]
+		}
+
+		switch result.Winner {
+		case Player:
+			summary.Record.Wins++
+			if record != nil {
+				record.Wins++
+			}
+			streak++
+			summary.LongestStreak = max(summary.LongestStreak, streak)
+			if summary.FastestWin == 0 || result.Moves < summary.FastestWin {
+				summary.FastestWin = result.Moves
+			}
+		case Computer:
+			summary.Record.Losses++
+			if record != nil {
+				record.Losses++
+			}
+			streak = 0
+		default:
+			summary.Record.Draws++
+			if record != nil {
+				record.Draws++
+			}
+			streak = 0
+		}
+	}
+
+	if summary.Played > 0 {
+		summary.AverageMoves = float64(totalMoves) / float64(summary.Played)
+	}
+	return summary
+}
+
+// winRate returns the percentage of games won
+func (s StatsSummary) winRate() float64 {
+	if s.Played == 0 {
+		return 0
+	}
+	return 100 * float64(s.Record.Wins) / float64(s.Played)
+}
+
+// startOfWeek returns midnight on the Monday of the week containing t
+func startOfWeek(t time.Time) time.Time {
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	year, month, day := t.Date()
+	return time.Date(year, month, day-daysSinceMonday, 0, 0, 0, 0, t.Location())
+}
+
+// openStats loads the user's stats and shows the stats screen
+func (g *ConnectFourGame) openStats() {
+	stats := loadStats(g.userStore, g.username)
+	g.weekStats = summarize(stats.Results, startOfWeek(time.Now()))
+	g.allTimeStats = summarize(stats.Results, time.Time{})
+	g.transitionTo(StateStats)
+}
+
+// statsRows formats the summaries as table rows of label, this week, all time
+func statsRows(week, allTime StatsSummary) [][3]string {
+	fastest := func(s StatsSummary) string {
+		if s.FastestWin == 0 {
+			return "-"
+		}
+		return tr("stats.moves", s.FastestWin)
+	}
+	record := func(r WinLossRecord) string {
+		return fmt.Sprintf("%d / %d / %d", r.Wins, r.Losses, r.Draws)
+	}
+
+	rows := [][3]string{
+		{"", tr("stats.this_week"), tr("stats.all_time")},
+		{tr("stats.games_played"), fmt.Sprint(week.Played), fmt.Sprint(allTime.Played)},
+		{tr("stats.record"), record(week.Record), record(allTime.Record)},
+		{tr("stats.win_rate"), fmt.Sprintf("%.0f%%", week.winRate()), fmt.Sprintf("%.0f%%", allTime.winRate())},
+		{tr("stats.longest_streak"), fmt.Sprint(week.LongestStreak), fmt.Sprint(allTime.LongestStreak)},
+		{tr("stats.average_length"), tr("stats.average_moves", week.AverageMoves), tr("stats.average_moves", allTime.AverageMoves)},
+		{tr("stats.fastest_win"), fastest(week), fastest(allTime)},
+	}
+	for difficulty := range numDifficulties {
+		rows = append(rows, [3]string{
+			tr("stats.by_difficulty", difficultyName(difficulty)),
+			record(week.ByDifficulty[difficulty]),
+			record(allTime.ByDifficulty[difficulty]),
+		})
+	}
+	return rows
+}
+
+// drawStatsScreen renders the user's stats as a table
+func (g *ConnectFourGame) drawStatsScreen(screen *ebiten.Image) {
+	title := tr("stats.title", g.username)
+	titleBounds := boundString(fontFace, title)
+	titleX := g.screenWidth/2 - titleBounds.Dx()/2
+	text.Draw(screen, title, fontFace, titleX, int(80*g.scaleY), colorText)
+	g.drawAvatar(screen, g.profile.Avatar, float64(titleX)-28, 80*g.scaleY-18, 24)
+
+	columnX := []float64{
+		float64(g.screenWidth)/2 - 250*g.scaleX,
+		float64(g.screenWidth)/2 + 20*g.scaleX,
+		float64(g.screenWidth)/2 + 150*g.scaleX,
+	}
+	for i, row := range statsRows(g.weekStats, g.allTimeStats) {
+		y := int((130 + float64(i)*30) * g.scaleY)
+		for col, cell := range row {
+			text.Draw(screen, cell, fontFace, int(columnX[col]), y, colorText)
+		}
+	}
+
+	// Draw buttons
+	for _, btn := range g.buttons {
+		g.drawButton(screen, btn)
+	}
+}
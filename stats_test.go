@@ -0,0 +1,71 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSummarizeTotals(t *testing.T) {
+	monday := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	results := []GameResult{
+		{Played: monday.AddDate(0, 0, -3), Difficulty: 0, Winner: Player, Moves: 9},
+		{Played: monday.AddDate(0, 0, -2), Difficulty: 0, Winner: Player, Moves: 13},
+		{Played: monday.AddDate(0, 0, -1), Difficulty: 1, Winner: Player, Moves: 7},
+		{Played: monday, Difficulty: 1, Winner: Computer, Moves: 20},
+		{Played: monday.Add(time.Hour), Difficulty: 2, Winner: Empty, Moves: 42},
+		{Played: monday.Add(2 * time.Hour), Difficulty: 2, Winner: Player, Moves: 11},
+		{Played: monday.Add(3 * time.Hour), Difficulty: 99, Winner: Player, Moves: 15}, // Unknown level
+	}
+
+	all := summarize(results, time.Time{})
+	want := StatsSummary{
+		Played:        7,
+		Record:        WinLossRecord{Wins: 5, Losses: 1, Draws: 1},
+		LongestStreak: 3,
+		AverageMoves:  117.0 / 7,
+		FastestWin:    7,
+	}
+	want.ByDifficulty[0] = WinLossRecord{Wins: 2}
+	want.ByDifficulty[1] = WinLossRecord{Wins: 1, Losses: 1}
+	want.ByDifficulty[2] = WinLossRecord{Wins: 1, Draws: 1}
+	if all != want {
+		t.Errorf("all time summary\n got %+v\nwant %+v", all, want)
+	}
+	if rate := all.winRate(); rate != 100*5.0/7 {
+		t.Errorf("win rate %g%%, want %g%%", rate, 100*5.0/7)
+	}
+
+	// The week leaves out the games before Monday
+	week := summarize(results, startOfWeek(monday.Add(50*time.Hour)))
+	if week.Played != 4 || week.Record != (WinLossRecord{Wins: 2, Losses: 1, Draws: 1}) || week.LongestStreak != 2 || week.FastestWin != 11 {
+		t.Errorf("week summary %+v", week)
+	}
+
+	if empty := summarize(nil, time.Time{}); empty != (StatsSummary{}) || empty.winRate() != 0 {
+		t.Errorf("summary of no games %+v", empty)
+	}
+}
+
+func TestStatsRecoverFromCorruptFile(t *testing.T) {
+	store := newMemoryStorage()
+	store.Save(statsKey("alice"), []byte(`{"username": "alice", "results": [{"played": `))
+
+	if stats := loadStats(store, "alice"); len(stats.Results) != 0 {
+		t.Errorf("corrupt stats loaded %d results", len(stats.Results))
+	}
+
+	// Recording carries on from an empty history and writes a good file
+	result := GameResult{Played: time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC), Winner: Player, Moves: 7}
+	if err := recordResult(store, "alice", result); err != nil {
+		t.Fatalf("recordResult: %v", err)
+	}
+	stats := loadStats(store, "alice")
+	if stats.Username != "alice" || len(stats.Results) != 1 || !stats.Results[0].Played.Equal(result.Played) {
+		t.Errorf("stats after recovering: %+v", stats)
+	}
+
+	// Other users' stats are untouched by it
+	if other := loadStats(store, "bob"); len(other.Results) != 0 {
+		t.Errorf("bob has %d results", len(other.Results))
+	}
+}
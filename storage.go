@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+// Storage persists small named blobs of data such as stats, settings and saved
+// games. Keys are slash-separated paths like "puzzles/alice.json". Loading a
+// key that was never saved returns an error wrapping fs.ErrNotExist.
+type Storage interface {
+	Load(key string) ([]byte, error)
+	Save(key string, data []byte) error
+	Delete(key string) error
+	List(dir string) ([]string, error) // Keys directly inside dir, sorted
+}
+
+// memoryStorage keeps data in memory only, used when no persistent storage is available
+type memoryStorage struct {
+	data map[string][]byte
+}
+
+// newMemoryStorage creates an empty in-memory storage
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{data: make(map[string][]byte)}
+}
+
+// Load returns a copy of the data saved under key
+func (s *memoryStorage) Load(key string) ([]byte, error) {
+	data, ok := s.data[key]
+	if !ok {
+		return nil, fmt.Errorf("load %s: %w", key, fs.ErrNotExist)
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// Save stores a copy of data under key
+func (s *memoryStorage) Save(key string, data []byte) error {
+	s.data[key] = append([]byte(nil), data...)
+	return nil
+}
+
+// Delete removes key
+func (s *memoryStorage) Delete(key string) error {
+	delete(s.data, key)
+	return nil
+}
+
+// List returns the keys directly inside dir
+func (s *memoryStorage) List(dir string) ([]string, error) {
+	keys := []string{}
+	prefix := dir + "/"
+	for key := range s.data {
+		if strings.HasPrefix(key, prefix) && !strings.Contains(key[len(prefix):], "/") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
@@ -0,0 +1,69 @@
+//go:build !js
+
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// fileStorage keeps each key as a file below a base directory
+type fileStorage struct {
+	dir string
+}
+
+// newStorage returns the platform's default storage, a directory in the
+// user's config folder
+func newStorage() (Storage, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return &fileStorage{dir: filepath.Join(dir, "ConnectFour")}, nil
+}
+
+// path converts a key into a file path inside the storage directory
+func (s *fileStorage) path(key string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(key))
+}
+
+// Load reads the file for key
+func (s *fileStorage) Load(key string) ([]byte, error) {
+	return os.ReadFile(s.path(key))
+}
+
+// Save writes the file for key, creating parent directories as needed
+func (s *fileStorage) Save(key string, data []byte) error {
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Delete removes the file for key
+func (s *fileStorage) Delete(key string) error {
+	return os.Remove(s.path(key))
+}
+
+// List returns the keys of the files directly inside dir
+func (s *fileStorage) List(dir string) ([]string, error) {
+	entries, err := os.ReadDir(s.path(dir))
+	if errors.Is(err, fs.ErrNotExist) {
+		return []string{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	// ReadDir already sorts entries by name
+	keys := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			keys = append(keys, path.Join(dir, entry.Name()))
+		}
+	}
+	return keys, nil
+}
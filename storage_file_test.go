@@ -0,0 +1,9 @@
+//go:build !js
+
+package main
+
+import "testing"
+
+func TestFileStorage(t *testing.T) {
+	testStorage(t, &fileStorage{dir: t.TempDir()})
+}
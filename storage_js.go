@@ -0,0 +1,70 @@
+//go:build js
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"syscall/js"
+)
+
+// Prefix keeping our keys apart from anything else on the page's origin
+const localStoragePrefix = "connectfour/"
+
+// localStorage keeps each key as an entry in the browser's window.localStorage
+type localStorage struct {
+	store js.Value
+}
+
+// newStorage returns the platform's default storage, the browser's localStorage
+func newStorage() (Storage, error) {
+	store := js.Global().Get("localStorage")
+	if store.IsUndefined() || store.IsNull() {
+		return nil, errors.New("localStorage is not available")
+	}
+	return &localStorage{store: store}, nil
+}
+
+// Load reads the entry for key
+func (s *localStorage) Load(key string) ([]byte, error) {
+	value := s.store.Call("getItem", localStoragePrefix+key)
+	if value.IsNull() {
+		return nil, fmt.Errorf("load %s: %w", key, fs.ErrNotExist)
+	}
+	return []byte(value.String()), nil
+}
+
+// Save writes the entry for key
+func (s *localStorage) Save(key string, data []byte) (err error) {
+	// setItem throws when the quota is exceeded
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("save %s: %v", key, r)
+		}
+	}()
+	s.store.Call("setItem", localStoragePrefix+key, string(data))
+	return nil
+}
+
+// Delete removes the entry for key
+func (s *localStorage) Delete(key string) error {
+	s.store.Call("removeItem", localStoragePrefix+key)
+	return nil
+}
+
+// List returns the keys of the entries directly inside dir
+func (s *localStorage) List(dir string) ([]string, error) {
+	keys := []string{}
+	prefix := localStoragePrefix + dir + "/"
+	for i := 0; i < s.store.Get("length").Int(); i++ {
+		key := s.store.Call("key", i).String()
+		if strings.HasPrefix(key, prefix) && !strings.Contains(key[len(prefix):], "/") {
+			keys = append(keys, strings.TrimPrefix(key, localStoragePrefix))
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
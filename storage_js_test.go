@@ -0,0 +1,13 @@
+//go:build js
+
+package main
+
+import "testing"
+
+func TestLocalStorage(t *testing.T) {
+	store, err := newStorage()
+	if err != nil {
+		t.Skipf("no localStorage: %v", err)
+	}
+	testStorage(t, store)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"slices"
+	"testing"
+)
+
+// testStorage checks the behaviour every Storage must share, on an empty store
+func testStorage(t *testing.T, store Storage) {
+	t.Helper()
+
+	if _, err := store.Load("stats/alice.json"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("loading a missing key: got %v, want fs.ErrNotExist", err)
+	}
+	if keys, err := store.List("stats"); err != nil || len(keys) != 0 {
+		t.Errorf("listing an empty dir: got %v, %v, want no keys", keys, err)
+	}
+
+	for key, data := range map[string]string{
+		"settings.json":         `{"tps":60}`,
+		"stats/bob.json":        "bob",
+		"stats/alice.json":      "alice",
+		"stats/nested/eve.json": "eve",
+	} {
+		if err := store.Save(key, []byte(data)); err != nil {
+			t.Fatalf("saving %s: %v", key, err)
+		}
+	}
+
+	data, err := store.Load("stats/alice.json")
+	if err != nil || string(data) != "alice" {
+		t.Errorf("loading a saved key: got %q, %v, want %q", data, err, "alice")
+	}
+	if err := store.Save("stats/alice.json", []byte("alice again")); err != nil {
+		t.Fatalf("overwriting: %v", err)
+	}
+	if data, _ := store.Load("stats/alice.json"); string(data) != "alice again" {
+		t.Errorf("overwritten key: got %q, want %q", data, "alice again")
+	}
+
+	// Only the keys directly inside the dir, sorted
+	keys, err := store.List("stats")
+	if want := []string{"stats/alice.json", "stats/bob.json"}; err != nil || !slices.Equal(keys, want) {
+		t.Errorf("List(stats) = %v, %v, want %v", keys, err, want)
+	}
+
+	if err := store.Delete("stats/bob.json"); err != nil {
+		t.Fatalf("deleting: %v", err)
+	}
+	if _, err := store.Load("stats/bob.json"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("loading a deleted key: got %v, want fs.ErrNotExist", err)
+	}
+	if keys, _ := store.List("stats"); !slices.Equal(keys, []string{"stats/alice.json"}) {
+		t.Errorf("List(stats) after delete = %v", keys)
+	}
+}
+
+func TestMemoryStorage(t *testing.T) {
+	testStorage(t, newMemoryStorage())
+}
+
+func TestMemoryStorageCopies(t *testing.T) {
+	store := newMemoryStorage()
+	data := []byte("abc")
+	store.Save("k", data)
+	data[0] = 'x'
+	loaded, _ := store.Load("k")
+	loaded[1] = 'y'
+	if again, _ := store.Load("k"); string(again) != "abc" {
+		t.Errorf("stored data changed through a caller's slice: %q", again)
+	}
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Padding either side of the text in a text input
+const inputPadding = 5
+
+// Key repeat timing for held editing keys
+const (
+	keyRepeatDelay    = 250 * time.Millisecond // Before the first repeat
+	keyRepeatInterval = 50 * time.Millisecond  // Between repeats
+)
+
+// length returns the number of characters in the input
+func (t *TextInput) length() int {
+	return utf8.RuneCountInString(t.value)
+}
+
+// displayText returns the text as drawn, masked for passwords unless revealed
+func (t *TextInput) displayText() string {
+	if t.isPassword && !t.revealed {
+		return strings.Repeat("*", t.length())
+	}
+	return t.value
+}
+
+// textAreaWidth returns the width available for text, leaving room for
+// the reveal button in password inputs
+func (t *TextInput) textAreaWidth() float64 {
+	width := t.w - 2*inputPadding
+	if t.isPassword {
+		width -= t.h
+	}
+	return width
+}
+
+// fits reports whether text fits in the input's text area
+func (t *TextInput) fits(text []rune) bool {
+	return textWidth(string(text)) <= t.textAreaWidth()
+}
+
+// revealContains reports whether a point is on a password input's reveal
+// button, a square at the right end of the input
+func (t *TextInput) revealContains(x, y float64) bool {
+	return t.isPassword && pointInRect(x, y, t.x+t.w-t.h, t.y, t.h, t.h)
+}
+
+// toggleReveal switches a password between masked and plain text. The
+// scroll position is worked out again since the two differ in width.
+func (t *TextInput) toggleReveal() {
+	t.revealed = !t.revealed
+	t.scrollToCursor()
+}
+
+// visibleEnd returns the index after the last character that fits when
+// the text is shown from the scroll position
+func (t *TextInput) visibleEnd() int {
+	runes := []rune(t.displayText())
+	start := min(t.scrollPos, len(runes))
+	end := len(runes)
+	for end > start && !t.fits(runes[start:end]) {
+		end--
+	}
+	return end
+}
+
+// setCursor moves the cursor to a character index, kept within the text
+func (t *TextInput) setCursor(i int) {
+	t.cursor = max(0, min(t.length(), i))
+}
+
+// insert adds text at the cursor and moves the cursor past it. Text past
+// the input's maximum length is dropped.
+func (t *TextInput) insert(s string) {
+	runes := []rune(t.value)
+	t.setCursor(t.cursor)
+	inserted := []rune(s)
+	if t.maxLength > 0 {
+		inserted = inserted[:max(0, min(len(inserted), t.maxLength-len(runes)))]
+	}
+	runes = append(runes[:t.cursor], append(inserted, runes[t.cursor:]...)...)
+	t.value = string(runes)
+	t.cursor += len(inserted)
+}
+
+// backspace deletes the character before the cursor
+func (t *TextInput) backspace() {
+	t.setCursor(t.cursor)
+	if t.cursor == 0 {
+		return
+	}
+	runes := []rune(t.value)
+	t.value = string(append(runes[:t.cursor-1], runes[t.cursor:]...))
+	t.cursor--
+}
+
+// deleteForward deletes the character after the cursor
+func (t *TextInput) deleteForward() {
+	t.setCursor(t.cursor)
+	runes := []rune(t.value)
+	if t.cursor >= len(runes) {
+		return
+	}
+	t.value = string(append(runes[:t.cursor], runes[t.cursor+1:]...))
+}
+
+// scrollToCursor adjusts the scroll position so the cursor stays in view,
+// without leaving empty space at the end once the text has been scrolled
+func (t *TextInput) scrollToCursor() {
+	runes := []rune(t.displayText())
+	t.scrollPos = max(0, min(t.scrollPos, t.cursor))
+	for t.scrollPos < t.cursor && !t.fits(runes[t.scrollPos:t.cursor]) {
+		t.scrollPos++
+	}
+	for t.scrollPos > 0 && t.fits(runes[t.scrollPos-1:]) {
+		t.scrollPos--
+	}
+}
+
+// caretOffset returns the distance from the start of the visible text to the cursor
+func (t *TextInput) caretOffset() float64 {
+	runes := []rune(t.displayText())
+	start := min(t.scrollPos, len(runes))
+	return textWidth(string(runes[start:max(start, min(t.cursor, len(runes)))]))
+}
+
+// cursorAt returns the character index nearest to an x position in the input
+func (t *TextInput) cursorAt(x float64) int {
+	runes := []rune(t.displayText())
+	start := min(t.scrollPos, len(runes))
+	offset := x - t.x - inputPadding
+	best, bestDistance := start, offset
+	if bestDistance < 0 {
+		bestDistance = -bestDistance
+	}
+	for i := start + 1; i <= t.visibleEnd(); i++ {
+		distance := textWidth(string(runes[start:i])) - offset
+		if distance < 0 {
+			distance = -distance
+		}
+		if distance < bestDistance {
+			best, bestDistance = i, distance
+		}
+	}
+	return best
+}
+
+// keyRepeated reports whether a key was just pressed, or has been held long
+// enough to repeat this frame
+func keyRepeated(key ebiten.Key) bool {
+	d := inpututil.KeyPressDuration(key)
+	delay, interval := ticks(keyRepeatDelay), ticks(keyRepeatInterval)
+	return d == 1 || d > delay && (d-delay)%interval == 0
+}
+
+// inputChars is where text inputs learn of typed characters. Tests replace
+// it to type.
+var inputChars = ebiten.AppendInputChars
+
+// updateActiveInput sends this frame's keys to the focused input on the
+// current screen, if there is one
+func (g *ConnectFourGame) updateActiveInput() {
+	g.checkActiveInput()
+	if g.activeInput == nil {
+		return
+	}
+
+	// Typing, deleting and moving the cursor
+	g.updateTextInput(g.activeInput)
+
+	// Enter moves on to the next field, or submits from the last one
+	if action(ActionSubmit) {
+		g.submitInput(g.activeInput)
+	}
+
+	// Escape hands the keys back to the game from the chat
+	if (g.state == StateGame || g.state == StateGameOver) && action(ActionCancel) {
+		g.setFocus(nil)
+	}
+}
+
+// updateTextInput edits the active input: typing inserts at the cursor,
+// Backspace and Delete remove either side of it, and the arrows, Home and
+// End move it
+func (g *ConnectFourGame) updateTextInput(input *TextInput) {
+	value := input.value
+	changed := false
+	if runes := inputChars(nil); len(runes) > 0 {
+		input.insert(string(runes))
+		changed = true
+	}
+	switch {
+	case actionRepeated(ActionBackspace):
+		input.backspace()
+		changed = true
+	case actionRepeated(ActionDelete):
+		input.deleteForward()
+		changed = true
+	case actionRepeated(ActionLeft):
+		input.setCursor(input.cursor - 1)
+		changed = true
+	case actionRepeated(ActionRight):
+		input.setCursor(input.cursor + 1)
+		changed = true
+	case action(ActionHome):
+		input.setCursor(0)
+		changed = true
+	case action(ActionEnd):
+		input.setCursor(input.length())
+		changed = true
+	}
+	if input.value != value {
+		input.err = "" // The user is fixing it
+	}
+	if changed {
+		g.resetCaretBlink()
+		input.scrollToCursor()
+	}
+}
+
+// submitInput handles Enter in a text input: it moves the focus to the next
+// input on the screen, and submits the form from the last one
+func (g *ConnectFourGame) submitInput(input *TextInput) {
+	for i, other := range g.textInputs {
+		if other == input && i+1 < len(g.textInputs) {
+			g.setFocus(g.textInputs[i+1])
+			return
+		}
+	}
+	switch g.state {
+	case StateLogin:
+		g.login()
+	case StateRegister:
+		g.createAccount()
+	case StateTournament:
+		g.addTournamentPlayer()
+	case StateOnline:
+		g.connectToServer()
+	case StateLoadPosition:
+		g.loadPosition()
+	case StateGame, StateGameOver:
+		g.sendChat(input)
+	}
+}
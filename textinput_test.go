@@ -0,0 +1,213 @@
+package main
+
+import (
+	"testing"
+
+	"golang.org/x/image/font/basicfont"
+)
+
+// testInput returns an input 10 characters wide, measuring text in
+// basicfont until the test ends whichever face earlier tests left
+func testInput(t *testing.T) *TextInput {
+	face := fontFace
+	fontFace = basicfont.Face7x13
+	t.Cleanup(func() { fontFace = face })
+	return &TextInput{w: 2*inputPadding + 70, h: 26}
+}
+
+// Edits as updateTextInput makes them for each key
+var (
+	typeText   = func(s string) func(*TextInput) { return func(t *TextInput) { t.insert(s) } }
+	pressLeft  = func(t *TextInput) { t.setCursor(t.cursor - 1) }
+	pressRight = func(t *TextInput) { t.setCursor(t.cursor + 1) }
+	pressHome  = func(t *TextInput) { t.setCursor(0) }
+	pressEnd   = func(t *TextInput) { t.setCursor(t.length()) }
+	pressBack  = func(t *TextInput) { t.backspace() }
+	pressDel   = func(t *TextInput) { t.deleteForward() }
+)
+
+func TestTextInputEditing(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		keys   []func(*TextInput)
+		value  string
+		cursor int
+	}{
+		{"typing", []func(*TextInput){typeText("ab"), typeText("c")}, "abc", 3},
+		{"insert mid-string", []func(*TextInput){typeText("ac"), pressLeft, typeText("b")}, "abc", 2},
+		{"insert at start", []func(*TextInput){typeText("bc"), pressHome, typeText("a")}, "abc", 1},
+		{"backspace at end", []func(*TextInput){typeText("abc"), pressBack}, "ab", 2},
+		{"backspace mid-string", []func(*TextInput){typeText("abxc"), pressLeft, pressBack}, "abc", 2},
+		{"backspace at start", []func(*TextInput){typeText("abc"), pressHome, pressBack}, "abc", 0},
+		{"delete forward", []func(*TextInput){typeText("xabc"), pressHome, pressDel}, "abc", 0},
+		{"delete at end", []func(*TextInput){typeText("abc"), pressDel}, "abc", 3},
+		{"left stops at start", []func(*TextInput){typeText("ab"), pressLeft, pressLeft, pressLeft}, "ab", 0},
+		{"right stops at end", []func(*TextInput){typeText("ab"), pressHome, pressRight, pressRight, pressRight}, "ab", 2},
+		{"home then end", []func(*TextInput){typeText("abc"), pressHome, pressEnd}, "abc", 3},
+		{"multi-byte characters", []func(*TextInput){typeText("ñañ"), pressLeft, pressBack, typeText("ü")}, "ñüñ", 2},
+	} {
+		input := testInput(t)
+		for _, key := range tt.keys {
+			key(input)
+			input.scrollToCursor()
+		}
+		if input.value != tt.value || input.cursor != tt.cursor {
+			t.Errorf("%s: value %q cursor %d, want %q cursor %d",
+				tt.name, input.value, input.cursor, tt.value, tt.cursor)
+		}
+	}
+}
+
+func TestTextInputMaxLength(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		maxLength int
+		keys      []func(*TextInput)
+		value     string
+		cursor    int
+	}{
+		{"no limit", 0, []func(*TextInput){typeText("abcdefgh")}, "abcdefgh", 8},
+		{"up to the limit", 4, []func(*TextInput){typeText("abcd")}, "abcd", 4},
+		{"past the limit", 4, []func(*TextInput){typeText("abcdef")}, "abcd", 4},
+		{"one at a time", 2, []func(*TextInput){typeText("a"), typeText("b"), typeText("c")}, "ab", 2},
+		{"mid-string when full", 3, []func(*TextInput){typeText("abc"), pressHome, typeText("x")}, "abc", 0},
+		{"room after backspace", 3, []func(*TextInput){typeText("abc"), pressBack, typeText("xy")}, "abx", 3},
+		{"counts characters", 3, []func(*TextInput){typeText("ñüéa")}, "ñüé", 3},
+	} {
+		input := testInput(t)
+		input.maxLength = tt.maxLength
+		for _, key := range tt.keys {
+			key(input)
+		}
+		if input.value != tt.value || input.cursor != tt.cursor {
+			t.Errorf("%s: value %q cursor %d, want %q cursor %d",
+				tt.name, input.value, input.cursor, tt.value, tt.cursor)
+		}
+	}
+}
+
+func TestTextInputScrollsToTheCursor(t *testing.T) {
+	input := testInput(t)
+	input.insert("abcdefghijklmnop") // 16 characters, 10 fit
+	input.scrollToCursor()
+	if input.scrollPos != 6 {
+		t.Errorf("at the end, scrolled to %d, want the tail shown from 6", input.scrollPos)
+	}
+	for range 16 {
+		pressLeft(input)
+		input.scrollToCursor()
+		if input.cursor < input.scrollPos || input.cursor > input.visibleEnd() {
+			t.Fatalf("cursor %d out of view %d-%d", input.cursor, input.scrollPos, input.visibleEnd())
+		}
+	}
+	if input.scrollPos != 0 {
+		t.Errorf("at the start, scrolled to %d, want 0", input.scrollPos)
+	}
+	pressEnd(input)
+	input.scrollToCursor()
+	if input.scrollPos != 6 {
+		t.Errorf("back at the end, scrolled to %d, want 6", input.scrollPos)
+	}
+}
+
+func TestPasswordRevealSurvivesRelayout(t *testing.T) {
+	g := newConnectFourGame(newMemoryStorage())
+	g.transitionTo(StateLogin)
+	g.textInputs[1].insert("secret")
+	g.textInputs[1].toggleReveal()
+
+	g.screenWidth, g.screenHeight = 1000, 800
+	g.updateLayout()
+	g.relayout()
+	if password := g.textInputs[1]; !password.revealed || password.displayText() != "secret" {
+		t.Errorf("after a resize the password shows as %q", password.displayText())
+	}
+}
+
+// typeChars types s into whichever input has the focus until the test ends
+func typeChars(t *testing.T, s string) {
+	t.Helper()
+	saved := inputChars
+	inputChars = func(runes []rune) []rune { return append(runes, []rune(s)...) }
+	t.Cleanup(func() { inputChars = saved })
+}
+
+func TestTypingReachesOnlyTheCurrentScreen(t *testing.T) {
+	g := newConnectFourGame(newMemoryStorage())
+	g.transitionTo(StateLogin)
+	login := g.textInputs
+
+	typeChars(t, "alice")
+	g.updateActiveInput()
+	if login[0].value != "alice" {
+		t.Fatalf("username typed as %q, want alice", login[0].value)
+	}
+
+	// Typing on the next screen goes into its first input alone
+	g.openRegister()
+	typeChars(t, "bob")
+	g.updateActiveInput()
+	if g.textInputs[0].value != "bob" {
+		t.Errorf("register username typed as %q, want bob", g.textInputs[0].value)
+	}
+	for i, input := range g.textInputs[1:] {
+		if input.value != "" {
+			t.Errorf("register input %d typed into: %q", i+1, input.value)
+		}
+	}
+
+	// An input left focused from the last screen gets nothing
+	g.activeInput = login[1]
+	g.updateActiveInput()
+	if g.activeInput != nil {
+		t.Error("input from another screen kept the focus")
+	}
+	for i, want := range []string{"alice", ""} {
+		if login[i].value != want {
+			t.Errorf("login input %d changed to %q off screen, want %q", i, login[i].value, want)
+		}
+	}
+}
+
+func TestPasswordReveal(t *testing.T) {
+	in := testInput(t)
+	in.isPassword = true
+	in.insert("hunter2")
+	if got := in.displayText(); got != "*******" {
+		t.Errorf("masked password shown as %q", got)
+	}
+
+	in.toggleReveal()
+	if got := in.displayText(); got != "hunter2" {
+		t.Errorf("revealed password shown as %q", got)
+	}
+	if in.value != "hunter2" || in.cursor != 7 {
+		t.Errorf("revealing changed the input to %q with the cursor at %d", in.value, in.cursor)
+	}
+	in.toggleReveal()
+	if got := in.displayText(); got != "*******" {
+		t.Errorf("hidden again, password shown as %q", got)
+	}
+
+	// The button is the square at the right end, on password inputs only
+	in.x, in.y = 100, 50
+	for _, tt := range []struct {
+		name string
+		x, y float64
+		want bool
+	}{
+		{"on the button", in.x + in.w - in.h/2, in.y + in.h/2, true},
+		{"button's left edge", in.x + in.w - in.h, in.y, true},
+		{"just left of the button", in.x + in.w - in.h - 0.5, in.y + in.h/2, false},
+		{"in the text", in.x + 10, in.y + in.h/2, false},
+		{"right of the input", in.x + in.w, in.y + in.h/2, false},
+	} {
+		if got := in.revealContains(tt.x, tt.y); got != tt.want {
+			t.Errorf("%s: revealContains = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	plain := &TextInput{x: in.x, y: in.y, w: in.w, h: in.h}
+	if plain.revealContains(in.x+in.w-in.h/2, in.y+in.h/2) {
+		t.Error("plain input has a reveal button")
+	}
+}
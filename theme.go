@@ -0,0 +1,69 @@
+package main
+
+import "image/color"
+
+// Theme is a set of interface colors
+type Theme struct {
+	ID         string // Saved in the settings
+	Name       string // Catalog key naming the theme
+	Background color.RGBA
+	Empty      color.RGBA
+	Button     color.RGBA
+	ButtonText color.RGBA
+	Text       color.RGBA
+	BoardBg    color.RGBA
+	SlotBg     color.RGBA
+	TitleText  color.RGBA
+}
+
+// Themes offered on the settings screen; the first is the default
+var themes = []Theme{
+	{
+		ID:         "light",
+		Name:       "theme.light",
+		Background: color.RGBA{240, 240, 240, 255},
+		Empty:      color.RGBA{200, 200, 200, 255},
+		Button:     color.RGBA{100, 100, 220, 255},
+		ButtonText: color.RGBA{255, 255, 255, 255},
+		Text:       color.RGBA{10, 10, 10, 255},
+		BoardBg:    color.RGBA{180, 180, 180, 255},
+		SlotBg:     color.RGBA{220, 220, 220, 255},
+		TitleText:  color.RGBA{50, 50, 220, 255},
+	},
+	{
+		ID:         "dark",
+		Name:       "theme.dark",
+		Background: color.RGBA{30, 32, 40, 255},
+		Empty:      color.RGBA{70, 72, 84, 255},
+		Button:     color.RGBA{80, 80, 170, 255},
+		ButtonText: color.RGBA{240, 240, 240, 255},
+		Text:       color.RGBA{225, 225, 230, 255},
+		BoardBg:    color.RGBA{55, 58, 70, 255},
+		SlotBg:     color.RGBA{90, 94, 108, 255},
+		TitleText:  color.RGBA{140, 140, 255, 255},
+	},
+}
+
+// applyTheme switches the interface colors, falling back to the default
+// theme for unknown ids
+func applyTheme(id string) {
+	theme := themes[themeIndex(id)]
+	colorBackground = theme.Background
+	colorEmpty = theme.Empty
+	colorButton = theme.Button
+	colorButtonText = theme.ButtonText
+	colorText = theme.Text
+	colorBoardBg = theme.BoardBg
+	colorSlotBg = theme.SlotBg
+	colorTitleText = theme.TitleText
+}
+
+// themeIndex returns the position of a theme in themes, or 0 if unknown
+func themeIndex(id string) int {
+	for i, theme := range themes {
+		if theme.ID == id {
+			return i
+		}
+	}
+	return 0
+}
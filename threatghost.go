@@ -0,0 +1,38 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Opacity of the markers on the computer's winning cells
+const threatGhostAlpha = 90
+
+// winningCells returns the cells where side would complete a four with its
+// next piece, one per column at most, since only the landing cell of a
+// column can be played
+func winningCells(board GameBoard, side int) [][2]int {
+	cells := [][2]int{}
+	for _, col := range getValidColumns(board) {
+		if row := landingRow(board, col); completesFour(board, row, col, side) {
+			cells = append(cells, [2]int{row, col})
+		}
+	}
+	return cells
+}
+
+// drawThreatGhosts marks, in assist mode, each cell where the computer would
+// win on its next move, so the player sees exactly what needs blocking.
+// There can be more than one, in which case the player can only block one.
+func (g *ConnectFourGame) drawThreatGhosts(screen *ebiten.Image, originX, originY float64) {
+	if !g.assistActive() || g.dropAnim != nil {
+		return
+	}
+	clr := color.RGBA{g.computerColor.R, g.computerColor.G, g.computerColor.B, threatGhostAlpha}
+	for _, cell := range winningCells(g.board, Computer) {
+		x := int(originX + float64(cell[1])*g.cellSize + g.cellSize/2)
+		y := int(originY + float64(cell[0])*g.cellSize + g.cellSize/2)
+		g.drawSmoothCircle(screen, x, y, g.cellSize*0.38, clr)
+	}
+}
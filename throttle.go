@@ -0,0 +1,52 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// menuTPS is the tick rate quiet screens drop to when throttling is on. It
+// still polls input often enough that clicks and hovering feel immediate.
+const menuTPS = 30
+
+// isQuietState reports whether a screen changes little from frame to frame,
+// so it can run at the lower menu rate
+func isQuietState(state int) bool {
+	switch state {
+	case StateLogin, StateGameMode, StateGameOver:
+		return true
+	}
+	return false
+}
+
+// throttled reports whether the game should run at the menu rate now: a
+// quiet screen with no piece still falling into place
+func (g *ConnectFourGame) throttled() bool {
+	return g.settings.ThrottleMenus && isQuietState(g.state) && g.dropAnim == nil
+}
+
+// updateTickRate switches between the chosen tick rate and the menu rate as
+// screens change. Timers count down in time rather than ticks, so they last
+// as long across the switch.
+func (g *ConnectFourGame) updateTickRate() {
+	tps := g.settings.TPS
+	if g.throttled() {
+		tps = min(tps, menuTPS)
+	}
+	current := ebiten.TPS()
+	if tps == current {
+		return
+	}
+	ebiten.SetTPS(tps)
+}
+
+// skipDraw reports whether Draw can leave the last frame on screen because
+// nothing has been updated since it was drawn. Only throttled screens skip,
+// which needs the screen to be kept between frames rather than cleared.
+func (g *ConnectFourGame) skipDraw(screen *ebiten.Image) bool {
+	w, h := screen.Bounds().Dx(), screen.Bounds().Dy()
+	if g.throttled() && g.frame == g.drawnFrame && w == g.drawnWidth && h == g.drawnHeight {
+		return true
+	}
+	g.drawnFrame, g.drawnWidth, g.drawnHeight = g.frame, w, h
+	return false
+}
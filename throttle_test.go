@@ -0,0 +1,60 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// TestTimersAcrossThrottling runs timers while the tick rate drops to the
+// menu rate part way through. Each must still last its length in seconds.
+func TestTimersAcrossThrottling(t *testing.T) {
+	defer ebiten.SetTPS(ebiten.TPS())
+	ebiten.SetTPS(120)
+	g := &ConnectFourGame{state: StateGame, settings: Settings{TPS: 120, ThrottleMenus: true}}
+
+	timers := map[string]*time.Duration{
+		"toast":         &g.toastTimer,
+		"move quality":  &g.moveQualityTimer,
+		"legal moves":   &g.legalMovesTimer,
+		"settings save": &g.settingsSaveTimer,
+		"thinking":      &g.thinkingTimer,
+	}
+	lengths := map[string]time.Duration{
+		"toast":         toastDuration,
+		"move quality":  moveQualityShown,
+		"legal moves":   legalMovesShown,
+		"settings save": settingsSaveDelay,
+		"thinking":      thinkingPauseTime,
+	}
+	for name, timer := range timers {
+		*timer = lengths[name]
+	}
+
+	elapsed := 0.0
+	ended := map[string]float64{}
+	for len(ended) < len(timers) {
+		if elapsed >= 0.25 && g.state == StateGame {
+			g.state = StateGameOver
+			g.updateTickRate()
+			if ebiten.TPS() != menuTPS {
+				t.Fatalf("throttled to %d TPS, want %d", ebiten.TPS(), menuTPS)
+			}
+		}
+		elapsed += tickSeconds()
+		for name, timer := range timers {
+			countDown(timer)
+			if _, done := ended[name]; !done && *timer == 0 {
+				ended[name] = elapsed
+			}
+		}
+	}
+
+	for name, at := range ended {
+		if want := lengths[name].Seconds(); math.Abs(at-want) > 1.0/menuTPS {
+			t.Errorf("%s timer lasted %.3fs, want %.3fs", name, at, want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Tick rates offered on the settings screen
+var tickRates = []int{30, 60, 120, 144}
+
+// Tick rate new settings start at, which per-tick amounts such as drop
+// gravity and disc speeds are tuned for
+const baseTPS = 60
+
+// ticksAt converts a duration into a whole number of ticks at tps, at least
+// one so a timer always runs out
+func ticksAt(d time.Duration, tps int) int {
+	return max(1, int(math.Round(d.Seconds()*float64(tps))))
+}
+
+// ticks converts a duration into ticks at the current tick rate, so timers
+// counted in ticks last as long at any rate
+func ticks(d time.Duration) int {
+	return ticksAt(d, ebiten.TPS())
+}
+
+// tickSeconds returns how long one tick lasts at the current tick rate
+func tickSeconds() float64 {
+	return 1 / float64(ebiten.TPS())
+}
+
+// tickDuration is tickSeconds as a Duration. Timers that count down by it
+// each tick last as long at any rate, even when the rate changes while they
+// run. It rounds up, so a timer of a whole number of ticks doesn't need
+// one more to make up nanoseconds lost in the division.
+func tickDuration() time.Duration {
+	tps := time.Duration(ebiten.TPS())
+	return (time.Second + tps - 1) / tps
+}
+
+// baseSteps returns how many base-rate steps the per-tick animations should
+// take this tick: one at 60 TPS, every other tick at 120 and two at 30
+func (g *ConnectFourGame) baseSteps() int {
+	g.stepDebt += float64(baseTPS) / float64(ebiten.TPS())
+	steps := int(g.stepDebt)
+	g.stepDebt -= float64(steps)
+	return steps
+}
+
+// countDown takes a tick's time off a running timer, stopping at zero
+func countDown(timer *time.Duration) {
+	if *timer > 0 {
+		*timer -= tickDuration()
+		if *timer < 0 {
+			*timer = 0
+		}
+	}
+}
+
+// applyTimingSettings sets the tick rate and vsync from the settings
+func applyTimingSettings(settings Settings) {
+	ebiten.SetTPS(settings.TPS)
+	ebiten.SetVsyncEnabled(settings.VSync)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// testRates are the tick rates timing is checked at
+var testRates = []int{30, 60, 120}
+
+// atTPS runs f with the tick rate set to tps
+func atTPS(tps int, f func()) {
+	defer ebiten.SetTPS(ebiten.TPS())
+	ebiten.SetTPS(tps)
+	f()
+}
+
+func TestTicksLastAsLongAtAnyRate(t *testing.T) {
+	durations := []time.Duration{thinkingPauseTime, moveQualityShown, time.Second, 2500 * time.Millisecond}
+	for _, tps := range testRates {
+		atTPS(tps, func() {
+			for _, d := range durations {
+				lasts := float64(ticks(d)) * tickSeconds()
+				if math.Abs(lasts-d.Seconds()) > tickSeconds()/2 {
+					t.Errorf("%s at %d TPS lasts %.3fs", d, tps, lasts)
+				}
+			}
+		})
+	}
+	if got := ticksAt(time.Millisecond, 30); got != 1 {
+		t.Errorf("ticksAt rounds a short timer to %d ticks, want 1", got)
+	}
+}
+
+func TestBaseStepsKeepBaseRate(t *testing.T) {
+	for _, tps := range append(testRates, 144) {
+		atTPS(tps, func() {
+			g := &ConnectFourGame{}
+			steps := 0
+			for range 10 * tps {
+				steps += g.baseSteps()
+			}
+			// Ten seconds of ticks take ten seconds of base-rate steps,
+			// less one still owed when the debt doesn't come out even
+			if steps != 10*baseTPS && steps != 10*baseTPS-1 {
+				t.Errorf("%d TPS took %d base steps in ten seconds, want %d", tps, steps, 10*baseTPS)
+			}
+		})
+	}
+}
+
+// countDown counts a timer down by tickDuration until it runs out,
+// returning the seconds of ticks it took
+func runDown(timer time.Duration) float64 {
+	seconds := 0.0
+	for timer > 0 {
+		timer -= tickDuration()
+		seconds += tickSeconds()
+	}
+	return seconds
+}
+
+func TestDurationTimersAcrossRates(t *testing.T) {
+	for _, tps := range testRates {
+		atTPS(tps, func() {
+			if lasts := runDown(thinkingPauseTime); math.Abs(lasts-thinkingPauseTime.Seconds()) > tickSeconds() {
+				t.Errorf("thinking pause at %d TPS lasts %.3fs", tps, lasts)
+			}
+		})
+	}
+
+	// A timer keeps its length when the rate changes part way through, as
+	// it does when a screen is throttled
+	timer, seconds := time.Second, 0.0
+	for _, tps := range []int{120, 30, 60} {
+		atTPS(tps, func() {
+			for range tps / 4 {
+				timer -= tickDuration()
+				seconds += tickSeconds()
+			}
+		})
+	}
+	atTPS(60, func() { seconds += runDown(timer) })
+	if math.Abs(seconds-1) > 1.0/30 {
+		t.Errorf("a one second timer across rate changes lasts %.3fs", seconds)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// Toggle is a labelled on/off checkbox for boolean settings
+type Toggle struct {
+	x, y, w, h float64 // Whole clickable area: the box followed by the label
+	label      string
+	on         bool
+	focused    bool
+	onChange   func(bool)
+}
+
+// contains reports whether a point is on the toggle's box or label
+func (t *Toggle) contains(x, y float64) bool {
+	return pointInRect(x, y, t.x, t.y, t.w, t.h)
+}
+
+// toggle flips the toggle and reports the new state
+func (t *Toggle) toggle() {
+	t.on = !t.on
+	if t.onChange != nil {
+		t.onChange(t.on)
+	}
+}
+
+// drawToggle renders a toggle as a box, filled when on, followed by its label
+func (g *ConnectFourGame) drawToggle(screen *ebiten.Image, t *Toggle) {
+	box := t.h
+	if t.focused {
+		drawRect(screen, t.x-2, t.y-2, box+4, box+4, colorText)
+	}
+	drawRect(screen, t.x, t.y, box, box, colorButton)
+	if !t.on {
+		drawRect(screen, t.x+3, t.y+3, box-6, box-6, colorSlotBg)
+	}
+
+	labelBounds := boundString(fontFace, t.label)
+	text.Draw(screen, t.label, fontFace,
+		int(t.x+box+10), int(t.y+box/2)+labelBounds.Dy()/2-1, colorText)
+}
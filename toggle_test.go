@@ -0,0 +1,47 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestToggleFlips(t *testing.T) {
+	var changes []bool
+	tg := &Toggle{onChange: func(on bool) { changes = append(changes, on) }}
+	for _, want := range []bool{true, false, true} {
+		tg.toggle()
+		if tg.on != want {
+			t.Errorf("toggled to %v, want %v", tg.on, want)
+		}
+	}
+	if want := []bool{true, false, true}; !slices.Equal(changes, want) {
+		t.Errorf("onChange called with %v, want %v", changes, want)
+	}
+
+	// A toggle without a handler still flips
+	bare := &Toggle{}
+	bare.toggle()
+	if !bare.on {
+		t.Error("toggle without onChange didn't flip")
+	}
+}
+
+func TestFocusedToggleFlipsOnActivate(t *testing.T) {
+	g := newConnectFourGame(newMemoryStorage())
+	g.state = StateLogin
+	g.initUI()
+	remember := g.toggles[0]
+	was := g.rememberMe
+
+	g.setFocus(remember)
+	for _, key := range []ebiten.Key{ebiten.KeyEnter, ebiten.KeySpace} {
+		pressKeys(t, key)
+		g.updateFocus()
+		was = !was
+		if remember.on != was || g.rememberMe != was {
+			t.Errorf("after %v toggle is %v and remember me %v, want both %v", key, remember.on, g.rememberMe, was)
+		}
+	}
+}
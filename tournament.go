@@ -0,0 +1,467 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// Limits on the number of players in a tournament
+const (
+	minTournamentPlayers = 2
+	maxTournamentPlayers = 16
+)
+
+// TournamentMatch is one pairing in the bracket. Names are empty until the
+// previous round decides them.
+type TournamentMatch struct {
+	A      string `json:"a"`
+	B      string `json:"b"`
+	Bye    bool   `json:"bye,omitempty"` // A has no opponent and goes through unplayed
+	Winner string `json:"winner,omitempty"`
+	Games  int    `json:"games"` // Drawn games so far, each one replayed
+}
+
+// ready reports whether both players are known and the match is unplayed
+func (m *TournamentMatch) ready() bool {
+	return m.A != "" && m.B != "" && m.Winner == ""
+}
+
+// sides returns the players in move order. The first player alternates
+// after every drawn game.
+func (m *TournamentMatch) sides() [2]string {
+	if m.Games%2 == 1 {
+		return [2]string{m.B, m.A}
+	}
+	return [2]string{m.A, m.B}
+}
+
+// Tournament is a single-elimination bracket. Rounds[0] holds the first
+// round's matches and each later round has half as many, down to the final.
+type Tournament struct {
+	Players []string            `json:"players"`
+	Rounds  [][]TournamentMatch `json:"rounds"`
+}
+
+// errInvalidBracket rejects a saved tournament whose rounds aren't a
+// bracket for its players
+var errInvalidBracket = errors.New("invalid bracket")
+
+// bracketSize returns the size of the field for n players, padded with byes
+// to a power of two
+func bracketSize(n int) int {
+	size := 2
+	for size < n {
+		size *= 2
+	}
+	return size
+}
+
+// bracketOrder returns the seeds, counting from 0, in the order they stand
+// in the first round of a bracket of size. Each pair meets in the first
+// round, and the top two seeds can only meet in the final, the top four
+// only in the semi-finals, and so on.
+func bracketOrder(size int) []int {
+	order := []int{0}
+	for len(order) < size {
+		// Each seed is joined by the one that makes their sum the same
+		next := make([]int, 0, 2*len(order))
+		for _, seed := range order {
+			next = append(next, seed, 2*len(order)-1-seed)
+		}
+		order = next
+	}
+	return order
+}
+
+// newTournament creates a bracket for the players in seeding order. The
+// field is padded to a power of two with byes, which go to the top seeds.
+func newTournament(players []string) *Tournament {
+	size := bracketSize(len(players))
+	order := bracketOrder(size)
+
+	t := &Tournament{Players: players}
+	first := make([]TournamentMatch, size/2)
+	for i := range first {
+		// The better seed comes first, and its opponent may not exist
+		first[i].A = players[order[2*i]]
+		if opponent := order[2*i+1]; opponent < len(players) {
+			first[i].B = players[opponent]
+		} else {
+			first[i].Bye = true
+		}
+	}
+	t.Rounds = append(t.Rounds, first)
+	for matches := size / 4; matches >= 1; matches /= 2 {
+		t.Rounds = append(t.Rounds, make([]TournamentMatch, matches))
+	}
+	t.advance()
+	return t
+}
+
+// advance settles byes and moves winners into the next round's matches
+func (t *Tournament) advance() {
+	for round, matches := range t.Rounds {
+		for i := range matches {
+			match := &matches[i]
+			if match.Bye {
+				match.Winner = match.A
+			}
+			if match.Winner == "" || round+1 == len(t.Rounds) {
+				continue
+			}
+			next := &t.Rounds[round+1][i/2]
+			if i%2 == 0 {
+				next.A = match.Winner
+			} else {
+				next.B = match.Winner
+			}
+		}
+	}
+}
+
+// nextMatch returns the earliest match still to be played, or nil once the
+// tournament is over
+func (t *Tournament) nextMatch() *TournamentMatch {
+	for _, matches := range t.Rounds {
+		for i := range matches {
+			if matches[i].ready() {
+				return &matches[i]
+			}
+		}
+	}
+	return nil
+}
+
+// record stores the result of a game in a match. An empty winner is a draw,
+// which the players replay with the first move swapped.
+func (t *Tournament) record(match *TournamentMatch, winner string) {
+	if winner == "" {
+		match.Games++
+		return
+	}
+	match.Winner = winner
+	t.advance()
+}
+
+// validate checks the rounds are a bracket for the players: a first round
+// of half the padded field, then each round half the one before, down to a
+// final of one match
+func (t *Tournament) validate() error {
+	n := len(t.Players)
+	if n < minTournamentPlayers || n > maxTournamentPlayers || len(t.Rounds) == 0 ||
+		len(t.Rounds[0]) != bracketSize(n)/2 {
+		return errInvalidBracket
+	}
+	for round := 1; round < len(t.Rounds); round++ {
+		if 2*len(t.Rounds[round]) != len(t.Rounds[round-1]) {
+			return errInvalidBracket
+		}
+	}
+	if len(t.Rounds[len(t.Rounds)-1]) != 1 {
+		return errInvalidBracket
+	}
+	return nil
+}
+
+// champion returns the winner of the final, or "" while it is undecided
+func (t *Tournament) champion() string {
+	return t.Rounds[len(t.Rounds)-1][0].Winner
+}
+
+// tournamentKey returns the storage key holding a user's tournament
+func tournamentKey(username string) string {
+	return "tournaments/" + userFileName(username) + ".json"
+}
+
+// loadTournament reads a user's tournament in progress, or nil if there is none
+func loadTournament(store Storage, username string) *Tournament {
+	data, err := store.Load(tournamentKey(username))
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("loading tournament: %v", err)
+		}
+		return nil
+	}
+	var t Tournament
+	if err := json.Unmarshal(data, &t); err != nil {
+		log.Printf("loading tournament: %v", err)
+		return nil
+	}
+	if err := t.validate(); err != nil {
+		log.Printf("loading tournament: %v", err)
+		return nil
+	}
+	return &t
+}
+
+// saveTournament writes a user's tournament to storage
+func saveTournament(store Storage, username string, t *Tournament) error {
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	return store.Save(tournamentKey(username), data)
+}
+
+// openTournament shows the current user's bracket, or the setup screen if
+// they have no tournament in progress
+func (g *ConnectFourGame) openTournament() {
+	g.tournament = loadTournament(g.userStore, g.username)
+	g.transitionTo(StateTournament)
+}
+
+// addTournamentPlayer adds the entered name to the setup list
+func (g *ConnectFourGame) addTournamentPlayer() {
+	input := g.textInputs[0]
+	name := strings.TrimSpace(input.value)
+	switch {
+	case name == "":
+		return
+	case len(g.tournamentPlayers) >= maxTournamentPlayers:
+		g.showToast(tr("tournament.full", maxTournamentPlayers))
+		return
+	case slices.Contains(g.tournamentPlayers, name):
+		g.showToast(tr("tournament.duplicate", name))
+		return
+	}
+	g.tournamentPlayers = append(g.tournamentPlayers, name)
+	input.value = ""
+	input.setCursor(0)
+}
+
+// startTournament creates the bracket from the entered players
+func (g *ConnectFourGame) startTournament() {
+	if len(g.tournamentPlayers) < minTournamentPlayers {
+		g.showToast(tr("tournament.too_few", minTournamentPlayers))
+		return
+	}
+	g.tournament = newTournament(g.tournamentPlayers)
+	g.tournamentPlayers = nil
+	if err := saveTournament(g.userStore, g.username, g.tournament); err != nil {
+		log.Printf("saving tournament: %v", err)
+	}
+	g.initUI()
+}
+
+// playTournamentMatch starts a two-player game for the next match
+func (g *ConnectFourGame) playTournamentMatch() {
+	match := g.tournament.nextMatch()
+	if match == nil {
+		return
+	}
+	g.startNewGame()
+	g.hotseat = true
+	g.sideNames = match.sides()
+	g.tournamentMatch = match
+}
+
+// endTwoPlayerGame finishes a game between two people at this computer,
+// recording the result in the tournament it belongs to
+func (g *ConnectFourGame) endTwoPlayerGame(winner int) {
+	name := ""
+	if winner != Empty {
+		name = g.sideNames[winner-Player]
+	}
+	if name == "" {
+		g.gameResult = tr("result.tie")
+	} else {
+		g.gameResult = tr("tournament.wins", name)
+	}
+
+	if g.tournamentMatch != nil {
+		g.tournament.record(g.tournamentMatch, name)
+		if err := saveTournament(g.userStore, g.username, g.tournament); err != nil {
+			log.Printf("saving tournament: %v", err)
+		}
+		if name == "" {
+			g.gameResult = tr("tournament.replay")
+		}
+	}
+
+	g.finishedGame = nil
+	g.gameInProgress = false
+	g.transitionTo(StateGameOver)
+}
+
+// initTournamentUI creates the setup form, or the bracket controls once the
+// tournament has started
+func (g *ConnectFourGame) initTournamentUI() {
+	// Back button
+	g.buttons = append(g.buttons, &Button{
+		x:    float64(g.screenWidth) - 120*g.scaleX,
+		y:    20 * g.scaleY,
+		w:    100 * g.scaleX,
+		h:    30 * g.scaleY,
+		text: tr("common.back"),
+		action: func() {
+			g.transitionTo(StateGameMode)
+		},
+	})
+
+	if g.tournament == nil {
+		g.textInputs = append(g.textInputs, &TextInput{
+			x:           float64(g.screenWidth)/2 - 150*g.scaleX,
+			y:           120 * g.scaleY,
+			w:           200 * g.scaleX,
+			h:           30 * g.scaleY,
+			label:       tr("tournament.player_name"),
+			placeholder: tr("tournament.player_placeholder"),
+		})
+		g.buttons = append(g.buttons, &Button{
+			x:      float64(g.screenWidth)/2 + 60*g.scaleX,
+			y:      120 * g.scaleY,
+			w:      90 * g.scaleX,
+			h:      30 * g.scaleY,
+			text:   tr("tournament.add"),
+			action: g.addTournamentPlayer,
+		})
+		g.buttons = append(g.buttons, &Button{
+			x:      float64(g.screenWidth)/2 - 150*g.scaleX,
+			y:      float64(g.screenHeight) - 70*g.scaleY,
+			w:      140 * g.scaleX,
+			h:      40 * g.scaleY,
+			text:   tr("tournament.start"),
+			action: g.startTournament,
+		})
+		g.buttons = append(g.buttons, &Button{
+			x:    float64(g.screenWidth)/2 + 10*g.scaleX,
+			y:    float64(g.screenHeight) - 70*g.scaleY,
+			w:    140 * g.scaleX,
+			h:    40 * g.scaleY,
+			text: tr("tournament.clear"),
+			action: func() {
+				g.tournamentPlayers = nil
+			},
+		})
+		return
+	}
+
+	if match := g.tournament.nextMatch(); match != nil {
+		g.buttons = append(g.buttons, &Button{
+			x:      float64(g.screenWidth)/2 - 160*g.scaleX,
+			y:      float64(g.screenHeight) - 60*g.scaleY,
+			w:      200 * g.scaleX,
+			h:      40 * g.scaleY,
+			text:   tr("tournament.play_match", match.A, match.B),
+			action: g.playTournamentMatch,
+		})
+	}
+	g.buttons = append(g.buttons, &Button{
+		x:    float64(g.screenWidth)/2 + 50*g.scaleX,
+		y:    float64(g.screenHeight) - 60*g.scaleY,
+		w:    110 * g.scaleX,
+		h:    40 * g.scaleY,
+		text: tr("tournament.new"),
+		action: func() {
+			g.confirm(tr("tournament.new_confirm"), tr("tournament.new"), tr("register.cancel"), func() {
+				if err := g.userStore.Delete(tournamentKey(g.username)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+					log.Printf("deleting tournament: %v", err)
+				}
+				g.tournament = nil
+				g.initUI()
+			})
+		},
+	})
+}
+
+// drawTournamentScreen renders the setup list or the bracket
+func (g *ConnectFourGame) drawTournamentScreen(screen *ebiten.Image) {
+	title := tr("tournament.title")
+	if g.tournament != nil {
+		if champion := g.tournament.champion(); champion != "" {
+			title = tr("tournament.champion", champion)
+		}
+	}
+	titleBounds := boundString(fontFace, title)
+	text.Draw(screen, title, fontFace,
+		g.screenWidth/2-titleBounds.Dx()/2, int(70*g.scaleY), colorText)
+
+	if g.tournament == nil {
+		// Entered players, in two columns
+		for i, name := range g.tournamentPlayers {
+			x := float64(g.screenWidth)/2 - 150*g.scaleX + float64(i/(maxTournamentPlayers/2))*160*g.scaleX
+			y := (190 + float64(i%(maxTournamentPlayers/2))*24) * g.scaleY
+			text.Draw(screen, tr("tournament.seed", i+1, name), fontFace, int(x), int(y), colorText)
+		}
+		for _, input := range g.textInputs {
+			g.drawTextInput(screen, input)
+		}
+	} else {
+		g.drawBracket(screen)
+	}
+
+	for _, btn := range g.buttons {
+		g.drawButton(screen, btn)
+	}
+}
+
+// drawBracket renders each round as a column of matches, joined to the
+// match their winners play next
+func (g *ConnectFourGame) drawBracket(screen *ebiten.Image) {
+	rounds := g.tournament.Rounds
+	left := 20 * g.scaleX
+	top := 100 * g.scaleY
+	columnWidth := (float64(g.screenWidth) - 2*left) / float64(len(rounds))
+	height := float64(g.screenHeight) - top - 80*g.scaleY
+	boxWidth := columnWidth - 20*g.scaleX
+	lineHeight := 16.0
+
+	next := g.tournament.nextMatch()
+	for round, matches := range rounds {
+		x := left + float64(round)*columnWidth
+		slot := height / float64(len(matches))
+		for i := range matches {
+			match := &matches[i]
+			centerY := top + (float64(i)+0.5)*slot
+
+			// Line to the next round's match
+			if round+1 < len(rounds) {
+				nextY := top + (float64(i/2)+0.5)*height/float64(len(rounds[round+1]))
+				midX := x + boxWidth + 10*g.scaleX
+				drawLine(screen, x+boxWidth, centerY, midX, centerY, colorText)
+				drawLine(screen, midX, centerY, midX, nextY, colorText)
+				drawLine(screen, midX, nextY, x+columnWidth, nextY, colorText)
+			}
+
+			boxY := centerY - lineHeight
+			drawRect(screen, x, boxY, boxWidth, 2*lineHeight, colorSlotBg)
+			if match == next {
+				drawRect(screen, x, boxY, 3, 2*lineHeight, colorButton)
+			}
+			for side, name := range []string{match.A, match.B} {
+				if name == "" {
+					name = "-"
+					if side == 1 && match.Bye {
+						name = tr("tournament.bye")
+					}
+				}
+				nameY := boxY + float64(side)*lineHeight
+				clr := colorText
+				if name == match.Winner {
+					drawRect(screen, x, nameY, boxWidth, lineHeight, colorButton)
+					clr = colorButtonText
+				}
+				text.Draw(screen, g.fitText(name, boxWidth-10), fontFace, int(x)+5, int(nameY+lineHeight)-4, clr)
+			}
+		}
+	}
+}
+
+// fitText shortens s with an ellipsis so it is no wider than width
+func (g *ConnectFourGame) fitText(s string, width float64) string {
+	if textWidth(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && textWidth(string(runes)+"...") > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestBracketSeparatesTheTopSeeds(t *testing.T) {
+	for _, tt := range []struct {
+		size int
+		want []int
+	}{
+		{2, []int{0, 1}},
+		{4, []int{0, 3, 1, 2}},
+		{8, []int{0, 7, 3, 4, 1, 6, 2, 5}},
+	} {
+		if got := bracketOrder(tt.size); !slices.Equal(got, tt.want) {
+			t.Errorf("bracketOrder(%d) = %v, want %v", tt.size, got, tt.want)
+		}
+	}
+
+	// Seeds 1 and 2 start in opposite halves, and the byes go to the top
+	players := []string{"s1", "s2", "s3", "s4", "s5", "s6"}
+	tour := newTournament(players)
+	first := tour.Rounds[0]
+	half := len(first) / 2
+	inTop := func(name string) bool {
+		return slices.ContainsFunc(first[:half], func(m TournamentMatch) bool { return m.A == name || m.B == name })
+	}
+	if inTop("s1") == inTop("s2") {
+		t.Errorf("seeds 1 and 2 are in the same half: %+v", first)
+	}
+	for _, m := range first {
+		if m.Bye != (m.A == "s1" || m.A == "s2") {
+			t.Errorf("match %+v: only the top two seeds should have byes", m)
+		}
+	}
+	if semis := tour.Rounds[1]; semis[0].A != "s1" || semis[1].A != "s2" {
+		t.Errorf("byes didn't carry the top seeds through: %+v", semis)
+	}
+}
+
+func TestTournamentPlaysToAChampion(t *testing.T) {
+	tour := newTournament([]string{"a", "b", "c", "d", "e"})
+	for match := tour.nextMatch(); match != nil; match = tour.nextMatch() {
+		tour.record(match, "")
+		tour.record(match, match.A)
+	}
+	if tour.champion() != "a" {
+		t.Errorf("the top seed winning every match made %q champion", tour.champion())
+	}
+}
+
+func TestLoadTournamentRejectsBadBrackets(t *testing.T) {
+	good := newTournament([]string{"a", "b", "c"})
+	for _, tt := range []struct {
+		name  string
+		edit  func(*Tournament)
+		valid bool
+	}{
+		{"as saved", func(*Tournament) {}, true},
+		{"a round too long", func(t *Tournament) { t.Rounds[1] = append(t.Rounds[1], TournamentMatch{}) }, false},
+		{"a round too short", func(t *Tournament) { t.Rounds[0] = t.Rounds[0][:1] }, false},
+		{"no rounds", func(t *Tournament) { t.Rounds = nil }, false},
+		{"a round missing", func(t *Tournament) { t.Rounds = t.Rounds[1:] }, false},
+		{"more players than the first round holds", func(t *Tournament) {
+			t.Players = append(t.Players, "d", "e")
+		}, false},
+		{"too few players", func(t *Tournament) { t.Players = t.Players[:1] }, false},
+	} {
+		data, err := json.Marshal(good)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var tour Tournament
+		if err := json.Unmarshal(data, &tour); err != nil {
+			t.Fatal(err)
+		}
+		tt.edit(&tour)
+		store := newMemoryStorage()
+		if err := saveTournament(store, "alice", &tour); err != nil {
+			t.Fatal(err)
+		}
+
+		loaded := loadTournament(store, "alice")
+		if (loaded != nil) != tt.valid {
+			t.Errorf("%s: loaded %v, want valid %v", tt.name, loaded != nil, tt.valid)
+		}
+		if loaded != nil {
+			// Playing on mustn't index past a round
+			for match := loaded.nextMatch(); match != nil; match = loaded.nextMatch() {
+				loaded.record(match, match.B)
+			}
+		}
+	}
+}
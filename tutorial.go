@@ -0,0 +1,186 @@
+package main
+
+import (
+	"image/color"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// TutorialStep is one page of the tutorial: a caption and the part of the
+// game screen it points at
+type TutorialStep struct {
+	caption   string // Catalog key
+	highlight func(g *ConnectFourGame) (x, y, w, h float64)
+	fourInRow bool // Illustrate the goal with four discs in a row
+}
+
+// boardRect returns the area of the whole board
+func boardRect(g *ConnectFourGame) (x, y, w, h float64) {
+	x, y = g.boardOrigin()
+	return x, y, float64(Columns) * g.cellSize, float64(Rows) * g.cellSize
+}
+
+// tutorialSteps walks a new player through the game screen
+var tutorialSteps = []TutorialStep{
+	{caption: "tutorial.columns", highlight: boardRect},
+	{caption: "tutorial.hover", highlight: func(g *ConnectFourGame) (x, y, w, h float64) {
+		x, y = g.boardOrigin()
+		return x, y, float64(Columns) * g.cellSize, g.cellSize
+	}},
+	{caption: "tutorial.goal", highlight: boardRect, fourInRow: true},
+	{caption: "tutorial.buttons", highlight: func(g *ConnectFourGame) (x, y, w, h float64) {
+		// The Back button, and Play Again which takes its place after a game
+		return float64(g.screenWidth) - 120*g.scaleX, 20 * g.scaleY, 100 * g.scaleX, 30 * g.scaleY
+	}},
+}
+
+// Tutorial is the guided overlay shown over a user's first game
+type Tutorial struct {
+	step    int // Index into tutorialSteps
+	buttons []*Button
+}
+
+// startTutorial shows the tutorial over the current game from the first step
+func (g *ConnectFourGame) startTutorial() {
+	g.tutorial = &Tutorial{}
+	g.layoutTutorial()
+}
+
+// maybeStartTutorial shows the tutorial if the user hasn't seen it yet
+func (g *ConnectFourGame) maybeStartTutorial() {
+	if !g.profile.TutorialDone {
+		g.startTutorial()
+	}
+}
+
+// advanceTutorial moves to the next step, finishing after the last one
+func (g *ConnectFourGame) advanceTutorial() {
+	g.tutorial.step++
+	if g.tutorial.step >= len(tutorialSteps) {
+		g.finishTutorial()
+		return
+	}
+	g.layoutTutorial()
+}
+
+// finishTutorial closes the tutorial and remembers the user has seen it, so
+// it only comes back when asked for from the settings screen
+func (g *ConnectFourGame) finishTutorial() {
+	g.tutorial = nil
+	if g.profile.TutorialDone {
+		return
+	}
+	g.profile.TutorialDone = true
+	if err := saveProfile(g.userStore, g.profile); err != nil {
+		log.Printf("saving profile: %v", err)
+	}
+}
+
+// tutorialBox returns the caption box, placed away from the highlighted area
+func (g *ConnectFourGame) tutorialBox() (x, y, w, h float64) {
+	w = 440 * g.scaleX
+	h = 110 * g.scaleY
+	x = float64(g.screenWidth)/2 - w/2
+	_, highlightY, _, _ := tutorialSteps[g.tutorial.step].highlight(g)
+	if highlightY > h+30*g.scaleY {
+		return x, 20 * g.scaleY, w, h
+	}
+	return x, float64(g.screenHeight) - h - 20*g.scaleY, w, h
+}
+
+// layoutTutorial positions the Next and Skip buttons in the caption box
+func (g *ConnectFourGame) layoutTutorial() {
+	if g.tutorial == nil {
+		return
+	}
+	x, y, w, h := g.tutorialBox()
+	nextText := tr("tutorial.next")
+	if g.tutorial.step == len(tutorialSteps)-1 {
+		nextText = tr("tutorial.done")
+	}
+	g.tutorial.buttons = []*Button{
+		{
+			x:      x + w - 190*g.scaleX,
+			y:      y + h - 40*g.scaleY,
+			w:      85 * g.scaleX,
+			h:      30 * g.scaleY,
+			text:   tr("tutorial.skip"),
+			action: g.finishTutorial,
+		},
+		{
+			x:      x + w - 95*g.scaleX,
+			y:      y + h - 40*g.scaleY,
+			w:      85 * g.scaleX,
+			h:      30 * g.scaleY,
+			text:   nextText,
+			action: g.advanceTutorial,
+		},
+	}
+}
+
+// updateTutorial handles input while the tutorial is showing. Enter or the
+// right arrow moves on and Escape skips the rest.
+func (g *ConnectFourGame) updateTutorial() {
+	if action(ActionSubmit) || action(ActionRight) {
+		g.advanceTutorial()
+		return
+	}
+	if action(ActionCancel) {
+		g.finishTutorial()
+		return
+	}
+
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		x, y := ebiten.CursorPosition()
+		for _, btn := range g.tutorial.buttons {
+			if btn.containsPoint(float64(x), float64(y)) {
+				btn.action()
+				return
+			}
+		}
+	}
+}
+
+// drawTutorial dims everything but the highlighted area and draws the
+// current step's caption
+func (g *ConnectFourGame) drawTutorial(screen *ebiten.Image) {
+	if g.tutorial == nil {
+		return
+	}
+	step := tutorialSteps[g.tutorial.step]
+
+	// Dim around the cut-out, one strip per side
+	hx, hy, hw, hh := step.highlight(g)
+	pad := 6.0
+	hx, hy, hw, hh = hx-pad, hy-pad, hw+2*pad, hh+2*pad
+	sw, sh := float64(g.screenWidth), float64(g.screenHeight)
+	dim := color.RGBA{0, 0, 0, 150}
+	drawRect(screen, 0, 0, sw, hy, dim)
+	drawRect(screen, 0, hy+hh, sw, sh-hy-hh, dim)
+	drawRect(screen, 0, hy, hx, hh, dim)
+	drawRect(screen, hx+hw, hy, sw-hx-hw, hh, dim)
+
+	// Caption box
+	x, y, w, h := g.tutorialBox()
+	drawRect(screen, x-2, y-2, w+4, h+4, colorButton)
+	drawRect(screen, x, y, w, h, colorBackground)
+	progress := tr("tutorial.progress", g.tutorial.step+1, len(tutorialSteps))
+	text.Draw(screen, progress, fontFace, int(x+10), int(y+20*g.scaleY), colorText)
+	text.Draw(screen, tr(step.caption), fontFace, int(x+10), int(y+42*g.scaleY), colorText)
+
+	if step.fourInRow {
+		size := 22 * g.scaleY
+		for i := range 4 {
+			cx := int(x + 10 + size/2 + float64(i)*(size+4))
+			cy := int(y + h - 25*g.scaleY)
+			g.drawSmoothCircle(screen, cx, cy, size/2, g.playerColor)
+		}
+	}
+
+	for _, btn := range g.tutorial.buttons {
+		g.drawButton(screen, btn)
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// tutorialTestGame returns a new user's game with the tutorial showing
+func tutorialTestGame(t *testing.T) (*ConnectFourGame, Storage) {
+	t.Helper()
+	store := newMemoryStorage()
+	g := newConnectFourGame(store)
+	g.username, g.userStore = "alice", store
+	g.profile = Profile{Username: "alice"}
+	g.frame = 1
+	g.startNewGame()
+	g.maybeStartTutorial()
+	if g.tutorial == nil {
+		t.Fatal("tutorial didn't start for a new user")
+	}
+	return g, store
+}
+
+func TestTutorialSteps(t *testing.T) {
+	g, store := tutorialTestGame(t)
+
+	// Enter and the right arrow both move on, in order
+	keys := []ebiten.Key{ebiten.KeyEnter, ebiten.KeyArrowRight, ebiten.KeyEnter}
+	for step, key := range keys {
+		if g.tutorial.step != step {
+			t.Fatalf("on step %d, want %d", g.tutorial.step, step)
+		}
+		next := g.tutorial.buttons[1].text
+		if want := tr("tutorial.next"); next != want {
+			t.Errorf("step %d's button reads %q, want %q", step, next, want)
+		}
+		pressKeys(t, key)
+		g.updateTutorial()
+	}
+
+	// The last step's button finishes instead
+	if g.tutorial.step != len(tutorialSteps)-1 || g.tutorial.buttons[1].text != tr("tutorial.done") {
+		t.Fatalf("on step %d reading %q, want the last step", g.tutorial.step, g.tutorial.buttons[1].text)
+	}
+	g.tutorial.buttons[1].action()
+	if g.tutorial != nil {
+		t.Fatal("tutorial still showing after the last step")
+	}
+
+	// Finishing is saved, so it doesn't show again
+	if profile, _ := loadProfile(store, "alice"); !profile.TutorialDone {
+		t.Error("finishing the tutorial wasn't saved")
+	}
+	g.maybeStartTutorial()
+	if g.tutorial != nil {
+		t.Error("tutorial shown again after being finished")
+	}
+
+	// Asked for again from the settings, it starts over
+	g.startTutorial()
+	if g.tutorial == nil || g.tutorial.step != 0 {
+		t.Error("restarted tutorial doesn't begin at the first step")
+	}
+}
+
+func TestTutorialSkipSaves(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		skip func(g *ConnectFourGame)
+	}{
+		{"Escape", func(g *ConnectFourGame) {
+			pressKeys(t, ebiten.KeyEscape)
+			g.updateTutorial()
+		}},
+		{"Skip button", func(g *ConnectFourGame) { g.tutorial.buttons[0].action() }},
+	} {
+		g, store := tutorialTestGame(t)
+		pressKeys(t, ebiten.KeyEnter)
+		g.updateTutorial()
+		tt.skip(g)
+		if g.tutorial != nil {
+			t.Errorf("%s left the tutorial showing", tt.name)
+		}
+		if profile, _ := loadProfile(store, "alice"); !profile.TutorialDone {
+			t.Errorf("skipping with %s wasn't saved", tt.name)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import "strconv"
+
+// undoUnlimited is the undo limit that allows any number of takebacks
+const undoUnlimited = -1
+
+// Takebacks per game offered in the settings
+var undoLimits = []int{undoUnlimited, 0, 1, 2, 3}
+
+// undoLimitName names an undo limit for the settings dropdown
+func undoLimitName(limit int) string {
+	switch limit {
+	case undoUnlimited:
+		return tr("settings.undo_unlimited")
+	case 0:
+		return tr("settings.undo_none")
+	}
+	return strconv.Itoa(limit)
+}
+
+// undoMoves returns how many of the last moves a takeback removes: the
+// player's last move, and the computer's reply if it has made one, so that
+// it is the player's turn again. In a two-player game it is the last move
+// alone. It returns 0 if there is nothing of the player's to take back.
+func (g *ConnectFourGame) undoMoves() int {
+	if g.hotseat {
+		return min(1, len(g.moves))
+	}
+	for i := len(g.moves) - 1; i >= 0; i-- {
+		if g.moves[i].Player == Player {
+			return len(g.moves) - i
+		}
+	}
+	return 0
+}
+
+// canUndo reports whether a move may be taken back now. Online games can't
+// be, as the opponent would have to agree.
+func (g *ConnectFourGame) canUndo() bool {
+	return g.state == StateGame && g.gameInProgress && g.online == nil && g.dialog == nil &&
+		g.undoBudget != 0 && g.undoMoves() > 0
+}
+
+// takeBack undoes the player's last move, and the computer's reply, using up
+// one of the game's takebacks. A move the computer is thinking about is
+// dropped.
+func (g *ConnectFourGame) takeBack() {
+	if !g.canUndo() {
+		return
+	}
+	g.cancelComputerMove()
+	g.dropAnim = nil
+	g.pendingColumn = -1
+	g.moveQualityTimer = 0
+
+	board := g.board
+	for range g.undoMoves() {
+		last := g.moves[len(g.moves)-1]
+		g.moves = g.moves[:len(g.moves)-1]
+		board.Set(board.columnSpace(last.Column), last.Column, Empty)
+		g.turn = last.Player
+	}
+	g.setBoard(board)
+
+	if g.undoBudget > 0 {
+		g.undoBudget--
+	}
+	g.takebacks++
+	g.initUI()
+}
+
+// undoText labels the undo button with the takebacks left in the game
+func (g *ConnectFourGame) undoText() string {
+	switch {
+	case g.undoBudget == undoUnlimited:
+		return tr("game.undo")
+	case g.undoBudget == 0:
+		return tr("game.undo_none_left")
+	}
+	return tr("game.undo_left", g.undoBudget)
+}
+
+// initUndoUI adds the undo button to the game screen. Games allowing no
+// takebacks at all don't show it, and it is disabled once they are used up.
+func (g *ConnectFourGame) initUndoUI() {
+	if !g.gameInProgress || g.online != nil || (g.undoBudget == 0 && g.takebacks == 0) {
+		return
+	}
+	g.buttons = append(g.buttons, &Button{
+		x:        20 * g.scaleX,
+		y:        60 * g.scaleY,
+		w:        140 * g.scaleX,
+		h:        30 * g.scaleY,
+		text:     g.undoText(),
+		action:   g.takeBack,
+		disabled: g.undoBudget == 0,
+	})
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// Rule sets a game can be played under
+const (
+	VariantStandard = iota // The first four in a row wins
+	VariantCount           // Play goes on until the board is full and the most fours wins
+	numVariants
+)
+
+// Weight of each completed four in evaluateBoard under VariantCount. A four
+// can't be undone, so it outweighs any number of open threats.
+const countWinWeight = 1000
+
+// Rules the engine and the game-end checks follow. It is the standard rules
+// unless a game says otherwise.
+var gameVariant = VariantStandard
+
+// setVariant switches the rules
+func setVariant(variant int) {
+	gameVariant = variant
+}
+
+// variantName returns the display name of a rule set
+func variantName(variant int) string {
+	if variant == VariantCount {
+		return tr("variant.count")
+	}
+	return tr("variant.standard")
+}
+
+// countWins counts player's lines of four. Every line counts, overlapping
+// ones included, so five in a row is two fours and a full column of one
+// side's pieces would be three.
+func countWins(board GameBoard, player int) int {
+	count := 0
+	directions := [4][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}}
+	for row := 0; row < Rows; row++ {
+		for col := 0; col < Columns; col++ {
+			for _, d := range directions {
+				endRow, endCol := row+3*d[0], col+3*d[1]
+				if endRow >= Rows || endCol < 0 || endCol >= Columns {
+					continue
+				}
+				if board[row][col] == player && board[row+d[0]][col+d[1]] == player &&
+					board[row+2*d[0]][col+2*d[1]] == player && board[endRow][endCol] == player {
+					count++
+				}
+			}
+		}
+	}
+	return count
+}
+
+// gameOver reports whether the game on board has finished under the current
+// rules and, if so, who won: Player, Computer or Empty for a tie
+func gameOver(board GameBoard) (winner int, over bool) {
+	return currentRules().gameOver(board)
+}
+
+// gameOver reports whether the game on board has finished under the rules
+// and, if so, who won
+func (r Rules) gameOver(board GameBoard) (winner int, over bool) {
+	if r.Variant == VariantCount {
+		if !isBoardFull(board) {
+			return Empty, false
+		}
+		return countWinner(board), true
+	}
+	switch {
+	case checkWin(board, Player):
+		return Player, true
+	case checkWin(board, Computer):
+		return Computer, true
+	case isBoardFull(board):
+		return Empty, true
+	}
+	return Empty, false
+}
+
+// countWinner returns the side with more fours on board, or Empty if the
+// counts are level
+func countWinner(board GameBoard) int {
+	switch player, computer := countWins(board, Player), countWins(board, Computer); {
+	case player > computer:
+		return Player
+	case computer > player:
+		return Computer
+	}
+	return Empty
+}
+
+// drawFoursTally shows how many fours each side has made, centered at y,
+// when the most fours wins
+func (g *ConnectFourGame) drawFoursTally(screen *ebiten.Image, y int) {
+	if gameVariant != VariantCount {
+		return
+	}
+	names := [2]string{g.username, tr("game.computer")}
+	if g.hotseat || g.online != nil || g.tournamentMatch != nil {
+		names = g.sideNames
+	}
+	tally := tr("variant.tally", names[0], countWins(g.board, Player), names[1], countWins(g.board, Computer))
+	bounds := boundString(fontFace, tally)
+	text.Draw(screen, tally, fontFace, g.screenWidth/2-bounds.Dx()/2, y, colorText)
+}